@@ -5,21 +5,34 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/thanhphuchuynh/dear-future/pkg/adapters/database"
 	"github.com/thanhphuchuynh/dear-future/pkg/composition"
 	"github.com/thanhphuchuynh/dear-future/pkg/config"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
 	"github.com/thanhphuchuynh/dear-future/pkg/mocks"
+	"github.com/thanhphuchuynh/dear-future/pkg/services/scheduler"
+	"github.com/thanhphuchuynh/dear-future/pkg/services/transfer"
 )
 
 func main() {
 	// Define CLI flags
 	var (
-		command    = flag.String("cmd", "help", "Command to execute (help, health, version)")
+		command    = flag.String("cmd", "help", "Command to execute (help, health, version, import, export)")
 		configFile = flag.String("config", "config.yaml", "Path to configuration file")
 		verbose    = flag.Bool("v", false, "Verbose output")
+		userID     = flag.String("user", "", "User ID for import/export")
+		format     = flag.String("format", "jsonl", "Import/export format (jsonl, csv, mbox)")
+		file       = flag.String("file", "-", "Import source or export destination (- for stdin/stdout)")
+		dryRun     = flag.Bool("dry-run", false, "Validate an import without saving messages")
 	)
 	flag.Parse()
 
@@ -42,6 +55,12 @@ func main() {
 		checkHealth(configResult.Value())
 	case "test":
 		runTests()
+	case "import", "export":
+		configResult := config.LoadWithPath(*configFile)
+		if configResult.IsErr() {
+			log.Fatalf("Failed to load configuration: %v", configResult.Error())
+		}
+		runTransfer(configResult.Value(), *command, *userID, *format, *file, *dryRun)
 	default:
 		fmt.Printf("Unknown command: %s\n", *command)
 		showHelp()
@@ -61,17 +80,25 @@ FLAGS:
     -config <path>    Path to configuration file
     -v               Verbose output
     -cmd <command>   Command to execute
+    -user <id>       User ID for import/export
+    -format <name>   Import/export format: jsonl, csv or mbox (default jsonl)
+    -file <path>     Import source or export destination (default stdin/stdout)
+    -dry-run         Validate an import without saving messages
 
 COMMANDS:
     help             Show this help message
     version          Show version information
     health           Check application health
     test             Run functional tests
+    import           Import messages for a user and print a per-row report
+    export           Export all messages of a user
 
 EXAMPLES:
     dear-future-cli --cmd help
     dear-future-cli --cmd health -v
     dear-future-cli --cmd test
+    dear-future-cli --cmd import -user <id> -format csv -file letters.csv -dry-run
+    dear-future-cli --cmd export -user <id> -format mbox -file letters.mbox
 
 For more information, visit: https://github.com/your-username/dear-future`)
 }
@@ -152,6 +179,133 @@ func checkHealth(cfg *config.Config) {
 	}
 }
 
+func runTransfer(cfg *config.Config, command, userIDValue, formatName, path string, dryRun bool) {
+	ctx := context.Background()
+
+	userID, err := uuid.Parse(userIDValue)
+	if err != nil {
+		log.Fatalf("A valid -user ID is required")
+	}
+
+	format := message.ParseTransferFormat(formatName)
+	if format.IsErr() {
+		log.Fatalf("%v", format.Error())
+	}
+
+	if cfg.Database.URL == "" {
+		log.Fatalf("DATABASE_URL is required for %s", command)
+	}
+
+	db, err := database.NewSimplePostgresDB(database.PostgresConfig{
+		DatabaseURL:  cfg.Database.URL,
+		MaxConns:     cfg.Database.MaxConns,
+		MaxIdleConns: cfg.Database.MaxIdleConns,
+		ConnLifetime: cfg.DatabaseConnLifetime,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if command == "export" {
+		out := io.Writer(os.Stdout)
+		if path != "-" {
+			f, err := os.Create(path)
+			if err != nil {
+				log.Fatalf("Failed to create %s: %v", path, err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		exportResult := transfer.NewService(db, nil).Export(ctx, userID, format.Value(), out)
+		if exportResult.IsErr() {
+			log.Fatalf("Export failed: %v", exportResult.Error())
+		}
+		fmt.Fprintf(os.Stderr, "✅ Exported %d messages\n", exportResult.Value())
+		return
+	}
+
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open %s: %v", path, err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	// Queue imported messages with River like the server does; the simple
+	// scheduler polls for due messages so nothing needs to be queued for it
+	var scheduling effects.SchedulingService
+	if pool, err := pgxpool.New(ctx, cfg.Database.URL); err == nil {
+		defer pool.Close()
+		if riverScheduler, err := scheduler.NewRiverScheduler(pool, db, mocks.NewMockEmailService(), nil, cfg); err == nil {
+			scheduling = riverScheduler
+		}
+	}
+
+	// Imported messages count against the user's plan like created ones
+	limits, usage, err := loadPlanUsage(ctx, db, cfg, userID)
+	if err != nil {
+		log.Fatalf("Failed to load plan usage: %v", err)
+	}
+
+	reportResult := transfer.NewService(db, scheduling).Import(ctx, transfer.ImportOptions{
+		UserID: userID,
+		Format: format.Value(),
+		DryRun: dryRun,
+		Limits: limits,
+		Usage:  usage,
+	}, in)
+	if reportResult.IsErr() {
+		log.Fatalf("Import failed: %v", reportResult.Error())
+	}
+
+	report := reportResult.Value()
+	for _, row := range report.Rows {
+		switch row.Status {
+		case transfer.RowFailed:
+			fmt.Printf("❌ row %d: %s\n", row.Row, row.Error)
+		case transfer.RowImported:
+			fmt.Printf("✅ row %d: %s (%s)\n", row.Row, row.Title, row.MessageID)
+		default:
+			fmt.Printf("✅ row %d: %s\n", row.Row, row.Title)
+		}
+	}
+
+	fmt.Printf("\n📊 Import Results: %d rows, %d imported, %d failed", report.Total, report.Imported, report.Failed)
+	if report.DryRun {
+		fmt.Print(" (dry run)")
+	}
+	fmt.Println()
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadPlanUsage loads the limits of the user's plan and their current usage,
+// like the import endpoint does
+func loadPlanUsage(ctx context.Context, db effects.Database, cfg *config.Config, userID uuid.UUID) (user.PlanLimits, user.Usage, error) {
+	profileResult := db.FindUserProfile(ctx, userID)
+	if profileResult.IsErr() {
+		userResult := db.FindUserByID(ctx, userID)
+		if userResult.IsErr() {
+			return user.PlanLimits{}, user.Usage{}, userResult.Error()
+		}
+		profileResult = common.Ok(user.NewUserProfile(userResult.Value()))
+	}
+
+	usageResult := db.FindUserUsage(ctx, userID)
+	if usageResult.IsErr() {
+		return user.PlanLimits{}, user.Usage{}, usageResult.Error()
+	}
+
+	return cfg.PlanLimits(profileResult.Value().Plan()), usageResult.Value(), nil
+}
+
 func runTests() {
 	fmt.Println("🧪 Running Dear Future functional tests...")
 
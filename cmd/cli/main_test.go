@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/config"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/mocks"
+	"github.com/thanhphuchuynh/dear-future/pkg/services/transfer"
+)
+
+// planDatabase serves the profile and usage of one user
+type planDatabase struct {
+	*mocks.MockDatabase
+	profile user.UserProfile
+	usage   user.Usage
+}
+
+func (d planDatabase) FindUserProfile(ctx context.Context, userID uuid.UUID) common.Result[user.UserProfile] {
+	return common.Ok(d.profile)
+}
+
+func (d planDatabase) FindUserUsage(ctx context.Context, userID uuid.UUID) common.Result[user.Usage] {
+	return common.Ok(d.usage)
+}
+
+func TestImportRecurringRowWithPlanUsage(t *testing.T) {
+	userResult := user.NewUser(user.CreateUserRequest{Email: "author@example.com", Name: "Author", Timezone: "UTC", UserID: uuid.New()})
+	if userResult.IsErr() {
+		t.Fatalf("NewUser() error = %v", userResult.Error())
+	}
+
+	cfg := &config.Config{Plans: config.PlansConfig{
+		Free: config.PlanConfig{MaxScheduledMessages: 100, Recurrence: false},
+		Pro:  config.PlanConfig{MaxScheduledMessages: 5000, Recurrence: true},
+	}}
+	deliveryDate := time.Now().AddDate(0, 1, 0).UTC().Format(time.RFC3339)
+	row := `{"title":"Monthly note","content":"Hello again","delivery_date":"` + deliveryDate + `","recurrence":"monthly"}` + "\n"
+
+	tests := []struct {
+		name       string
+		plan       user.Plan
+		wantStatus string
+	}{
+		{"pro plan allows recurrence", user.PlanPro, transfer.RowValid},
+		{"free plan rejects recurrence", user.PlanFree, transfer.RowFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := planDatabase{
+				MockDatabase: mocks.NewMockDatabase(),
+				profile:      user.NewUserProfile(userResult.Value()).WithPlan(tt.plan),
+				usage:        user.Usage{ScheduledMessages: 3},
+			}
+
+			limits, usage, err := loadPlanUsage(context.Background(), db, cfg, userResult.Value().ID())
+			if err != nil {
+				t.Fatalf("loadPlanUsage() error = %v", err)
+			}
+			if limits.Recurrence != (tt.plan == user.PlanPro) || usage.ScheduledMessages != 3 {
+				t.Fatalf("loadPlanUsage() = %+v, %+v", limits, usage)
+			}
+
+			report := transfer.NewService(db, nil).Import(context.Background(), transfer.ImportOptions{
+				UserID: userResult.Value().ID(),
+				Format: message.TransferJSONLines,
+				DryRun: true,
+				Limits: limits,
+				Usage:  usage,
+			}, strings.NewReader(row))
+			if report.IsErr() {
+				t.Fatalf("Import() error = %v", report.Error())
+			}
+			if got := report.Value().Rows[0]; got.Status != tt.wantStatus {
+				t.Errorf("row status = %s (%s), want %s", got.Status, got.Error, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -152,6 +152,7 @@ func createDevelopmentApp(ctx context.Context, appConfig composition.AppConfig)
 			FromName:      cfg.SMTP.FromName,
 			UseTLS:        cfg.SMTP.UseTLS,
 			SkipTLSVerify: cfg.SMTP.SkipTLSVerify,
+			TrackOpens:    cfg.SMTP.TrackOpens,
 		}
 
 		emailService, err := email.NewSMTPEmailService(emailConfig)
@@ -204,13 +205,13 @@ func createDevelopmentApp(ctx context.Context, appConfig composition.AppConfig)
 		if err != nil {
 			log.Printf("❌ Failed to create pgxpool for River: %v", err)
 			log.Println("⚠️  Falling back to simple scheduler")
-			appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, cfg)
+			appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, appConfig.Storage, cfg)
 		} else {
-			riverScheduler, err := scheduler.NewRiverScheduler(pool, appConfig.Database, appConfig.Email, cfg)
+			riverScheduler, err := scheduler.NewRiverScheduler(pool, appConfig.Database, appConfig.Email, appConfig.Storage, cfg)
 			if err != nil {
 				log.Printf("❌ Failed to initialize River scheduler: %v", err)
 				log.Println("⚠️  Falling back to simple scheduler")
-				appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, cfg)
+				appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, appConfig.Storage, cfg)
 			} else {
 				log.Println("✅ River Queue scheduler initialized")
 				appConfig.Scheduling = riverScheduler
@@ -218,7 +219,7 @@ func createDevelopmentApp(ctx context.Context, appConfig composition.AppConfig)
 		}
 	} else {
 		log.Println("⚠️  No database URL, using simple scheduler")
-		appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, cfg)
+		appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, appConfig.Storage, cfg)
 	}
 
 	return composition.NewApp(ctx, appConfig)
@@ -266,6 +267,7 @@ func createProductionApp(ctx context.Context, appConfig composition.AppConfig) c
 			FromName:      cfg.SMTP.FromName,
 			UseTLS:        cfg.SMTP.UseTLS,
 			SkipTLSVerify: cfg.SMTP.SkipTLSVerify,
+			TrackOpens:    cfg.SMTP.TrackOpens,
 		}
 
 		emailService, err := email.NewSMTPEmailService(emailConfig)
@@ -320,13 +322,13 @@ func createProductionApp(ctx context.Context, appConfig composition.AppConfig) c
 		if err != nil {
 			log.Printf("❌ Failed to create pgxpool for River: %v", err)
 			log.Println("⚠️  Falling back to simple scheduler")
-			appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, cfg)
+			appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, appConfig.Storage, cfg)
 		} else {
-			riverScheduler, err := scheduler.NewRiverScheduler(pool, appConfig.Database, appConfig.Email, cfg)
+			riverScheduler, err := scheduler.NewRiverScheduler(pool, appConfig.Database, appConfig.Email, appConfig.Storage, cfg)
 			if err != nil {
 				log.Printf("❌ Failed to initialize River scheduler: %v", err)
 				log.Println("⚠️  Falling back to simple scheduler")
-				appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, cfg)
+				appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, appConfig.Storage, cfg)
 			} else {
 				log.Println("✅ River Queue scheduler initialized")
 				appConfig.Scheduling = riverScheduler
@@ -334,7 +336,7 @@ func createProductionApp(ctx context.Context, appConfig composition.AppConfig) c
 		}
 	} else {
 		log.Println("⚠️  No database URL, using simple scheduler")
-		appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, cfg)
+		appConfig.Scheduling = scheduler.NewSimpleScheduler(appConfig.Database, appConfig.Email, appConfig.Storage, cfg)
 	}
 
 	return composition.NewApp(ctx, appConfig)
@@ -0,0 +1,15 @@
+-- Full-text search for messages
+-- Adds a generated tsvector over subject and content with a GIN index
+
+-- The 'simple' configuration is used because letters are written in many
+-- languages; it lowercases and tokenizes without language-specific stemming.
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(subject, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING GIN (search_vector);
+
+COMMENT ON COLUMN messages.search_vector IS 'Weighted full-text index of subject (A) and content (B)';
@@ -0,0 +1,25 @@
+-- Tags for organizing messages
+-- User-defined tags with a many-to-many link to messages
+
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES user_profiles(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Tag names are unique per user regardless of case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags(user_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS message_tags (
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (message_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_tags_tag_id ON message_tags(tag_id);
+
+COMMENT ON TABLE tags IS 'User-defined labels for grouping messages';
+COMMENT ON TABLE message_tags IS 'Links messages to tags (many-to-many)';
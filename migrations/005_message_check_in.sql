@@ -0,0 +1,12 @@
+-- Check-in messages
+-- An armed message is held until its author misses a check-in. Check-in
+-- settings live in messages.metadata under "check_in".
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_status_check;
+ALTER TABLE messages ADD CONSTRAINT messages_status_check
+    CHECK (status IN ('draft', 'scheduled', 'sent', 'failed', 'cancelled', 'armed'));
+
+-- The check-in evaluator scans armed messages on every run
+CREATE INDEX IF NOT EXISTS idx_messages_armed ON messages(scheduled_for) WHERE status = 'armed';
+
+COMMENT ON COLUMN messages.status IS 'Message status: draft, scheduled, armed, sent, failed, cancelled';
@@ -0,0 +1,17 @@
+-- Delivery receipts
+-- One receipt per recipient of a delivered message, recording when the
+-- email was opened (tracking pixel) and read ("mark as read" link).
+
+CREATE TABLE IF NOT EXISTS delivery_receipts (
+    id UUID PRIMARY KEY,
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    recipient_email VARCHAR(255) NOT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    opened_at TIMESTAMP WITH TIME ZONE,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_receipts_message_id ON delivery_receipts(message_id, delivered_at);
+
+COMMENT ON TABLE delivery_receipts IS 'Per-recipient delivery, open and read times of delivered messages';
@@ -0,0 +1,10 @@
+-- Message trash
+-- Deleting a message moves it to the trash by setting deleted_at. Messages
+-- in the trash are never delivered and are purged after the retention period,
+-- together with their attachment objects.
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_messages_deleted_at ON messages(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN messages.deleted_at IS 'When the message was moved to the trash; NULL for live messages';
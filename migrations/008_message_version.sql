@@ -0,0 +1,8 @@
+-- Message versions
+-- Every update increments the version. It is returned as the ETag of a
+-- message, and updates only apply when the version still matches what the
+-- client read, so concurrent edits cannot silently overwrite each other.
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN messages.version IS 'Revision of the message, incremented on every update';
@@ -0,0 +1,20 @@
+-- Idempotency keys
+-- Requests sent with an Idempotency-Key header are recorded per user with a
+-- hash of the request and the response, so a retried request replays the
+-- stored response instead of creating a second letter or upload.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id UUID NOT NULL REFERENCES user_profiles(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response_headers JSONB NOT NULL DEFAULT '{}'::jsonb,
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+COMMENT ON TABLE idempotency_keys IS 'Stored responses of requests sent with an Idempotency-Key header; status_code 0 while in progress';
@@ -0,0 +1,15 @@
+-- Weekly digests
+-- One row per user and ISO week whose digest was sent, so the hourly digest
+-- job never emails a user twice in the same week.
+
+CREATE TABLE IF NOT EXISTS weekly_digests (
+    user_id UUID NOT NULL REFERENCES user_profiles(id) ON DELETE CASCADE,
+    week VARCHAR(8) NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, week)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_profiles_weekly_digest ON user_profiles(id)
+    WHERE metadata->'notifications'->>'weekly_digest' = 'true';
+
+COMMENT ON TABLE weekly_digests IS 'Weekly digest emails sent per user and ISO week (e.g. 2026-W43)';
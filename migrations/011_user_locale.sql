@@ -0,0 +1,7 @@
+-- User locale
+-- Language of the emails sent to a user, as a BCP 47 primary language
+-- subtag. API error messages follow the Accept-Language header instead.
+
+ALTER TABLE user_profiles ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'en';
+
+COMMENT ON COLUMN user_profiles.locale IS 'Language of emails sent to the user (en, vi)';
@@ -0,0 +1,273 @@
+// Package database provides batch operations for the PostgreSQL adapter
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// maxBatchStatementRows bounds the rows sent in one multi-row statement,
+// keeping the parameter count well below PostgreSQL's limit of 65535
+const maxBatchStatementRows = 500
+
+// messageBatchStatement builds a multi-row statement for a chunk of messages.
+// The statement must return messageColumns for every row it affected.
+type messageBatchStatement func(messages []message.Message) (string, []interface{})
+
+// SaveMessagesBatch inserts messages in one transaction. Messages whose ID
+// already exists are reported as failed instead of being overwritten.
+func (p *SimplePostgresDB) SaveMessagesBatch(ctx context.Context, messages []message.Message) common.Result[[]effects.BatchResult[message.Message]] {
+	return p.runMessageBatch(ctx, messages, insertMessagesStatement, "message already exists")
+}
+
+// UpdateMessagesBatch updates messages in one transaction. Like
+// UpdateMessage, a message is only updated if it is still at the version it
+// was read at; messages changed in the meantime are reported as failed.
+func (p *SimplePostgresDB) UpdateMessagesBatch(ctx context.Context, messages []message.Message) common.Result[[]effects.BatchResult[message.Message]] {
+	return p.runMessageBatch(ctx, messages, updateMessagesStatement, "message not found or modified by another request")
+}
+
+// SaveUsersBatch upserts users in one transaction, reporting each user separately
+func (p *SimplePostgresDB) SaveUsersBatch(ctx context.Context, users []user.User) common.Result[[]effects.BatchResult[user.User]] {
+	results := make([]effects.BatchResult[user.User], 0, len(users))
+	if len(users) == 0 {
+		return common.Ok(results)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return common.Err[[]effects.BatchResult[user.User]](fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	for _, u := range users {
+		var id uuid.UUID
+		var email, name, timezone, locale string
+		var createdAt, updatedAt time.Time
+
+		err := withSavepoint(ctx, tx, func() error {
+			return tx.QueryRowContext(ctx, saveUserQuery,
+				u.ID(), u.Email(), u.Name(), u.Timezone(), string(u.Locale()), u.CreatedAt(), u.UpdatedAt(),
+			).Scan(&id, &email, &name, &timezone, &locale, &createdAt, &updatedAt)
+		})
+		if err != nil {
+			results = append(results, failedBatchResult[user.User](u.ID(), fmt.Errorf("failed to save user: %w", err)))
+			continue
+		}
+
+		saved := userFromDB(id, email, name, timezone, locale, createdAt, updatedAt)
+		if saved.IsErr() {
+			results = append(results, failedBatchResult[user.User](u.ID(), saved.Error()))
+			continue
+		}
+		results = append(results, effects.BatchResult[user.User]{
+			ID:      u.ID().String(),
+			Success: true,
+			Result:  common.Some(saved.Value()),
+			Error:   common.None[string](),
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return common.Err[[]effects.BatchResult[user.User]](fmt.Errorf("failed to commit user batch: %w", err))
+	}
+
+	return common.Ok(results)
+}
+
+// runMessageBatch executes a message statement chunk by chunk inside one
+// transaction. When a chunk fails as a whole (for example one row breaks a
+// constraint), its rows are retried one at a time so that only the offending
+// messages are reported as failed.
+func (p *SimplePostgresDB) runMessageBatch(ctx context.Context, messages []message.Message, build messageBatchStatement, missing string) common.Result[[]effects.BatchResult[message.Message]] {
+	results := make([]effects.BatchResult[message.Message], len(messages))
+	if len(messages) == 0 {
+		return common.Ok(results)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return common.Err[[]effects.BatchResult[message.Message]](fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	offset := 0
+	for _, chunk := range message.BatchMessages(messages, maxBatchStatementRows) {
+		saved, err := execMessageBatch(ctx, tx, build, chunk)
+		if err == nil {
+			for i, msg := range chunk {
+				results[offset+i] = messageBatchResult(msg, saved, nil, missing)
+			}
+		} else {
+			for i, msg := range chunk {
+				single, err := execMessageBatch(ctx, tx, build, []message.Message{msg})
+				results[offset+i] = messageBatchResult(msg, single, err, missing)
+			}
+		}
+		offset += len(chunk)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return common.Err[[]effects.BatchResult[message.Message]](fmt.Errorf("failed to commit message batch: %w", err))
+	}
+
+	return common.Ok(results)
+}
+
+// execMessageBatch runs one statement under a savepoint and returns the
+// affected messages by ID
+func execMessageBatch(ctx context.Context, tx *sql.Tx, build messageBatchStatement, messages []message.Message) (map[uuid.UUID]common.Result[message.Message], error) {
+	query, args := build(messages)
+	saved := make(map[uuid.UUID]common.Result[message.Message], len(messages))
+
+	err := withSavepoint(ctx, tx, func() error {
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			msgResult, err := scanMessage(rows)
+			if err != nil {
+				return err
+			}
+			if msgResult.IsOk() {
+				saved[msgResult.Value().ID()] = msgResult
+			}
+		}
+		return rows.Err()
+	})
+
+	return saved, err
+}
+
+func messageBatchResult(msg message.Message, saved map[uuid.UUID]common.Result[message.Message], err error, missing string) effects.BatchResult[message.Message] {
+	if err != nil {
+		return failedBatchResult[message.Message](msg.ID(), err)
+	}
+
+	msgResult, ok := saved[msg.ID()]
+	if !ok {
+		return failedBatchResult[message.Message](msg.ID(), fmt.Errorf("%s", missing))
+	}
+
+	return effects.BatchResult[message.Message]{
+		ID:      msg.ID().String(),
+		Success: true,
+		Result:  common.Some(msgResult.Value()),
+		Error:   common.None[string](),
+	}
+}
+
+func failedBatchResult[T any](id uuid.UUID, err error) effects.BatchResult[T] {
+	return effects.BatchResult[T]{
+		ID:      id.String(),
+		Success: false,
+		Result:  common.None[T](),
+		Error:   common.Some(err.Error()),
+	}
+}
+
+// withSavepoint runs fn under a savepoint so that a failing statement does not
+// abort the surrounding transaction
+func withSavepoint(ctx context.Context, tx *sql.Tx, fn func() error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item"); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item")
+	return err
+}
+
+// insertMessagesStatement builds a multi-row INSERT for messages
+func insertMessagesStatement(messages []message.Message) (string, []interface{}) {
+	const columns = 9
+	values := make([]string, len(messages))
+	args := make([]interface{}, 0, len(messages)*columns)
+
+	for i, msg := range messages {
+		values[i] = placeholderRow(i*columns, columns, nil)
+		args = append(args,
+			msg.ID(),
+			msg.UserID(),
+			msg.Title(),
+			msg.Content(),
+			msg.DeliveryDate(),
+			toDBStatus(msg.Status()),
+			msg.CreatedAt(),
+			msg.UpdatedAt(),
+			metadataFromMessage(msg).toJSON(),
+		)
+	}
+
+	query := `
+		INSERT INTO messages (id, user_id, subject, content, scheduled_for, status, created_at, updated_at, metadata)
+		VALUES ` + strings.Join(values, ", ") + `
+		ON CONFLICT (id) DO NOTHING
+		RETURNING ` + messageColumns
+
+	return query, args
+}
+
+// updateMessagesStatement builds a single UPDATE ... FROM (VALUES ...) for
+// messages, skipping those no longer at the version they were read at
+func updateMessagesStatement(messages []message.Message) (string, []interface{}) {
+	casts := []string{"uuid", "text", "text", "timestamptz", "text", "jsonb", "bigint"}
+	values := make([]string, len(messages))
+	args := make([]interface{}, 0, len(messages)*len(casts))
+	now := time.Now()
+
+	for i, msg := range messages {
+		values[i] = placeholderRow(i*len(casts), len(casts), casts)
+		args = append(args,
+			msg.ID(),
+			msg.Title(),
+			msg.Content(),
+			msg.DeliveryDate(),
+			toDBStatus(msg.Status()),
+			string(metadataFromMessage(msg).toJSON()),
+			msg.Version(),
+		)
+	}
+
+	query := `
+		UPDATE messages
+		SET subject = v.v_subject, content = v.v_content, scheduled_for = v.v_scheduled_for,
+			status = v.v_status, metadata = v.v_metadata, version = messages.version + 1,
+			updated_at = $` + fmt.Sprint(len(args)+1) + `
+		FROM (VALUES ` + strings.Join(values, ", ") + `) AS v(v_id, v_subject, v_content, v_scheduled_for, v_status, v_metadata, v_version)
+		WHERE messages.id = v.v_id AND messages.version = v.v_version
+		RETURNING ` + messageColumns
+
+	return query, append(args, now)
+}
+
+// placeholderRow renders "($n, $n+1, ...)" starting after offset, with
+// optional type casts per column
+func placeholderRow(offset, columns int, casts []string) string {
+	placeholders := make([]string, columns)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", offset+i+1)
+		if casts != nil {
+			placeholders[i] += "::" + casts[i]
+		}
+	}
+	return "(" + strings.Join(placeholders, ", ") + ")"
+}
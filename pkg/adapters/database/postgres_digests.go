@@ -0,0 +1,145 @@
+// Package database provides weekly digest persistence for the PostgreSQL adapter
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// FindWeeklyDigestRecipients finds users subscribed to the weekly digest
+// whose ID sorts after the given one
+func (p *SimplePostgresDB) FindWeeklyDigestRecipients(ctx context.Context, after uuid.UUID, limit int) common.Result[[]user.UserProfile] {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT id, email, COALESCE(name, ''), COALESCE(timezone, 'UTC'), COALESCE(locale, 'en'), created_at, updated_at,
+			COALESCE(metadata, '{}'::jsonb)
+		FROM user_profiles
+		WHERE metadata->'notifications'->>'weekly_digest' = 'true' AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, after, limit)
+	if err != nil {
+		return common.Err[[]user.UserProfile](fmt.Errorf("failed to find digest recipients: %w", err))
+	}
+	defer rows.Close()
+
+	var profiles []user.UserProfile
+	for rows.Next() {
+		var id uuid.UUID
+		var email, name, timezone, locale string
+		var createdAt, updatedAt time.Time
+		var metadataJSON []byte
+
+		if err := rows.Scan(&id, &email, &name, &timezone, &locale, &createdAt, &updatedAt, &metadataJSON); err != nil {
+			return common.Err[[]user.UserProfile](fmt.Errorf("failed to scan digest recipient: %w", err))
+		}
+
+		userResult := userFromDB(id, email, name, timezone, locale, createdAt, updatedAt)
+		if userResult.IsErr() {
+			return common.Err[[]user.UserProfile](userResult.Error())
+		}
+
+		var meta profileMetadata
+		if err := json.Unmarshal(metadataJSON, &meta); err != nil {
+			return common.Err[[]user.UserProfile](fmt.Errorf("failed to decode user profile: %w", err))
+		}
+
+		profiles = append(profiles, profileFromDB(userResult.Value(), meta))
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[[]user.UserProfile](fmt.Errorf("failed to iterate digest recipients: %w", err))
+	}
+
+	return common.Ok(profiles)
+}
+
+// FindWeeklyDigestMessages finds the letters of a user a digest reports on
+// as of now: scheduled letters due within the period, failed letters and
+// recurring letters delivered within the period before now
+func (p *SimplePostgresDB) FindWeeklyDigestMessages(ctx context.Context, userID uuid.UUID, now time.Time, period time.Duration, limit int) common.Result[[]message.Message] {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE user_id = $1 AND deleted_at IS NULL AND (
+			(status = 'scheduled' AND scheduled_for > $2 AND scheduled_for < $3)
+			OR status = 'failed'
+			OR (COALESCE(metadata->>'recurrence', 'none') <> 'none' AND EXISTS (
+				SELECT 1 FROM delivery_receipts r
+				WHERE r.message_id = messages.id AND r.delivered_at > $4 AND r.delivered_at <= $2
+			))
+		)
+		ORDER BY scheduled_for
+		LIMIT $5
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, userID, now, now.Add(period), now.Add(-period), limit)
+	if err != nil {
+		return common.Err[[]message.Message](fmt.Errorf("failed to find digest messages: %w", err))
+	}
+	defer rows.Close()
+
+	var messages []message.Message
+	for rows.Next() {
+		msgResult, err := scanMessage(rows)
+		if err != nil {
+			return common.Err[[]message.Message](fmt.Errorf("failed to scan message: %w", err))
+		}
+		if msgResult.IsOk() {
+			messages = append(messages, msgResult.Value())
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[[]message.Message](fmt.Errorf("failed to iterate digest messages: %w", err))
+	}
+
+	return common.Ok(messages)
+}
+
+// ClaimWeeklyDigest records the digest of a week for a user unless it was
+// already claimed
+func (p *SimplePostgresDB) ClaimWeeklyDigest(ctx context.Context, userID uuid.UUID, week string, claimedAt time.Time) common.Result[bool] {
+	query := `
+		INSERT INTO weekly_digests (user_id, week, sent_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, week) DO NOTHING
+	`
+
+	result, err := p.db.ExecContext(ctx, query, userID, week, claimedAt)
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to claim weekly digest: %w", err))
+	}
+
+	rows, _ := result.RowsAffected()
+	return common.Ok(rows > 0)
+}
+
+// ReleaseWeeklyDigest forgets the digest of a week so it can be sent again
+func (p *SimplePostgresDB) ReleaseWeeklyDigest(ctx context.Context, userID uuid.UUID, week string) common.Result[bool] {
+	query := `DELETE FROM weekly_digests WHERE user_id = $1 AND week = $2`
+
+	result, err := p.db.ExecContext(ctx, query, userID, week)
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to release weekly digest: %w", err))
+	}
+
+	rows, _ := result.RowsAffected()
+	return common.Ok(rows > 0)
+}
@@ -0,0 +1,141 @@
+// Package database provides idempotency key persistence for the PostgreSQL adapter
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// ReserveIdempotencyKey claims a key for a request. An expired record with
+// the same key is taken over; a live one is returned unchanged.
+func (p *SimplePostgresDB) ReserveIdempotencyKey(ctx context.Context, record effects.IdempotencyRecord) common.Result[common.Option[effects.IdempotencyRecord]] {
+	query := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, status_code, response_headers, response_body, created_at, expires_at)
+		VALUES ($1, $2, $3, 0, '{}'::jsonb, NULL, $4, $5)
+		ON CONFLICT (user_id, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			status_code = 0,
+			response_headers = '{}'::jsonb,
+			response_body = NULL,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	`
+
+	result, err := p.db.ExecContext(ctx, query, record.UserID, record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return common.Err[common.Option[effects.IdempotencyRecord]](fmt.Errorf("failed to reserve idempotency key: %w", err))
+	}
+
+	if rows, _ := result.RowsAffected(); rows > 0 {
+		return common.Ok(common.None[effects.IdempotencyRecord]())
+	}
+
+	existing := p.findIdempotencyRecord(ctx, record.UserID, record.Key)
+	if existing.IsErr() {
+		return common.Err[common.Option[effects.IdempotencyRecord]](existing.Error())
+	}
+	return common.Ok(common.Some(existing.Value()))
+}
+
+// CompleteIdempotencyKey stores the response of a reserved request and
+// extends the reservation to the record's expiry. Only the reservation made
+// at the record's CreatedAt is completed, so a request whose lease ran out
+// does not overwrite the one that took its key over.
+func (p *SimplePostgresDB) CompleteIdempotencyKey(ctx context.Context, record effects.IdempotencyRecord) common.Result[effects.IdempotencyRecord] {
+	headersJSON, err := json.Marshal(record.Headers)
+	if err != nil {
+		return common.Err[effects.IdempotencyRecord](fmt.Errorf("failed to encode response headers: %w", err))
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $4, response_headers = $5, response_body = $6, expires_at = $7
+		WHERE user_id = $1 AND idempotency_key = $2 AND created_at = $3 AND status_code = 0
+	`
+
+	result, err := p.db.ExecContext(ctx, query, record.UserID, record.Key, record.CreatedAt, record.StatusCode, headersJSON, record.Body, record.ExpiresAt)
+	if err != nil {
+		return common.Err[effects.IdempotencyRecord](fmt.Errorf("failed to complete idempotency key: %w", err))
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return common.Err[effects.IdempotencyRecord](fmt.Errorf("idempotency key reservation not found"))
+	}
+
+	return common.Ok(record)
+}
+
+// ReleaseIdempotencyKey forgets a reservation still in progress so the
+// request can be tried again. A key taken over by another request after the
+// lease ran out, or already completed, is kept.
+func (p *SimplePostgresDB) ReleaseIdempotencyKey(ctx context.Context, record effects.IdempotencyRecord) common.Result[bool] {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2 AND created_at = $3 AND status_code = 0
+	`
+
+	result, err := p.db.ExecContext(ctx, query, record.UserID, record.Key, record.CreatedAt)
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to release idempotency key: %w", err))
+	}
+
+	rows, _ := result.RowsAffected()
+	return common.Ok(rows > 0)
+}
+
+// DeleteExpiredIdempotencyKeys deletes keys that expired before the given time
+func (p *SimplePostgresDB) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) common.Result[int] {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < $1`
+
+	result, err := p.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return common.Err[int](fmt.Errorf("failed to delete expired idempotency keys: %w", err))
+	}
+
+	rows, _ := result.RowsAffected()
+	return common.Ok(int(rows))
+}
+
+func (p *SimplePostgresDB) findIdempotencyRecord(ctx context.Context, userID uuid.UUID, key string) common.Result[effects.IdempotencyRecord] {
+	query := `
+		SELECT user_id, idempotency_key, request_hash, status_code, response_headers, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+	`
+
+	var (
+		record      effects.IdempotencyRecord
+		headersJSON []byte
+	)
+	err := p.db.QueryRowContext(ctx, query, userID, key).Scan(
+		&record.UserID,
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&headersJSON,
+		&record.Body,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return common.Err[effects.IdempotencyRecord](fmt.Errorf("idempotency key not found"))
+	}
+	if err != nil {
+		return common.Err[effects.IdempotencyRecord](fmt.Errorf("failed to find idempotency key: %w", err))
+	}
+
+	if err := json.Unmarshal(headersJSON, &record.Headers); err != nil {
+		return common.Err[effects.IdempotencyRecord](fmt.Errorf("failed to decode response headers: %w", err))
+	}
+
+	return common.Ok(record)
+}
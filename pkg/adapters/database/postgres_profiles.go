@@ -0,0 +1,326 @@
+// Package database provides user profile persistence for the PostgreSQL adapter
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// profileMetadata is the JSON form of profile settings in the user_profiles
+// metadata column
+type profileMetadata struct {
+	ProfilePictureURL   *string                      `json:"profile_picture_url,omitempty"`
+	EmailNotifications  *bool                        `json:"email_notifications,omitempty"`
+	NotificationEmail   *string                      `json:"notification_email,omitempty"`
+	DeliveryPreferences *deliveryPreferencesMetadata `json:"delivery_preferences,omitempty"`
+	Notifications       *notificationsMetadata       `json:"notifications,omitempty"`
+	Plan                string                       `json:"plan,omitempty"`
+	Milestones          []milestoneMetadata          `json:"milestones,omitempty"`
+}
+
+// milestoneMetadata is the JSON form of a personal milestone
+type milestoneMetadata struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Date string `json:"date"`
+}
+
+// deliveryPreferencesMetadata is the JSON form of quiet hours and delivery windows
+type deliveryPreferencesMetadata struct {
+	QuietHours    []scheduleRuleMetadata `json:"quiet_hours,omitempty"`
+	Windows       []scheduleRuleMetadata `json:"windows,omitempty"`
+	Country       string                 `json:"country,omitempty"`
+	AvoidHolidays bool                   `json:"avoid_holidays,omitempty"`
+}
+
+// notificationsMetadata is the JSON form of the notification categories; the
+// email switch is stored as email_notifications
+type notificationsMetadata struct {
+	PushEnabled       bool    `json:"push_enabled"`
+	WebhookURL        *string `json:"webhook_url,omitempty"`
+	DeliveryReminders bool    `json:"delivery_reminders"`
+	MarketingEmails   bool    `json:"marketing_emails"`
+	SecurityAlerts    bool    `json:"security_alerts"`
+	WeeklyDigest      bool    `json:"weekly_digest"`
+}
+
+// scheduleRuleMetadata is the JSON form of a schedule rule
+type scheduleRuleMetadata struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+// FindUserProfile finds a user together with their profile settings
+func (p *SimplePostgresDB) FindUserProfile(ctx context.Context, userID uuid.UUID) common.Result[user.UserProfile] {
+	query := `
+		SELECT id, email, COALESCE(name, ''), COALESCE(timezone, 'UTC'), COALESCE(locale, 'en'), created_at, updated_at,
+			COALESCE(metadata, '{}'::jsonb)
+		FROM user_profiles
+		WHERE id = $1
+	`
+
+	var id uuid.UUID
+	var email, name, timezone, locale string
+	var createdAt, updatedAt time.Time
+	var metadataJSON []byte
+
+	err := p.db.QueryRowContext(ctx, query, userID).Scan(&id, &email, &name, &timezone, &locale, &createdAt, &updatedAt, &metadataJSON)
+	if err == sql.ErrNoRows {
+		return common.Err[user.UserProfile](fmt.Errorf("user not found"))
+	}
+	if err != nil {
+		return common.Err[user.UserProfile](fmt.Errorf("failed to find user profile: %w", err))
+	}
+
+	userResult := userFromDB(id, email, name, timezone, locale, createdAt, updatedAt)
+	if userResult.IsErr() {
+		return common.Err[user.UserProfile](userResult.Error())
+	}
+
+	var meta profileMetadata
+	if err := json.Unmarshal(metadataJSON, &meta); err != nil {
+		return common.Err[user.UserProfile](fmt.Errorf("failed to decode user profile: %w", err))
+	}
+
+	return common.Ok(profileFromDB(userResult.Value(), meta))
+}
+
+// SaveUserProfile saves a user and their profile settings
+func (p *SimplePostgresDB) SaveUserProfile(ctx context.Context, profile user.UserProfile) common.Result[user.UserProfile] {
+	saved := p.SaveUser(ctx, profile.User())
+	if saved.IsErr() {
+		return common.Err[user.UserProfile](saved.Error())
+	}
+
+	return p.saveProfileMetadata(ctx, saved.Value().ID(), profile)
+}
+
+// UpdateUserProfile updates a user and their profile settings
+func (p *SimplePostgresDB) UpdateUserProfile(ctx context.Context, profile user.UserProfile) common.Result[user.UserProfile] {
+	updated := p.UpdateUser(ctx, profile.User())
+	if updated.IsErr() {
+		return common.Err[user.UserProfile](updated.Error())
+	}
+
+	return p.saveProfileMetadata(ctx, updated.Value().ID(), profile)
+}
+
+// FindUserUsage measures what a user consumes of their plan: messages
+// waiting for delivery and the total size of their attachments
+func (p *SimplePostgresDB) FindUserUsage(ctx context.Context, userID uuid.UUID) common.Result[user.Usage] {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM messages
+				WHERE user_id = $1 AND status IN ('scheduled', 'armed', 'failed') AND deleted_at IS NULL),
+			(SELECT COALESCE(SUM(a.file_size), 0) FROM message_attachments a
+				JOIN messages m ON m.id = a.message_id
+				WHERE m.user_id = $1)
+	`
+
+	var usage user.Usage
+	if err := p.db.QueryRowContext(ctx, query, userID).Scan(&usage.ScheduledMessages, &usage.AttachmentBytes); err != nil {
+		return common.Err[user.Usage](fmt.Errorf("failed to measure usage: %w", err))
+	}
+
+	return common.Ok(usage)
+}
+
+// saveProfileMetadata merges the profile settings into the metadata column,
+// keeping unrelated keys, and returns the stored profile
+func (p *SimplePostgresDB) saveProfileMetadata(ctx context.Context, userID uuid.UUID, profile user.UserProfile) common.Result[user.UserProfile] {
+	query := `
+		UPDATE user_profiles
+		SET metadata = COALESCE(metadata, '{}'::jsonb) || $2::jsonb, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	metadataJSON, err := json.Marshal(metadataFromProfile(profile))
+	if err != nil {
+		return common.Err[user.UserProfile](fmt.Errorf("failed to encode user profile: %w", err))
+	}
+
+	if _, err := p.db.ExecContext(ctx, query, userID, metadataJSON); err != nil {
+		return common.Err[user.UserProfile](fmt.Errorf("failed to save user profile: %w", err))
+	}
+
+	return p.FindUserProfile(ctx, userID)
+}
+
+// metadataFromProfile collects the metadata attributes of a profile
+func metadataFromProfile(profile user.UserProfile) profileMetadata {
+	enabled := profile.EmailNotifications()
+	prefs := profile.DeliveryPreferences()
+	notifications := profile.NotificationPreferences().Settings()
+
+	meta := profileMetadata{
+		EmailNotifications: &enabled,
+		DeliveryPreferences: &deliveryPreferencesMetadata{
+			QuietHours:    scheduleRulesToMetadata(prefs.QuietHours()),
+			Windows:       scheduleRulesToMetadata(prefs.Windows()),
+			Country:       prefs.Country(),
+			AvoidHolidays: prefs.AvoidsHolidays(),
+		},
+		Notifications: &notificationsMetadata{
+			PushEnabled:       notifications.PushEnabled,
+			DeliveryReminders: notifications.DeliveryReminders,
+			MarketingEmails:   notifications.MarketingEmails,
+			SecurityAlerts:    notifications.SecurityAlerts,
+			WeeklyDigest:      notifications.WeeklyDigest,
+		},
+		Plan: string(profile.Plan()),
+	}
+	if notifications.WebhookURL.IsSome() {
+		webhookURL := notifications.WebhookURL.Value()
+		meta.Notifications.WebhookURL = &webhookURL
+	}
+	for _, milestone := range profile.Milestones() {
+		meta.Milestones = append(meta.Milestones, milestoneMetadata{
+			Key:  milestone.Key(),
+			Name: milestone.Name(),
+			Date: milestone.Date().Format("2006-01-02"),
+		})
+	}
+	if profile.ProfilePictureURL().IsSome() {
+		url := profile.ProfilePictureURL().Value()
+		meta.ProfilePictureURL = &url
+	}
+	if profile.NotificationEmail().IsSome() {
+		email := profile.NotificationEmail().Value()
+		meta.NotificationEmail = &email
+	}
+	return meta
+}
+
+// profileFromDB reconstructs a profile; settings that cannot be decoded fall
+// back to their defaults
+func profileFromDB(u user.User, meta profileMetadata) user.UserProfile {
+	stored := user.StoredUserProfile{
+		User:                u,
+		ProfilePictureURL:   common.None[string](),
+		NotificationEmail:   common.None[string](),
+		DeliveryPreferences: user.DefaultDeliveryPreferences(),
+		Plan:                user.PlanFree,
+	}
+
+	if meta.ProfilePictureURL != nil {
+		stored.ProfilePictureURL = common.Some(*meta.ProfilePictureURL)
+	}
+	settings := user.DefaultNotificationPreferences().Settings()
+	if meta.Notifications != nil {
+		settings = notificationSettingsFromMetadata(*meta.Notifications)
+	}
+	if meta.EmailNotifications != nil {
+		settings.EmailEnabled = *meta.EmailNotifications
+	}
+	stored.Notifications = user.DefaultNotificationPreferences()
+	if notifications := user.NewNotificationPreferences(settings); notifications.IsOk() {
+		stored.Notifications = notifications.Value()
+	}
+	if meta.NotificationEmail != nil {
+		stored.NotificationEmail = common.Some(*meta.NotificationEmail)
+	}
+	if plan, err := user.ParsePlan(meta.Plan); err == nil {
+		stored.Plan = plan
+	}
+	if meta.DeliveryPreferences != nil {
+		prefs := deliveryPreferencesFromMetadata(*meta.DeliveryPreferences)
+		if prefs.IsOk() {
+			stored.DeliveryPreferences = prefs.Value()
+		}
+	}
+	for _, raw := range meta.Milestones {
+		date, err := time.Parse("2006-01-02", raw.Date)
+		if err != nil {
+			continue
+		}
+		if milestone := user.NewMilestone(raw.Key, raw.Name, date); milestone.IsOk() {
+			stored.Milestones = append(stored.Milestones, milestone.Value())
+		}
+	}
+
+	return user.RestoreUserProfile(stored)
+}
+
+func notificationSettingsFromMetadata(meta notificationsMetadata) user.NotificationSettings {
+	settings := user.NotificationSettings{
+		EmailEnabled:      true,
+		PushEnabled:       meta.PushEnabled,
+		WebhookURL:        common.None[string](),
+		DeliveryReminders: meta.DeliveryReminders,
+		MarketingEmails:   meta.MarketingEmails,
+		SecurityAlerts:    meta.SecurityAlerts,
+		WeeklyDigest:      meta.WeeklyDigest,
+	}
+	if meta.WebhookURL != nil {
+		settings.WebhookURL = common.Some(*meta.WebhookURL)
+	}
+	return settings
+}
+
+func deliveryPreferencesFromMetadata(meta deliveryPreferencesMetadata) common.Result[user.DeliveryPreferences] {
+	quietHours := scheduleRulesFromMetadata(meta.QuietHours)
+	if quietHours.IsErr() {
+		return common.Err[user.DeliveryPreferences](quietHours.Error())
+	}
+
+	windows := scheduleRulesFromMetadata(meta.Windows)
+	if windows.IsErr() {
+		return common.Err[user.DeliveryPreferences](windows.Error())
+	}
+
+	prefs := user.NewDeliveryPreferences(quietHours.Value(), windows.Value())
+	if prefs.IsErr() {
+		return prefs
+	}
+	return prefs.Value().WithHolidays(meta.Country, meta.AvoidHolidays)
+}
+
+func scheduleRulesToMetadata(rules []user.ScheduleRule) []scheduleRuleMetadata {
+	converted := make([]scheduleRuleMetadata, 0, len(rules))
+	for _, rule := range rules {
+		days := make([]string, 0, len(rule.Days))
+		for _, day := range rule.Days {
+			days = append(days, user.WeekdayName(day))
+		}
+		converted = append(converted, scheduleRuleMetadata{
+			Days:  days,
+			Start: rule.Start.String(),
+			End:   rule.End.String(),
+		})
+	}
+	return converted
+}
+
+func scheduleRulesFromMetadata(rules []scheduleRuleMetadata) common.Result[[]user.ScheduleRule] {
+	converted := make([]user.ScheduleRule, 0, len(rules))
+	for _, rule := range rules {
+		start := user.ParseTimeOfDay(rule.Start)
+		if start.IsErr() {
+			return common.Err[[]user.ScheduleRule](start.Error())
+		}
+		end := user.ParseTimeOfDay(rule.End)
+		if end.IsErr() {
+			return common.Err[[]user.ScheduleRule](end.Error())
+		}
+
+		days := make([]time.Weekday, 0, len(rule.Days))
+		for _, name := range rule.Days {
+			day := user.ParseWeekday(name)
+			if day.IsErr() {
+				return common.Err[[]user.ScheduleRule](day.Error())
+			}
+			days = append(days, day.Value())
+		}
+
+		converted = append(converted, user.ScheduleRule{Days: days, Start: start.Value(), End: end.Value()})
+	}
+	return common.Ok(converted)
+}
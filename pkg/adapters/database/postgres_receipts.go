@@ -0,0 +1,141 @@
+// Package database provides delivery receipt persistence for the PostgreSQL adapter
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+)
+
+// SaveDeliveryReceipt saves the receipt of a delivery
+func (p *SimplePostgresDB) SaveDeliveryReceipt(ctx context.Context, receipt message.DeliveryReceipt) common.Result[message.DeliveryReceipt] {
+	query := `
+		INSERT INTO delivery_receipts (id, message_id, recipient_email, delivered_at, opened_at, read_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := p.db.ExecContext(ctx, query,
+		receipt.ID(),
+		receipt.MessageID(),
+		receipt.Recipient(),
+		receipt.DeliveredAt(),
+		nullableTime(receipt.OpenedAt()),
+		nullableTime(receipt.ReadAt()),
+	)
+	if err != nil {
+		return common.Err[message.DeliveryReceipt](fmt.Errorf("failed to save delivery receipt: %w", err))
+	}
+
+	return common.Ok(receipt)
+}
+
+// UpdateDeliveryReceipt stores the open and read times of a receipt
+func (p *SimplePostgresDB) UpdateDeliveryReceipt(ctx context.Context, receipt message.DeliveryReceipt) common.Result[message.DeliveryReceipt] {
+	query := `
+		UPDATE delivery_receipts
+		SET opened_at = $2, read_at = $3
+		WHERE id = $1
+	`
+
+	result, err := p.db.ExecContext(ctx, query, receipt.ID(), nullableTime(receipt.OpenedAt()), nullableTime(receipt.ReadAt()))
+	if err != nil {
+		return common.Err[message.DeliveryReceipt](fmt.Errorf("failed to update delivery receipt: %w", err))
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return common.Err[message.DeliveryReceipt](fmt.Errorf("delivery receipt not found"))
+	}
+
+	return common.Ok(receipt)
+}
+
+// FindDeliveryReceiptByID finds a delivery receipt by ID
+func (p *SimplePostgresDB) FindDeliveryReceiptByID(ctx context.Context, receiptID uuid.UUID) common.Result[message.DeliveryReceipt] {
+	query := `
+		SELECT id, message_id, recipient_email, delivered_at, opened_at, read_at
+		FROM delivery_receipts
+		WHERE id = $1
+	`
+
+	receipt := scanDeliveryReceipt(p.db.QueryRowContext(ctx, query, receiptID))
+	if receipt.IsErr() && receipt.Error() == sql.ErrNoRows {
+		return common.Err[message.DeliveryReceipt](fmt.Errorf("delivery receipt not found"))
+	}
+	return receipt
+}
+
+// FindDeliveryReceiptsByMessageID finds the receipts of a message, oldest delivery first
+func (p *SimplePostgresDB) FindDeliveryReceiptsByMessageID(ctx context.Context, messageID uuid.UUID) common.Result[[]message.DeliveryReceipt] {
+	query := `
+		SELECT id, message_id, recipient_email, delivered_at, opened_at, read_at
+		FROM delivery_receipts
+		WHERE message_id = $1
+		ORDER BY delivered_at ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, messageID)
+	if err != nil {
+		return common.Err[[]message.DeliveryReceipt](fmt.Errorf("failed to query delivery receipts: %w", err))
+	}
+	defer rows.Close()
+
+	receipts := []message.DeliveryReceipt{}
+	for rows.Next() {
+		receipt := scanDeliveryReceipt(rows)
+		if receipt.IsErr() {
+			return common.Err[[]message.DeliveryReceipt](receipt.Error())
+		}
+		receipts = append(receipts, receipt.Value())
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[[]message.DeliveryReceipt](fmt.Errorf("failed to read delivery receipts: %w", err))
+	}
+
+	return common.Ok(receipts)
+}
+
+// scanDeliveryReceipt reads a delivery receipt row. sql.ErrNoRows is
+// returned unwrapped so callers can report a missing receipt.
+func scanDeliveryReceipt(row rowScanner) common.Result[message.DeliveryReceipt] {
+	var id, messageID uuid.UUID
+	var recipient string
+	var deliveredAt time.Time
+	var openedAt, readAt sql.NullTime
+
+	err := row.Scan(&id, &messageID, &recipient, &deliveredAt, &openedAt, &readAt)
+	if err == sql.ErrNoRows {
+		return common.Err[message.DeliveryReceipt](err)
+	}
+	if err != nil {
+		return common.Err[message.DeliveryReceipt](fmt.Errorf("failed to scan delivery receipt: %w", err))
+	}
+
+	return message.RestoreDeliveryReceipt(message.StoredDeliveryReceipt{
+		ID:          id,
+		MessageID:   messageID,
+		Recipient:   recipient,
+		DeliveredAt: deliveredAt,
+		OpenedAt:    optionalTime(openedAt),
+		ReadAt:      optionalTime(readAt),
+	})
+}
+
+// nullableTime converts an optional time into a nullable column value
+func nullableTime(t common.Option[time.Time]) sql.NullTime {
+	return sql.NullTime{Time: t.ValueOr(time.Time{}), Valid: t.IsSome()}
+}
+
+// optionalTime converts a nullable column value into an optional time
+func optionalTime(t sql.NullTime) common.Option[time.Time] {
+	if !t.Valid {
+		return common.None[time.Time]()
+	}
+	return common.Some(t.Time)
+}
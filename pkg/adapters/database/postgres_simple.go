@@ -6,11 +6,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"html"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
@@ -69,63 +71,67 @@ func (p *SimplePostgresDB) Ping(ctx context.Context) common.Result[bool] {
 }
 
 // Helper function to reconstruct User from database row
-func userFromDB(id uuid.UUID, email, name, timezone string, createdAt, updatedAt time.Time) common.Result[user.User] {
+func userFromDB(id uuid.UUID, email, name, timezone, locale string, createdAt, updatedAt time.Time) common.Result[user.User] {
 	result := user.NewUser(user.CreateUserRequest{
 		Email:    email,
 		Name:     name,
 		Timezone: timezone,
+		Locale:   locale,
 		UserID:   id,
 	})
 	return result
 }
 
+// saveUserQuery upserts a user by email
+const saveUserQuery = `
+	INSERT INTO user_profiles (id, email, name, timezone, locale, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (email) DO UPDATE
+	SET name = EXCLUDED.name,
+		timezone = EXCLUDED.timezone,
+		locale = EXCLUDED.locale,
+		updated_at = EXCLUDED.updated_at
+	RETURNING id, email, name, timezone, locale, created_at, updated_at
+`
+
 // SaveUser inserts or updates a user in the database
 func (p *SimplePostgresDB) SaveUser(ctx context.Context, u user.User) common.Result[user.User] {
-	query := `
-		INSERT INTO user_profiles (id, email, name, timezone, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (email) DO UPDATE
-		SET name = EXCLUDED.name,
-			timezone = EXCLUDED.timezone,
-			updated_at = EXCLUDED.updated_at
-		RETURNING id, email, name, timezone, created_at, updated_at
-	`
-
 	var id uuid.UUID
-	var email, name, timezone string
+	var email, name, timezone, locale string
 	var createdAt, updatedAt time.Time
 
 	err := p.db.QueryRowContext(
 		ctx,
-		query,
+		saveUserQuery,
 		u.ID(),
 		u.Email(),
 		u.Name(),
 		u.Timezone(),
+		string(u.Locale()),
 		u.CreatedAt(),
 		u.UpdatedAt(),
-	).Scan(&id, &email, &name, &timezone, &createdAt, &updatedAt)
+	).Scan(&id, &email, &name, &timezone, &locale, &createdAt, &updatedAt)
 
 	if err != nil {
 		return common.Err[user.User](fmt.Errorf("failed to save user: %w", err))
 	}
 
-	return userFromDB(id, email, name, timezone, createdAt, updatedAt)
+	return userFromDB(id, email, name, timezone, locale, createdAt, updatedAt)
 }
 
 // FindUserByID finds a user by ID
 func (p *SimplePostgresDB) FindUserByID(ctx context.Context, userID uuid.UUID) common.Result[user.User] {
 	query := `
-		SELECT id, email, COALESCE(name, ''), COALESCE(timezone, 'UTC'), created_at, updated_at
+		SELECT id, email, COALESCE(name, ''), COALESCE(timezone, 'UTC'), COALESCE(locale, 'en'), created_at, updated_at
 		FROM user_profiles
 		WHERE id = $1
 	`
 
 	var id uuid.UUID
-	var email, name, timezone string
+	var email, name, timezone, locale string
 	var createdAt, updatedAt time.Time
 
-	err := p.db.QueryRowContext(ctx, query, userID).Scan(&id, &email, &name, &timezone, &createdAt, &updatedAt)
+	err := p.db.QueryRowContext(ctx, query, userID).Scan(&id, &email, &name, &timezone, &locale, &createdAt, &updatedAt)
 	if err == sql.ErrNoRows {
 		return common.Err[user.User](fmt.Errorf("user not found"))
 	}
@@ -133,22 +139,22 @@ func (p *SimplePostgresDB) FindUserByID(ctx context.Context, userID uuid.UUID) c
 		return common.Err[user.User](fmt.Errorf("failed to find user: %w", err))
 	}
 
-	return userFromDB(id, email, name, timezone, createdAt, updatedAt)
+	return userFromDB(id, email, name, timezone, locale, createdAt, updatedAt)
 }
 
 // FindUserByEmail finds a user by email
 func (p *SimplePostgresDB) FindUserByEmail(ctx context.Context, email string) common.Result[user.User] {
 	query := `
-		SELECT id, email, COALESCE(name, ''), COALESCE(timezone, 'UTC'), created_at, updated_at
+		SELECT id, email, COALESCE(name, ''), COALESCE(timezone, 'UTC'), COALESCE(locale, 'en'), created_at, updated_at
 		FROM user_profiles
 		WHERE email = $1
 	`
 
 	var id uuid.UUID
-	var dbEmail, name, timezone string
+	var dbEmail, name, timezone, locale string
 	var createdAt, updatedAt time.Time
 
-	err := p.db.QueryRowContext(ctx, query, email).Scan(&id, &dbEmail, &name, &timezone, &createdAt, &updatedAt)
+	err := p.db.QueryRowContext(ctx, query, email).Scan(&id, &dbEmail, &name, &timezone, &locale, &createdAt, &updatedAt)
 	if err == sql.ErrNoRows {
 		return common.Err[user.User](fmt.Errorf("user not found"))
 	}
@@ -158,20 +164,20 @@ func (p *SimplePostgresDB) FindUserByEmail(ctx context.Context, email string) co
 
 	fmt.Println("Found user:", dbEmail, id)
 
-	return userFromDB(id, dbEmail, name, timezone, createdAt, updatedAt)
+	return userFromDB(id, dbEmail, name, timezone, locale, createdAt, updatedAt)
 }
 
 // UpdateUser updates an existing user
 func (p *SimplePostgresDB) UpdateUser(ctx context.Context, u user.User) common.Result[user.User] {
 	query := `
 		UPDATE user_profiles
-		SET name = $2, timezone = $3, updated_at = $4
+		SET name = $2, timezone = $3, locale = $4, updated_at = $5
 		WHERE id = $1
-		RETURNING id, email, name, timezone, created_at, updated_at
+		RETURNING id, email, name, timezone, locale, created_at, updated_at
 	`
 
 	var id uuid.UUID
-	var email, name, timezone string
+	var email, name, timezone, locale string
 	var createdAt, updatedAt time.Time
 
 	err := p.db.QueryRowContext(
@@ -180,14 +186,15 @@ func (p *SimplePostgresDB) UpdateUser(ctx context.Context, u user.User) common.R
 		u.ID(),
 		u.Name(),
 		u.Timezone(),
+		string(u.Locale()),
 		time.Now(),
-	).Scan(&id, &email, &name, &timezone, &createdAt, &updatedAt)
+	).Scan(&id, &email, &name, &timezone, &locale, &createdAt, &updatedAt)
 
 	if err != nil {
 		return common.Err[user.User](fmt.Errorf("failed to update user: %w", err))
 	}
 
-	return userFromDB(id, email, name, timezone, createdAt, updatedAt)
+	return userFromDB(id, email, name, timezone, locale, createdAt, updatedAt)
 }
 
 // DeleteUser deletes a user by ID
@@ -207,23 +214,157 @@ func (p *SimplePostgresDB) DeleteUser(ctx context.Context, userID uuid.UUID) com
 	return common.Ok(rowsAffected > 0)
 }
 
-// SaveUserProfile - placeholder implementation
-func (p *SimplePostgresDB) SaveUserProfile(ctx context.Context, profile user.UserProfile) common.Result[user.UserProfile] {
-	return common.Ok(profile)
+// messageColumns is the column list read by scanMessage
+const messageColumns = `id, user_id, subject, content, scheduled_for, status, created_at, updated_at, COALESCE(metadata, '{}'::jsonb), deleted_at, version`
+
+// Listings use the delivery window the author may see rather than
+// scheduled_for, which holds the drawn time of an undelivered surprise
+// message (see message.Message.VisibleDeliveryWindow)
+const (
+	hiddenDeliveryCondition = `(metadata->'surprise' IS NOT NULL AND status <> 'sent')`
+	visibleDeliveryStart    = `(CASE WHEN ` + hiddenDeliveryCondition + ` THEN (metadata->'surprise'->>'earliest')::timestamptz ELSE scheduled_for END)`
+	visibleDeliveryEnd      = `(CASE WHEN ` + hiddenDeliveryCondition + ` THEN (metadata->'surprise'->>'latest')::timestamptz ELSE scheduled_for END)`
+)
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
-// FindUserProfile - placeholder implementation
-func (p *SimplePostgresDB) FindUserProfile(ctx context.Context, userID uuid.UUID) common.Result[user.UserProfile] {
-	return common.Err[user.UserProfile](fmt.Errorf("not implemented yet"))
+// messageMetadata holds the message attributes stored in the metadata JSONB column
+type messageMetadata struct {
+	Timezone       string
+	DeliveryMethod string
+	Recurrence     message.RecurrencePattern
+	Reminder       common.Option[int]
+	ContentFormat  message.ContentFormat
+	CheckIn        common.Option[message.CheckInSettings]
+	IntendedDate   common.Option[time.Time]
+	Surprise       common.Option[message.SurpriseWindow]
+	ParentID       common.Option[uuid.UUID]
+	Anchor         common.Option[message.MilestoneAnchor]
+	Holiday        common.Option[message.HolidayTarget]
 }
 
-// UpdateUserProfile - placeholder implementation
-func (p *SimplePostgresDB) UpdateUserProfile(ctx context.Context, profile user.UserProfile) common.Result[user.UserProfile] {
-	return common.Ok(profile)
+// anchorMetadata is the JSON form of a milestone anchor in the metadata column
+type anchorMetadata struct {
+	Milestone     string `json:"milestone"`
+	Occurrence    int    `json:"occurrence"`
+	OffsetMonths  int    `json:"offset_months,omitempty"`
+	OffsetDays    int    `json:"offset_days,omitempty"`
+	At            string `json:"at"`
+	MilestoneDate string `json:"milestone_date"`
+}
+
+// holidayMetadata is the JSON form of a holiday target in the metadata column
+type holidayMetadata struct {
+	Country string `json:"country"`
+	Holiday string `json:"holiday"`
+	At      string `json:"at"`
+}
+
+// surpriseMetadata is the JSON form of a surprise window in the metadata
+// column. The seed is a string because JSON numbers lose uint64 precision.
+type surpriseMetadata struct {
+	Earliest time.Time `json:"earliest"`
+	Latest   time.Time `json:"latest"`
+	Seed     string    `json:"seed"`
+}
+
+// checkInMetadata is the JSON form of check-in settings in the metadata column
+type checkInMetadata struct {
+	IntervalHours int        `json:"interval_hours"`
+	GraceHours    int        `json:"grace_hours"`
+	Recipients    []string   `json:"recipients,omitempty"`
+	LastCheckInAt time.Time  `json:"last_check_in_at"`
+	WarnedAt      *time.Time `json:"warned_at,omitempty"`
+}
+
+// metadataFromMessage collects the metadata attributes of a message
+func metadataFromMessage(msg message.Message) messageMetadata {
+	return messageMetadata{
+		Timezone:       msg.Timezone(),
+		DeliveryMethod: string(msg.DeliveryMethod()),
+		Recurrence:     msg.Recurrence(),
+		Reminder:       msg.ReminderMinutes(),
+		ContentFormat:  msg.ContentFormat(),
+		CheckIn:        msg.CheckIn(),
+		IntendedDate:   msg.IntendedDeliveryDate(),
+		Surprise:       msg.Surprise(),
+		ParentID:       msg.ParentID(),
+		Anchor:         msg.Anchor(),
+		Holiday:        msg.HolidayTarget(),
+	}
+}
+
+// toJSON encodes the metadata for the metadata column
+func (m messageMetadata) toJSON() []byte {
+	metadata := map[string]interface{}{
+		"timezone":        m.Timezone,
+		"delivery_method": m.DeliveryMethod,
+	}
+	if m.Recurrence != "" && m.Recurrence != message.RecurrenceNone {
+		metadata["recurrence"] = string(m.Recurrence)
+	}
+	if m.Reminder.IsSome() {
+		metadata["reminder_minutes"] = m.Reminder.Value()
+	}
+	if m.ContentFormat != "" && m.ContentFormat != message.FormatPlain {
+		metadata["content_format"] = string(m.ContentFormat)
+	}
+	if m.CheckIn.IsSome() {
+		settings := m.CheckIn.Value()
+		checkIn := checkInMetadata{
+			IntervalHours: int(settings.Interval() / time.Hour),
+			GraceHours:    int(settings.GracePeriod() / time.Hour),
+			Recipients:    settings.Recipients(),
+			LastCheckInAt: settings.LastCheckInAt().UTC(),
+		}
+		if settings.WarnedAt().IsSome() {
+			warnedAt := settings.WarnedAt().Value().UTC()
+			checkIn.WarnedAt = &warnedAt
+		}
+		metadata["check_in"] = checkIn
+	}
+	if m.Surprise.IsSome() {
+		window := m.Surprise.Value()
+		metadata["surprise"] = surpriseMetadata{
+			Earliest: window.Earliest().UTC(),
+			Latest:   window.Latest().UTC(),
+			Seed:     strconv.FormatUint(window.Seed(), 10),
+		}
+	}
+	if m.IntendedDate.IsSome() {
+		metadata["intended_delivery_date"] = m.IntendedDate.Value().UTC().Format(time.RFC3339)
+	}
+	if m.ParentID.IsSome() {
+		metadata["parent_message_id"] = m.ParentID.Value().String()
+	}
+	if m.Anchor.IsSome() {
+		anchor := m.Anchor.Value()
+		metadata["anchor"] = anchorMetadata{
+			Milestone:     anchor.Milestone(),
+			Occurrence:    anchor.Occurrence(),
+			OffsetMonths:  anchor.OffsetMonths(),
+			OffsetDays:    anchor.OffsetDays(),
+			At:            anchor.At().String(),
+			MilestoneDate: anchor.MilestoneDate().Format("2006-01-02"),
+		}
+	}
+	if m.Holiday.IsSome() {
+		target := m.Holiday.Value()
+		metadata["holiday"] = holidayMetadata{
+			Country: target.Country(),
+			Holiday: target.Holiday(),
+			At:      target.At().String(),
+		}
+	}
+	metadataJSON, _ := json.Marshal(metadata)
+	return metadataJSON
 }
 
 // Helper to reconstruct Message from database
-func messageFromDB(id, userID uuid.UUID, title, content string, deliveryDate time.Time, timezone, status, deliveryMethod string, createdAt, updatedAt time.Time, recurrence message.RecurrencePattern, reminder common.Option[int]) common.Result[message.Message] {
+func messageFromDB(id, userID uuid.UUID, title, content string, deliveryDate time.Time, status string, createdAt, updatedAt time.Time, deletedAt sql.NullTime, version int64, meta messageMetadata) common.Result[message.Message] {
 	var msgStatus message.MessageStatus
 	switch status {
 	case "scheduled":
@@ -232,6 +373,10 @@ func messageFromDB(id, userID uuid.UUID, title, content string, deliveryDate tim
 		msgStatus = message.StatusDelivered
 	case "failed":
 		msgStatus = message.StatusFailed
+	case "cancelled":
+		msgStatus = message.StatusCancelled
+	case "armed":
+		msgStatus = message.StatusArmed
 	case "draft":
 		msgStatus = message.StatusScheduled
 	default:
@@ -239,7 +384,7 @@ func messageFromDB(id, userID uuid.UUID, title, content string, deliveryDate tim
 	}
 
 	var method message.DeliveryMethod
-	switch deliveryMethod {
+	switch meta.DeliveryMethod {
 	case "email":
 		method = message.DeliveryEmail
 	case "push":
@@ -255,49 +400,75 @@ func messageFromDB(id, userID uuid.UUID, title, content string, deliveryDate tim
 		UserID:          userID,
 		Title:           title,
 		Content:         content,
+		ContentFormat:   meta.ContentFormat,
 		DeliveryDate:    deliveryDate,
-		Timezone:        timezone,
+		Timezone:        meta.Timezone,
 		Status:          msgStatus,
 		DeliveryMethod:  method,
-		Recurrence:      recurrence,
-		ReminderMinutes: reminder,
+		Recurrence:      meta.Recurrence,
+		ReminderMinutes: meta.Reminder,
 		CreatedAt:       createdAt,
 		UpdatedAt:       updatedAt,
 		DeliveredAt:     deliveredAt,
+		CheckIn:         meta.CheckIn,
+		IntendedDate:    meta.IntendedDate,
+		Surprise:        meta.Surprise,
+		ParentID:        meta.ParentID,
+		DeletedAt:       optionalTime(deletedAt),
+		Version:         version,
+		Anchor:          meta.Anchor,
+		Holiday:         meta.Holiday,
 	}
 
 	return message.RestoreMessage(stored)
 }
 
-// SaveMessage inserts a new message
-func (p *SimplePostgresDB) SaveMessage(ctx context.Context, msg message.Message) common.Result[message.Message] {
-	metadata := map[string]interface{}{
-		"timezone":        msg.Timezone(),
-		"delivery_method": string(msg.DeliveryMethod()),
+// toDBStatus maps an application status to the value stored in the database
+func toDBStatus(status message.MessageStatus) string {
+	if status == message.StatusDelivered {
+		return "sent"
 	}
-	if msg.HasRecurrence() {
-		metadata["recurrence"] = string(msg.Recurrence())
-	}
-	if msg.HasReminder() {
-		metadata["reminder_minutes"] = msg.ReminderMinutes().Value()
+	return string(status)
+}
+
+// scanMessage reads a row selected with messageColumns into a Message. Any
+// extra destinations receive the columns selected after messageColumns.
+func scanMessage(row rowScanner, extra ...interface{}) (common.Result[message.Message], error) {
+	var id, userID uuid.UUID
+	var title, content, status string
+	var scheduledFor, createdAt, updatedAt time.Time
+	var metadataJSON []byte
+	var deletedAt sql.NullTime
+	var version int64
+
+	dest := append([]interface{}{&id, &userID, &title, &content, &scheduledFor, &status, &createdAt, &updatedAt, &metadataJSON, &deletedAt, &version}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return common.Result[message.Message]{}, err
 	}
-	metadataJSON, _ := json.Marshal(metadata)
+
+	var metadata map[string]interface{}
+	json.Unmarshal(metadataJSON, &metadata)
+
+	return messageFromDB(id, userID, title, content, scheduledFor, status, createdAt, updatedAt, deletedAt, version, extractMessageMetadata(metadata)), nil
+}
+
+// SaveMessage inserts a new message
+func (p *SimplePostgresDB) SaveMessage(ctx context.Context, msg message.Message) common.Result[message.Message] {
+	meta := metadataFromMessage(msg)
 
 	// Map application status to database status
-	dbStatus := string(msg.Status())
-	if msg.Status() == message.StatusDelivered {
-		dbStatus = "sent"
-	}
+	dbStatus := toDBStatus(msg.Status())
 
 	query := `
 		INSERT INTO messages (id, user_id, subject, content, scheduled_for, status, created_at, updated_at, metadata)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, user_id, subject, content, scheduled_for, status, created_at, updated_at
+		RETURNING id, user_id, subject, content, scheduled_for, status, created_at, updated_at, version
 	`
 
 	var id, userID uuid.UUID
 	var title, content, status string
 	var scheduledFor, createdAt, updatedAt time.Time
+	var version int64
 	fmt.Println(msg.UserID())
 	err := p.db.QueryRowContext(
 		ctx,
@@ -310,30 +481,25 @@ func (p *SimplePostgresDB) SaveMessage(ctx context.Context, msg message.Message)
 		dbStatus,
 		msg.CreatedAt(),
 		msg.UpdatedAt(),
-		metadataJSON,
-	).Scan(&id, &userID, &title, &content, &scheduledFor, &status, &createdAt, &updatedAt)
+		meta.toJSON(),
+	).Scan(&id, &userID, &title, &content, &scheduledFor, &status, &createdAt, &updatedAt, &version)
 
 	if err != nil {
 		return common.Err[message.Message](fmt.Errorf("failed to save message: %w", err))
 	}
 
-	return messageFromDB(id, userID, title, content, scheduledFor, msg.Timezone(), status, string(msg.DeliveryMethod()), createdAt, updatedAt, msg.Recurrence(), msg.ReminderMinutes())
+	return messageFromDB(id, userID, title, content, scheduledFor, status, createdAt, updatedAt, sql.NullTime{}, version, meta)
 }
 
 // FindMessageByID finds a message by ID
 func (p *SimplePostgresDB) FindMessageByID(ctx context.Context, messageID uuid.UUID) common.Result[message.Message] {
 	query := `
-		SELECT id, user_id, subject, content, scheduled_for, status, created_at, updated_at, COALESCE(metadata, '{}'::jsonb)
+		SELECT ` + messageColumns + `
 		FROM messages
 		WHERE id = $1
 	`
 
-	var id, userID uuid.UUID
-	var title, content, status string
-	var scheduledFor, createdAt, updatedAt time.Time
-	var metadataJSON []byte
-
-	err := p.db.QueryRowContext(ctx, query, messageID).Scan(&id, &userID, &title, &content, &scheduledFor, &status, &createdAt, &updatedAt, &metadataJSON)
+	msgResult, err := scanMessage(p.db.QueryRowContext(ctx, query, messageID))
 	if err == sql.ErrNoRows {
 		return common.Err[message.Message](fmt.Errorf("message not found"))
 	}
@@ -341,12 +507,7 @@ func (p *SimplePostgresDB) FindMessageByID(ctx context.Context, messageID uuid.U
 		return common.Err[message.Message](fmt.Errorf("failed to find message: %w", err))
 	}
 
-	var metadata map[string]interface{}
-	json.Unmarshal(metadataJSON, &metadata)
-
-	timezone, deliveryMethod, recurrence, reminder := extractMessageMetadata(metadata)
-
-	return messageFromDB(id, userID, title, content, scheduledFor, timezone, status, deliveryMethod, createdAt, updatedAt, recurrence, reminder)
+	return msgResult
 }
 
 // FindMessagesByUserID finds all messages for a user
@@ -356,9 +517,9 @@ func (p *SimplePostgresDB) FindMessagesByUserID(ctx context.Context, userID uuid
 	}
 
 	query := `
-		SELECT id, user_id, subject, content, scheduled_for, status, created_at, updated_at, COALESCE(metadata, '{}'::jsonb)
+		SELECT ` + messageColumns + `
 		FROM messages
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 		ORDER BY scheduled_for DESC
 		LIMIT $2 OFFSET $3
 	`
@@ -371,22 +532,11 @@ func (p *SimplePostgresDB) FindMessagesByUserID(ctx context.Context, userID uuid
 
 	var messages []message.Message
 	for rows.Next() {
-		var id, uid uuid.UUID
-		var title, content, status string
-		var scheduledFor, createdAt, updatedAt time.Time
-		var metadataJSON []byte
-
-		err := rows.Scan(&id, &uid, &title, &content, &scheduledFor, &status, &createdAt, &updatedAt, &metadataJSON)
+		msgResult, err := scanMessage(rows)
 		if err != nil {
 			return common.Err[[]message.Message](fmt.Errorf("failed to scan message: %w", err))
 		}
 
-		var metadata map[string]interface{}
-		json.Unmarshal(metadataJSON, &metadata)
-
-		timezone, deliveryMethod, recurrence, reminder := extractMessageMetadata(metadata)
-
-		msgResult := messageFromDB(id, uid, title, content, scheduledFor, timezone, status, deliveryMethod, createdAt, updatedAt, recurrence, reminder)
 		if msgResult.IsOk() {
 			messages = append(messages, msgResult.Value())
 		}
@@ -395,6 +545,117 @@ func (p *SimplePostgresDB) FindMessagesByUserID(ctx context.Context, userID uuid
 	return common.Ok(messages)
 }
 
+// FindMessagesPage returns one page of a user's messages using keyset pagination
+func (p *SimplePostgresDB) FindMessagesPage(ctx context.Context, userID uuid.UUID, options message.ListOptions) common.Result[effects.MessagePage] {
+	where, args := listFilterClause(userID, options.Filter)
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM messages WHERE ` + where
+	if err := p.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return common.Err[effects.MessagePage](fmt.Errorf("failed to count messages: %w", err))
+	}
+
+	sortColumn := visibleDeliveryStart
+	if options.Sort == message.SortByCreatedAt {
+		sortColumn = "created_at"
+	}
+	direction, comparator := "DESC", "<"
+	if options.Direction == message.SortAscending {
+		direction, comparator = "ASC", ">"
+	}
+
+	if options.Cursor.IsSome() {
+		cursor := options.Cursor.Value()
+		args = append(args, cursor.Value, cursor.ID)
+		where += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", sortColumn, comparator, len(args)-1, len(args))
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, options.Limit+1)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM messages
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT $%d
+	`, messageColumns, where, sortColumn, direction, direction, len(args))
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return common.Err[effects.MessagePage](fmt.Errorf("failed to list messages: %w", err))
+	}
+	defer rows.Close()
+
+	page := effects.MessagePage{
+		Messages:   []message.Message{},
+		NextCursor: common.None[message.ListCursor](),
+		Total:      total,
+	}
+
+	fetched := 0
+	var last message.Message
+	for rows.Next() {
+		fetched++
+		if fetched > options.Limit {
+			page.NextCursor = common.Some(options.CursorAfter(last))
+			break
+		}
+
+		msgResult, err := scanMessage(rows)
+		if err != nil {
+			return common.Err[effects.MessagePage](fmt.Errorf("failed to scan message: %w", err))
+		}
+		if msgResult.IsErr() {
+			return common.Err[effects.MessagePage](msgResult.Error())
+		}
+
+		last = msgResult.Value()
+		page.Messages = append(page.Messages, last)
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[effects.MessagePage](fmt.Errorf("failed to read messages: %w", err))
+	}
+
+	return common.Ok(page)
+}
+
+// listFilterClause builds the WHERE clause and arguments for a listing filter
+func listFilterClause(userID uuid.UUID, filter message.ListFilter) (string, []interface{}) {
+	conditions := []string{"user_id = $1", "deleted_at IS NULL"}
+	args := []interface{}{userID}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Status.IsSome() {
+		add("status = $%d", toDBStatus(filter.Status.Value()))
+	}
+	if filter.DeliveryMethod.IsSome() {
+		add("COALESCE(metadata->>'delivery_method', 'email') = $%d", string(filter.DeliveryMethod.Value()))
+	}
+	if filter.Recurrence.IsSome() {
+		add("COALESCE(metadata->>'recurrence', 'none') = $%d", string(filter.Recurrence.Value()))
+	}
+	// A hidden surprise message matches when its window overlaps the range
+	if filter.From.IsSome() {
+		add(visibleDeliveryEnd+" >= $%d", filter.From.Value())
+	}
+	if filter.To.IsSome() {
+		add(visibleDeliveryStart+" <= $%d", filter.To.Value())
+	}
+	if len(filter.Tags) > 0 {
+		add(`EXISTS (
+			SELECT 1 FROM message_tags mt JOIN tags t ON t.id = mt.tag_id
+			WHERE mt.message_id = messages.id AND lower(t.name) = ANY($%d)
+		)`, pq.Array(filter.Tags))
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
 func extractReminder(metadata map[string]interface{}) common.Option[int] {
 	if metadata == nil {
 		return common.None[int]()
@@ -422,25 +683,51 @@ func extractReminder(metadata map[string]interface{}) common.Option[int] {
 	return common.None[int]()
 }
 
-func extractMessageMetadata(metadata map[string]interface{}) (string, string, message.RecurrencePattern, common.Option[int]) {
-	timezone := "UTC"
-	deliveryMethod := "email"
-	if metadata != nil {
-		if tz, ok := metadata["timezone"].(string); ok && tz != "" {
-			timezone = tz
-		}
-		if dm, ok := metadata["delivery_method"].(string); ok && dm != "" {
-			deliveryMethod = strings.ToLower(dm)
+func extractMessageMetadata(metadata map[string]interface{}) messageMetadata {
+	meta := messageMetadata{
+		Timezone:       "UTC",
+		DeliveryMethod: "email",
+		Recurrence:     message.RecurrenceNone,
+		Reminder:       extractReminder(metadata),
+		ContentFormat:  message.FormatPlain,
+		CheckIn:        common.None[message.CheckInSettings](),
+		IntendedDate:   common.None[time.Time](),
+		Surprise:       common.None[message.SurpriseWindow](),
+		ParentID:       common.None[uuid.UUID](),
+		Anchor:         common.None[message.MilestoneAnchor](),
+		Holiday:        common.None[message.HolidayTarget](),
+	}
+	if metadata == nil {
+		return meta
+	}
+
+	if tz, ok := metadata["timezone"].(string); ok && tz != "" {
+		meta.Timezone = tz
+	}
+	if dm, ok := metadata["delivery_method"].(string); ok && dm != "" {
+		meta.DeliveryMethod = strings.ToLower(dm)
+	}
+	if rc, ok := metadata["recurrence"].(string); ok && rc != "" {
+		meta.Recurrence = message.RecurrencePattern(strings.ToLower(rc))
+	}
+	if cf, ok := metadata["content_format"].(string); ok && cf != "" {
+		meta.ContentFormat = message.ContentFormat(strings.ToLower(cf))
+	}
+	if intended, ok := metadata["intended_delivery_date"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, intended); err == nil {
+			meta.IntendedDate = common.Some(parsed)
 		}
 	}
-	recurrence := message.RecurrenceNone
-	if metadata != nil {
-		if rc, ok := metadata["recurrence"].(string); ok && rc != "" {
-			recurrence = message.RecurrencePattern(strings.ToLower(rc))
+	if parent, ok := metadata["parent_message_id"].(string); ok {
+		if parsed, err := uuid.Parse(parent); err == nil {
+			meta.ParentID = common.Some(parsed)
 		}
 	}
-	reminder := extractReminder(metadata)
-	return timezone, deliveryMethod, recurrence, reminder
+	meta.CheckIn = extractCheckIn(metadata)
+	meta.Surprise = extractSurprise(metadata)
+	meta.Anchor = extractAnchor(metadata)
+	meta.Holiday = extractHoliday(metadata)
+	return meta
 }
 
 // FindMessagesByStatus - placeholder implementation
@@ -450,9 +737,9 @@ func (p *SimplePostgresDB) FindMessagesByStatus(ctx context.Context, status mess
 	}
 
 	query := `
-		SELECT id, user_id, subject, content, scheduled_for, status, created_at, updated_at, COALESCE(metadata, '{}'::jsonb)
+		SELECT ` + messageColumns + `
 		FROM messages
-		WHERE status = $1
+		WHERE status = $1 AND deleted_at IS NULL
 		ORDER BY scheduled_for ASC
 		LIMIT $2
 	`
@@ -465,21 +752,10 @@ func (p *SimplePostgresDB) FindMessagesByStatus(ctx context.Context, status mess
 
 	var messagesList []message.Message
 	for rows.Next() {
-		var id, uid uuid.UUID
-		var title, content, rowStatus string
-		var scheduledFor, createdAt, updatedAt time.Time
-		var metadataJSON []byte
-
-		err := rows.Scan(&id, &uid, &title, &content, &scheduledFor, &rowStatus, &createdAt, &updatedAt, &metadataJSON)
+		msgResult, err := scanMessage(rows)
 		if err != nil {
 			return common.Err[[]message.Message](fmt.Errorf("failed to scan message: %w", err))
 		}
-
-		var metadata map[string]interface{}
-		json.Unmarshal(metadataJSON, &metadata)
-		timezone, deliveryMethod, recurrence, reminder := extractMessageMetadata(metadata)
-
-		msgResult := messageFromDB(id, uid, title, content, scheduledFor, timezone, rowStatus, deliveryMethod, createdAt, updatedAt, recurrence, reminder)
 		if msgResult.IsErr() {
 			return common.Err[[]message.Message](msgResult.Error())
 		}
@@ -497,9 +773,9 @@ func (p *SimplePostgresDB) FindDueMessages(ctx context.Context, before time.Time
 	}
 
 	query := `
-		SELECT id, user_id, subject, content, scheduled_for, status, created_at, updated_at, COALESCE(metadata, '{}'::jsonb)
+		SELECT ` + messageColumns + `
 		FROM messages
-		WHERE status = 'scheduled' AND scheduled_for <= $1
+		WHERE status = 'scheduled' AND scheduled_for <= $1 AND deleted_at IS NULL
 		ORDER BY scheduled_for ASC
 		LIMIT $2
 	`
@@ -512,21 +788,10 @@ func (p *SimplePostgresDB) FindDueMessages(ctx context.Context, before time.Time
 
 	var dueMessages []message.Message
 	for rows.Next() {
-		var id, uid uuid.UUID
-		var title, content, rowStatus string
-		var scheduledFor, createdAt, updatedAt time.Time
-		var metadataJSON []byte
-
-		err := rows.Scan(&id, &uid, &title, &content, &scheduledFor, &rowStatus, &createdAt, &updatedAt, &metadataJSON)
+		msgResult, err := scanMessage(rows)
 		if err != nil {
 			return common.Err[[]message.Message](fmt.Errorf("failed to scan due message: %w", err))
 		}
-
-		var metadata map[string]interface{}
-		json.Unmarshal(metadataJSON, &metadata)
-		timezone, deliveryMethod, recurrence, reminder := extractMessageMetadata(metadata)
-
-		msgResult := messageFromDB(id, uid, title, content, scheduledFor, timezone, rowStatus, deliveryMethod, createdAt, updatedAt, recurrence, reminder)
 		if msgResult.IsErr() {
 			return common.Err[[]message.Message](msgResult.Error())
 		}
@@ -537,36 +802,41 @@ func (p *SimplePostgresDB) FindDueMessages(ctx context.Context, before time.Time
 	return common.Ok(dueMessages)
 }
 
-// UpdateMessage updates an existing message
+// FindPendingMessages returns a user's messages that still wait for
+// delivery, scheduled or armed, outside the trash
+func (p *SimplePostgresDB) FindPendingMessages(ctx context.Context, userID uuid.UUID) common.Result[[]message.Message] {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE user_id = $1 AND status IN ('scheduled', 'armed') AND deleted_at IS NULL
+		ORDER BY scheduled_for ASC
+	`
+
+	return p.queryMessages(ctx, "failed to find pending messages", query, userID)
+}
+
+// UpdateMessage updates an existing message if it is still at the version
+// it was read at, and increments the version. A message changed in the
+// meantime is left alone and message.ErrVersionConflict is returned.
 func (p *SimplePostgresDB) UpdateMessage(ctx context.Context, msg message.Message) common.Result[message.Message] {
-	metadata := map[string]interface{}{
-		"timezone":        msg.Timezone(),
-		"delivery_method": string(msg.DeliveryMethod()),
-	}
-	if msg.HasRecurrence() {
-		metadata["recurrence"] = string(msg.Recurrence())
-	}
-	if msg.HasReminder() {
-		metadata["reminder_minutes"] = msg.ReminderMinutes().Value()
-	}
-	metadataJSON, _ := json.Marshal(metadata)
+	meta := metadataFromMessage(msg)
 
 	// Map application status to database status
-	dbStatus := string(msg.Status())
-	if msg.Status() == message.StatusDelivered {
-		dbStatus = "sent"
-	}
+	dbStatus := toDBStatus(msg.Status())
 
 	query := `
 		UPDATE messages
-		SET subject = $2, content = $3, scheduled_for = $4, status = $5, updated_at = $6, metadata = $7
-		WHERE id = $1
-		RETURNING id, user_id, subject, content, scheduled_for, status, created_at, updated_at
+		SET subject = $2, content = $3, scheduled_for = $4, status = $5, updated_at = $6, metadata = $7, deleted_at = $8,
+			version = version + 1
+		WHERE id = $1 AND version = $9
+		RETURNING id, user_id, subject, content, scheduled_for, status, created_at, updated_at, deleted_at, version
 	`
 
 	var id, userID uuid.UUID
 	var title, content, status string
 	var scheduledFor, createdAt, updatedAt time.Time
+	var deletedAt sql.NullTime
+	var version int64
 
 	err := p.db.QueryRowContext(
 		ctx,
@@ -577,17 +847,30 @@ func (p *SimplePostgresDB) UpdateMessage(ctx context.Context, msg message.Messag
 		msg.DeliveryDate(),
 		dbStatus,
 		time.Now(),
-		metadataJSON,
-	).Scan(&id, &userID, &title, &content, &scheduledFor, &status, &createdAt, &updatedAt)
+		meta.toJSON(),
+		nullableTime(msg.DeletedAt()),
+		msg.Version(),
+	).Scan(&id, &userID, &title, &content, &scheduledFor, &status, &createdAt, &updatedAt, &deletedAt, &version)
 
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := p.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM messages WHERE id = $1)`, msg.ID()).Scan(&exists); err != nil {
+			return common.Err[message.Message](fmt.Errorf("failed to update message: %w", err))
+		}
+		if !exists {
+			return common.Err[message.Message](fmt.Errorf("message not found"))
+		}
+		return common.Err[message.Message](message.ErrVersionConflict)
+	}
 	if err != nil {
 		return common.Err[message.Message](fmt.Errorf("failed to update message: %w", err))
 	}
 
-	return messageFromDB(id, userID, title, content, scheduledFor, msg.Timezone(), status, string(msg.DeliveryMethod()), createdAt, updatedAt, msg.Recurrence(), msg.ReminderMinutes())
+	return messageFromDB(id, userID, title, content, scheduledFor, status, createdAt, updatedAt, deletedAt, version, meta)
 }
 
-// DeleteMessage deletes a message by ID
+// DeleteMessage permanently deletes a message by ID; its attachment rows
+// go with it through ON DELETE CASCADE
 func (p *SimplePostgresDB) DeleteMessage(ctx context.Context, messageID uuid.UUID) common.Result[bool] {
 	query := `DELETE FROM messages WHERE id = $1`
 
@@ -604,20 +887,120 @@ func (p *SimplePostgresDB) DeleteMessage(ctx context.Context, messageID uuid.UUI
 	return common.Ok(rowsAffected > 0)
 }
 
+// Markers used by ts_headline. Control characters survive HTML escaping, so
+// they can be swapped for <mark> tags after the snippet has been escaped.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+// ts_headline options for title and content snippets
+var (
+	titleHeadlineOptions   = "StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", HighlightAll=true"
+	contentHeadlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+
+	headlineMarkers = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+)
+
+// formatHeadline escapes a ts_headline result and converts its markers to <mark>
+func formatHeadline(headline string) string {
+	return headlineMarkers.Replace(html.EscapeString(headline))
+}
+
+// SearchMessages performs a ranked full-text search over a user's messages
+func (p *SimplePostgresDB) SearchMessages(ctx context.Context, q effects.MessageSearchQuery) common.Result[[]effects.MessageSearchResult] {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	var status, from, to interface{}
+	if q.Criteria.Status.IsSome() {
+		status = toDBStatus(q.Criteria.Status.Value())
+	}
+	if q.Criteria.From.IsSome() {
+		from = q.Criteria.From.Value()
+	}
+	if q.Criteria.To.IsSome() {
+		to = q.Criteria.To.Value()
+	}
+
+	query := `
+		SELECT ` + messageColumns + `,
+			ts_rank_cd(search_vector, query) AS rank,
+			ts_headline('simple', subject, query, $8),
+			ts_headline('simple', content, query, $9)
+		FROM messages, websearch_to_tsquery('simple', $2) query
+		WHERE user_id = $1
+			AND deleted_at IS NULL
+			AND search_vector @@ query
+			AND ($3::text IS NULL OR status = $3::text)
+			AND ($4::timestamptz IS NULL OR ` + visibleDeliveryEnd + ` >= $4::timestamptz)
+			AND ($5::timestamptz IS NULL OR ` + visibleDeliveryStart + ` <= $5::timestamptz)
+		ORDER BY rank DESC, ` + visibleDeliveryStart + ` DESC
+		LIMIT $6 OFFSET $7
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, q.UserID, q.Criteria.Query, status, from, to, limit, q.Offset, titleHeadlineOptions, contentHeadlineOptions)
+	if err != nil {
+		return common.Err[[]effects.MessageSearchResult](fmt.Errorf("failed to search messages: %w", err))
+	}
+	defer rows.Close()
+
+	results := []effects.MessageSearchResult{}
+	for rows.Next() {
+		var rank float64
+		var titleHeadline, contentHeadline string
+
+		msgResult, err := scanMessage(rows, &rank, &titleHeadline, &contentHeadline)
+		if err != nil {
+			return common.Err[[]effects.MessageSearchResult](fmt.Errorf("failed to scan search result: %w", err))
+		}
+		if msgResult.IsErr() {
+			continue
+		}
+
+		results = append(results, effects.MessageSearchResult{
+			Message:        msgResult.Value(),
+			Rank:           rank,
+			TitleSnippet:   formatHeadline(titleHeadline),
+			ContentSnippet: formatHeadline(contentHeadline),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[[]effects.MessageSearchResult](fmt.Errorf("failed to read search results: %w", err))
+	}
+
+	return common.Ok(results)
+}
+
+// attachmentMetadata is the JSON form of attachment details in the
+// message_attachments metadata column
+type attachmentMetadata struct {
+	Thumbnails map[message.ThumbnailSize]string `json:"thumbnails,omitempty"`
+}
+
 // SaveMessageAttachment - placeholder implementation
 func (p *SimplePostgresDB) SaveMessageAttachment(ctx context.Context, attachment message.MessageAttachment) common.Result[message.MessageAttachment] {
+	metadataJSON, err := json.Marshal(attachmentMetadata{Thumbnails: attachment.Thumbnails()})
+	if err != nil {
+		return common.Err[message.MessageAttachment](fmt.Errorf("failed to encode attachment metadata: %w", err))
+	}
+
 	query := `
 		INSERT INTO message_attachments (id, message_id, file_name, file_type, file_size, storage_path, created_at, metadata)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, '{}'::jsonb)
-		RETURNING id, message_id, file_name, file_type, file_size, storage_path, created_at
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, message_id, file_name, file_type, file_size, storage_path, created_at, COALESCE(metadata, '{}'::jsonb)
 	`
 
 	var id, messageID uuid.UUID
 	var fileName, fileType, storagePath string
 	var fileSize int64
 	var createdAt time.Time
+	var savedMetadata []byte
 
-	err := p.db.QueryRowContext(
+	err = p.db.QueryRowContext(
 		ctx,
 		query,
 		attachment.ID(),
@@ -627,12 +1010,18 @@ func (p *SimplePostgresDB) SaveMessageAttachment(ctx context.Context, attachment
 		attachment.FileSize(),
 		attachment.S3Key(),
 		attachment.UploadedAt(),
-	).Scan(&id, &messageID, &fileName, &fileType, &fileSize, &storagePath, &createdAt)
+		metadataJSON,
+	).Scan(&id, &messageID, &fileName, &fileType, &fileSize, &storagePath, &createdAt, &savedMetadata)
 
 	if err != nil {
 		return common.Err[message.MessageAttachment](fmt.Errorf("failed to save attachment: %w", err))
 	}
 
+	var meta attachmentMetadata
+	if err := json.Unmarshal(savedMetadata, &meta); err != nil {
+		return common.Err[message.MessageAttachment](fmt.Errorf("failed to decode attachment metadata: %w", err))
+	}
+
 	stored := message.StoredMessageAttachment{
 		ID:         id,
 		MessageID:  messageID,
@@ -641,15 +1030,42 @@ func (p *SimplePostgresDB) SaveMessageAttachment(ctx context.Context, attachment
 		StorageKey: storagePath,
 		FileSize:   fileSize,
 		UploadedAt: createdAt,
+		Thumbnails: meta.Thumbnails,
 	}
 
 	return message.RestoreMessageAttachment(stored)
 }
 
+// SaveAttachmentThumbnails stores the thumbnail keys of a saved attachment
+func (p *SimplePostgresDB) SaveAttachmentThumbnails(ctx context.Context, attachment message.MessageAttachment) common.Result[bool] {
+	metadataJSON, err := json.Marshal(attachmentMetadata{Thumbnails: attachment.Thumbnails()})
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to encode attachment metadata: %w", err))
+	}
+
+	query := `
+		UPDATE message_attachments
+		SET metadata = COALESCE(metadata, '{}'::jsonb) || $2::jsonb
+		WHERE id = $1
+	`
+
+	result, err := p.db.ExecContext(ctx, query, attachment.ID(), metadataJSON)
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to save attachment thumbnails: %w", err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to get rows affected: %w", err))
+	}
+
+	return common.Ok(rowsAffected > 0)
+}
+
 // FindAttachmentsByMessageID - placeholder implementation
 func (p *SimplePostgresDB) FindAttachmentsByMessageID(ctx context.Context, messageID uuid.UUID) common.Result[[]message.MessageAttachment] {
 	query := `
-		SELECT id, message_id, file_name, file_type, file_size, storage_path, created_at
+		SELECT id, message_id, file_name, file_type, file_size, storage_path, created_at, COALESCE(metadata, '{}'::jsonb)
 		FROM message_attachments
 		WHERE message_id = $1
 		ORDER BY created_at ASC
@@ -667,12 +1083,18 @@ func (p *SimplePostgresDB) FindAttachmentsByMessageID(ctx context.Context, messa
 		var fileName, fileType, storagePath string
 		var fileSize int64
 		var createdAt time.Time
+		var metadataJSON []byte
 
-		err := rows.Scan(&id, &mid, &fileName, &fileType, &fileSize, &storagePath, &createdAt)
+		err := rows.Scan(&id, &mid, &fileName, &fileType, &fileSize, &storagePath, &createdAt, &metadataJSON)
 		if err != nil {
 			return common.Err[[]message.MessageAttachment](fmt.Errorf("failed to scan attachment: %w", err))
 		}
 
+		var meta attachmentMetadata
+		if err := json.Unmarshal(metadataJSON, &meta); err != nil {
+			return common.Err[[]message.MessageAttachment](fmt.Errorf("failed to decode attachment metadata: %w", err))
+		}
+
 		stored := message.StoredMessageAttachment{
 			ID:         id,
 			MessageID:  mid,
@@ -681,6 +1103,7 @@ func (p *SimplePostgresDB) FindAttachmentsByMessageID(ctx context.Context, messa
 			StorageKey: storagePath,
 			FileSize:   fileSize,
 			UploadedAt: createdAt,
+			Thumbnails: meta.Thumbnails,
 		}
 
 		attachmentResult := message.RestoreMessageAttachment(stored)
@@ -725,3 +1148,141 @@ func (p *SimplePostgresDB) FindDeliveryLogsByMessageID(ctx context.Context, mess
 func (p *SimplePostgresDB) Close() error {
 	return p.db.Close()
 }
+
+// extractCheckIn reads check-in settings from message metadata; settings
+// that cannot be decoded are treated as absent
+func extractCheckIn(metadata map[string]interface{}) common.Option[message.CheckInSettings] {
+	raw, ok := metadata["check_in"]
+	if !ok || raw == nil {
+		return common.None[message.CheckInSettings]()
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return common.None[message.CheckInSettings]()
+	}
+
+	var checkIn checkInMetadata
+	if err := json.Unmarshal(encoded, &checkIn); err != nil {
+		return common.None[message.CheckInSettings]()
+	}
+
+	warnedAt := common.None[time.Time]()
+	if checkIn.WarnedAt != nil {
+		warnedAt = common.Some(*checkIn.WarnedAt)
+	}
+
+	settings := message.RestoreCheckInSettings(message.StoredCheckInSettings{
+		Interval:      time.Duration(checkIn.IntervalHours) * time.Hour,
+		GracePeriod:   time.Duration(checkIn.GraceHours) * time.Hour,
+		Recipients:    checkIn.Recipients,
+		LastCheckInAt: checkIn.LastCheckInAt,
+		WarnedAt:      warnedAt,
+	})
+	if settings.IsErr() {
+		return common.None[message.CheckInSettings]()
+	}
+	return common.Some(settings.Value())
+}
+
+// extractSurprise reads a surprise window from message metadata; windows
+// that cannot be decoded are treated as absent
+func extractSurprise(metadata map[string]interface{}) common.Option[message.SurpriseWindow] {
+	raw, ok := metadata["surprise"]
+	if !ok || raw == nil {
+		return common.None[message.SurpriseWindow]()
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return common.None[message.SurpriseWindow]()
+	}
+
+	var surprise surpriseMetadata
+	if err := json.Unmarshal(encoded, &surprise); err != nil {
+		return common.None[message.SurpriseWindow]()
+	}
+
+	seed, err := strconv.ParseUint(surprise.Seed, 10, 64)
+	if err != nil {
+		return common.None[message.SurpriseWindow]()
+	}
+
+	window := message.RestoreSurpriseWindow(message.StoredSurpriseWindow{
+		Earliest: surprise.Earliest,
+		Latest:   surprise.Latest,
+		Seed:     seed,
+	})
+	if window.IsErr() {
+		return common.None[message.SurpriseWindow]()
+	}
+	return common.Some(window.Value())
+}
+
+func extractAnchor(metadata map[string]interface{}) common.Option[message.MilestoneAnchor] {
+	raw, ok := metadata["anchor"]
+	if !ok || raw == nil {
+		return common.None[message.MilestoneAnchor]()
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return common.None[message.MilestoneAnchor]()
+	}
+
+	var anchor anchorMetadata
+	if err := json.Unmarshal(encoded, &anchor); err != nil {
+		return common.None[message.MilestoneAnchor]()
+	}
+
+	at := user.ParseTimeOfDay(anchor.At)
+	milestoneDate, err := time.Parse("2006-01-02", anchor.MilestoneDate)
+	if at.IsErr() || err != nil {
+		return common.None[message.MilestoneAnchor]()
+	}
+
+	restored := message.RestoreMilestoneAnchor(message.StoredMilestoneAnchor{
+		Milestone:     anchor.Milestone,
+		Occurrence:    anchor.Occurrence,
+		OffsetMonths:  anchor.OffsetMonths,
+		OffsetDays:    anchor.OffsetDays,
+		At:            at.Value(),
+		MilestoneDate: milestoneDate,
+	})
+	if restored.IsErr() {
+		return common.None[message.MilestoneAnchor]()
+	}
+	return common.Some(restored.Value())
+}
+
+func extractHoliday(metadata map[string]interface{}) common.Option[message.HolidayTarget] {
+	raw, ok := metadata["holiday"]
+	if !ok || raw == nil {
+		return common.None[message.HolidayTarget]()
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return common.None[message.HolidayTarget]()
+	}
+
+	var target holidayMetadata
+	if err := json.Unmarshal(encoded, &target); err != nil {
+		return common.None[message.HolidayTarget]()
+	}
+
+	at := user.ParseTimeOfDay(target.At)
+	if at.IsErr() {
+		return common.None[message.HolidayTarget]()
+	}
+
+	restored := message.RestoreHolidayTarget(message.StoredHolidayTarget{
+		Country: target.Country,
+		Holiday: target.Holiday,
+		At:      at.Value(),
+	})
+	if restored.IsErr() {
+		return common.None[message.HolidayTarget]()
+	}
+	return common.Some(restored.Value())
+}
@@ -0,0 +1,46 @@
+// Package database provides delivery load counts for the PostgreSQL adapter
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// CountDeliveriesPerMinute counts the pending deliveries due from from up to
+// but excluding to, per minute, leaving out one message
+func (p *SimplePostgresDB) CountDeliveriesPerMinute(ctx context.Context, from, to time.Time, exclude uuid.UUID) common.Result[map[time.Time]int] {
+	query := `
+		SELECT date_trunc('minute', scheduled_for AT TIME ZONE 'UTC') AS minute, COUNT(*)
+		FROM messages
+		WHERE status IN ('scheduled', 'armed') AND deleted_at IS NULL
+			AND scheduled_for >= $1 AND scheduled_for < $2 AND id <> $3
+		GROUP BY minute
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, from, to, exclude)
+	if err != nil {
+		return common.Err[map[time.Time]int](fmt.Errorf("failed to count deliveries: %w", err))
+	}
+	defer rows.Close()
+
+	counts := make(map[time.Time]int)
+	for rows.Next() {
+		var minute time.Time
+		var count int
+		if err := rows.Scan(&minute, &count); err != nil {
+			return common.Err[map[time.Time]int](fmt.Errorf("failed to scan delivery count: %w", err))
+		}
+		// A timestamp without time zone is read back as UTC wall time
+		counts[time.Date(minute.Year(), minute.Month(), minute.Day(), minute.Hour(), minute.Minute(), 0, 0, time.UTC)] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[map[time.Time]int](fmt.Errorf("failed to read delivery counts: %w", err))
+	}
+
+	return common.Ok(counts)
+}
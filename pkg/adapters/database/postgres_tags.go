@@ -0,0 +1,330 @@
+// Package database provides tag persistence for the PostgreSQL adapter
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// tagFromDB reconstructs a Tag from database values
+func tagFromDB(id, userID uuid.UUID, name string, createdAt time.Time) common.Result[message.Tag] {
+	return message.RestoreTag(message.StoredTag{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: createdAt,
+	})
+}
+
+// SaveTag inserts a new tag
+func (p *SimplePostgresDB) SaveTag(ctx context.Context, tag message.Tag) common.Result[message.Tag] {
+	query := `
+		INSERT INTO tags (id, user_id, name, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, name, created_at
+	`
+
+	var id, userID uuid.UUID
+	var name string
+	var createdAt time.Time
+
+	err := p.db.QueryRowContext(ctx, query, tag.ID(), tag.UserID(), tag.Name(), tag.CreatedAt()).
+		Scan(&id, &userID, &name, &createdAt)
+	if isUniqueViolation(err) {
+		return common.Err[message.Tag](message.ErrTagExists)
+	}
+	if err != nil {
+		return common.Err[message.Tag](fmt.Errorf("failed to save tag: %w", err))
+	}
+
+	return tagFromDB(id, userID, name, createdAt)
+}
+
+// FindTagByID finds a tag by ID
+func (p *SimplePostgresDB) FindTagByID(ctx context.Context, tagID uuid.UUID) common.Result[message.Tag] {
+	query := `SELECT id, user_id, name, created_at FROM tags WHERE id = $1`
+
+	var id, userID uuid.UUID
+	var name string
+	var createdAt time.Time
+
+	err := p.db.QueryRowContext(ctx, query, tagID).Scan(&id, &userID, &name, &createdAt)
+	if err == sql.ErrNoRows {
+		return common.Err[message.Tag](fmt.Errorf("tag not found"))
+	}
+	if err != nil {
+		return common.Err[message.Tag](fmt.Errorf("failed to find tag: %w", err))
+	}
+
+	return tagFromDB(id, userID, name, createdAt)
+}
+
+// FindTagsByUserID lists a user's tags with the number of messages using each
+func (p *SimplePostgresDB) FindTagsByUserID(ctx context.Context, userID uuid.UUID) common.Result[[]effects.TagUsage] {
+	query := `
+		SELECT t.id, t.user_id, t.name, t.created_at, COUNT(m.id)
+		FROM tags t
+		LEFT JOIN message_tags mt ON mt.tag_id = t.id
+		LEFT JOIN messages m ON m.id = mt.message_id AND m.deleted_at IS NULL
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY lower(t.name) ASC
+	`
+
+	rows, err := p.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return common.Err[[]effects.TagUsage](fmt.Errorf("failed to query tags: %w", err))
+	}
+	defer rows.Close()
+
+	usages := []effects.TagUsage{}
+	for rows.Next() {
+		var id, uid uuid.UUID
+		var name string
+		var createdAt time.Time
+		var count int
+
+		if err := rows.Scan(&id, &uid, &name, &createdAt, &count); err != nil {
+			return common.Err[[]effects.TagUsage](fmt.Errorf("failed to scan tag: %w", err))
+		}
+
+		tagResult := tagFromDB(id, uid, name, createdAt)
+		if tagResult.IsErr() {
+			return common.Err[[]effects.TagUsage](tagResult.Error())
+		}
+
+		usages = append(usages, effects.TagUsage{Tag: tagResult.Value(), MessageCount: count})
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[[]effects.TagUsage](fmt.Errorf("failed to read tags: %w", err))
+	}
+
+	return common.Ok(usages)
+}
+
+// UpdateTag renames an existing tag
+func (p *SimplePostgresDB) UpdateTag(ctx context.Context, tag message.Tag) common.Result[message.Tag] {
+	query := `
+		UPDATE tags SET name = $2
+		WHERE id = $1
+		RETURNING id, user_id, name, created_at
+	`
+
+	var id, userID uuid.UUID
+	var name string
+	var createdAt time.Time
+
+	err := p.db.QueryRowContext(ctx, query, tag.ID(), tag.Name()).Scan(&id, &userID, &name, &createdAt)
+	if isUniqueViolation(err) {
+		return common.Err[message.Tag](message.ErrTagExists)
+	}
+	if err == sql.ErrNoRows {
+		return common.Err[message.Tag](fmt.Errorf("tag not found"))
+	}
+	if err != nil {
+		return common.Err[message.Tag](fmt.Errorf("failed to update tag: %w", err))
+	}
+
+	return tagFromDB(id, userID, name, createdAt)
+}
+
+// DeleteTag deletes a tag and detaches it from all messages
+func (p *SimplePostgresDB) DeleteTag(ctx context.Context, tagID uuid.UUID) common.Result[bool] {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, tagID)
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to delete tag: %w", err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to get rows affected: %w", err))
+	}
+
+	return common.Ok(rowsAffected > 0)
+}
+
+// MergeTags moves every message tagged with source onto target and deletes source
+func (p *SimplePostgresDB) MergeTags(ctx context.Context, sourceID, targetID uuid.UUID) common.Result[bool] {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO message_tags (message_id, tag_id)
+		SELECT message_id, $2 FROM message_tags WHERE tag_id = $1
+		ON CONFLICT DO NOTHING
+	`, sourceID, targetID)
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to move tagged messages: %w", err))
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, sourceID); err != nil {
+		return common.Err[bool](fmt.Errorf("failed to delete merged tag: %w", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return common.Err[bool](fmt.Errorf("failed to commit tag merge: %w", err))
+	}
+
+	return common.Ok(true)
+}
+
+// EnsureTags returns the user's tags with the given names, creating missing ones
+func (p *SimplePostgresDB) EnsureTags(ctx context.Context, userID uuid.UUID, names []string) common.Result[[]message.Tag] {
+	if len(names) == 0 {
+		return common.Ok([]message.Tag{})
+	}
+
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		tagResult := message.NewTag(userID, name)
+		if tagResult.IsErr() {
+			return common.Err[[]message.Tag](tagResult.Error())
+		}
+		tag := tagResult.Value()
+
+		_, err := p.db.ExecContext(ctx, `
+			INSERT INTO tags (id, user_id, name, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, (lower(name))) DO NOTHING
+		`, tag.ID(), tag.UserID(), tag.Name(), tag.CreatedAt())
+		if err != nil {
+			return common.Err[[]message.Tag](fmt.Errorf("failed to create tag: %w", err))
+		}
+		keys = append(keys, tag.Key())
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, user_id, name, created_at
+		FROM tags
+		WHERE user_id = $1 AND lower(name) = ANY($2)
+		ORDER BY lower(name) ASC
+	`, userID, pq.Array(keys))
+	if err != nil {
+		return common.Err[[]message.Tag](fmt.Errorf("failed to load tags: %w", err))
+	}
+	defer rows.Close()
+
+	tags := []message.Tag{}
+	for rows.Next() {
+		var id, uid uuid.UUID
+		var name string
+		var createdAt time.Time
+
+		if err := rows.Scan(&id, &uid, &name, &createdAt); err != nil {
+			return common.Err[[]message.Tag](fmt.Errorf("failed to scan tag: %w", err))
+		}
+
+		tagResult := tagFromDB(id, uid, name, createdAt)
+		if tagResult.IsErr() {
+			return common.Err[[]message.Tag](tagResult.Error())
+		}
+		tags = append(tags, tagResult.Value())
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[[]message.Tag](fmt.Errorf("failed to read tags: %w", err))
+	}
+
+	return common.Ok(tags)
+}
+
+// SetMessageTags replaces the tags attached to a message
+func (p *SimplePostgresDB) SetMessageTags(ctx context.Context, messageID uuid.UUID, tagIDs []uuid.UUID) common.Result[bool] {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return common.Err[bool](fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM message_tags WHERE message_id = $1`, messageID); err != nil {
+		return common.Err[bool](fmt.Errorf("failed to clear message tags: %w", err))
+	}
+
+	if len(tagIDs) > 0 {
+		ids := make([]string, len(tagIDs))
+		for i, id := range tagIDs {
+			ids[i] = id.String()
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO message_tags (message_id, tag_id)
+			SELECT $1, unnest($2::uuid[])
+			ON CONFLICT DO NOTHING
+		`, messageID, pq.Array(ids))
+		if err != nil {
+			return common.Err[bool](fmt.Errorf("failed to attach message tags: %w", err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return common.Err[bool](fmt.Errorf("failed to commit message tags: %w", err))
+	}
+
+	return common.Ok(true)
+}
+
+// FindTagsForMessages loads the tags of several messages at once
+func (p *SimplePostgresDB) FindTagsForMessages(ctx context.Context, messageIDs []uuid.UUID) common.Result[map[uuid.UUID][]message.Tag] {
+	tagsByMessage := make(map[uuid.UUID][]message.Tag, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return common.Ok(tagsByMessage)
+	}
+
+	ids := make([]string, len(messageIDs))
+	for i, id := range messageIDs {
+		ids[i] = id.String()
+	}
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT mt.message_id, t.id, t.user_id, t.name, t.created_at
+		FROM message_tags mt
+		JOIN tags t ON t.id = mt.tag_id
+		WHERE mt.message_id = ANY($1::uuid[])
+		ORDER BY lower(t.name) ASC
+	`, pq.Array(ids))
+	if err != nil {
+		return common.Err[map[uuid.UUID][]message.Tag](fmt.Errorf("failed to query message tags: %w", err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID, id, uid uuid.UUID
+		var name string
+		var createdAt time.Time
+
+		if err := rows.Scan(&messageID, &id, &uid, &name, &createdAt); err != nil {
+			return common.Err[map[uuid.UUID][]message.Tag](fmt.Errorf("failed to scan message tag: %w", err))
+		}
+
+		tagResult := tagFromDB(id, uid, name, createdAt)
+		if tagResult.IsErr() {
+			return common.Err[map[uuid.UUID][]message.Tag](tagResult.Error())
+		}
+		tagsByMessage[messageID] = append(tagsByMessage[messageID], tagResult.Value())
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[map[uuid.UUID][]message.Tag](fmt.Errorf("failed to read message tags: %w", err))
+	}
+
+	return common.Ok(tagsByMessage)
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+)
+
+// FindDeletedMessages lists the messages in a user's trash, most recently
+// deleted first
+func (p *SimplePostgresDB) FindDeletedMessages(ctx context.Context, userID uuid.UUID, limit, offset int) common.Result[[]message.Message] {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	return p.queryMessages(ctx, "failed to find deleted messages", query, userID, limit, offset)
+}
+
+// FindPurgeableMessages returns messages that were moved to the trash before
+// deletedBefore, oldest first
+func (p *SimplePostgresDB) FindPurgeableMessages(ctx context.Context, deletedBefore time.Time, limit int) common.Result[[]message.Message] {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE deleted_at IS NOT NULL AND deleted_at <= $1
+		ORDER BY deleted_at ASC
+		LIMIT $2
+	`
+
+	return p.queryMessages(ctx, "failed to find purgeable messages", query, deletedBefore, limit)
+}
+
+// queryMessages runs a query selecting messageColumns and scans every row
+func (p *SimplePostgresDB) queryMessages(ctx context.Context, failure string, query string, args ...interface{}) common.Result[[]message.Message] {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return common.Err[[]message.Message](fmt.Errorf("%s: %w", failure, err))
+	}
+	defer rows.Close()
+
+	messages := []message.Message{}
+	for rows.Next() {
+		msgResult, err := scanMessage(rows)
+		if err != nil {
+			return common.Err[[]message.Message](fmt.Errorf("failed to scan message: %w", err))
+		}
+		if msgResult.IsErr() {
+			return common.Err[[]message.Message](msgResult.Error())
+		}
+
+		messages = append(messages, msgResult.Value())
+	}
+
+	if err := rows.Err(); err != nil {
+		return common.Err[[]message.Message](fmt.Errorf("%s: %w", failure, err))
+	}
+
+	return common.Ok(messages)
+}
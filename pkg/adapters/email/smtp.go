@@ -2,13 +2,21 @@
 package email
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
 	"net/smtp"
+	"strings"
 	"time"
 
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
 	"github.com/thanhphuchuynh/dear-future/pkg/effects"
 )
@@ -23,6 +31,7 @@ type SMTPConfig struct {
 	FromName      string // Display name for sender
 	UseTLS        bool   // Use TLS (STARTTLS)
 	SkipTLSVerify bool   // Skip TLS certificate verification (for dev only)
+	TrackOpens    bool   // Embed an open-tracking pixel in delivered messages
 }
 
 // SMTPEmailService implements EmailService using SMTP
@@ -53,10 +62,16 @@ func NewSMTPEmailService(config SMTPConfig) (*SMTPEmailService, error) {
 // SendMessage sends a scheduled message via email
 func (s *SMTPEmailService) SendMessage(ctx context.Context, deliveryInfo message.MessageDeliveryInfo) common.Result[effects.EmailResult] {
 	subject := deliveryInfo.Subject
-	body := s.buildMessageBody(deliveryInfo)
 	recipient := deliveryInfo.RecipientEmail
 
-	err := s.sendEmail(ctx, recipient, subject, body)
+	body, err := s.buildMessageBody(deliveryInfo)
+	var text string
+	if err == nil {
+		text, err = s.buildTextBody(deliveryInfo)
+	}
+	if err == nil {
+		err = s.sendEmail(ctx, recipient, subject, body, text, "", deliveryInfo.Attachments)
+	}
 	if err != nil {
 		return common.Ok(effects.EmailResult{
 			MessageID: deliveryInfo.Message.ID().String(),
@@ -78,12 +93,14 @@ func (s *SMTPEmailService) SendMessage(ctx context.Context, deliveryInfo message
 	})
 }
 
-// SendVerificationEmail sends an email verification email
+// SendVerificationEmail sends an email verification email in the locale of
+// the request that asked for it
 func (s *SMTPEmailService) SendVerificationEmail(ctx context.Context, email, verificationToken string) common.Result[effects.EmailResult] {
-	subject := "Verify your Dear Future account"
-	body := s.buildVerificationEmailBody(email, verificationToken)
+	locale := i18n.FromContext(ctx)
+	subject := locale.T("verify.subject")
+	body := s.buildVerificationEmailBody(locale, verificationToken)
 
-	err := s.sendEmail(ctx, email, subject, body)
+	err := s.sendEmail(ctx, email, subject, body, "", "", nil)
 	if err != nil {
 		return common.Ok(effects.EmailResult{
 			MessageID: "",
@@ -105,12 +122,14 @@ func (s *SMTPEmailService) SendVerificationEmail(ctx context.Context, email, ver
 	})
 }
 
-// SendPasswordResetEmail sends a password reset email
+// SendPasswordResetEmail sends a password reset email in the locale of the
+// request that asked for it
 func (s *SMTPEmailService) SendPasswordResetEmail(ctx context.Context, email, resetToken string) common.Result[effects.EmailResult] {
-	subject := "Reset your Dear Future password"
-	body := s.buildPasswordResetEmailBody(email, resetToken)
+	locale := i18n.FromContext(ctx)
+	subject := locale.T("reset.subject")
+	body := s.buildPasswordResetEmailBody(locale, resetToken)
 
-	err := s.sendEmail(ctx, email, subject, body)
+	err := s.sendEmail(ctx, email, subject, body, "", "", nil)
 	if err != nil {
 		return common.Ok(effects.EmailResult{
 			MessageID: "",
@@ -132,6 +151,24 @@ func (s *SMTPEmailService) SendPasswordResetEmail(ctx context.Context, email, re
 	})
 }
 
+// PreviewMessage renders a message exactly as SendMessage would, without sending it
+func (s *SMTPEmailService) PreviewMessage(ctx context.Context, deliveryInfo message.MessageDeliveryInfo) common.Result[effects.EmailPreview] {
+	body, err := s.buildMessageBody(deliveryInfo)
+	if err != nil {
+		return common.Err[effects.EmailPreview](err)
+	}
+	text, err := s.buildTextBody(deliveryInfo)
+	if err != nil {
+		return common.Err[effects.EmailPreview](err)
+	}
+
+	return common.Ok(effects.EmailPreview{
+		Subject:  deliveryInfo.Subject,
+		HTMLBody: body,
+		TextBody: text,
+	})
+}
+
 // ValidateEmailConfiguration validates the SMTP configuration by attempting to connect
 func (s *SMTPEmailService) ValidateEmailConfiguration(ctx context.Context) common.Result[bool] {
 	addr := fmt.Sprintf("%s:%s", s.config.Host, s.config.Port)
@@ -189,138 +226,330 @@ func (s *SMTPEmailService) ValidateEmailConfiguration(ctx context.Context) commo
 	return common.Ok(true)
 }
 
-// sendEmail sends an email via SMTP
-func (s *SMTPEmailService) sendEmail(ctx context.Context, to, subject, body string) error {
-	from := s.config.FromEmail
-	msg := s.buildEmailMessage(from, to, subject, body)
-
-	auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
-	addr := fmt.Sprintf("%s:%s", s.config.Host, s.config.Port)
-
-	// For Gmail and most modern SMTP servers, use STARTTLS on port 587
-	if s.config.UseTLS && s.config.Port == "465" {
-		// Use TLS directly (SSL)
-		return s.sendEmailTLS(addr, auth, from, []string{to}, msg)
-	}
-
-	// Use STARTTLS (port 587)
-	return smtp.SendMail(addr, auth, from, []string{to}, []byte(msg))
-}
-
-// sendEmailTLS sends email using TLS (for port 465)
-func (s *SMTPEmailService) sendEmailTLS(addr string, auth smtp.Auth, from string, to []string, msg string) error {
-	tlsConfig := &tls.Config{
-		ServerName:         s.config.Host,
-		InsecureSkipVerify: s.config.SkipTLSVerify,
-	}
-
-	conn, err := tls.Dial("tcp", addr, tlsConfig)
+// sendEmail sends an email via SMTP. textBody, unsubscribeURL and
+// attachments are optional.
+func (s *SMTPEmailService) sendEmail(ctx context.Context, to, subject, htmlBody, textBody, unsubscribeURL string, attachments []message.DeliveredAttachment) error {
+	client, err := s.dial()
 	if err != nil {
-		return fmt.Errorf("TLS connection failed: %w", err)
-	}
-	defer conn.Close()
-
-	client, err := smtp.NewClient(conn, s.config.Host)
-	if err != nil {
-		return fmt.Errorf("SMTP client creation failed: %w", err)
+		return err
 	}
 	defer client.Quit()
 
-	if err = client.Auth(auth); err != nil {
-		return fmt.Errorf("SMTP authentication failed: %w", err)
-	}
+	from := s.config.FromEmail
+	return s.transmit(client, from, to, func(w io.Writer) error {
+		return s.writeEmailMessage(w, from, to, subject, htmlBody, textBody, unsubscribeURL, attachments)
+	})
+}
 
-	if err = client.Mail(from); err != nil {
+// transmit sends one email over an established SMTP session, writing it
+// straight to the server so attachments are never held in memory. An email
+// that fails while being written is abandoned by closing the connection,
+// since ending the data would deliver it truncated.
+func (s *SMTPEmailService) transmit(client *smtp.Client, from, to string, write func(w io.Writer) error) error {
+	if err := client.Mail(from); err != nil {
 		return fmt.Errorf("MAIL command failed: %w", err)
 	}
-
-	for _, recipient := range to {
-		if err = client.Rcpt(recipient); err != nil {
-			return fmt.Errorf("RCPT command failed: %w", err)
-		}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("RCPT command failed: %w", err)
 	}
 
 	w, err := client.Data()
 	if err != nil {
 		return fmt.Errorf("DATA command failed: %w", err)
 	}
-
-	_, err = w.Write([]byte(msg))
-	if err != nil {
+	if err := write(w); err != nil {
+		client.Close()
 		return fmt.Errorf("writing message failed: %w", err)
 	}
-
-	err = w.Close()
-	if err != nil {
+	if err := w.Close(); err != nil {
 		return fmt.Errorf("closing data writer failed: %w", err)
 	}
 
 	return nil
 }
 
-// buildEmailMessage builds a properly formatted email message
-func (s *SMTPEmailService) buildEmailMessage(from, to, subject, body string) string {
+// writeEmailMessage writes a properly formatted email message. When a text
+// body is provided the message is sent as multipart/alternative so clients
+// without HTML support still get a readable version. Embedded attachments
+// are added as inline images and attached files (see emailBody). An
+// unsubscribe URL is announced in the List-Unsubscribe headers for one-click
+// unsubscribing (RFC 8058).
+func (s *SMTPEmailService) writeEmailMessage(w io.Writer, from, to, subject, htmlBody, textBody, unsubscribeURL string, attachments []message.DeliveredAttachment) error {
 	fromHeader := from
 	if s.config.FromName != "" {
-		fromHeader = fmt.Sprintf("%s <%s>", s.config.FromName, from)
+		fromHeader = (&mail.Address{Name: s.config.FromName, Address: from}).String()
 	}
 
-	return fmt.Sprintf("From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"MIME-Version: 1.0\r\n"+
-		"Content-Type: text/html; charset=UTF-8\r\n"+
-		"\r\n"+
-		"%s", fromHeader, to, subject, body)
+	var headers bytes.Buffer
+	fmt.Fprintf(&headers, "From: %s\r\n", fromHeader)
+	fmt.Fprintf(&headers, "To: %s\r\n", to)
+	fmt.Fprintf(&headers, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	if unsubscribeURL != "" {
+		fmt.Fprintf(&headers, "List-Unsubscribe: <%s>\r\n", unsubscribeURL)
+		headers.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
+	headers.WriteString("MIME-Version: 1.0\r\n")
+	if _, err := w.Write(headers.Bytes()); err != nil {
+		return err
+	}
+
+	return emailBody(htmlBody, textBody, attachments).writeTo(w)
 }
 
-// buildMessageBody builds the HTML body for a scheduled message
-func (s *SMTPEmailService) buildMessageBody(deliveryInfo message.MessageDeliveryInfo) string {
-	return fmt.Sprintf(`
+// writeQuotedPrintable writes body to w using quoted-printable encoding
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	return nil
+}
+
+// messageBodyTemplate renders delivered messages. html/template escapes the
+// title and date; Content is already sanitized by the message domain.
+var messageBodyTemplate = template.Must(template.New("message").Parse(`
 <!DOCTYPE html>
-<html>
+<html lang="{{.Locale.OrDefault}}">
 <head>
     <meta charset="UTF-8">
     <style>
         body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .header { background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
         .content { background: #f9f9f9; padding: 30px; border-radius: 0 0 10px 10px; }
         .message { background: white; padding: 20px; border-left: 4px solid #667eea; margin: 20px 0; border-radius: 5px; }
         .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
         .scheduled-date { color: #667eea; font-weight: bold; }
+        .thread { margin: 20px 0; }
+        .quoted { border-left: 3px solid #ccc; padding: 0 0 0 15px; margin: 15px 0; color: #666; }
+        .quoted-meta { font-size: 13px; color: #999; }
+        .read-receipt { text-align: center; margin: 20px 0; }
+        .read-receipt a { color: #667eea; }
+        .images img { display: block; max-width: 100%; height: auto; margin: 15px auto; border-radius: 5px; }
+        .attachments { background: white; padding: 15px 20px; margin: 20px 0; border-radius: 5px; }
+        .attachments-expiry { font-size: 13px; color: #999; }
     </style>
 </head>
 <body>
     <div class="header">
-        <h1>📬 A Message from Your Past Self</h1>
-        <p>Scheduled for: <span class="scheduled-date">%s</span></p>
+        <h1>{{.Locale.T "letter.heading"}}</h1>
+        {{if .ScheduledFor}}<p>{{.Locale.T "letter.scheduled_for"}} <span class="scheduled-date">{{.ScheduledFor}}</span></p>{{end}}
     </div>
     <div class="content">
-        <h2>%s</h2>
+        <h2>{{.Subject}}</h2>
         <div class="message">
-            <p>%s</p>
+            {{.Content}}
+        </div>
+        {{if .Images}}
+        <div class="images">
+            {{range .Images}}<img src="{{.Src}}" alt="{{.Alt}}">{{end}}
+        </div>
+        {{end}}
+        {{if .Links}}
+        <div class="attachments">
+            <h3>{{.Locale.T "letter.attachments"}}</h3>
+            <ul>{{range .Links}}
+                <li><a href="{{.URL}}">{{.Name}}</a> ({{.Size}})</li>{{end}}
+            </ul>
+            <p class="attachments-expiry">{{.Locale.T "letter.links_expire" .LinksExpire}}</p>
         </div>
+        {{end}}
+        {{if .Thread}}
+        <div class="thread">
+            <h3>{{.Locale.T "letter.thread"}}</h3>
+            {{range .Thread}}
+            <div class="quoted">
+                <p class="quoted-meta">{{$.Locale.T "letter.thread_entry" .WrittenAt .DeliveredAt}}</p>
+                <h4>{{.Title}}</h4>
+                {{.Content}}
+            </div>
+            {{end}}
+        </div>
+        {{end}}
+        {{if .ReadURL}}
+        <p class="read-receipt"><a href="{{.ReadURL}}">{{.Locale.T "letter.read_link"}}</a></p>
+        {{end}}
         <div class="footer">
-            <p>This message was sent by <strong>Dear Future</strong></p>
-            <p>Your message to tomorrow, delivered today.</p>
+            <p>{{.Locale.T "letter.sent_by"}} <strong>Dear Future</strong></p>
+            <p>{{.Locale.T "letter.tagline"}}</p>
         </div>
     </div>
+    {{if .PixelURL}}<img src="{{.PixelURL}}" width="1" height="1" alt="" style="display:block;border:0;">{{end}}
 </body>
 </html>
-`, deliveryInfo.ScheduledTime.Format("January 2, 2006 at 3:04 PM"), deliveryInfo.Subject, deliveryInfo.Body)
+`))
+
+// messageBodyData holds the values rendered into messageBodyTemplate
+type messageBodyData struct {
+	Locale       i18n.Locale
+	ScheduledFor string // Empty when the delivery time is hidden
+	Subject      string
+	Content      template.HTML
+	Images       []inlineImageData // Images shown by Content-ID below the content
+	Links        []linkedFileData  // Files too large to attach
+	LinksExpire  string
+	Thread       []threadEntryData
+	ReadURL      string // "Mark as read" link, empty without a receipt
+	PixelURL     string // Open-tracking pixel, empty unless tracking is enabled
+}
+
+// threadEntryData holds a quoted letter rendered below the message
+type threadEntryData struct {
+	Title       string
+	WrittenAt   string
+	DeliveredAt string
+	Content     template.HTML
+}
+
+// buildMessageBody builds the HTML body for a scheduled message
+func (s *SMTPEmailService) buildMessageBody(deliveryInfo message.MessageDeliveryInfo) (string, error) {
+	content, err := renderedContent(deliveryInfo)
+	if err != nil {
+		return "", err
+	}
+
+	// Quoted letters are rendered by the message domain like the content
+	thread := make([]threadEntryData, 0, len(deliveryInfo.Thread))
+	for _, entry := range deliveryInfo.Thread {
+		thread = append(thread, threadEntryData{
+			Title:       entry.Title,
+			WrittenAt:   deliveryInfo.Locale.FormatDate(entry.WrittenAt, deliveryInfo.Location),
+			DeliveredAt: deliveryInfo.Locale.FormatDate(entry.DeliveredAt, deliveryInfo.Location),
+			Content:     template.HTML(entry.HTMLContent),
+		})
+	}
+
+	readURL, pixelURL := s.receiptLinks(deliveryInfo)
+	images, links, linksExpire := attachmentData(deliveryInfo.Attachments)
+
+	scheduledFor := ""
+	if !deliveryInfo.ScheduledTime.IsZero() {
+		scheduledFor = deliveryInfo.Locale.FormatDateTime(deliveryInfo.ScheduledTime, deliveryInfo.Location)
+	}
+
+	var buf bytes.Buffer
+	err = messageBodyTemplate.Execute(&buf, messageBodyData{
+		Locale:       deliveryInfo.Locale,
+		ScheduledFor: scheduledFor,
+		Subject:      deliveryInfo.Subject,
+		Content:      template.HTML(content.HTML),
+		Images:       images,
+		Links:        links,
+		LinksExpire:  deliveryInfo.Locale.FormatDateTime(linksExpire, deliveryInfo.Location),
+		Thread:       thread,
+		ReadURL:      readURL,
+		PixelURL:     pixelURL,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render message body: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// renderedContent returns the rendered content of a delivered message
+func renderedContent(deliveryInfo message.MessageDeliveryInfo) (message.RenderedContent, error) {
+	if deliveryInfo.HTMLContent != "" {
+		return message.RenderedContent{HTML: deliveryInfo.HTMLContent, Text: deliveryInfo.TextContent}, nil
+	}
+
+	// Delivery info built without rendering; render the message itself
+	rendered := message.RenderContent(deliveryInfo.Message.Content(), deliveryInfo.Message.ContentFormat())
+	if rendered.IsErr() {
+		return message.RenderedContent{}, rendered.Error()
+	}
+	return rendered.Value(), nil
+}
+
+// buildTextBody builds the plain-text alternative of a delivered message
+// with the same sections as buildMessageBody, so text-only mail clients and
+// previews show the rendered content rather than its Markdown source
+func (s *SMTPEmailService) buildTextBody(deliveryInfo message.MessageDeliveryInfo) (string, error) {
+	content, err := renderedContent(deliveryInfo)
+	if err != nil {
+		return "", err
+	}
+
+	locale := deliveryInfo.Locale
+	var text strings.Builder
+	text.WriteString(locale.T("letter.heading") + "\n")
+	if !deliveryInfo.ScheduledTime.IsZero() {
+		text.WriteString(locale.T("letter.scheduled_for") + " " + locale.FormatDateTime(deliveryInfo.ScheduledTime, deliveryInfo.Location) + "\n")
+	}
+	text.WriteString("\n" + deliveryInfo.Subject + "\n\n")
+	text.WriteString(strings.TrimRight(content.Text, "\n") + "\n")
+
+	if _, links, linksExpire := attachmentData(deliveryInfo.Attachments); len(links) > 0 {
+		fmt.Fprintf(&text, "\n%s:\n", locale.T("letter.attachments"))
+		for _, link := range links {
+			fmt.Fprintf(&text, "- %s (%s): %s\n", link.Name, link.Size, link.URL)
+		}
+		text.WriteString(locale.T("letter.links_expire", locale.FormatDateTime(linksExpire, deliveryInfo.Location)) + "\n")
+	}
+
+	if len(deliveryInfo.Thread) > 0 {
+		fmt.Fprintf(&text, "\n%s:\n", locale.T("letter.thread"))
+		for _, entry := range deliveryInfo.Thread {
+			text.WriteString("\n> " + locale.T("letter.thread_entry", locale.FormatDate(entry.WrittenAt, deliveryInfo.Location), locale.FormatDate(entry.DeliveredAt, deliveryInfo.Location)) + "\n")
+			if entry.Title != "" {
+				text.WriteString("> " + entry.Title + "\n>\n")
+			}
+			for _, line := range strings.Split(strings.TrimRight(entry.TextContent, "\n"), "\n") {
+				text.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+		}
+	}
+
+	if readURL, _ := s.receiptLinks(deliveryInfo); readURL != "" {
+		text.WriteString("\n" + locale.T("letter.read_link") + ":\n" + readURL + "\n")
+	}
+
+	text.WriteString("\n---\n" + locale.T("letter.sent_by") + " Dear Future\n" + locale.T("letter.tagline") + "\n")
+	return text.String(), nil
+}
+
+// accountEmailData holds the values rendered into the account email templates
+type accountEmailData struct {
+	Locale i18n.Locale
+	URL    string
 }
 
 // buildVerificationEmailBody builds the verification email HTML body
-func (s *SMTPEmailService) buildVerificationEmailBody(email, token string) string {
-	verificationURL := fmt.Sprintf("https://dearfuture.app/verify?token=%s", token)
-	return fmt.Sprintf(`
+func (s *SMTPEmailService) buildVerificationEmailBody(locale i18n.Locale, token string) string {
+	return renderAccountEmail(verificationEmailTemplate, accountEmailData{
+		Locale: locale,
+		URL:    fmt.Sprintf("https://dearfuture.app/verify?token=%s", token),
+	})
+}
+
+// buildPasswordResetEmailBody builds the password reset email HTML body
+func (s *SMTPEmailService) buildPasswordResetEmailBody(locale i18n.Locale, token string) string {
+	return renderAccountEmail(passwordResetEmailTemplate, accountEmailData{
+		Locale: locale,
+		URL:    fmt.Sprintf("https://dearfuture.app/reset-password?token=%s", token),
+	})
+}
+
+// renderAccountEmail renders an account email template. The templates only
+// fail on programming errors, so an empty body is returned in that case.
+func renderAccountEmail(tmpl *template.Template, data accountEmailData) string {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+var verificationEmailTemplate = template.Must(template.New("verification").Parse(`
 <!DOCTYPE html>
-<html>
+<html lang="{{.Locale.OrDefault}}">
 <head>
     <meta charset="UTF-8">
     <style>
         body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .header { background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
         .content { background: #f9f9f9; padding: 30px; border-radius: 0 0 10px 10px; }
         .button { display: inline-block; padding: 15px 30px; background: #667eea; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
         .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
@@ -328,36 +557,32 @@ func (s *SMTPEmailService) buildVerificationEmailBody(email, token string) strin
 </head>
 <body>
     <div class="header">
-        <h1>Welcome to Dear Future!</h1>
+        <h1>{{.Locale.T "verify.heading"}}</h1>
     </div>
     <div class="content">
-        <p>Hello,</p>
-        <p>Thank you for signing up! Please verify your email address to start sending messages to your future self.</p>
+        <p>{{.Locale.T "email.greeting"}}</p>
+        <p>{{.Locale.T "verify.body"}}</p>
         <center>
-            <a href="%s" class="button">Verify Email Address</a>
+            <a href="{{.URL}}" class="button">{{.Locale.T "verify.button"}}</a>
         </center>
-        <p>Or copy and paste this link into your browser:</p>
-        <p style="word-break: break-all; color: #667eea;">%s</p>
+        <p>{{.Locale.T "email.copy_link"}}</p>
+        <p style="word-break: break-all; color: #667eea;">{{.URL}}</p>
         <div class="footer">
-            <p>If you didn't create this account, you can safely ignore this email.</p>
+            <p>{{.Locale.T "verify.footer"}}</p>
         </div>
     </div>
 </body>
 </html>
-`, verificationURL, verificationURL)
-}
+`))
 
-// buildPasswordResetEmailBody builds the password reset email HTML body
-func (s *SMTPEmailService) buildPasswordResetEmailBody(email, token string) string {
-	resetURL := fmt.Sprintf("https://dearfuture.app/reset-password?token=%s", token)
-	return fmt.Sprintf(`
+var passwordResetEmailTemplate = template.Must(template.New("password-reset").Parse(`
 <!DOCTYPE html>
-<html>
+<html lang="{{.Locale.OrDefault}}">
 <head>
     <meta charset="UTF-8">
     <style>
         body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .header { background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
         .content { background: #f9f9f9; padding: 30px; border-radius: 0 0 10px 10px; }
         .button { display: inline-block; padding: 15px 30px; background: #667eea; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
         .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
@@ -366,25 +591,24 @@ func (s *SMTPEmailService) buildPasswordResetEmailBody(email, token string) stri
 </head>
 <body>
     <div class="header">
-        <h1>Password Reset Request</h1>
+        <h1>{{.Locale.T "reset.heading"}}</h1>
     </div>
     <div class="content">
-        <p>Hello,</p>
-        <p>We received a request to reset your password for your Dear Future account.</p>
+        <p>{{.Locale.T "email.greeting"}}</p>
+        <p>{{.Locale.T "reset.body"}}</p>
         <center>
-            <a href="%s" class="button">Reset Password</a>
+            <a href="{{.URL}}" class="button">{{.Locale.T "reset.button"}}</a>
         </center>
-        <p>Or copy and paste this link into your browser:</p>
-        <p style="word-break: break-all; color: #667eea;">%s</p>
+        <p>{{.Locale.T "email.copy_link"}}</p>
+        <p style="word-break: break-all; color: #667eea;">{{.URL}}</p>
         <div class="warning">
-            <strong>Important:</strong> This link will expire in 1 hour for security reasons.
+            <strong>{{.Locale.T "reset.important"}}</strong> {{.Locale.T "reset.expiry"}}
         </div>
         <div class="footer">
-            <p>If you didn't request a password reset, you can safely ignore this email.</p>
-            <p>Your password will not be changed unless you click the link above.</p>
+            <p>{{.Locale.T "reset.footer"}}</p>
+            <p>{{.Locale.T "reset.unchanged"}}</p>
         </div>
     </div>
 </body>
 </html>
-`, resetURL, resetURL)
-}
+`))
@@ -0,0 +1,245 @@
+// Package email provides MIME attachments for the SMTP adapter
+package email
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+)
+
+// base64LineLength is the longest encoded line allowed by RFC 2045
+const base64LineLength = 76
+
+// mimePart is a MIME entity: its headers and a function writing its body
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   func(w io.Writer) error
+}
+
+// writeTo writes the entity as the top level of an email, after the
+// message headers
+func (p mimePart) writeTo(w io.Writer) error {
+	keys := make([]string, 0, len(p.header))
+	for key := range p.header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range p.header[key] {
+			if _, err := fmt.Fprintf(w, "%s: %s\r\n", key, value); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := io.WriteString(w, "\r\n"); err != nil {
+		return err
+	}
+	return p.body(w)
+}
+
+// textPart is a quoted-printable text entity
+func textPart(contentType, text string) mimePart {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	return mimePart{header: header, body: func(w io.Writer) error {
+		return writeQuotedPrintable(w, text)
+	}}
+}
+
+// multipartPart nests parts in a multipart entity of the given subtype
+func multipartPart(subtype string, parts ...mimePart) mimePart {
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", fmt.Sprintf("multipart/%s; boundary=%q", subtype, boundary))
+
+	return mimePart{header: header, body: func(w io.Writer) error {
+		writer := multipart.NewWriter(w)
+		if err := writer.SetBoundary(boundary); err != nil {
+			return fmt.Errorf("failed to create MIME writer: %w", err)
+		}
+
+		for _, part := range parts {
+			partWriter, err := writer.CreatePart(part.header)
+			if err != nil {
+				return fmt.Errorf("failed to create MIME part: %w", err)
+			}
+			if err := part.body(partWriter); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("failed to close MIME writer: %w", err)
+		}
+		return nil
+	}}
+}
+
+// attachmentPart is a base64 file entity, streamed from the attachment's
+// content as it is written. Inline images carry the Content-ID the HTML body
+// refers to.
+func attachmentPart(attachment message.DeliveredAttachment) mimePart {
+	params := map[string]string{"name": attachment.FileName}
+	contentType := mime.FormatMediaType(attachment.ContentType, params)
+	if contentType == "" {
+		contentType = mime.FormatMediaType("application/octet-stream", params)
+	}
+
+	disposition := "attachment"
+	header := textproto.MIMEHeader{}
+	if attachment.Disposition == message.AttachmentInline {
+		disposition = "inline"
+		header.Set("Content-ID", "<"+attachment.ContentID()+">")
+	}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}))
+
+	return mimePart{header: header, body: func(w io.Writer) error {
+		if attachment.Open == nil {
+			return fmt.Errorf("attachment %s has no content", attachment.FileName)
+		}
+		content, err := attachment.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open attachment %s: %w", attachment.FileName, err)
+		}
+		defer content.Close()
+
+		encoder := base64.NewEncoder(base64.StdEncoding, &lineWriter{w: w})
+		if _, err := io.Copy(encoder, content); err != nil {
+			return fmt.Errorf("failed to encode attachment: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return fmt.Errorf("failed to encode attachment: %w", err)
+		}
+		return nil
+	}}
+}
+
+// emailBody assembles the body of an email, nesting multipart entities only
+// as deep as the content needs:
+//
+//	multipart/mixed              when files are attached
+//	  multipart/related          when images are shown inline
+//	    multipart/alternative    when there is a text body
+//	      text/plain
+//	      text/html
+//	    image/* (Content-ID)
+//	  file attachments
+func emailBody(htmlBody, textBody string, attachments []message.DeliveredAttachment) mimePart {
+	body := textPart("text/html; charset=UTF-8", htmlBody)
+	if textBody != "" {
+		body = multipartPart("alternative", textPart("text/plain; charset=UTF-8", textBody), body)
+	}
+
+	var inline, files []mimePart
+	for _, attachment := range attachments {
+		switch attachment.Disposition {
+		case message.AttachmentInline:
+			inline = append(inline, attachmentPart(attachment))
+		case message.AttachmentEmbedded:
+			files = append(files, attachmentPart(attachment))
+		}
+	}
+
+	if len(inline) > 0 {
+		body = multipartPart("related", append([]mimePart{body}, inline...)...)
+	}
+	if len(files) > 0 {
+		body = multipartPart("mixed", append([]mimePart{body}, files...)...)
+	}
+	return body
+}
+
+// lineWriter breaks base64 output into lines of base64LineLength
+type lineWriter struct {
+	w      io.Writer
+	column int
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(base64LineLength-lw.column, len(p))
+		if _, err := lw.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		lw.column += n
+		p = p[n:]
+
+		if lw.column == base64LineLength {
+			if _, err := io.WriteString(lw.w, "\r\n"); err != nil {
+				return written, err
+			}
+			lw.column = 0
+		}
+	}
+	return written, nil
+}
+
+// inlineImageData is an image shown in the letter
+type inlineImageData struct {
+	Src template.URL // cid: reference to the image part
+	Alt string
+}
+
+// linkedFileData is a file too large to attach, sent as a download link
+type linkedFileData struct {
+	Name string
+	Size string
+	URL  string
+}
+
+// attachmentData splits the attachments of a letter into the images shown
+// in it and the files linked below it. linksExpire is the moment the first
+// link stops working, zero without links.
+func attachmentData(attachments []message.DeliveredAttachment) (images []inlineImageData, links []linkedFileData, linksExpire time.Time) {
+	for _, attachment := range attachments {
+		switch attachment.Disposition {
+		case message.AttachmentInline:
+			images = append(images, inlineImageData{
+				Src: template.URL("cid:" + attachment.ContentID()),
+				Alt: attachment.FileName,
+			})
+		case message.AttachmentLinked:
+			links = append(links, linkedFileData{
+				Name: attachment.FileName,
+				Size: formatFileSize(attachment.Size),
+				URL:  attachment.URL,
+			})
+			if linksExpire.IsZero() || attachment.ExpiresAt.Before(linksExpire) {
+				linksExpire = attachment.ExpiresAt
+			}
+		}
+	}
+	return images, links, linksExpire
+}
+
+// formatFileSize formats a size in bytes for people, such as "2.4 MB"
+func formatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
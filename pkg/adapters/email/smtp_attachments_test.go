@@ -0,0 +1,162 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+)
+
+func content(data string) message.AttachmentOpener {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(data)), nil
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	var buf bytes.Buffer
+	lw := &lineWriter{w: &buf}
+
+	data := strings.Repeat("a", 200)
+	for _, chunk := range []string{data[:10], data[10:150], data[150:]} {
+		if n, err := lw.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write() = %d, %v, want %d", n, err, len(chunk))
+		}
+	}
+
+	lines := strings.Split(buf.String(), "\r\n")
+	want := []int{base64LineLength, base64LineLength, 200 - 2*base64LineLength}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(want))
+	}
+	for i, length := range want {
+		if len(lines[i]) != length {
+			t.Errorf("line %d has %d characters, want %d", i, len(lines[i]), length)
+		}
+	}
+}
+
+func TestAttachmentPart(t *testing.T) {
+	data := strings.Repeat("0123456789", 20)
+	attachment := message.DeliveredAttachment{
+		ID:          uuid.New(),
+		FileName:    "photo.png",
+		ContentType: "image/png",
+		Disposition: message.AttachmentInline,
+		Open:        content(data),
+	}
+
+	part := attachmentPart(attachment)
+	if got := part.header.Get("Content-ID"); got != "<"+attachment.ContentID()+">" {
+		t.Errorf("Content-ID = %q", got)
+	}
+	if got := part.header.Get("Content-Disposition"); !strings.HasPrefix(got, "inline") {
+		t.Errorf("Content-Disposition = %q, want inline", got)
+	}
+
+	var buf bytes.Buffer
+	if err := part.body(&buf); err != nil {
+		t.Fatalf("body() error = %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > base64LineLength {
+			t.Errorf("line of %d characters exceeds %d", len(line), base64LineLength)
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(buf.String(), "\r\n", ""))
+	if err != nil || string(decoded) != data {
+		t.Errorf("decoded body = %q, %v, want the attachment content", decoded, err)
+	}
+
+	attachment.Disposition = message.AttachmentEmbedded
+	if part := attachmentPart(attachment); part.header.Get("Content-ID") != "" {
+		t.Error("attached files should have no Content-ID")
+	}
+
+	failing := attachment
+	failing.Open = func() (io.ReadCloser, error) { return nil, errors.New("storage unavailable") }
+	if err := attachmentPart(failing).body(io.Discard); err == nil {
+		t.Error("body() should fail when the content cannot be opened")
+	}
+
+	failing.Open = nil
+	if err := attachmentPart(failing).body(io.Discard); err == nil {
+		t.Error("body() should fail without content")
+	}
+}
+
+func TestEmailBody(t *testing.T) {
+	image := message.DeliveredAttachment{ID: uuid.New(), FileName: "photo.png", ContentType: "image/png", Disposition: message.AttachmentInline, Open: content("png")}
+	file := message.DeliveredAttachment{ID: uuid.New(), FileName: "notes.txt", ContentType: "text/plain", Disposition: message.AttachmentEmbedded, Open: content("notes")}
+	link := message.DeliveredAttachment{ID: uuid.New(), FileName: "video.mp4", ContentType: "video/mp4", Disposition: message.AttachmentLinked, URL: "https://files.example/video.mp4"}
+
+	tests := []struct {
+		name        string
+		text        string
+		attachments []message.DeliveredAttachment
+		want        string
+	}{
+		{"html only", "", nil, "text/html"},
+		{"html and text", "Hello", nil, "multipart/alternative[text/plain text/html]"},
+		{"linked files are not embedded", "", []message.DeliveredAttachment{link}, "text/html"},
+		{"inline image", "", []message.DeliveredAttachment{image}, "multipart/related[text/html image/png]"},
+		{"attached file", "Hello", []message.DeliveredAttachment{file}, "multipart/mixed[multipart/alternative[text/plain text/html] text/plain]"},
+		{
+			"everything", "Hello", []message.DeliveredAttachment{image, file, link},
+			"multipart/mixed[multipart/related[multipart/alternative[text/plain text/html] image/png] text/plain]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := emailBody("<p>Hello</p>", tt.text, tt.attachments).writeTo(&buf); err != nil {
+				t.Fatalf("writeTo() error = %v", err)
+			}
+
+			msg, err := mail.ReadMessage(&buf)
+			if err != nil {
+				t.Fatalf("ReadMessage() error = %v", err)
+			}
+			if got := structure(t, msg.Header.Get("Content-Type"), msg.Body); got != tt.want {
+				t.Errorf("structure = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// structure describes the nesting of a MIME entity, such as
+// "multipart/alternative[text/plain text/html]"
+func structure(t *testing.T, contentType string, body io.Reader) string {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("ParseMediaType(%q) error = %v", contentType, err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return mediaType
+	}
+
+	var parts []string
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		parts = append(parts, structure(t, part.Header.Get("Content-Type"), part))
+	}
+	return mediaType + "[" + strings.Join(parts, " ") + "]"
+}
@@ -0,0 +1,162 @@
+// Package email provides batch sending for the SMTP adapter
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/smtp"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// SendMessagesBatch sends several messages over a single SMTP connection.
+// A failed message does not stop the batch; the session is reset and, if the
+// server dropped the connection, re-established once for the remaining messages.
+func (s *SMTPEmailService) SendMessagesBatch(ctx context.Context, deliveryInfos []message.MessageDeliveryInfo) common.Result[[]effects.BatchResult[effects.EmailResult]] {
+	results := make([]effects.BatchResult[effects.EmailResult], 0, len(deliveryInfos))
+	if len(deliveryInfos) == 0 {
+		return common.Ok(results)
+	}
+
+	client, err := s.dial()
+	if err != nil {
+		return common.Err[[]effects.BatchResult[effects.EmailResult]](err)
+	}
+	defer func() {
+		if client != nil {
+			client.Quit()
+		}
+	}()
+
+	redialed := false
+	for _, info := range deliveryInfos {
+		if ctx.Err() != nil {
+			results = append(results, emailBatchResult(info, ctx.Err()))
+			continue
+		}
+
+		if client == nil {
+			results = append(results, emailBatchResult(info, fmt.Errorf("SMTP connection lost")))
+			continue
+		}
+
+		err := s.sendOnClient(client, info)
+		results = append(results, emailBatchResult(info, err))
+		if err == nil {
+			continue
+		}
+
+		// Clear the failed transaction; a failing RSET means the connection is gone
+		if resetErr := client.Reset(); resetErr != nil {
+			client.Close()
+			client = nil
+			if !redialed {
+				redialed = true
+				if client, err = s.dial(); err != nil {
+					client = nil
+				}
+			}
+		}
+	}
+
+	return common.Ok(results)
+}
+
+// sendOnClient sends one message over an established SMTP session
+func (s *SMTPEmailService) sendOnClient(client *smtp.Client, info message.MessageDeliveryInfo) error {
+	body, err := s.buildMessageBody(info)
+	if err != nil {
+		return err
+	}
+
+	text, err := s.buildTextBody(info)
+	if err != nil {
+		return err
+	}
+
+	from := s.config.FromEmail
+	return s.transmit(client, from, info.RecipientEmail, func(w io.Writer) error {
+		return s.writeEmailMessage(w, from, info.RecipientEmail, info.Subject, body, text, "", info.Attachments)
+	})
+}
+
+// dial opens an authenticated SMTP session, using implicit TLS on port 465
+// and STARTTLS otherwise
+func (s *SMTPEmailService) dial() (*smtp.Client, error) {
+	addr := fmt.Sprintf("%s:%s", s.config.Host, s.config.Port)
+	auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	tlsConfig := &tls.Config{
+		ServerName:         s.config.Host,
+		InsecureSkipVerify: s.config.SkipTLSVerify,
+	}
+
+	var client *smtp.Client
+	if s.config.UseTLS && s.config.Port == "465" {
+		conn, err := tls.Dial("tcp", addr, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("TLS connection failed: %w", err)
+		}
+
+		client, err = smtp.NewClient(conn, s.config.Host)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("SMTP client creation failed: %w", err)
+		}
+	} else {
+		var err error
+		client, err = smtp.Dial(addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+		}
+
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("STARTTLS failed: %w", err)
+			}
+		}
+	}
+
+	if ok, _ := client.Extension("AUTH"); ok {
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+func emailBatchResult(info message.MessageDeliveryInfo, err error) effects.BatchResult[effects.EmailResult] {
+	result := effects.EmailResult{
+		MessageID: info.Message.ID().String(),
+		Status:    effects.EmailStatusSent,
+		SentAt:    time.Now(),
+		Error:     common.None[string](),
+		Recipient: info.RecipientEmail,
+		Subject:   info.Subject,
+	}
+
+	if err != nil {
+		result.Status = effects.EmailStatusFailed
+		result.Error = common.Some(err.Error())
+		return effects.BatchResult[effects.EmailResult]{
+			ID:      result.MessageID,
+			Success: false,
+			Result:  common.Some(result),
+			Error:   common.Some(err.Error()),
+		}
+	}
+
+	return effects.BatchResult[effects.EmailResult]{
+		ID:      result.MessageID,
+		Success: true,
+		Result:  common.Some(result),
+		Error:   common.None[string](),
+	}
+}
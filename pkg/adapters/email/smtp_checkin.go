@@ -0,0 +1,101 @@
+// Package email provides check-in warnings for the SMTP adapter
+package email
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/url"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// SendCheckInWarning tells a user they missed a check-in and that their
+// message will be released unless they check in or cancel the release
+func (s *SMTPEmailService) SendCheckInWarning(ctx context.Context, warning effects.CheckInWarning) common.Result[effects.EmailResult] {
+	subject := warning.Locale.T("checkin.subject", warning.MessageTitle)
+	checkInURL := "https://dearfuture.app/api/v1/checkin/confirm?token=" + url.QueryEscape(warning.CheckInToken)
+	cancelURL := "https://dearfuture.app/api/v1/checkin/cancel?token=" + url.QueryEscape(warning.CancelToken)
+	releaseAt := warning.Locale.FormatDateTime(warning.ReleaseAt, warning.Location)
+
+	var buf bytes.Buffer
+	err := checkInWarningTemplate.Execute(&buf, checkInWarningData{
+		Locale:     warning.Locale,
+		Title:      warning.MessageTitle,
+		ReleaseAt:  releaseAt,
+		CheckInURL: checkInURL,
+		CancelURL:  cancelURL,
+	})
+	if err == nil {
+		text := warning.Locale.T("checkin.text", warning.MessageTitle, releaseAt, checkInURL, cancelURL)
+		err = s.sendEmail(ctx, warning.RecipientEmail, subject, buf.String(), text, "", nil)
+	}
+
+	if err != nil {
+		return common.Ok(effects.EmailResult{
+			MessageID: warning.MessageID.String(),
+			Status:    effects.EmailStatusFailed,
+			SentAt:    time.Now(),
+			Error:     common.Some(err.Error()),
+			Recipient: warning.RecipientEmail,
+			Subject:   subject,
+		})
+	}
+
+	return common.Ok(effects.EmailResult{
+		MessageID: warning.MessageID.String(),
+		Status:    effects.EmailStatusSent,
+		SentAt:    time.Now(),
+		Error:     common.None[string](),
+		Recipient: warning.RecipientEmail,
+		Subject:   subject,
+	})
+}
+
+// checkInWarningData holds the values rendered into checkInWarningTemplate
+type checkInWarningData struct {
+	Locale     i18n.Locale
+	Title      string
+	ReleaseAt  string
+	CheckInURL string
+	CancelURL  string
+}
+
+var checkInWarningTemplate = template.Must(template.New("check-in").Parse(`
+<!DOCTYPE html>
+<html lang="{{.Locale.OrDefault}}">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .content { background: #f9f9f9; padding: 30px; border-radius: 0 0 10px 10px; }
+        .button { display: inline-block; padding: 15px 30px; background: #667eea; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
+        .warning { background: #fff3cd; border-left: 4px solid #ffc107; padding: 15px; margin: 20px 0; border-radius: 5px; }
+        .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>{{.Locale.T "checkin.heading"}}</h1>
+    </div>
+    <div class="content">
+        <p>{{.Locale.T "email.greeting"}}</p>
+        <p>{{.Locale.T "checkin.missed"}} <strong>{{.Title}}</strong>.</p>
+        <div class="warning">
+            {{.Locale.T "checkin.release_on"}} <strong>{{.ReleaseAt}}</strong>.
+        </div>
+        <center>
+            <a href="{{.CheckInURL}}" class="button">{{.Locale.T "checkin.button"}}</a>
+        </center>
+        <p>{{.Locale.T "checkin.cancel"}} <a href="{{.CancelURL}}">{{.Locale.T "checkin.cancel_link"}}</a>.</p>
+        <div class="footer">
+            <p>{{.Locale.T "checkin.sent_by"}} <strong>Dear Future</strong></p>
+        </div>
+    </div>
+</body>
+</html>
+`))
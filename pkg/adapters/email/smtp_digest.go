@@ -0,0 +1,169 @@
+// Package email provides the weekly digest for the SMTP adapter
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// SendWeeklyDigest emails a user the summary of their letters for the week
+func (s *SMTPEmailService) SendWeeklyDigest(ctx context.Context, digest effects.WeeklyDigestEmail) common.Result[effects.EmailResult] {
+	locale := digest.Locale
+	subject := locale.T("digest.subject")
+	unsubscribe := unsubscribeURL(user.NotifyWeeklyDigest, digest.UnsubscribeToken)
+
+	data := weeklyDigestData{
+		Locale:         locale,
+		Name:           digest.RecipientName,
+		Upcoming:       digestEntries(digest.Digest.Upcoming, locale, digest.Location),
+		RecurringSent:  digestEntries(digest.Digest.RecurringSent, locale, digest.Location),
+		NeedsAttention: digestEntries(digest.Digest.NeedsAttention, locale, digest.Location),
+		UnsubscribeURL: unsubscribe,
+	}
+
+	var buf bytes.Buffer
+	err := weeklyDigestTemplate.Execute(&buf, data)
+	if err == nil {
+		err = s.sendEmail(ctx, digest.RecipientEmail, subject, buf.String(), buildDigestText(data), unsubscribe, nil)
+	}
+
+	if err != nil {
+		return common.Ok(effects.EmailResult{
+			MessageID: "",
+			Status:    effects.EmailStatusFailed,
+			SentAt:    time.Now(),
+			Error:     common.Some(err.Error()),
+			Recipient: digest.RecipientEmail,
+			Subject:   subject,
+		})
+	}
+
+	return common.Ok(effects.EmailResult{
+		MessageID: "",
+		Status:    effects.EmailStatusSent,
+		SentAt:    time.Now(),
+		Error:     common.None[string](),
+		Recipient: digest.RecipientEmail,
+		Subject:   subject,
+	})
+}
+
+// weeklyDigestData holds the values rendered into weeklyDigestTemplate
+type weeklyDigestData struct {
+	Locale         i18n.Locale
+	Name           string
+	Upcoming       []digestEntry
+	RecurringSent  []digestEntry
+	NeedsAttention []digestEntry
+	UnsubscribeURL string // Empty when the digest has no unsubscribe token
+}
+
+// digestEntry is one letter listed in the digest
+type digestEntry struct {
+	Title string
+	Date  string // Delivery date in the user's timezone
+}
+
+func digestEntries(messages []message.Message, locale i18n.Locale, loc *time.Location) []digestEntry {
+	entries := make([]digestEntry, 0, len(messages))
+	for _, msg := range messages {
+		title := msg.Title()
+		if title == "" {
+			title = locale.T("email.untitled")
+		}
+		entries = append(entries, digestEntry{
+			Title: title,
+			Date:  locale.FormatWeekdayTime(msg.DeliveryDate(), loc),
+		})
+	}
+	return entries
+}
+
+// buildDigestText creates the plain text alternative of the digest
+func buildDigestText(data weeklyDigestData) string {
+	locale := data.Locale
+	var text strings.Builder
+	if data.Name != "" {
+		text.WriteString(locale.T("email.greeting_named", data.Name) + "\n\n")
+	} else {
+		text.WriteString(locale.T("email.greeting") + "\n\n")
+	}
+	text.WriteString(locale.T("digest.intro") + "\n")
+
+	section := func(headingKey, dateKey string, entries []digestEntry) {
+		if len(entries) == 0 {
+			return
+		}
+		fmt.Fprintf(&text, "\n%s:\n", locale.T(headingKey))
+		for _, entry := range entries {
+			fmt.Fprintf(&text, "- %s (%s)\n", entry.Title, locale.T(dateKey, entry.Date))
+		}
+	}
+	section("digest.upcoming", "digest.arrives", data.Upcoming)
+	section("digest.recurring", "digest.next_on", data.RecurringSent)
+	section("digest.attention", "digest.was_due", data.NeedsAttention)
+
+	if data.UnsubscribeURL != "" {
+		text.WriteString("\n" + locale.T("digest.unsubscribe") + ":\n" + data.UnsubscribeURL + "\n")
+	}
+	return text.String()
+}
+
+var weeklyDigestTemplate = template.Must(template.New("weekly-digest").Parse(`
+<!DOCTYPE html>
+<html lang="{{.Locale.OrDefault}}">
+<head>
+    <meta charset="UTF-8">
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+        .content { background: #f9f9f9; padding: 30px; border-radius: 0 0 10px 10px; }
+        .section { background: white; padding: 15px 20px; margin: 20px 0; border-left: 4px solid #667eea; border-radius: 5px; }
+        .warning { background: #fff3cd; border-left: 4px solid #ffc107; padding: 15px 20px; margin: 20px 0; border-radius: 5px; }
+        .date { color: #666; font-size: 14px; }
+        .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="header">
+        <h1>{{.Locale.T "digest.heading"}}</h1>
+    </div>
+    <div class="content">
+        <p>{{if .Name}}{{.Locale.T "email.greeting_named" .Name}}{{else}}{{.Locale.T "email.greeting"}}{{end}}</p>
+        {{if .Upcoming}}<div class="section">
+            <h3>{{.Locale.T "digest.upcoming"}}</h3>
+            <ul>{{range .Upcoming}}
+                <li><strong>{{.Title}}</strong> <span class="date">{{$.Locale.T "digest.arrives" .Date}}</span></li>{{end}}
+            </ul>
+        </div>{{end}}
+        {{if .RecurringSent}}<div class="section">
+            <h3>{{.Locale.T "digest.recurring"}}</h3>
+            <ul>{{range .RecurringSent}}
+                <li><strong>{{.Title}}</strong> <span class="date">{{$.Locale.T "digest.next_on" .Date}}</span></li>{{end}}
+            </ul>
+        </div>{{end}}
+        {{if .NeedsAttention}}<div class="warning">
+            <h3>{{.Locale.T "digest.attention"}}</h3>
+            <p>{{.Locale.T "digest.attention_intro"}}</p>
+            <ul>{{range .NeedsAttention}}
+                <li><strong>{{.Title}}</strong> <span class="date">{{$.Locale.T "digest.was_due" .Date}}</span></li>{{end}}
+            </ul>
+        </div>{{end}}
+        <div class="footer">
+            <p>{{.Locale.T "digest.sent_by"}} <strong>Dear Future</strong></p>
+            {{if .UnsubscribeURL}}<p><a href="{{.UnsubscribeURL}}">{{.Locale.T "digest.unsubscribe"}}</a></p>{{end}}
+        </div>
+    </div>
+</body>
+</html>
+`))
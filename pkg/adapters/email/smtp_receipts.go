@@ -0,0 +1,24 @@
+// Package email provides read receipt links for the SMTP adapter
+package email
+
+import (
+	"net/url"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+)
+
+// receiptLinks returns the "mark as read" link and, when open tracking is
+// enabled, the tracking pixel URL of a delivery. Both are empty for
+// deliveries without a receipt.
+func (s *SMTPEmailService) receiptLinks(deliveryInfo message.MessageDeliveryInfo) (readURL, pixelURL string) {
+	if deliveryInfo.ReceiptToken == "" {
+		return "", ""
+	}
+
+	token := url.QueryEscape(deliveryInfo.ReceiptToken)
+	readURL = "https://dearfuture.app/api/v1/receipts/read?token=" + token
+	if s.config.TrackOpens {
+		pixelURL = "https://dearfuture.app/api/v1/receipts/open?token=" + token
+	}
+	return readURL, pixelURL
+}
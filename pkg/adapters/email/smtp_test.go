@@ -0,0 +1,71 @@
+package email
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+)
+
+func TestBuildTextBody(t *testing.T) {
+	msgResult := message.RestoreMessage(message.StoredMessage{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		Title:          "Hello",
+		Content:        "Hello **future** me",
+		ContentFormat:  message.FormatMarkdown,
+		DeliveryDate:   time.Date(2030, 6, 1, 9, 0, 0, 0, time.UTC),
+		Timezone:       "UTC",
+		Status:         message.StatusScheduled,
+		DeliveryMethod: message.DeliveryEmail,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	})
+	if msgResult.IsErr() {
+		t.Fatalf("RestoreMessage() error = %v", msgResult.Error())
+	}
+
+	info := message.MessageDeliveryInfo{
+		Message:       msgResult.Value(),
+		Subject:       "Message from your past: Hello",
+		Body:          "Legacy wrapper with Hello **future** me",
+		Locale:        i18n.English,
+		Location:      time.UTC,
+		ScheduledTime: msgResult.Value().DeliveryDate(),
+		ReceiptToken:  "token",
+		Thread: []message.ThreadEntry{{
+			Title:       "Earlier",
+			TextContent: "First line\nSecond line",
+			WrittenAt:   time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC),
+			DeliveredAt: time.Date(2029, 6, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	service := &SMTPEmailService{}
+
+	text, err := service.buildTextBody(info)
+	if err != nil {
+		t.Fatalf("buildTextBody() error = %v", err)
+	}
+	for _, want := range []string{"Hello future me", "> First line\n> Second line", "receipts/read?token=token", info.Subject} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text body is missing %q:\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"**", "Legacy wrapper"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("text body contains %q:\n%s", unwanted, text)
+		}
+	}
+
+	preview := service.PreviewMessage(context.Background(), info)
+	if preview.IsErr() {
+		t.Fatalf("PreviewMessage() error = %v", preview.Error())
+	}
+	if preview.Value().TextBody != text {
+		t.Errorf("preview text differs from the delivered text:\n%s", preview.Value().TextBody)
+	}
+}
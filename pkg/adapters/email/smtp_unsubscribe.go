@@ -0,0 +1,21 @@
+// Package email provides one-click unsubscribe links for the SMTP adapter
+package email
+
+import (
+	"net/url"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// unsubscribeURL returns the one-click link unsubscribing the recipient from
+// a notification category, or "" for emails sent without a token
+func unsubscribeURL(category user.NotificationCategory, token string) string {
+	if token == "" {
+		return ""
+	}
+
+	query := url.Values{}
+	query.Set("category", string(category))
+	query.Set("token", token)
+	return "https://dearfuture.app/api/v1/notifications/unsubscribe?" + query.Encode()
+}
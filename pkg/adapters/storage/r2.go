@@ -141,6 +141,19 @@ func (r *R2Storage) DownloadFile(ctx context.Context, key string) common.Result[
 	return common.Ok(result)
 }
 
+// OpenFile opens a file for streaming instead of reading it into memory
+func (r *R2Storage) OpenFile(ctx context.Context, key string) common.Result[io.ReadCloser] {
+	output, err := r.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(r.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return common.Err[io.ReadCloser](fmt.Errorf("failed to open file from R2: %w", err))
+	}
+
+	return common.Ok(output.Body)
+}
+
 // GeneratePresignedURL generates a presigned URL for temporary access
 func (r *R2Storage) GeneratePresignedURL(ctx context.Context, key string, expiration time.Duration) common.Result[string] {
 	presignClient := s3.NewPresignClient(r.client)
@@ -13,6 +13,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
 )
 
 // Config holds all application configuration
@@ -49,6 +50,9 @@ type Config struct {
 	// Message limits
 	Message MessageConfig `yaml:"message"`
 
+	// Subscription plan limits
+	Plans PlansConfig `yaml:"plans"`
+
 	// Email configuration
 	Email EmailConfig `yaml:"email"`
 
@@ -103,6 +107,7 @@ type Config struct {
 	MaxMessageLength       int           `yaml:"-"`
 	MaxTitleLength         int           `yaml:"-"`
 	MessageQuotaPerUser    int           `yaml:"-"`
+	TrashRetention         time.Duration `yaml:"-"`
 	EmailTemplatesPath     string        `yaml:"-"`
 	EmailRateLimit         int           `yaml:"-"`
 	SchedulerInterval      time.Duration `yaml:"-"`
@@ -177,6 +182,7 @@ type SMTPConfig struct {
 	FromName      string `yaml:"from_name"`       // From display name
 	UseTLS        bool   `yaml:"use_tls"`         // Use TLS
 	SkipTLSVerify bool   `yaml:"skip_tls_verify"` // Skip TLS verify (dev only)
+	TrackOpens    bool   `yaml:"track_opens"`     // Embed an open-tracking pixel in delivered messages
 }
 
 type FileUploadConfig struct {
@@ -186,9 +192,22 @@ type FileUploadConfig struct {
 }
 
 type MessageConfig struct {
-	MaxMessageLength int `yaml:"max_message_length"`
-	MaxTitleLength   int `yaml:"max_title_length"`
-	QuotaPerUser     int `yaml:"quota_per_user"`
+	MaxMessageLength   int `yaml:"max_message_length"`
+	MaxTitleLength     int `yaml:"max_title_length"`
+	QuotaPerUser       int `yaml:"quota_per_user"`
+	TrashRetentionDays int `yaml:"trash_retention_days"` // Days deleted messages stay in the trash before they are purged
+}
+
+type PlansConfig struct {
+	Free PlanConfig `yaml:"free"`
+	Pro  PlanConfig `yaml:"pro"`
+}
+
+// PlanConfig holds the limits of one plan. Zero values mean unlimited.
+type PlanConfig struct {
+	MaxScheduledMessages int   `yaml:"max_scheduled_messages"`
+	MaxAttachmentBytes   int64 `yaml:"max_attachment_bytes"`
+	Recurrence           bool  `yaml:"recurrence"`
 }
 
 type EmailConfig struct {
@@ -200,7 +219,16 @@ type SchedulingConfig struct {
 	Interval               string           `yaml:"interval"`
 	MaxRetryAttempts       int              `yaml:"max_retry_attempts"`
 	RetryBackoffMultiplier float64          `yaml:"retry_backoff_multiplier"`
+	BatchSize              int              `yaml:"batch_size"` // Messages per delivery batch when batch processing is enabled
 	River                  RiverQueueConfig `yaml:"river"`
+	Smoothing              SmoothingConfig  `yaml:"smoothing"`
+}
+
+// SmoothingConfig spreads deliveries due at the same moment over the
+// following minutes
+type SmoothingConfig struct {
+	PerMinute int               `yaml:"per_minute"` // Deliveries started per minute by each scheduler process, 0 disables smoothing
+	MaxDelay  map[string]string `yaml:"max_delay"`  // Longest delay per class: scheduled, recurring, occasion, surprise
 }
 
 type RiverQueueConfig struct {
@@ -339,9 +367,22 @@ func getDefaultConfig() *Config {
 			},
 		},
 		Message: MessageConfig{
-			MaxMessageLength: 10000,
-			MaxTitleLength:   200,
-			QuotaPerUser:     100,
+			MaxMessageLength:   10000,
+			MaxTitleLength:     200,
+			QuotaPerUser:       100,
+			TrashRetentionDays: 30,
+		},
+		Plans: PlansConfig{
+			Free: PlanConfig{
+				MaxScheduledMessages: 100,
+				MaxAttachmentBytes:   100 * 1024 * 1024,
+				Recurrence:           false,
+			},
+			Pro: PlanConfig{
+				MaxScheduledMessages: 5000,
+				MaxAttachmentBytes:   10 * 1024 * 1024 * 1024,
+				Recurrence:           true,
+			},
 		},
 		Email: EmailConfig{
 			TemplatesPath: "./templates",
@@ -351,6 +392,7 @@ func getDefaultConfig() *Config {
 			Interval:               "1m",
 			MaxRetryAttempts:       5,
 			RetryBackoffMultiplier: 2.0,
+			BatchSize:              50,
 			River: RiverQueueConfig{
 				Enabled:      true,
 				MaxWorkers:   10,
@@ -358,6 +400,9 @@ func getDefaultConfig() *Config {
 				MaxAttempts:  5,
 				PollInterval: "1s",
 			},
+			Smoothing: SmoothingConfig{
+				PerMinute: 120,
+			},
 		},
 		Cache: CacheConfig{
 			Enabled:   false,
@@ -490,6 +535,9 @@ func applyEnvironmentOverrides(config *Config) {
 	if smtpUseTLS := os.Getenv("SMTP_USE_TLS"); smtpUseTLS != "" {
 		config.SMTP.UseTLS = getBoolFromEnv("SMTP_USE_TLS", true)
 	}
+	if smtpTrackOpens := os.Getenv("SMTP_TRACK_OPENS"); smtpTrackOpens != "" {
+		config.SMTP.TrackOpens = getBoolFromEnv("SMTP_TRACK_OPENS", false)
+	}
 
 	// File upload
 	if maxSize := os.Getenv("MAX_FILE_SIZE"); maxSize != "" {
@@ -499,6 +547,16 @@ func applyEnvironmentOverrides(config *Config) {
 		config.FileUpload.MaxAttachments = getIntFromEnv("MAX_ATTACHMENTS", config.FileUpload.MaxAttachments)
 	}
 
+	// Scheduling
+	if perMinute := os.Getenv("SCHEDULER_SMOOTHING_PER_MINUTE"); perMinute != "" {
+		config.Scheduling.Smoothing.PerMinute = getIntFromEnv("SCHEDULER_SMOOTHING_PER_MINUTE", config.Scheduling.Smoothing.PerMinute)
+	}
+
+	// Message
+	if trashRetention := os.Getenv("TRASH_RETENTION_DAYS"); trashRetention != "" {
+		config.Message.TrashRetentionDays = getIntFromEnv("TRASH_RETENTION_DAYS", config.Message.TrashRetentionDays)
+	}
+
 	// Cache
 	if cacheURL := os.Getenv("CACHE_URL"); cacheURL != "" {
 		config.Cache.URL = cacheURL
@@ -558,6 +616,7 @@ func mapToLegacyFields(config *Config) {
 	config.MaxMessageLength = config.Message.MaxMessageLength
 	config.MaxTitleLength = config.Message.MaxTitleLength
 	config.MessageQuotaPerUser = config.Message.QuotaPerUser
+	config.TrashRetention = time.Duration(config.Message.TrashRetentionDays) * 24 * time.Hour
 
 	// Email
 	config.EmailTemplatesPath = config.Email.TemplatesPath
@@ -640,6 +699,10 @@ func validateConfig(config *Config) common.Result[*Config] {
 		return common.Err[*Config](errors.New("MAX_ATTACHMENTS must be positive"))
 	}
 
+	if config.TrashRetention <= 0 {
+		return common.Err[*Config](errors.New("TRASH_RETENTION_DAYS must be positive"))
+	}
+
 	// Validate timeouts
 	if config.ReadTimeout <= 0 {
 		return common.Err[*Config](errors.New("read timeout must be positive"))
@@ -740,6 +803,20 @@ func (c *Config) GetAWSConfig() AWSConfigInfo {
 	}
 }
 
+// PlanLimits returns the limits of a subscription plan
+func (c *Config) PlanLimits(plan user.Plan) user.PlanLimits {
+	planConfig := c.Plans.Free
+	if plan == user.PlanPro {
+		planConfig = c.Plans.Pro
+	}
+
+	return user.PlanLimits{
+		MaxScheduledMessages: planConfig.MaxScheduledMessages,
+		MaxAttachmentBytes:   planConfig.MaxAttachmentBytes,
+		Recurrence:           planConfig.Recurrence,
+	}
+}
+
 // Configuration info structures for backward compatibility
 type DatabaseConfigInfo struct {
 	URL          string
@@ -0,0 +1,263 @@
+// Package holiday provides the public holidays of a country from calendars
+// bundled with the application, so no network access is needed
+package holiday
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// holidaysJSON holds the holiday rules of each supported country. Rules
+// rather than dates are bundled so letters can be scheduled decades ahead.
+//
+//go:embed holidays.json
+var holidaysJSON []byte
+
+// calendars is parsed once from the bundled rules
+var calendars = mustParseCalendars(holidaysJSON)
+
+// maxSearchYears bounds how far ahead the next occurrence of a holiday is searched
+const maxSearchYears = 3
+
+// Holiday is a public holiday on a calendar date
+type Holiday struct {
+	key  string
+	name string
+	date time.Time
+}
+
+func (h Holiday) Key() string {
+	return h.key
+}
+
+func (h Holiday) Name() string {
+	return h.name
+}
+
+// Date returns the calendar date of the holiday as midnight UTC
+func (h Holiday) Date() time.Time {
+	return h.date
+}
+
+// Calendar is the public holidays of a country
+type Calendar struct {
+	country string
+	name    string
+	rules   []rule
+}
+
+// rule describes when a holiday falls in a given year. Exactly one of the
+// forms is used: a fixed month and day; a weekday in a month, either the nth
+// one (negative counts from the end) or the last one on or before the day;
+// a number of days from Easter Sunday; or a day of the Vietnamese lunar
+// calendar. The offset moves any of them by whole days.
+type rule struct {
+	Key        string `json:"key"`
+	Name       string `json:"name"`
+	Month      int    `json:"month,omitempty"`
+	Day        int    `json:"day,omitempty"`
+	Weekday    string `json:"weekday,omitempty"`
+	Nth        int    `json:"nth,omitempty"`
+	Easter     *int   `json:"easter,omitempty"`
+	LunarMonth int    `json:"lunar_month,omitempty"`
+	LunarDay   int    `json:"lunar_day,omitempty"`
+	Offset     int    `json:"offset,omitempty"`
+	Since      int    `json:"since,omitempty"` // First year the holiday is observed
+}
+
+// ForCountry returns the calendar of a country by its ISO 3166-1 alpha-2 code
+func ForCountry(country string) common.Result[Calendar] {
+	calendar, ok := calendars[strings.ToUpper(strings.TrimSpace(country))]
+	if !ok {
+		return common.Err[Calendar](fmt.Errorf("no holiday calendar for country %q (supported: %s)", country, strings.Join(Countries(), ", ")))
+	}
+	return common.Ok(calendar)
+}
+
+// Countries returns the codes of the countries with a bundled calendar
+func Countries() []string {
+	countries := make([]string, 0, len(calendars))
+	for country := range calendars {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the calendar
+func (c Calendar) Country() string {
+	return c.country
+}
+
+// Name returns the name of the country
+func (c Calendar) Name() string {
+	return c.name
+}
+
+// HolidayName returns the name of the holiday with the key
+func (c Calendar) HolidayName(key string) common.Option[string] {
+	for _, r := range c.rules {
+		if r.Key == key {
+			return common.Some(r.Name)
+		}
+	}
+	return common.None[string]()
+}
+
+// InYear returns the holidays of a year in date order
+func (c Calendar) InYear(year int) []Holiday {
+	holidays := make([]Holiday, 0, len(c.rules))
+	for _, r := range c.rules {
+		if h, ok := r.in(year); ok {
+			holidays = append(holidays, h)
+		}
+	}
+	sort.SliceStable(holidays, func(i, j int) bool { return holidays[i].date.Before(holidays[j].date) })
+	return holidays
+}
+
+// On returns the holiday on the calendar day of t, read in t's location
+func (c Calendar) On(t time.Time) common.Option[Holiday] {
+	day := civilDate(t)
+	for _, r := range c.rules {
+		// Holidays near the turn of the year can come from the rule of the
+		// adjacent year, such as the eve of a lunar new year
+		for year := day.Year() - 1; year <= day.Year()+1; year++ {
+			if h, ok := r.in(year); ok && h.date.Equal(day) {
+				return common.Some(h)
+			}
+		}
+	}
+	return common.None[Holiday]()
+}
+
+// IsWorkingDay reports whether the calendar day of t is neither a weekend
+// day nor a holiday
+func (c Calendar) IsWorkingDay(t time.Time) bool {
+	if weekday := t.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	return c.On(t).IsNone()
+}
+
+// FirstWorkingDayAfter returns t moved by whole days to the first working
+// day after its calendar day, keeping the wall-clock time
+func (c Calendar) FirstWorkingDayAfter(t time.Time) time.Time {
+	next := t.AddDate(0, 0, 1)
+	for !c.IsWorkingDay(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Next returns the first occurrence of the holiday whose calendar date is
+// on or after the calendar day of t
+func (c Calendar) Next(key string, t time.Time) common.Result[Holiday] {
+	day := civilDate(t)
+	for _, r := range c.rules {
+		if r.Key != key {
+			continue
+		}
+		for year := day.Year() - 1; year <= day.Year()+maxSearchYears; year++ {
+			if h, ok := r.in(year); ok && !h.date.Before(day) {
+				return common.Ok(h)
+			}
+		}
+		return common.Err[Holiday](fmt.Errorf("no upcoming %s", r.Name))
+	}
+	return common.Err[Holiday](fmt.Errorf("unknown holiday %q for %s", key, c.name))
+}
+
+// in returns the holiday of the rule in a year
+func (r rule) in(year int) (Holiday, bool) {
+	if year < r.Since {
+		return Holiday{}, false
+	}
+
+	var date time.Time
+	switch {
+	case r.LunarMonth > 0:
+		date = lunarToSolar(r.LunarDay, r.LunarMonth, year)
+	case r.Easter != nil:
+		date = easterSunday(year).AddDate(0, 0, *r.Easter)
+	case r.Weekday != "":
+		date = weekdayInMonth(year, time.Month(r.Month), weekdays[r.Weekday], r.Nth, r.Day)
+	default:
+		date = time.Date(year, time.Month(r.Month), r.Day, 0, 0, 0, 0, time.UTC)
+	}
+
+	return Holiday{key: r.Key, name: r.Name, date: date.AddDate(0, 0, r.Offset)}, true
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// weekdayInMonth returns the nth weekday of a month, counting from the end
+// when n is negative. With a day, it returns the last weekday on or before
+// that day instead.
+func weekdayInMonth(year int, month time.Month, weekday time.Weekday, n, day int) time.Time {
+	if day > 0 {
+		date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return date.AddDate(0, 0, -((int(date.Weekday()) - int(weekday) + 7) % 7))
+	}
+	if n < 0 {
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		last = last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7))
+		return last.AddDate(0, 0, 7*(n+1))
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	first = first.AddDate(0, 0, (int(weekday)-int(first.Weekday())+7)%7)
+	return first.AddDate(0, 0, 7*(n-1))
+}
+
+// easterSunday returns Western Easter Sunday of a year (anonymous Gregorian algorithm)
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// civilDate returns the calendar day of t, in t's location, as midnight UTC
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func mustParseCalendars(data []byte) map[string]Calendar {
+	var parsed map[string]struct {
+		Name     string `json:"name"`
+		Holidays []rule `json:"holidays"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		panic(fmt.Sprintf("holiday: invalid bundled calendars: %v", err))
+	}
+
+	result := make(map[string]Calendar, len(parsed))
+	for country, calendar := range parsed {
+		for _, r := range calendar.Holidays {
+			if r.Weekday != "" {
+				if _, ok := weekdays[r.Weekday]; !ok {
+					panic(fmt.Sprintf("holiday: invalid weekday %q for %s in %s", r.Weekday, r.Key, country))
+				}
+			}
+		}
+		result[country] = Calendar{country: country, name: calendar.Name, rules: calendar.Holidays}
+	}
+	return result
+}
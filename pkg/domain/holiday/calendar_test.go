@@ -0,0 +1,77 @@
+package holiday
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestHolidayRules(t *testing.T) {
+	tests := []struct {
+		country string
+		key     string
+		year    int
+		want    time.Time
+	}{
+		{"US", "thanksgiving", 2026, date(2026, time.November, 26)},
+		{"US", "memorial-day", 2026, date(2026, time.May, 25)},
+		{"CA", "victoria-day", 2026, date(2026, time.May, 18)},
+		{"GB", "good-friday", 2024, date(2024, time.March, 29)},
+		{"DE", "easter-monday", 2025, date(2025, time.April, 21)},
+		{"FR", "whit-monday", 2026, date(2026, time.May, 25)},
+		{"VN", "tet", 2024, date(2024, time.February, 10)},
+		{"VN", "tet", 2025, date(2025, time.January, 29)},
+		{"VN", "tet", 2026, date(2026, time.February, 17)},
+		// Computed in UTC+7, a day before the Chinese new year (February 18)
+		{"VN", "tet", 2007, date(2007, time.February, 17)},
+		{"VN", "tet-eve", 2027, date(2027, time.February, 5)},
+		// 2020 and 2023 have a leap month
+		{"VN", "hung-kings-day", 2020, date(2020, time.April, 2)},
+		{"VN", "hung-kings-day", 2023, date(2023, time.April, 29)},
+		{"VN", "hung-kings-day", 2025, date(2025, time.April, 7)},
+	}
+
+	for _, tt := range tests {
+		calendar := ForCountry(tt.country)
+		if calendar.IsErr() {
+			t.Fatalf("ForCountry(%s) error = %v", tt.country, calendar.Error())
+		}
+
+		next := calendar.Value().Next(tt.key, date(tt.year, time.January, 1))
+		if next.IsErr() || !next.Value().Date().Equal(tt.want) {
+			t.Errorf("%s %s %d = %v, want %v", tt.country, tt.key, tt.year, next.Value().Date(), tt.want)
+		}
+	}
+
+	if us := ForCountry("us").Value(); us.On(date(2020, time.June, 19)).IsSome() {
+		t.Error("holidays should not apply before they were introduced")
+	}
+	if ForCountry("XX").IsOk() {
+		t.Error("unknown countries should be rejected")
+	}
+}
+
+func TestWorkingDays(t *testing.T) {
+	gb := ForCountry("GB").Value()
+
+	// Christmas 2026 is a Friday, so Boxing Day is the Saturday after it
+	christmas := time.Date(2026, time.December, 25, 8, 30, 0, 0, time.UTC)
+	if holiday := gb.On(christmas); holiday.IsNone() || holiday.Value().Key() != "christmas" {
+		t.Fatal("expected Christmas Day")
+	}
+	if gb.IsWorkingDay(christmas) {
+		t.Error("holidays are not working days")
+	}
+
+	want := time.Date(2026, time.December, 28, 8, 30, 0, 0, time.UTC)
+	if got := gb.FirstWorkingDayAfter(christmas); !got.Equal(want) {
+		t.Errorf("FirstWorkingDayAfter() = %v, want %v", got, want)
+	}
+
+	if holidays := gb.InYear(2026); len(holidays) != 8 || holidays[0].Key() != "new-years-day" {
+		t.Errorf("InYear() returned %d holidays", len(holidays))
+	}
+}
@@ -0,0 +1,147 @@
+package holiday
+
+import (
+	"math"
+	"time"
+)
+
+// vietnamTimezone is the UTC offset, in hours, the Vietnamese lunar calendar
+// is computed in
+const vietnamTimezone = 7.0
+
+// lunarToSolar returns the solar date of a day of a regular (not leap) month
+// of the Vietnamese lunar calendar. It follows Hồ Ngọc Đức's astronomical
+// algorithm: months start on the day of the new moon, month 11 contains the
+// winter solstice, and a leap month is the first one without a major solar
+// term in a year with 13 months.
+func lunarToSolar(lunarDay, lunarMonth, lunarYear int) time.Time {
+	var a11, b11 int
+	if lunarMonth < 11 {
+		a11 = lunarMonth11(lunarYear - 1)
+		b11 = lunarMonth11(lunarYear)
+	} else {
+		a11 = lunarMonth11(lunarYear)
+		b11 = lunarMonth11(lunarYear + 1)
+	}
+
+	k := int(math.Floor(0.5 + (float64(a11)-2415021.076998695)/29.530588853))
+	offset := lunarMonth - 11
+	if offset < 0 {
+		offset += 12
+	}
+	if b11-a11 > 365 {
+		if offset >= leapMonthOffset(a11) {
+			offset++
+		}
+	}
+
+	return julianDayToDate(newMoonDay(k+offset) + lunarDay - 1)
+}
+
+// lunarMonth11 returns the Julian day number of the start of lunar month 11,
+// the month containing the winter solstice of the year
+func lunarMonth11(year int) int {
+	off := dateToJulianDay(31, 12, year) - 2415021
+	k := int(math.Floor(float64(off) / 29.530588853))
+	nm := newMoonDay(k)
+	if sunLongitudeSector(nm) >= 9 {
+		nm = newMoonDay(k - 1)
+	}
+	return nm
+}
+
+// leapMonthOffset returns the offset from month 11 of the leap month in a
+// lunar year with 13 months
+func leapMonthOffset(a11 int) int {
+	k := int(math.Floor((float64(a11)-2415021.076998695)/29.530588853 + 0.5))
+	i := 1
+	arc := sunLongitudeSector(newMoonDay(k + i))
+	for {
+		last := arc
+		i++
+		arc = sunLongitudeSector(newMoonDay(k + i))
+		if arc == last || i >= 14 {
+			break
+		}
+	}
+	return i - 1
+}
+
+// newMoonDay returns the Julian day number of the kth new moon after
+// 1900-01-01, in local time
+func newMoonDay(k int) int {
+	return int(math.Floor(newMoon(k) + 0.5 + vietnamTimezone/24))
+}
+
+// newMoon returns the Julian date of the kth new moon after 1900-01-01 (UTC)
+func newMoon(k int) float64 {
+	t := float64(k) / 1236.85
+	t2, t3 := t*t, t*t*t
+	dr := math.Pi / 180
+
+	jd := 2415020.75933 + 29.53058868*float64(k) + 0.0001178*t2 - 0.000000155*t3
+	jd += 0.00033 * math.Sin((166.56+132.87*t-0.009173*t2)*dr)
+	m := 359.2242 + 29.10535608*float64(k) - 0.0000333*t2 - 0.00000347*t3
+	mpr := 306.0253 + 385.81691806*float64(k) + 0.0107306*t2 + 0.00001236*t3
+	f := 21.2964 + 390.67050646*float64(k) - 0.0016528*t2 - 0.00000239*t3
+
+	c1 := (0.1734-0.000393*t)*math.Sin(m*dr) + 0.0021*math.Sin(2*dr*m)
+	c1 = c1 - 0.4068*math.Sin(mpr*dr) + 0.0161*math.Sin(dr*2*mpr)
+	c1 = c1 - 0.0004*math.Sin(dr*3*mpr)
+	c1 = c1 + 0.0104*math.Sin(dr*2*f) - 0.0051*math.Sin(dr*(m+mpr))
+	c1 = c1 - 0.0074*math.Sin(dr*(m-mpr)) + 0.0004*math.Sin(dr*(2*f+m))
+	c1 = c1 - 0.0004*math.Sin(dr*(2*f-m)) - 0.0006*math.Sin(dr*(2*f+mpr))
+	c1 = c1 + 0.0010*math.Sin(dr*(2*f-mpr)) + 0.0005*math.Sin(dr*(2*mpr+m))
+
+	var deltaT float64
+	if t < -11 {
+		deltaT = 0.001 + 0.000839*t + 0.0002261*t2 - 0.00000845*t3 - 0.000000081*t*t3
+	} else {
+		deltaT = -0.000278 + 0.000265*t + 0.000262*t2
+	}
+	return jd + c1 - deltaT
+}
+
+// sunLongitudeSector returns which of the 12 major solar terms the sun is in
+// at the start of a local day
+func sunLongitudeSector(dayNumber int) int {
+	return int(math.Floor(sunLongitude(float64(dayNumber)-0.5-vietnamTimezone/24) / math.Pi * 6))
+}
+
+// sunLongitude returns the sun's longitude in radians at a Julian date
+func sunLongitude(jdn float64) float64 {
+	t := (jdn - 2451545.0) / 36525
+	t2 := t * t
+	dr := math.Pi / 180
+
+	m := 357.52910 + 35999.05030*t - 0.0001559*t2 - 0.00000048*t*t2
+	l0 := 280.46645 + 36000.76983*t + 0.0003032*t2
+	dl := (1.914600 - 0.004817*t - 0.000014*t2) * math.Sin(dr*m)
+	dl += (0.019993-0.000101*t)*math.Sin(dr*2*m) + 0.000290*math.Sin(dr*3*m)
+
+	l := (l0 + dl) * dr
+	return l - math.Pi*2*math.Floor(l/(math.Pi*2))
+}
+
+// dateToJulianDay returns the Julian day number of a Gregorian date
+func dateToJulianDay(day, month, year int) int {
+	a := (14 - month) / 12
+	y := year + 4800 - a
+	m := month + 12*a - 3
+	return day + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+}
+
+// julianDayToDate returns the Gregorian date of a Julian day number as midnight UTC
+func julianDayToDate(jd int) time.Time {
+	a := jd + 32044
+	b := (4*a + 3) / 146097
+	c := a - (b*146097)/4
+	d := (4*c + 3) / 1461
+	e := c - (1461*d)/4
+	m := (5*e + 2) / 153
+
+	day := e - (153*m+2)/5 + 1
+	month := m + 3 - 12*(m/10)
+	year := b*100 + d - 4800 + m/10
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
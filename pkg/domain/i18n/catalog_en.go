@@ -0,0 +1,111 @@
+// Package i18n contains the English message catalog
+package i18n
+
+// catalogs holds the email copy of each supported locale
+var catalogs = map[Locale]map[string]string{
+	English:    english,
+	Vietnamese: vietnamese,
+}
+
+// errorCatalogs holds the translations of API error messages, keyed by the
+// English message. English needs none.
+var errorCatalogs = map[Locale]map[string]string{
+	Vietnamese: vietnameseErrors,
+}
+
+var english = map[string]string{
+	// Shared email copy
+	"email.greeting":       "Hello,",
+	"email.greeting_named": "Hello %s,",
+	"email.copy_link":      "Or copy and paste this link into your browser:",
+	"email.untitled":       "Untitled letter",
+
+	// Delivered letters
+	"letter.subject":          "Message from your past: %s",
+	"letter.subject_untitled": "You have a message from your past self",
+	"letter.intro":            "You scheduled this message to be delivered to your future self.",
+	"letter.subject_line":     "Subject: %s",
+	"letter.message":          "Message:",
+	"letter.written_on":       "Originally written on: %s",
+	"letter.scheduled_on":     "Scheduled for delivery on: %s",
+	"letter.signoff":          "Best regards,",
+	"letter.signature":        "Your Past Self",
+	"letter.delivered_by":     "This message was delivered by Dear Future - Your Message to Tomorrow",
+	"letter.heading":          "📬 A Message from Your Past Self",
+	"letter.scheduled_for":    "Scheduled for:",
+	"letter.thread":           "Earlier in this conversation",
+	"letter.thread_entry":     "Written %s, delivered %s",
+	"letter.read_link":        "Let your past self know you read this letter",
+	"letter.sent_by":          "This message was sent by",
+	"letter.tagline":          "Your message to tomorrow, delivered today.",
+	"letter.attachments":      "Attachments",
+	"letter.links_expire":     "Download links work until %s.",
+
+	// Check-in warnings
+	"checkin.subject":     "Check in to keep \"%s\" private",
+	"checkin.heading":     "Time to Check In",
+	"checkin.missed":      "You missed a check-in for",
+	"checkin.release_on":  "Unless you check in, this message will be released on",
+	"checkin.button":      "Check In",
+	"checkin.cancel":      "If you no longer want this message released, you can",
+	"checkin.cancel_link": "cancel the release",
+	"checkin.sent_by":     "This reminder was sent by",
+	"checkin.text":        "You missed a check-in for \"%s\".\n\nIt will be released on %s unless you check in:\n%s\n\nTo cancel the release instead:\n%s\n",
+
+	// Weekly digest
+	"digest.subject":         "Your week with Dear Future",
+	"digest.heading":         "Your Week with Dear Future",
+	"digest.intro":           "Here is your week with Dear Future.",
+	"digest.upcoming":        "Arriving soon",
+	"digest.arrives":         "arrives %s",
+	"digest.recurring":       "Recurring letters sent this week",
+	"digest.next_on":         "next on %s",
+	"digest.attention":       "Needs your attention",
+	"digest.attention_intro": "These letters could not be delivered. Check the recipient and reschedule them.",
+	"digest.was_due":         "was due %s",
+	"digest.sent_by":         "This digest was sent by",
+	"digest.unsubscribe":     "Stop the weekly digest",
+
+	// Unsubscribe pages
+	"unsubscribe.heading":            "Unsubscribe",
+	"unsubscribe.confirm":            "Stop receiving %s from Dear Future?",
+	"unsubscribe.button":             "Unsubscribe",
+	"unsubscribe.done":               "You will no longer receive %s. You can turn them back on in your notification settings.",
+	"unsubscribe.delivery_reminders": "delivery reminders",
+	"unsubscribe.marketing":          "news and offers",
+	"unsubscribe.weekly_digest":      "weekly digests",
+
+	// Read receipt pages
+	"read.heading": "Mark as read",
+	"read.confirm": "Let your past self know you read this letter?",
+	"read.button":  "Mark as read",
+	"read.done":    "Thank you. Your letter is marked as read.",
+
+	// Check-in pages
+	"checkin.confirm": "Check in now to keep your letters private?",
+	"checkin.done":    "You are checked in. Your letters stay private until your next check-in is due.",
+	"checkin.partial": "%d of %d letters were checked in. Please try again for the others.",
+	"checkin.failed":  "We could not check you in. Please try again.",
+
+	// Cancel release pages
+	"cancel_release.heading":   "Cancel release",
+	"cancel_release.confirm":   "Cancel the release of this letter? It will not be sent to anyone.",
+	"cancel_release.button":    "Cancel release",
+	"cancel_release.done":      "The release is cancelled. This letter will not be sent.",
+	"cancel_release.not_armed": "This letter is no longer waiting for check-ins, so there is no release to cancel.",
+
+	// Account emails
+	"verify.subject":  "Verify your Dear Future account",
+	"verify.heading":  "Welcome to Dear Future!",
+	"verify.body":     "Thank you for signing up! Please verify your email address to start sending messages to your future self.",
+	"verify.button":   "Verify Email Address",
+	"verify.footer":   "If you didn't create this account, you can safely ignore this email.",
+	"reset.subject":   "Reset your Dear Future password",
+	"reset.heading":   "Password Reset Request",
+	"reset.body":      "We received a request to reset your password for your Dear Future account.",
+	"reset.button":    "Reset Password",
+	"reset.important": "Important:",
+	"reset.expiry":    "This link will expire in 1 hour for security reasons.",
+	"reset.footer":    "If you didn't request a password reset, you can safely ignore this email.",
+	"reset.unchanged": "Your password will not be changed unless you click the link above.",
+}
@@ -0,0 +1,187 @@
+// Package i18n contains the Vietnamese message catalog
+package i18n
+
+var vietnamese = map[string]string{
+	// Shared email copy
+	"email.greeting":       "Xin chào,",
+	"email.greeting_named": "Xin chào %s,",
+	"email.copy_link":      "Hoặc sao chép và dán liên kết này vào trình duyệt:",
+	"email.untitled":       "Thư không có tiêu đề",
+
+	// Delivered letters
+	"letter.subject":          "Thư từ quá khứ của bạn: %s",
+	"letter.subject_untitled": "Bạn có một lá thư từ chính mình trong quá khứ",
+	"letter.intro":            "Bạn đã hẹn gửi lá thư này cho chính mình trong tương lai.",
+	"letter.subject_line":     "Tiêu đề: %s",
+	"letter.message":          "Nội dung:",
+	"letter.written_on":       "Viết vào: %s",
+	"letter.scheduled_on":     "Hẹn gửi vào: %s",
+	"letter.signoff":          "Thân mến,",
+	"letter.signature":        "Bạn của ngày hôm qua",
+	"letter.delivered_by":     "Lá thư này được gửi bởi Dear Future - Lời nhắn gửi ngày mai",
+	"letter.heading":          "📬 Lá thư từ chính bạn trong quá khứ",
+	"letter.scheduled_for":    "Hẹn gửi vào:",
+	"letter.thread":           "Những lá thư trước trong cuộc trò chuyện",
+	"letter.thread_entry":     "Viết %s, đã gửi %s",
+	"letter.read_link":        "Cho bạn của ngày hôm qua biết bạn đã đọc lá thư này",
+	"letter.sent_by":          "Lá thư này được gửi bởi",
+	"letter.tagline":          "Lời nhắn gửi ngày mai, đến tay bạn hôm nay.",
+	"letter.attachments":      "Tệp đính kèm",
+	"letter.links_expire":     "Liên kết tải xuống có hiệu lực đến %s.",
+
+	// Check-in warnings
+	"checkin.subject":     "Hãy xác nhận để giữ kín \"%s\"",
+	"checkin.heading":     "Đã đến lúc xác nhận",
+	"checkin.missed":      "Bạn đã bỏ lỡ một lần xác nhận cho",
+	"checkin.release_on":  "Nếu bạn không xác nhận, lá thư này sẽ được gửi đi vào",
+	"checkin.button":      "Xác nhận",
+	"checkin.cancel":      "Nếu bạn không còn muốn gửi lá thư này, bạn có thể",
+	"checkin.cancel_link": "hủy việc gửi",
+	"checkin.sent_by":     "Lời nhắc này được gửi bởi",
+	"checkin.text":        "Bạn đã bỏ lỡ một lần xác nhận cho \"%s\".\n\nLá thư sẽ được gửi đi vào %s nếu bạn không xác nhận:\n%s\n\nĐể hủy việc gửi:\n%s\n",
+
+	// Weekly digest
+	"digest.subject":         "Tuần của bạn cùng Dear Future",
+	"digest.heading":         "Tuần của bạn cùng Dear Future",
+	"digest.intro":           "Đây là tóm tắt tuần của bạn cùng Dear Future.",
+	"digest.upcoming":        "Sắp đến",
+	"digest.arrives":         "đến vào %s",
+	"digest.recurring":       "Thư định kỳ đã gửi trong tuần",
+	"digest.next_on":         "lần tới vào %s",
+	"digest.attention":       "Cần bạn chú ý",
+	"digest.attention_intro": "Không thể gửi những lá thư này. Hãy kiểm tra người nhận và hẹn lại thời gian gửi.",
+	"digest.was_due":         "lẽ ra gửi vào %s",
+	"digest.sent_by":         "Bản tóm tắt này được gửi bởi",
+	"digest.unsubscribe":     "Ngừng nhận bản tóm tắt hằng tuần",
+
+	// Unsubscribe pages
+	"unsubscribe.heading":            "Hủy đăng ký",
+	"unsubscribe.confirm":            "Ngừng nhận %s từ Dear Future?",
+	"unsubscribe.button":             "Hủy đăng ký",
+	"unsubscribe.done":               "Bạn sẽ không nhận %s nữa. Bạn có thể bật lại trong cài đặt thông báo.",
+	"unsubscribe.delivery_reminders": "lời nhắc gửi thư",
+	"unsubscribe.marketing":          "tin tức và ưu đãi",
+	"unsubscribe.weekly_digest":      "bản tóm tắt hằng tuần",
+
+	// Read receipt pages
+	"read.heading": "Đánh dấu đã đọc",
+	"read.confirm": "Cho bạn của ngày hôm qua biết bạn đã đọc lá thư này?",
+	"read.button":  "Đánh dấu đã đọc",
+	"read.done":    "Cảm ơn bạn. Lá thư đã được đánh dấu là đã đọc.",
+
+	// Check-in pages
+	"checkin.confirm": "Xác nhận ngay để giữ kín các lá thư của bạn?",
+	"checkin.done":    "Bạn đã xác nhận. Các lá thư sẽ được giữ kín đến lần xác nhận tiếp theo.",
+	"checkin.partial": "Đã xác nhận %d trên %d lá thư. Vui lòng thử lại cho các lá thư còn lại.",
+	"checkin.failed":  "Không thể xác nhận. Vui lòng thử lại.",
+
+	// Cancel release pages
+	"cancel_release.heading":   "Hủy việc gửi",
+	"cancel_release.confirm":   "Hủy việc gửi lá thư này? Thư sẽ không được gửi cho ai.",
+	"cancel_release.button":    "Hủy việc gửi",
+	"cancel_release.done":      "Đã hủy việc gửi. Lá thư này sẽ không được gửi đi.",
+	"cancel_release.not_armed": "Lá thư này không còn chờ xác nhận nên không có gì để hủy.",
+
+	// Account emails
+	"verify.subject":  "Xác minh tài khoản Dear Future của bạn",
+	"verify.heading":  "Chào mừng bạn đến với Dear Future!",
+	"verify.body":     "Cảm ơn bạn đã đăng ký! Hãy xác minh địa chỉ email để bắt đầu gửi thư cho chính mình trong tương lai.",
+	"verify.button":   "Xác minh email",
+	"verify.footer":   "Nếu bạn không tạo tài khoản này, bạn có thể bỏ qua email này.",
+	"reset.subject":   "Đặt lại mật khẩu Dear Future",
+	"reset.heading":   "Yêu cầu đặt lại mật khẩu",
+	"reset.body":      "Chúng tôi đã nhận được yêu cầu đặt lại mật khẩu cho tài khoản Dear Future của bạn.",
+	"reset.button":    "Đặt lại mật khẩu",
+	"reset.important": "Quan trọng:",
+	"reset.expiry":    "Vì lý do bảo mật, liên kết này sẽ hết hạn sau 1 giờ.",
+	"reset.footer":    "Nếu bạn không yêu cầu đặt lại mật khẩu, bạn có thể bỏ qua email này.",
+	"reset.unchanged": "Mật khẩu của bạn sẽ không thay đổi trừ khi bạn nhấn vào liên kết ở trên.",
+}
+
+var vietnameseErrors = map[string]string{
+	"If-Match header is required":                                 "Cần có header If-Match",
+	"a request with this idempotency key is still in progress":    "Một yêu cầu với khóa idempotency này vẫn đang được xử lý",
+	"a tag with this name already exists":                         "Đã có nhãn với tên này",
+	"a tag with this name already exists; merge the tags instead": "Đã có nhãn với tên này; hãy gộp các nhãn lại",
+	"access denied":                                             "Không có quyền truy cập",
+	"attachment not found":                                      "Không tìm thấy tệp đính kèm",
+	"country is required":                                       "Cần chọn quốc gia",
+	"delivery receipts are not supported":                       "Không hỗ trợ xác nhận đã nhận thư",
+	"email and password are required":                           "Cần nhập email và mật khẩu",
+	"email, name, and password are required":                    "Cần nhập email, tên và mật khẩu",
+	"failed to cancel release":                                  "Không thể hủy việc gửi thư",
+	"failed to check in":                                        "Không thể xác nhận",
+	"failed to create message":                                  "Không thể tạo thư",
+	"failed to create tag":                                      "Không thể tạo nhãn",
+	"failed to create user":                                     "Không thể tạo người dùng",
+	"failed to delete attachment":                               "Không thể xóa tệp đính kèm",
+	"failed to delete tag":                                      "Không thể xóa nhãn",
+	"failed to export messages":                                 "Không thể xuất thư",
+	"failed to generate tokens":                                 "Không thể tạo token",
+	"failed to import messages":                                 "Không thể nhập thư",
+	"failed to list trash":                                      "Không thể tải thùng rác",
+	"failed to load analytics":                                  "Không thể tải số liệu thống kê",
+	"failed to load attachments":                                "Không thể tải tệp đính kèm",
+	"failed to load plan usage":                                 "Không thể tải mức sử dụng gói",
+	"failed to load tags":                                       "Không thể tải nhãn",
+	"failed to load usage":                                      "Không thể tải mức sử dụng",
+	"failed to mark message as read":                            "Không thể đánh dấu thư là đã đọc",
+	"failed to merge tags":                                      "Không thể gộp nhãn",
+	"failed to parse upload form":                               "Không thể đọc biểu mẫu tải lên",
+	"failed to process idempotency key":                         "Không thể xử lý khóa idempotency",
+	"failed to process password":                                "Không thể xử lý mật khẩu",
+	"failed to read file data":                                  "Không thể đọc dữ liệu tệp",
+	"failed to read request body":                               "Không thể đọc nội dung yêu cầu",
+	"failed to remove file from storage":                        "Không thể xóa tệp khỏi bộ lưu trữ",
+	"failed to rename tag":                                      "Không thể đổi tên nhãn",
+	"failed to render preview":                                  "Không thể tạo bản xem trước",
+	"failed to resolve date":                                    "Không thể xác định ngày",
+	"failed to restore message":                                 "Không thể khôi phục thư",
+	"failed to retrieve messages":                               "Không thể tải thư",
+	"failed to save attachment metadata":                        "Không thể lưu thông tin tệp đính kèm",
+	"failed to save message tags":                               "Không thể lưu nhãn của thư",
+	"failed to search messages":                                 "Không thể tìm kiếm thư",
+	"failed to unsubscribe":                                     "Không thể hủy đăng ký",
+	"failed to update message":                                  "Không thể cập nhật thư",
+	"failed to update notification preferences":                 "Không thể cập nhật tùy chọn thông báo",
+	"failed to update user":                                     "Không thể cập nhật người dùng",
+	"failed to upload file":                                     "Không thể tải tệp lên",
+	"file attachments are disabled":                             "Tính năng đính kèm tệp đang bị tắt",
+	"file exceeds maximum allowed size":                         "Tệp vượt quá dung lượng tối đa cho phép",
+	"file field is required":                                    "Cần có trường file",
+	"idempotency key is too long":                               "Khóa idempotency quá dài",
+	"idempotency key was already used for a different request":  "Khóa idempotency đã được dùng cho một yêu cầu khác",
+	"internal server error":                                     "Lỗi máy chủ nội bộ",
+	"invalid attachment_id":                                     "attachment_id không hợp lệ",
+	"invalid authorization header format":                       "Header Authorization không đúng định dạng",
+	"invalid credentials":                                       "Thông tin đăng nhập không đúng",
+	"invalid delivery_date format":                              "delivery_date không đúng định dạng",
+	"invalid from date (use RFC3339 or YYYY-MM-DD)":             "Ngày bắt đầu không hợp lệ (dùng RFC3339 hoặc YYYY-MM-DD)",
+	"invalid message id":                                        "Mã thư không hợp lệ",
+	"invalid message_id":                                        "message_id không hợp lệ",
+	"invalid or expired token":                                  "Token không hợp lệ hoặc đã hết hạn",
+	"invalid refresh token":                                     "Refresh token không hợp lệ",
+	"invalid request body":                                      "Nội dung yêu cầu không hợp lệ",
+	"invalid source_id":                                         "source_id không hợp lệ",
+	"invalid status":                                            "Trạng thái không hợp lệ",
+	"invalid tag ID":                                            "Mã nhãn không hợp lệ",
+	"invalid target_id":                                         "target_id không hợp lệ",
+	"invalid to date (use RFC3339 or YYYY-MM-DD)":               "Ngày kết thúc không hợp lệ (dùng RFC3339 hoặc YYYY-MM-DD)",
+	"invalid year":                                              "Năm không hợp lệ",
+	"message cannot be edited (already delivered or cancelled)": "Không thể sửa thư (thư đã được gửi hoặc đã hủy)",
+	"message id is required":                                    "Cần có mã thư",
+	"message is not waiting for check-ins":                      "Thư không chờ xác nhận",
+	"message not found":                                         "Không tìm thấy thư",
+	"method not allowed":                                        "Phương thức không được hỗ trợ",
+	"missing authorization header":                              "Thiếu header Authorization",
+	"only delivered messages can be replied to":                 "Chỉ có thể trả lời những thư đã được gửi",
+	"refresh_token is required":                                 "Cần có refresh_token",
+	"request body is too large":                                 "Nội dung yêu cầu quá lớn",
+	"source tag not found":                                      "Không tìm thấy nhãn nguồn",
+	"storage service unavailable":                               "Dịch vụ lưu trữ không khả dụng",
+	"tag not found":                                             "Không tìm thấy nhãn",
+	"target tag not found":                                      "Không tìm thấy nhãn đích",
+	"this category cannot be unsubscribed from a link":          "Không thể hủy đăng ký loại thông báo này qua liên kết",
+	"unauthorized":                                              "Chưa xác thực",
+	"user not found":                                            "Không tìm thấy người dùng",
+}
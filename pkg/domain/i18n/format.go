@@ -0,0 +1,54 @@
+// Package i18n contains locale-aware date formatting
+package i18n
+
+import (
+	"fmt"
+	"time"
+)
+
+// vietnameseWeekdays names the days of the week, Sunday first like time.Weekday
+var vietnameseWeekdays = [...]string{"Chủ Nhật", "Thứ Hai", "Thứ Ba", "Thứ Tư", "Thứ Năm", "Thứ Sáu", "Thứ Bảy"}
+
+// FormatDate formats the date of t in loc, such as "October 19, 2026" or
+// "ngày 19 tháng 10 năm 2026". A nil loc formats in UTC.
+func (l Locale) FormatDate(t time.Time, loc *time.Location) string {
+	local := t.In(orUTC(loc))
+	switch l.OrDefault() {
+	case Vietnamese:
+		return fmt.Sprintf("ngày %d tháng %d năm %d", local.Day(), int(local.Month()), local.Year())
+	default:
+		return local.Format("January 2, 2006")
+	}
+}
+
+// FormatDateTime formats t in loc with its date, time and zone, such as
+// "October 19, 2026 at 9:00 AM +07" or "09:00 ngày 19 tháng 10 năm 2026 (+07)"
+func (l Locale) FormatDateTime(t time.Time, loc *time.Location) string {
+	local := t.In(orUTC(loc))
+	switch l.OrDefault() {
+	case Vietnamese:
+		return fmt.Sprintf("%s %s (%s)", local.Format("15:04"), l.FormatDate(local, local.Location()), local.Format("MST"))
+	default:
+		return local.Format("January 2, 2006 at 3:04 PM MST")
+	}
+}
+
+// FormatWeekdayTime formats t in loc with its day of the week and time but
+// no year, for dates in the coming days such as "Monday, October 19 at 9:00
+// AM" or "Thứ Hai, ngày 19 tháng 10 lúc 09:00"
+func (l Locale) FormatWeekdayTime(t time.Time, loc *time.Location) string {
+	local := t.In(orUTC(loc))
+	switch l.OrDefault() {
+	case Vietnamese:
+		return fmt.Sprintf("%s, ngày %d tháng %d lúc %s", vietnameseWeekdays[local.Weekday()], local.Day(), int(local.Month()), local.Format("15:04"))
+	default:
+		return local.Format("Monday, January 2 at 3:04 PM")
+	}
+}
+
+func orUTC(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,131 @@
+// Package i18n provides the locales, message catalogs and date formats used
+// for email content and API error messages
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a language the application speaks, as a BCP 47 primary
+// language subtag
+type Locale string
+
+const (
+	English    Locale = "en"
+	Vietnamese Locale = "vi"
+)
+
+// DefaultLocale is used when no supported locale was asked for
+const DefaultLocale = English
+
+// Locales lists the supported locales
+var Locales = []Locale{English, Vietnamese}
+
+// ParseLocale parses a language tag such as "vi", "vi-VN" or "en_US" into a
+// supported locale
+func ParseLocale(value string) (Locale, error) {
+	tag := strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	for _, locale := range Locales {
+		if Locale(tag) == locale {
+			return locale, nil
+		}
+	}
+	return "", errors.New("unsupported locale: " + value)
+}
+
+// Negotiate picks the supported locale preferred by an Accept-Language
+// header, or DefaultLocale when none is acceptable
+func Negotiate(acceptLanguage string) Locale {
+	type candidate struct {
+		locale  Locale
+		quality float64
+		order   int
+	}
+
+	var candidates []candidate
+	for i, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		locale, err := ParseLocale(fields[0])
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{locale: locale, quality: quality, order: i})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return DefaultLocale
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].locale
+}
+
+// OrDefault returns the locale, or DefaultLocale if it is not supported
+func (l Locale) OrDefault() Locale {
+	if _, ok := catalogs[l]; ok {
+		return l
+	}
+	return DefaultLocale
+}
+
+// T returns the text of a catalog key in the locale, formatted with args.
+// Keys missing from the locale fall back to English, then to the key.
+func (l Locale) T(key string, args ...any) string {
+	text, ok := catalogs[l.OrDefault()][key]
+	if !ok {
+		if text, ok = catalogs[DefaultLocale][key]; !ok {
+			text = key
+		}
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Error translates an API error message. Error messages are written in
+// English and used as their own key; messages without a translation, such
+// as validation errors naming a value, are returned unchanged.
+func (l Locale) Error(message string) string {
+	if translated, ok := errorCatalogs[l.OrDefault()][message]; ok {
+		return translated
+	}
+	return message
+}
+
+type contextKey struct{}
+
+// WithLocale returns a context carrying the locale of the request
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale carried by the context, or DefaultLocale
+func FromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(contextKey{}).(Locale); ok {
+		return locale.OrDefault()
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Locale
+	}{
+		{"", English},
+		{"vi", Vietnamese},
+		{"vi-VN,vi;q=0.9,en-US;q=0.8", Vietnamese},
+		{"fr-FR, en;q=0.5, vi;q=0.7", Vietnamese},
+		{"de, vi;q=0", English},
+		{"ja", English},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleText(t *testing.T) {
+	if got := Vietnamese.T("letter.subject", "Chúc mừng"); got != "Thư từ quá khứ của bạn: Chúc mừng" {
+		t.Errorf("T() = %q", got)
+	}
+	if got := Locale("fr").T("checkin.button"); got != "Check In" {
+		t.Errorf("unsupported locales should use English, got %q", got)
+	}
+	if got := Vietnamese.Error("message not found"); got != "Không tìm thấy thư" {
+		t.Errorf("Error() = %q", got)
+	}
+	if got := Vietnamese.Error("invalid timezone: Mars/Base"); got != "invalid timezone: Mars/Base" {
+		t.Errorf("untranslated errors should be returned unchanged, got %q", got)
+	}
+	if got := FromContext(WithLocale(context.Background(), Vietnamese)); got != Vietnamese {
+		t.Errorf("FromContext() = %s, want vi", got)
+	}
+
+	// Every English text needs a Vietnamese translation
+	for key := range english {
+		if _, ok := vietnamese[key]; !ok {
+			t.Errorf("missing Vietnamese translation of %q", key)
+		}
+	}
+}
+
+func TestFormatDateTime(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	at := time.Date(2026, time.October, 19, 2, 30, 0, 0, time.UTC)
+
+	if got := English.FormatDateTime(at, loc); got != "October 19, 2026 at 9:30 AM +07" {
+		t.Errorf("English.FormatDateTime() = %q", got)
+	}
+	if got := Vietnamese.FormatDateTime(at, loc); got != "09:30 ngày 19 tháng 10 năm 2026 (+07)" {
+		t.Errorf("Vietnamese.FormatDateTime() = %q", got)
+	}
+	if got := Vietnamese.FormatWeekdayTime(at, loc); got != "Thứ Hai, ngày 19 tháng 10 lúc 09:30" {
+		t.Errorf("Vietnamese.FormatWeekdayTime() = %q", got)
+	}
+}
@@ -0,0 +1,208 @@
+// Package message contains milestone-anchored delivery for message domain
+package message
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// DefaultAnchorTime is when an anchored message is delivered on its day
+// unless the author picks a time
+const DefaultAnchorTime = user.TimeOfDay(9 * 60)
+
+// maxAnchorOccurrence bounds how many years after a milestone a letter can go
+const maxAnchorOccurrence = 150
+
+// MilestoneAnchor ties the delivery of a message to a personal milestone,
+// such as "on my 40th birthday" or "a week before our 10th anniversary".
+// The delivery date is the occurrence-th anniversary of the milestone,
+// moved by the offset, at a wall-clock time in the message's timezone. The
+// milestone date is kept so a corrected milestone can be detected.
+type MilestoneAnchor struct {
+	milestone    user.Milestone
+	occurrence   int
+	offsetMonths int
+	offsetDays   int
+	at           user.TimeOfDay
+}
+
+// StoredMilestoneAnchor represents a persisted milestone anchor
+type StoredMilestoneAnchor struct {
+	Milestone     string
+	Occurrence    int
+	OffsetMonths  int
+	OffsetDays    int
+	At            user.TimeOfDay
+	MilestoneDate time.Time
+}
+
+// NewMilestoneAnchor anchors delivery to the occurrence-th anniversary of a
+// milestone, 0 being the milestone day itself, moved by the offset
+func NewMilestoneAnchor(milestone user.Milestone, occurrence, offsetMonths, offsetDays int, at user.TimeOfDay) common.Result[MilestoneAnchor] {
+	return RestoreMilestoneAnchor(StoredMilestoneAnchor{
+		Milestone:     milestone.Key(),
+		Occurrence:    occurrence,
+		OffsetMonths:  offsetMonths,
+		OffsetDays:    offsetDays,
+		At:            at,
+		MilestoneDate: milestone.Date(),
+	})
+}
+
+// RestoreMilestoneAnchor rebuilds a milestone anchor from stored data
+func RestoreMilestoneAnchor(data StoredMilestoneAnchor) common.Result[MilestoneAnchor] {
+	milestone := user.NewMilestone(data.Milestone, "", data.MilestoneDate)
+	if milestone.IsErr() {
+		return common.Err[MilestoneAnchor](milestone.Error())
+	}
+	if data.Occurrence < 0 || data.Occurrence > maxAnchorOccurrence {
+		return common.Err[MilestoneAnchor](fmt.Errorf("anniversary must be between 0 and %d", maxAnchorOccurrence))
+	}
+	if data.OffsetMonths < -1200 || data.OffsetMonths > 1200 || data.OffsetDays < -36500 || data.OffsetDays > 36500 {
+		return common.Err[MilestoneAnchor](errors.New("anchor offset is too large"))
+	}
+	if data.At < 0 || data.At >= 24*60 {
+		return common.Err[MilestoneAnchor](errors.New("anchor time must be between 00:00 and 23:59"))
+	}
+
+	return common.Ok(MilestoneAnchor{
+		milestone:    milestone.Value(),
+		occurrence:   data.Occurrence,
+		offsetMonths: data.OffsetMonths,
+		offsetDays:   data.OffsetDays,
+		at:           data.At,
+	})
+}
+
+// Milestone returns the key of the milestone on the author's profile
+func (a MilestoneAnchor) Milestone() string {
+	return a.milestone.Key()
+}
+
+// Occurrence returns which anniversary of the milestone is used
+func (a MilestoneAnchor) Occurrence() int {
+	return a.occurrence
+}
+
+func (a MilestoneAnchor) OffsetMonths() int {
+	return a.offsetMonths
+}
+
+func (a MilestoneAnchor) OffsetDays() int {
+	return a.offsetDays
+}
+
+// At returns the wall-clock delivery time
+func (a MilestoneAnchor) At() user.TimeOfDay {
+	return a.at
+}
+
+// MilestoneDate returns the milestone date the delivery was derived from
+func (a MilestoneAnchor) MilestoneDate() time.Time {
+	return a.milestone.Date()
+}
+
+// Resolve returns the delivery time of the anchor in the timezone
+func (a MilestoneAnchor) Resolve(timezone string) common.Result[time.Time] {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return common.Err[time.Time](errors.New("invalid timezone"))
+	}
+
+	day := a.milestone.Anniversary(a.occurrence).AddDate(0, a.offsetMonths, a.offsetDays)
+	return common.Ok(time.Date(day.Year(), day.Month(), day.Day(), int(a.at)/60, int(a.at)%60, 0, 0, loc).UTC())
+}
+
+// withMilestone returns the anchor recomputed from a corrected milestone date
+func (a MilestoneAnchor) withMilestone(milestone user.Milestone) MilestoneAnchor {
+	updated := a
+	updated.milestone = milestone
+	return updated
+}
+
+// Anchor returns the milestone the delivery date is derived from
+func (m Message) Anchor() common.Option[MilestoneAnchor] {
+	return m.anchor
+}
+
+// WithMilestoneAnchor returns a new Message delivered at the time derived
+// from the anchor. Anchored messages are delivered once.
+func (m Message) WithMilestoneAnchor(anchor MilestoneAnchor) common.Result[Message] {
+	if m.checkIn.IsSome() || m.surprise.IsSome() {
+		return common.Err[Message](errors.New("check-in and surprise messages cannot be anchored to a milestone"))
+	}
+	if m.recurrence != RecurrenceNone {
+		return common.Err[Message](errors.New("recurring messages cannot be anchored to a milestone"))
+	}
+
+	deliveryDate := resolveAnchor(anchor, m.timezone)
+	if deliveryDate.IsErr() {
+		return common.Err[Message](deliveryDate.Error())
+	}
+
+	updated := m
+	updated.anchor = common.Some(anchor)
+	updated.holiday = common.None[HolidayTarget]()
+	updated.deliveryDate = deliveryDate.Value()
+	updated.intendedDate = common.None[time.Time]()
+	updated.updatedAt = time.Now()
+	return common.Ok(updated)
+}
+
+// WithMilestoneCorrected returns the message with its delivery date
+// recomputed when it is anchored to the milestone and the milestone date
+// changed. Other messages are returned unchanged.
+func (m Message) WithMilestoneCorrected(milestone user.Milestone) common.Result[Message] {
+	if !m.IsAnchoredTo(milestone.Key()) || m.anchor.Value().MilestoneDate().Equal(milestone.Date()) {
+		return common.Ok(m)
+	}
+	if !m.IsEditable() {
+		return common.Ok(m)
+	}
+
+	return m.WithMilestoneAnchor(m.anchor.Value().withMilestone(milestone))
+}
+
+// ReanchorMessages recomputes the delivery dates of messages anchored to
+// corrected milestones and returns the messages that changed. It fails if a
+// letter would move into the past.
+func ReanchorMessages(messages []Message, corrected []user.Milestone) common.Result[[]Message] {
+	changed := []Message{}
+	for _, msg := range messages {
+		updated := msg
+		for _, milestone := range corrected {
+			result := updated.WithMilestoneCorrected(milestone)
+			if result.IsErr() {
+				return common.Err[[]Message](fmt.Errorf("letter %q: %w", msg.title, result.Error()))
+			}
+			updated = result.Value()
+		}
+
+		if updated.anchor.IsSome() && !updated.anchor.Value().MilestoneDate().Equal(msg.anchor.Value().MilestoneDate()) {
+			changed = append(changed, updated)
+		}
+	}
+	return common.Ok(changed)
+}
+
+// IsAnchoredTo returns true if the delivery date is derived from the milestone
+func (m Message) IsAnchoredTo(milestone string) bool {
+	return m.anchor.IsSome() && m.anchor.Value().Milestone() == milestone
+}
+
+// resolveAnchor returns the delivery time of an anchor, which must be a
+// valid future delivery date
+func resolveAnchor(anchor MilestoneAnchor, timezone string) common.Result[time.Time] {
+	deliveryDate := anchor.Resolve(timezone)
+	if deliveryDate.IsErr() {
+		return deliveryDate
+	}
+	if result := validateDeliveryDate(deliveryDate.Value(), timezone, false); result.IsErr() {
+		return common.Err[time.Time](fmt.Errorf("%s milestone: %w", anchor.Milestone(), result.Error()))
+	}
+	return deliveryDate
+}
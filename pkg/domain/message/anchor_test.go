@@ -0,0 +1,103 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+func TestMilestoneAnchoredMessage(t *testing.T) {
+	birthYear := time.Now().Year() - 30
+	birthday := user.NewMilestone(user.MilestoneBirthday, "", time.Date(birthYear, time.March, 10, 0, 0, 0, 0, time.UTC)).Value()
+
+	// A week before the 40th birthday, at 08:30 in Ho Chi Minh City (UTC+7)
+	anchor := NewMilestoneAnchor(birthday, 40, 0, -7, user.TimeOfDay(8*60+30))
+	if anchor.IsErr() {
+		t.Fatalf("NewMilestoneAnchor() error = %v", anchor.Error())
+	}
+
+	msgResult := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Forty",
+		Content:        "Happy almost-birthday.",
+		Timezone:       "Asia/Ho_Chi_Minh",
+		DeliveryMethod: DeliveryEmail,
+		Anchor:         common.Some(anchor.Value()),
+	})
+	if msgResult.IsErr() {
+		t.Fatalf("NewMessage() error = %v", msgResult.Error())
+	}
+	msg := msgResult.Value()
+
+	want := time.Date(birthYear+40, time.March, 3, 1, 30, 0, 0, time.UTC)
+	if !msg.DeliveryDate().Equal(want) {
+		t.Errorf("DeliveryDate() = %v, want %v", msg.DeliveryDate(), want)
+	}
+
+	// Correcting the birthday moves the letter with it
+	corrected := user.NewMilestone(user.MilestoneBirthday, "", time.Date(birthYear, time.March, 20, 0, 0, 0, 0, time.UTC)).Value()
+	moved := msg.WithMilestoneCorrected(corrected)
+	if moved.IsErr() {
+		t.Fatalf("WithMilestoneCorrected() error = %v", moved.Error())
+	}
+	if want := want.AddDate(0, 0, 10); !moved.Value().DeliveryDate().Equal(want) {
+		t.Errorf("corrected DeliveryDate() = %v, want %v", moved.Value().DeliveryDate(), want)
+	}
+
+	other := user.NewMilestone("wedding", "", time.Date(birthYear+25, time.June, 1, 0, 0, 0, 0, time.UTC)).Value()
+	if unchanged := msg.WithMilestoneCorrected(other); !unchanged.Value().DeliveryDate().Equal(msg.DeliveryDate()) {
+		t.Error("other milestones should not move the letter")
+	}
+
+	if msg.WithRecurrence(RecurrenceYearly).IsOk() {
+		t.Error("anchored messages should not recur")
+	}
+
+	// An anniversary that already passed cannot be scheduled
+	past := NewMilestoneAnchor(birthday, 18, 0, 0, DefaultAnchorTime).Value()
+	if msg.WithMilestoneAnchor(past).IsOk() {
+		t.Error("anchors resolving to the past should be rejected")
+	}
+}
+
+func TestReanchorMessages(t *testing.T) {
+	wedding := user.NewMilestone("wedding", "", time.Date(time.Now().Year()-2, time.June, 20, 0, 0, 0, 0, time.UTC)).Value()
+	anchor := NewMilestoneAnchor(wedding, 10, 0, 0, DefaultAnchorTime).Value()
+
+	anchored := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Ten years",
+		Content:        "Still us.",
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+		Anchor:         common.Some(anchor),
+	}).Value()
+	plain := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Plain",
+		Content:        "Fixed date.",
+		DeliveryDate:   time.Now().Add(48 * time.Hour),
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+	}).Value()
+
+	corrected := user.NewMilestone("wedding", "", wedding.Date().AddDate(0, 0, 1)).Value()
+	changed := ReanchorMessages([]Message{anchored, plain}, []user.Milestone{corrected})
+	if changed.IsErr() {
+		t.Fatalf("ReanchorMessages() error = %v", changed.Error())
+	}
+	if len(changed.Value()) != 1 || changed.Value()[0].ID() != anchored.ID() {
+		t.Fatalf("ReanchorMessages() changed %d messages, want only the anchored one", len(changed.Value()))
+	}
+	if got, want := changed.Value()[0].DeliveryDate(), anchored.DeliveryDate().AddDate(0, 0, 1); !got.Equal(want) {
+		t.Errorf("DeliveryDate() = %v, want %v", got, want)
+	}
+
+	tooEarly := user.NewMilestone("wedding", "", wedding.Date().AddDate(-20, 0, 0)).Value()
+	if ReanchorMessages([]Message{anchored}, []user.Milestone{tooEarly}).IsOk() {
+		t.Error("corrections moving a letter into the past should be rejected")
+	}
+}
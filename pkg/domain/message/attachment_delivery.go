@@ -0,0 +1,143 @@
+// Package message contains how attachments travel with a delivered letter
+package message
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachments are embedded in the delivered email up to these sizes. Larger
+// files, and files past the total, are sent as download links that expire
+// after AttachmentLinkLifetime, the longest lifetime presigned links allow.
+// The total is counted after base64 encoding, which grows files by over a
+// third, so emails stay below the 25 MB most mail servers accept.
+const (
+	MaxEmbeddedAttachmentSize  = 10 * 1024 * 1024 // Per file, as uploaded
+	MaxEmbeddedAttachmentTotal = 20 * 1024 * 1024 // Per email, after encoding
+	AttachmentLinkLifetime     = 7 * 24 * time.Hour
+)
+
+// encodedLineLength is the length of base64 lines in an email (RFC 2045)
+const encodedLineLength = 76
+
+// AttachmentDisposition says how an attachment is sent with a letter
+type AttachmentDisposition string
+
+const (
+	AttachmentInline   AttachmentDisposition = "inline"     // Image shown in the letter
+	AttachmentEmbedded AttachmentDisposition = "attachment" // File attached to the email
+	AttachmentLinked   AttachmentDisposition = "link"       // Expiring download link
+)
+
+// inlineImageTypes are the images email clients can show inside a letter.
+// SVG is attached instead because most clients refuse to render it.
+var inlineImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/jpg":  true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// AttachmentOpener opens the content of an embedded file. It is called
+// while the email is written, so files are streamed from storage instead of
+// being held in memory.
+type AttachmentOpener func() (io.ReadCloser, error)
+
+// DeliveredAttachment is an attachment as it is sent with a letter
+type DeliveredAttachment struct {
+	ID          uuid.UUID
+	FileName    string
+	ContentType string
+	Size        int64
+	StorageKey  string
+	Disposition AttachmentDisposition
+	Open        AttachmentOpener // Content of inline and embedded files
+	URL         string           // Download link of linked files
+	ExpiresAt   time.Time        // When the download link stops working
+}
+
+// ContentID identifies an inline image within its email
+func (da DeliveredAttachment) ContentID() string {
+	return da.ID.String() + "@dearfuture.app"
+}
+
+// IsEmbedded reports whether the file travels inside the email
+func (da DeliveredAttachment) IsEmbedded() bool {
+	return da.Disposition == AttachmentInline || da.Disposition == AttachmentEmbedded
+}
+
+// AsLink returns the attachment sent as a download link instead
+func (da DeliveredAttachment) AsLink(url string, expiresAt time.Time) DeliveredAttachment {
+	linked := da
+	linked.Disposition = AttachmentLinked
+	linked.Open = nil
+	linked.URL = url
+	linked.ExpiresAt = expiresAt
+	return linked
+}
+
+// WithContent returns the embedded attachment reading its content from open
+func (da DeliveredAttachment) WithContent(open AttachmentOpener) DeliveredAttachment {
+	loaded := da
+	loaded.Open = open
+	return loaded
+}
+
+// EncodedAttachmentSize returns the size of a file once base64 encoded in an
+// email, including the line breaks
+func EncodedAttachmentSize(size int64) int64 {
+	encoded := (size + 2) / 3 * 4
+	lines := (encoded + encodedLineLength - 1) / encodedLineLength
+	return encoded + lines*2
+}
+
+// PlanAttachmentDelivery decides how each attachment of a letter is sent.
+// Files are embedded in upload order while they fit MaxEmbeddedAttachmentSize
+// and, once encoded, MaxEmbeddedAttachmentTotal; embedded images are shown
+// inline. The rest
+// are sent as links. The content and links are filled in by the caller.
+func PlanAttachmentDelivery(attachments []MessageAttachment) []DeliveredAttachment {
+	ordered := make([]MessageAttachment, len(attachments))
+	copy(ordered, attachments)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].uploadedAt.Before(ordered[j].uploadedAt)
+	})
+
+	planned := make([]DeliveredAttachment, 0, len(ordered))
+	var embedded int64
+	for _, attachment := range ordered {
+		contentType := strings.ToLower(attachment.fileType)
+		disposition := AttachmentLinked
+		encoded := EncodedAttachmentSize(attachment.fileSize)
+		if attachment.fileSize <= MaxEmbeddedAttachmentSize && embedded+encoded <= MaxEmbeddedAttachmentTotal {
+			embedded += encoded
+			disposition = AttachmentEmbedded
+			if inlineImageTypes[contentType] {
+				disposition = AttachmentInline
+			}
+		}
+
+		planned = append(planned, DeliveredAttachment{
+			ID:          attachment.id,
+			FileName:    attachment.fileName,
+			ContentType: contentType,
+			Size:        attachment.fileSize,
+			StorageKey:  attachment.s3Key,
+			Disposition: disposition,
+		})
+	}
+
+	return planned
+}
+
+// WithAttachments returns the delivery info carrying the files of the letter
+func (mdi MessageDeliveryInfo) WithAttachments(attachments []DeliveredAttachment) MessageDeliveryInfo {
+	updated := mdi
+	updated.Attachments = attachments
+	return updated
+}
@@ -0,0 +1,96 @@
+package message
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPlanAttachmentDelivery(t *testing.T) {
+	uploaded := time.Date(2026, time.October, 1, 9, 0, 0, 0, time.UTC)
+	attachment := func(name, fileType string, size int64, minutes int) MessageAttachment {
+		return MessageAttachment{
+			id:         uuid.New(),
+			messageID:  uuid.New(),
+			fileName:   name,
+			fileType:   fileType,
+			s3Key:      "messages/" + name,
+			fileSize:   size,
+			uploadedAt: uploaded.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+
+	planned := PlanAttachmentDelivery([]MessageAttachment{
+		attachment("video.mp4", "video/mp4", MaxEmbeddedAttachmentSize+1, 0),
+		attachment("report.pdf", "application/pdf", 9*1024*1024, 2),
+		attachment("photo.jpg", "image/JPEG", 2*1024*1024, 1),
+		attachment("logo.svg", "image/svg+xml", 1024, 3),
+		attachment("scan.png", "image/png", 9*1024*1024, 4),
+		attachment("notes.txt", "text/plain", 1024, 5),
+	})
+
+	want := []struct {
+		name        string
+		disposition AttachmentDisposition
+	}{
+		{"video.mp4", AttachmentLinked}, // Larger than a single embedded file
+		{"photo.jpg", AttachmentInline}, // Images are shown in the letter
+		{"report.pdf", AttachmentEmbedded},
+		{"logo.svg", AttachmentEmbedded},  // SVG is never inlined
+		{"scan.png", AttachmentLinked},    // Past the total embedded size
+		{"notes.txt", AttachmentEmbedded}, // Still fits after a linked file
+	}
+
+	if len(planned) != len(want) {
+		t.Fatalf("PlanAttachmentDelivery() returned %d attachments, want %d", len(planned), len(want))
+	}
+	for i, w := range want {
+		if planned[i].FileName != w.name || planned[i].Disposition != w.disposition {
+			t.Errorf("attachment %d = %s (%s), want %s (%s)", i, planned[i].FileName, planned[i].Disposition, w.name, w.disposition)
+		}
+	}
+	if planned[1].ContentType != "image/jpeg" {
+		t.Errorf("content type should be normalized, got %q", planned[1].ContentType)
+	}
+
+	expires := uploaded.Add(AttachmentLinkLifetime)
+	open := func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("pdf")), nil }
+	linked := planned[2].WithContent(open).AsLink("https://files.example/report.pdf", expires)
+	if linked.IsEmbedded() || linked.Open != nil || linked.URL == "" || !linked.ExpiresAt.Equal(expires) {
+		t.Errorf("AsLink() = %+v, want a link without content", linked)
+	}
+}
+
+func TestPlanAttachmentDeliveryEncodedTotal(t *testing.T) {
+	// Two 7.5 MB files fit the total as uploaded but not once encoded
+	size := int64(7.5 * 1024 * 1024)
+	first := MessageAttachment{id: uuid.New(), fileName: "a.pdf", fileType: "application/pdf", fileSize: size}
+	second := MessageAttachment{id: uuid.New(), fileName: "b.pdf", fileType: "application/pdf", fileSize: size, uploadedAt: time.Now()}
+
+	planned := PlanAttachmentDelivery([]MessageAttachment{first, second})
+	if planned[0].Disposition != AttachmentEmbedded || planned[1].Disposition != AttachmentLinked {
+		t.Errorf("dispositions = %s, %s, want the second file linked", planned[0].Disposition, planned[1].Disposition)
+	}
+}
+
+func TestEncodedAttachmentSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want int64
+	}{
+		{0, 0},
+		{1, 6},   // One padded quantum and a line break
+		{57, 78}, // Exactly one full line
+		{58, 84}, // A second line starts
+		{3 * 1024 * 1024, 4*1024*1024 + (4*1024*1024+75)/76*2},
+	}
+
+	for _, tt := range tests {
+		if got := EncodedAttachmentSize(tt.size); got != tt.want {
+			t.Errorf("EncodedAttachmentSize(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
 )
 
@@ -17,24 +18,80 @@ func ScheduleMessage(message Message, userProfile user.UserProfile) common.Resul
 		return common.Err[ScheduleResult](errors.New("message is not in a schedulable state"))
 	}
 
-	// Calculate optimal delivery time
-	optimalTime := GetOptimalDeliveryTime(message.DeliveryDate(), message.Timezone())
-	if optimalTime.IsErr() {
-		return common.Err[ScheduleResult](optimalTime.Error())
+	// Move the delivery out of the recipient's quiet hours
+	shifted := ApplyDeliveryPreferences(message, userProfile)
+	if shifted.IsErr() {
+		return common.Err[ScheduleResult](shifted.Error())
 	}
 
 	// Calculate estimated delivery time (accounting for processing time)
-	estimatedDelivery := optimalTime.Value().Add(1 * time.Minute)
+	scheduledFor := shifted.Value().DeliveryDate()
+	estimatedDelivery := scheduledFor.Add(1 * time.Minute)
 
 	result := ScheduleResult{
-		Message:           message,
-		ScheduledFor:      optimalTime.Value(),
+		Message:           shifted.Value(),
+		ScheduledFor:      scheduledFor,
 		EstimatedDelivery: estimatedDelivery,
 	}
 
 	return common.Ok(result)
 }
 
+// ApplyDeliveryPreferences moves the delivery of a message to the next slot
+// allowed by the recipient's quiet hours and delivery windows, evaluated in
+// the recipient's timezone. The author's intended time is kept on the message;
+// if the preferences allow it again, the delivery moves back to it.
+func ApplyDeliveryPreferences(message Message, recipient user.UserProfile) common.Result[Message] {
+	if message.CheckIn().IsSome() {
+		// Check-in releases are driven by missed check-ins, not the schedule
+		return common.Ok(message)
+	}
+
+	intended := message.IntendedDeliveryDate().ValueOr(message.DeliveryDate())
+	optimalTime := GetOptimalDeliveryTime(intended, recipientTimezone(message, recipient), recipientPreferences(message, recipient))
+	if optimalTime.IsErr() {
+		return common.Err[Message](optimalTime.Error())
+	}
+
+	if optimalTime.Value().Equal(message.DeliveryDate()) {
+		return common.Ok(message)
+	}
+	return common.Ok(message.WithShiftedDelivery(optimalTime.Value()))
+}
+
+// DeferDelivery returns the message moved to the recipient's next allowed
+// slot if delivering it at now would fall in their quiet hours or outside
+// their delivery windows
+func DeferDelivery(message Message, recipient user.UserProfile, now time.Time) common.Option[Message] {
+	preferences := recipientPreferences(message, recipient)
+	if message.CheckIn().IsSome() || !preferences.IsRestricted() {
+		return common.None[Message]()
+	}
+
+	next := GetOptimalDeliveryTime(now, recipientTimezone(message, recipient), preferences)
+	if next.IsErr() || !next.Value().After(now) {
+		return common.None[Message]()
+	}
+	return common.Some(message.WithShiftedDelivery(next.Value()))
+}
+
+// recipientTimezone returns the timezone delivery preferences are evaluated in
+func recipientTimezone(message Message, recipient user.UserProfile) string {
+	if tz := recipient.User().Timezone(); tz != "" {
+		return tz
+	}
+	return message.Timezone()
+}
+
+// recipientPreferences returns the delivery preferences that apply to the
+// message. A letter aimed at a holiday is not moved off it.
+func recipientPreferences(message Message, recipient user.UserProfile) user.DeliveryPreferences {
+	if message.HolidayTarget().IsSome() {
+		return recipient.DeliveryPreferences().WithoutHolidayAvoidance()
+	}
+	return recipient.DeliveryPreferences()
+}
+
 // ProcessMessageDelivery processes a message for delivery (pure business logic)
 func ProcessMessageDelivery(message Message, recipient user.UserProfile) common.Result[MessageDeliveryInfo] {
 	// Check if message is ready for delivery
@@ -47,19 +104,38 @@ func ProcessMessageDelivery(message Message, recipient user.UserProfile) common.
 		return common.Err[MessageDeliveryInfo](errors.New("user has email notifications disabled"))
 	}
 
+	return BuildDeliveryInfo(message, recipient)
+}
+
+// BuildDeliveryInfo renders the deliverable form of a message without checking
+// whether it is due, so it can also be used to preview drafts
+func BuildDeliveryInfo(message Message, recipient user.UserProfile) common.Result[MessageDeliveryInfo] {
 	// Get effective recipient email
 	recipientEmail := recipient.GetEffectiveEmail()
 	if recipientEmail == "" {
 		return common.Err[MessageDeliveryInfo](errors.New("no valid recipient email"))
 	}
 
+	rendered := message.RenderedContent()
+	if rendered.IsErr() {
+		return common.Err[MessageDeliveryInfo](rendered.Error())
+	}
+
+	// Emails are written in the recipient's language with dates in their timezone
+	locale := recipient.User().Locale().OrDefault()
+	location := recipient.User().Location()
+
 	// Create delivery info
 	deliveryInfo := MessageDeliveryInfo{
 		Message:        message,
 		RecipientEmail: recipientEmail,
 		DeliveryMethod: message.DeliveryMethod(),
-		Subject:        generateEmailSubject(message),
-		Body:           generateEmailBody(message, recipient.User()),
+		Subject:        generateEmailSubject(message, locale),
+		Body:           generateEmailBody(message, recipient.User(), rendered.Value().Text, locale, location, message.DeliveryDate()),
+		HTMLContent:    rendered.Value().HTML,
+		TextContent:    rendered.Value().Text,
+		Locale:         locale,
+		Location:       location,
 		ScheduledTime:  message.DeliveryDate(),
 		ProcessedAt:    time.Now(),
 	}
@@ -67,6 +143,19 @@ func ProcessMessageDelivery(message Message, recipient user.UserProfile) common.
 	return common.Ok(deliveryInfo)
 }
 
+// BuildPreviewInfo builds the delivery info of a message as shown to its
+// author before delivery. The drawn time of a surprise message is left out,
+// so the preview does not spoil it.
+func BuildPreviewInfo(message Message, recipient user.UserProfile) common.Result[MessageDeliveryInfo] {
+	return common.Map(BuildDeliveryInfo(message, recipient), func(info MessageDeliveryInfo) MessageDeliveryInfo {
+		if message.IsDeliveryDateHidden() {
+			info.ScheduledTime = time.Time{}
+			info.Body = generateEmailBody(message, recipient.User(), info.TextContent, info.Locale, info.Location, time.Time{})
+		}
+		return info
+	})
+}
+
 // MessageDeliveryInfo contains information needed for message delivery
 type MessageDeliveryInfo struct {
 	Message        Message
@@ -74,7 +163,14 @@ type MessageDeliveryInfo struct {
 	DeliveryMethod DeliveryMethod
 	Subject        string
 	Body           string
-	ScheduledTime  time.Time
+	HTMLContent    string                // Sanitized HTML rendering of the message content
+	TextContent    string                // Plain-text rendering of the message content
+	Thread         []ThreadEntry         // Earlier letters of the thread, direct parent first
+	Attachments    []DeliveredAttachment // Files sent with the letter, see PlanAttachmentDelivery
+	ReceiptToken   string                // Opaque token for the open and read links, empty when not tracked
+	Locale         i18n.Locale           // Language the email is written in
+	Location       *time.Location        // Timezone dates are shown in
+	ScheduledTime  time.Time             // Zero in previews of surprise messages
 	ProcessedAt    time.Time
 }
 
@@ -99,6 +195,22 @@ func (mdi MessageDeliveryInfo) GetBody() string {
 	return mdi.Body
 }
 
+func (mdi MessageDeliveryInfo) GetHTMLContent() string {
+	return mdi.HTMLContent
+}
+
+func (mdi MessageDeliveryInfo) GetTextContent() string {
+	return mdi.TextContent
+}
+
+func (mdi MessageDeliveryInfo) GetThread() []ThreadEntry {
+	return mdi.Thread
+}
+
+func (mdi MessageDeliveryInfo) GetReceiptToken() string {
+	return mdi.ReceiptToken
+}
+
 func (mdi MessageDeliveryInfo) GetScheduledTime() time.Time {
 	return mdi.ScheduledTime
 }
@@ -233,14 +345,15 @@ type MessageStats struct {
 	DeliveryRate float64
 }
 
-// GetUpcomingMessages returns messages that will be delivered within the specified duration
-func GetUpcomingMessages(messages []Message, within time.Duration) []Message {
-	cutoff := time.Now().Add(within)
+// GetUpcomingMessages returns messages that will be delivered within the
+// specified duration after now
+func GetUpcomingMessages(messages []Message, now time.Time, within time.Duration) []Message {
+	cutoff := now.Add(within)
 
 	return common.FilterSlice(messages, func(m Message) bool {
 		return m.Status() == StatusScheduled &&
 			m.DeliveryDate().Before(cutoff) &&
-			m.DeliveryDate().After(time.Now())
+			m.DeliveryDate().After(now)
 	})
 }
 
@@ -256,37 +369,40 @@ func GetOverdueMessages(messages []Message) []Message {
 // Pure helper functions for email generation
 
 // generateEmailSubject creates an email subject for the message
-func generateEmailSubject(message Message) string {
+func generateEmailSubject(message Message, locale i18n.Locale) string {
 	if message.Title() != "" {
-		return "Message from your past: " + message.Title()
+		return locale.T("letter.subject", message.Title())
 	}
-	return "You have a message from your past self"
+	return locale.T("letter.subject_untitled")
 }
 
-// generateEmailBody creates an email body for the message
-func generateEmailBody(message Message, sender user.User) string {
-	body := "Hello " + sender.GetDisplayName() + ",\n\n"
-	body += "You scheduled this message to be delivered to your future self.\n\n"
+// generateEmailBody creates an email body for the message, with dates in
+// the recipient's timezone
+func generateEmailBody(message Message, sender user.User, content string, locale i18n.Locale, location *time.Location, scheduled time.Time) string {
+	body := locale.T("email.greeting_named", sender.GetDisplayName()) + "\n\n"
+	body += locale.T("letter.intro") + "\n\n"
 
 	if message.Title() != "" {
-		body += "Subject: " + message.Title() + "\n\n"
+		body += locale.T("letter.subject_line", message.Title()) + "\n\n"
 	}
 
-	body += "Message:\n"
-	body += message.Content() + "\n\n"
+	body += locale.T("letter.message") + "\n"
+	body += content + "\n\n"
 
 	// Format the original send date
-	originalDate := message.CreatedAt().Format("January 2, 2006 at 3:04 PM")
-	body += "Originally written on: " + originalDate + "\n"
+	originalDate := locale.FormatDateTime(message.CreatedAt(), location)
+	body += locale.T("letter.written_on", originalDate) + "\n"
 
-	// Format the delivery date
-	deliveryDate := message.DeliveryDate().Format("January 2, 2006 at 3:04 PM")
-	body += "Scheduled for delivery on: " + deliveryDate + "\n\n"
+	// Format the delivery date, unless it is kept from the reader
+	if !scheduled.IsZero() {
+		body += locale.T("letter.scheduled_on", locale.FormatDateTime(scheduled, location)) + "\n"
+	}
+	body += "\n"
 
-	body += "Best regards,\n"
-	body += "Your Past Self\n\n"
+	body += locale.T("letter.signoff") + "\n"
+	body += locale.T("letter.signature") + "\n\n"
 	body += "---\n"
-	body += "This message was delivered by Dear Future - Your Message to Tomorrow"
+	body += locale.T("letter.delivered_by")
 
 	return body
 }
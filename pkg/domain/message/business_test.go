@@ -0,0 +1,72 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+func newRecipient(t *testing.T, prefs user.DeliveryPreferences) user.UserProfile {
+	t.Helper()
+
+	u := user.NewUser(user.CreateUserRequest{
+		UserID:   uuid.New(),
+		Email:    "future@example.com",
+		Name:     "Future Me",
+		Timezone: "UTC",
+	})
+	if u.IsErr() {
+		t.Fatalf("NewUser() error = %v", u.Error())
+	}
+	return user.NewUserProfile(u.Value()).WithDeliveryPreferences(prefs)
+}
+
+func TestApplyDeliveryPreferences(t *testing.T) {
+	quiet := user.NewDeliveryPreferences([]user.ScheduleRule{{Start: 22 * 60, End: 7 * 60}}, nil)
+	if quiet.IsErr() {
+		t.Fatalf("NewDeliveryPreferences() error = %v", quiet.Error())
+	}
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	intended := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 23, 30, 0, 0, time.UTC)
+	msgResult := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Goodnight",
+		Content:        "Sleep well.",
+		DeliveryDate:   intended,
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+	})
+	if msgResult.IsErr() {
+		t.Fatalf("NewMessage() error = %v", msgResult.Error())
+	}
+
+	shifted := ApplyDeliveryPreferences(msgResult.Value(), newRecipient(t, quiet.Value()))
+	if shifted.IsErr() {
+		t.Fatalf("ApplyDeliveryPreferences() error = %v", shifted.Error())
+	}
+
+	want := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day()+1, 7, 0, 0, 0, time.UTC)
+	if !shifted.Value().DeliveryDate().Equal(want) {
+		t.Errorf("DeliveryDate() = %v, want %v", shifted.Value().DeliveryDate(), want)
+	}
+	if got := shifted.Value().IntendedDeliveryDate(); got.IsNone() || !got.Value().Equal(intended) {
+		t.Errorf("IntendedDeliveryDate() = %v, want %v", got, intended)
+	}
+
+	// Lifting the quiet hours moves the delivery back to the intended time
+	restored := ApplyDeliveryPreferences(shifted.Value(), newRecipient(t, user.DefaultDeliveryPreferences()))
+	if restored.IsErr() {
+		t.Fatalf("ApplyDeliveryPreferences() error = %v", restored.Error())
+	}
+	if !restored.Value().DeliveryDate().Equal(intended) || restored.Value().IntendedDeliveryDate().IsSome() {
+		t.Errorf("expected delivery back at %v without an intended date, got %v", intended, restored.Value().DeliveryDate())
+	}
+
+	// Deliveries that come due in quiet hours are deferred
+	if deferred := DeferDelivery(msgResult.Value(), newRecipient(t, quiet.Value()), intended); deferred.IsNone() {
+		t.Error("DeferDelivery() should defer a delivery in quiet hours")
+	}
+}
@@ -0,0 +1,219 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// Check-in limits
+const (
+	MinCheckInInterval   = 24 * time.Hour
+	MaxCheckInInterval   = 365 * 24 * time.Hour
+	MinCheckInGrace      = 24 * time.Hour
+	MaxCheckInGrace      = 90 * 24 * time.Hour
+	MaxCheckInRecipients = 10
+)
+
+// CheckInAction is what the evaluator should do with an armed message
+type CheckInAction string
+
+const (
+	CheckInNone    CheckInAction = "none"
+	CheckInWarn    CheckInAction = "warn"    // A check-in was missed; warn the author
+	CheckInRelease CheckInAction = "release" // The grace period passed; deliver the message
+)
+
+// CheckInSettings configures a check-in message. The message stays armed
+// while the author checks in at least once per interval. When a check-in is
+// missed the author is warned, and if the grace period then passes without a
+// check-in the message is released to its recipients.
+type CheckInSettings struct {
+	interval      time.Duration
+	gracePeriod   time.Duration
+	recipients    []string
+	lastCheckInAt time.Time
+	warnedAt      common.Option[time.Time]
+}
+
+// StoredCheckInSettings represents persisted check-in data
+type StoredCheckInSettings struct {
+	Interval      time.Duration
+	GracePeriod   time.Duration
+	Recipients    []string
+	LastCheckInAt time.Time
+	WarnedAt      common.Option[time.Time]
+}
+
+// NewCheckInSettings validates check-in settings. Without recipients the
+// message is released to its author.
+func NewCheckInSettings(interval, gracePeriod time.Duration, recipients []string) common.Result[CheckInSettings] {
+	if interval < MinCheckInInterval || interval > MaxCheckInInterval {
+		return common.Err[CheckInSettings](fmt.Errorf("check-in interval must be between %d and %d days", MinCheckInInterval/(24*time.Hour), MaxCheckInInterval/(24*time.Hour)))
+	}
+	if gracePeriod < MinCheckInGrace || gracePeriod > MaxCheckInGrace {
+		return common.Err[CheckInSettings](fmt.Errorf("check-in grace period must be between %d and %d days", MinCheckInGrace/(24*time.Hour), MaxCheckInGrace/(24*time.Hour)))
+	}
+
+	validRecipients := normalizeCheckInRecipients(recipients)
+	if validRecipients.IsErr() {
+		return common.Err[CheckInSettings](validRecipients.Error())
+	}
+
+	return common.Ok(CheckInSettings{
+		interval:    interval,
+		gracePeriod: gracePeriod,
+		recipients:  validRecipients.Value(),
+		warnedAt:    common.None[time.Time](),
+	})
+}
+
+// RestoreCheckInSettings rebuilds check-in settings from stored data
+func RestoreCheckInSettings(data StoredCheckInSettings) common.Result[CheckInSettings] {
+	settings := NewCheckInSettings(data.Interval, data.GracePeriod, data.Recipients)
+	if settings.IsErr() {
+		return settings
+	}
+
+	restored := settings.Value()
+	restored.lastCheckInAt = data.LastCheckInAt
+	restored.warnedAt = data.WarnedAt
+	return common.Ok(restored)
+}
+
+func normalizeCheckInRecipients(recipients []string) common.Result[[]string] {
+	normalized := make([]string, 0, len(recipients))
+	seen := make(map[string]bool, len(recipients))
+	for _, recipient := range recipients {
+		address, err := mail.ParseAddress(strings.TrimSpace(recipient))
+		if err != nil {
+			return common.Err[[]string](fmt.Errorf("invalid check-in recipient %q", recipient))
+		}
+
+		email := strings.ToLower(address.Address)
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		normalized = append(normalized, email)
+	}
+
+	if len(normalized) > MaxCheckInRecipients {
+		return common.Err[[]string](fmt.Errorf("a check-in message can have at most %d recipients", MaxCheckInRecipients))
+	}
+	return common.Ok(normalized)
+}
+
+func (s CheckInSettings) Interval() time.Duration {
+	return s.interval
+}
+
+func (s CheckInSettings) GracePeriod() time.Duration {
+	return s.gracePeriod
+}
+
+func (s CheckInSettings) Recipients() []string {
+	return s.recipients
+}
+
+func (s CheckInSettings) LastCheckInAt() time.Time {
+	return s.lastCheckInAt
+}
+
+func (s CheckInSettings) WarnedAt() common.Option[time.Time] {
+	return s.warnedAt
+}
+
+// WarnAt returns when the author is warned if they have not checked in
+func (s CheckInSettings) WarnAt() time.Time {
+	return s.lastCheckInAt.Add(s.interval)
+}
+
+// ReleaseAt returns when the message is released if the author does not
+// check in. The grace period always counts from the warning, so a late
+// evaluator run never shortens it.
+func (s CheckInSettings) ReleaseAt() time.Time {
+	if s.warnedAt.IsSome() {
+		return s.warnedAt.Value().Add(s.gracePeriod)
+	}
+	return s.WarnAt().Add(s.gracePeriod)
+}
+
+// checkedIn returns the settings with the timer restarted at now
+func (s CheckInSettings) checkedIn(now time.Time) CheckInSettings {
+	s.lastCheckInAt = now
+	s.warnedAt = common.None[time.Time]()
+	return s
+}
+
+// CheckIn returns the check-in settings of a check-in message
+func (m Message) CheckIn() common.Option[CheckInSettings] {
+	return m.checkIn
+}
+
+// IsArmed returns true if the message is waiting on its author's check-ins
+func (m Message) IsArmed() bool {
+	return m.status == StatusArmed && m.checkIn.IsSome()
+}
+
+// EvaluateCheckIn decides what should happen to an armed message at now
+func (m Message) EvaluateCheckIn(now time.Time) CheckInAction {
+	if !m.IsArmed() {
+		return CheckInNone
+	}
+
+	settings := m.checkIn.Value()
+	switch {
+	case now.Before(settings.WarnAt()):
+		return CheckInNone
+	case settings.warnedAt.IsNone():
+		return CheckInWarn
+	case !now.Before(settings.ReleaseAt()):
+		return CheckInRelease
+	default:
+		return CheckInNone
+	}
+}
+
+// WithCheckedIn returns a new Message with the check-in timer restarted at now
+func (m Message) WithCheckedIn(now time.Time) common.Result[Message] {
+	if !m.IsArmed() {
+		return common.Err[Message](errors.New("message is not waiting for check-ins"))
+	}
+	return common.Ok(m.withCheckInSettings(m.checkIn.Value().checkedIn(now), now))
+}
+
+// WithCheckInWarned returns a new Message recording that the author was
+// warned at now; the grace period starts from the warning
+func (m Message) WithCheckInWarned(now time.Time) common.Result[Message] {
+	if !m.IsArmed() {
+		return common.Err[Message](errors.New("message is not waiting for check-ins"))
+	}
+
+	settings := m.checkIn.Value()
+	settings.warnedAt = common.Some(now)
+	return common.Ok(m.withCheckInSettings(settings, now))
+}
+
+// WithCheckInSettings returns a new Message with replaced check-in settings.
+// Changing the settings counts as a check-in.
+func (m Message) WithCheckInSettings(settings CheckInSettings) common.Result[Message] {
+	if !m.IsArmed() {
+		return common.Err[Message](errors.New("message is not waiting for check-ins"))
+	}
+
+	now := time.Now()
+	return common.Ok(m.withCheckInSettings(settings.checkedIn(now), now))
+}
+
+func (m Message) withCheckInSettings(settings CheckInSettings, now time.Time) Message {
+	updated := m
+	updated.checkIn = common.Some(settings)
+	updated.deliveryDate = settings.ReleaseAt()
+	updated.updatedAt = now
+	return updated
+}
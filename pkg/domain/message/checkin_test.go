@@ -0,0 +1,124 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+func newArmedMessage(t *testing.T) Message {
+	t.Helper()
+
+	settings := NewCheckInSettings(7*24*time.Hour, 2*24*time.Hour, []string{"Friend@Example.com"})
+	if settings.IsErr() {
+		t.Fatalf("NewCheckInSettings() error = %v", settings.Error())
+	}
+
+	msgResult := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "If I go quiet",
+		Content:        "Please read this.",
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+		CheckIn:        common.Some(settings.Value()),
+	})
+	if msgResult.IsErr() {
+		t.Fatalf("NewMessage() error = %v", msgResult.Error())
+	}
+	return msgResult.Value()
+}
+
+func TestNewMessageWithCheckInIsArmed(t *testing.T) {
+	msg := newArmedMessage(t)
+
+	if msg.Status() != StatusArmed {
+		t.Fatalf("Status() = %s, want %s", msg.Status(), StatusArmed)
+	}
+
+	settings := msg.CheckIn().Value()
+	if got := settings.Recipients(); len(got) != 1 || got[0] != "friend@example.com" {
+		t.Errorf("Recipients() = %v, want [friend@example.com]", got)
+	}
+	if !msg.DeliveryDate().Equal(settings.ReleaseAt()) {
+		t.Errorf("DeliveryDate() = %v, want release time %v", msg.DeliveryDate(), settings.ReleaseAt())
+	}
+}
+
+func TestEvaluateCheckIn(t *testing.T) {
+	msg := newArmedMessage(t)
+	settings := msg.CheckIn().Value()
+
+	if action := msg.EvaluateCheckIn(settings.WarnAt().Add(-time.Minute)); action != CheckInNone {
+		t.Errorf("before the interval: action = %s, want %s", action, CheckInNone)
+	}
+
+	// A late evaluation must warn before releasing, even past the release time
+	late := settings.ReleaseAt().Add(time.Hour)
+	if action := msg.EvaluateCheckIn(late); action != CheckInWarn {
+		t.Fatalf("missed check-in: action = %s, want %s", action, CheckInWarn)
+	}
+
+	warned := msg.WithCheckInWarned(late)
+	if warned.IsErr() {
+		t.Fatalf("WithCheckInWarned() error = %v", warned.Error())
+	}
+	if !warned.Value().DeliveryDate().Equal(late.Add(settings.GracePeriod())) {
+		t.Errorf("grace period should start at the warning, release = %v", warned.Value().DeliveryDate())
+	}
+	if action := warned.Value().EvaluateCheckIn(late.Add(time.Hour)); action != CheckInNone {
+		t.Errorf("during grace period: action = %s, want %s", action, CheckInNone)
+	}
+	if action := warned.Value().EvaluateCheckIn(late.Add(settings.GracePeriod())); action != CheckInRelease {
+		t.Errorf("after grace period: action = %s, want %s", action, CheckInRelease)
+	}
+
+	// Checking in clears the warning and restarts the interval
+	checkedIn := warned.Value().WithCheckedIn(late.Add(time.Hour))
+	if checkedIn.IsErr() {
+		t.Fatalf("WithCheckedIn() error = %v", checkedIn.Error())
+	}
+	if checkedIn.Value().CheckIn().Value().WarnedAt().IsSome() {
+		t.Error("WithCheckedIn() should clear the warning")
+	}
+	if action := checkedIn.Value().EvaluateCheckIn(late.Add(settings.GracePeriod())); action != CheckInNone {
+		t.Errorf("after check-in: action = %s, want %s", action, CheckInNone)
+	}
+}
+
+func TestCheckInMessageRestrictions(t *testing.T) {
+	msg := newArmedMessage(t)
+
+	if msg.WithDeliveryDate(time.Now().Add(time.Hour), "UTC").IsOk() {
+		t.Error("WithDeliveryDate() should be rejected for check-in messages")
+	}
+	if msg.WithRecurrence(RecurrenceMonthly).IsOk() {
+		t.Error("WithRecurrence() should be rejected for check-in messages")
+	}
+	if msg.WithStatus(StatusCancelled).IsErr() {
+		t.Error("armed messages should be cancellable")
+	}
+}
+
+func TestNewCheckInSettingsValidation(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name       string
+		interval   time.Duration
+		grace      time.Duration
+		recipients []string
+	}{
+		{name: "interval too short", interval: time.Hour, grace: day},
+		{name: "grace too long", interval: day, grace: MaxCheckInGrace + day},
+		{name: "invalid recipient", interval: day, grace: day, recipients: []string{"not an email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if NewCheckInSettings(tt.interval, tt.grace, tt.recipients).IsOk() {
+				t.Error("NewCheckInSettings() expected error")
+			}
+		})
+	}
+}
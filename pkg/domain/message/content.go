@@ -0,0 +1,198 @@
+// Package message contains content formatting and rendering for message domain
+package message
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// ContentFormat represents how the message content should be interpreted
+type ContentFormat string
+
+const (
+	FormatPlain    ContentFormat = "plain"
+	FormatMarkdown ContentFormat = "markdown"
+)
+
+// RenderedContent holds the deliverable forms of a message's content
+type RenderedContent struct {
+	HTML string // Sanitized HTML fragment, safe to embed in an email body
+	Text string // Plain-text rendering for text/plain parts
+}
+
+// markdownRenderer renders CommonMark. Raw HTML in the source is omitted and
+// dangerous link destinations are dropped because WithUnsafe is never set.
+var markdownRenderer = goldmark.New(
+	goldmark.WithRendererOptions(
+		goldmarkhtml.WithHardWraps(),
+	),
+)
+
+var (
+	paragraphSplit = regexp.MustCompile(`\n\s*\n`)
+	excessNewlines = regexp.MustCompile(`\n{3,}`)
+)
+
+// validateContentFormat validates the content format
+func validateContentFormat(format ContentFormat) common.Result[ContentFormat] {
+	switch format {
+	case FormatPlain, FormatMarkdown:
+		return common.Ok(format)
+	case "":
+		return common.Ok(FormatPlain) // Default to plain text
+	default:
+		return common.Err[ContentFormat](errors.New("invalid content format (must be 'plain' or 'markdown')"))
+	}
+}
+
+// RenderContent renders message content into sanitized HTML and plain text
+func RenderContent(content string, format ContentFormat) common.Result[RenderedContent] {
+	validFormat := validateContentFormat(format)
+	if validFormat.IsErr() {
+		return common.Err[RenderedContent](validFormat.Error())
+	}
+
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+
+	if validFormat.Value() == FormatMarkdown {
+		return renderMarkdown(normalized)
+	}
+
+	return common.Ok(renderPlain(normalized))
+}
+
+// RenderedContent renders the message content according to its format
+func (m Message) RenderedContent() common.Result[RenderedContent] {
+	return RenderContent(m.content, m.contentFormat)
+}
+
+// renderPlain escapes plain text and keeps its paragraph and line structure
+func renderPlain(content string) RenderedContent {
+	var b strings.Builder
+	for _, paragraph := range paragraphSplit.Split(strings.TrimSpace(content), -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.Join(lines, "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+
+	return RenderedContent{
+		HTML: b.String(),
+		Text: strings.TrimSpace(content),
+	}
+}
+
+// renderMarkdown renders CommonMark content to sanitized HTML and plain text
+func renderMarkdown(content string) common.Result[RenderedContent] {
+	source := []byte(content)
+	document := markdownRenderer.Parser().Parse(text.NewReader(source))
+
+	var htmlOut bytes.Buffer
+	if err := markdownRenderer.Renderer().Render(&htmlOut, source, document); err != nil {
+		return common.Err[RenderedContent](errors.New("failed to render markdown content"))
+	}
+
+	return common.Ok(RenderedContent{
+		HTML: htmlOut.String(),
+		Text: markdownToText(document, source),
+	})
+}
+
+// markdownToText flattens a parsed markdown document into readable plain text
+func markdownToText(document ast.Node, source []byte) string {
+	var b strings.Builder
+
+	ast.Walk(document, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch n := node.(type) {
+		case *ast.Paragraph, *ast.Heading, *ast.Blockquote, *ast.List:
+			if !entering {
+				b.WriteString("\n\n")
+			}
+		case *ast.ListItem:
+			if entering {
+				list := n.Parent().(*ast.List)
+				if list.IsOrdered() {
+					b.WriteString(itemNumber(list, n))
+					b.WriteString(". ")
+				} else {
+					b.WriteString("- ")
+				}
+			} else if !strings.HasSuffix(b.String(), "\n") {
+				b.WriteString("\n")
+			}
+		case *ast.TextBlock:
+			if !entering {
+				b.WriteString("\n")
+			}
+		case *ast.ThematicBreak:
+			if entering {
+				b.WriteString("----\n\n")
+			}
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			if entering {
+				lines := n.Lines()
+				for i := 0; i < lines.Len(); i++ {
+					segment := lines.At(i)
+					b.Write(segment.Value(source))
+				}
+				b.WriteString("\n")
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.HTMLBlock, *ast.RawHTML:
+			// Raw HTML is omitted from the HTML part, so keep the parts consistent
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			if entering {
+				b.Write(n.Segment.Value(source))
+				if n.SoftLineBreak() || n.HardLineBreak() {
+					b.WriteString("\n")
+				}
+			}
+		case *ast.String:
+			if entering {
+				b.Write(n.Value)
+			}
+		case *ast.AutoLink:
+			if entering {
+				b.Write(n.URL(source))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Link:
+			if !entering {
+				b.WriteString(" (")
+				b.Write(n.Destination)
+				b.WriteString(")")
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	result := excessNewlines.ReplaceAllString(b.String(), "\n\n")
+	return strings.TrimSpace(result)
+}
+
+// itemNumber returns the display number of an ordered list item
+func itemNumber(list *ast.List, item ast.Node) string {
+	number := list.Start
+	for sibling := list.FirstChild(); sibling != nil && sibling != item; sibling = sibling.NextSibling() {
+		number++
+	}
+	return strconv.Itoa(number)
+}
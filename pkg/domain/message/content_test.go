@@ -0,0 +1,92 @@
+package message
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		format      ContentFormat
+		expectError bool
+		htmlHas     []string
+		htmlLacks   []string
+		text        string
+	}{
+		{
+			name:      "plain text is escaped",
+			content:   "Hi <b>me</b> & you\nsecond line",
+			format:    FormatPlain,
+			htmlHas:   []string{"&lt;b&gt;me&lt;/b&gt; &amp; you<br>"},
+			htmlLacks: []string{"<b>"},
+			text:      "Hi <b>me</b> & you\nsecond line",
+		},
+		{
+			name:    "empty format defaults to plain",
+			content: "hello",
+			format:  "",
+			htmlHas: []string{"<p>hello</p>"},
+			text:    "hello",
+		},
+		{
+			name:    "markdown renders emphasis and lists",
+			content: "# Dear me\n\nStay **kind**.\n\n- one\n- two",
+			format:  FormatMarkdown,
+			htmlHas: []string{"<h1>Dear me</h1>", "<strong>kind</strong>", "<li>one</li>"},
+			text:    "Dear me\n\nStay kind.\n\n- one\n- two",
+		},
+		{
+			name:      "markdown drops raw html",
+			content:   "hello <script>alert(1)</script>",
+			format:    FormatMarkdown,
+			htmlLacks: []string{"<script>"},
+			text:      "hello alert(1)",
+		},
+		{
+			name:      "markdown drops javascript links",
+			content:   "[click](javascript:alert(1))",
+			format:    FormatMarkdown,
+			htmlLacks: []string{"javascript:"},
+		},
+		{
+			name:        "invalid format",
+			content:     "hello",
+			format:      "html",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RenderContent(tt.content, tt.format)
+
+			if tt.expectError {
+				if result.IsOk() {
+					t.Fatalf("expected error but got none")
+				}
+				return
+			}
+
+			if result.IsErr() {
+				t.Fatalf("expected no error but got: %v", result.Error())
+			}
+
+			rendered := result.Value()
+			for _, want := range tt.htmlHas {
+				if !strings.Contains(rendered.HTML, want) {
+					t.Errorf("expected HTML to contain %q, got %q", want, rendered.HTML)
+				}
+			}
+			for _, unwanted := range tt.htmlLacks {
+				if strings.Contains(rendered.HTML, unwanted) {
+					t.Errorf("expected HTML not to contain %q, got %q", unwanted, rendered.HTML)
+				}
+			}
+			if tt.text != "" && rendered.Text != tt.text {
+				t.Errorf("expected text %q, got %q", tt.text, rendered.Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+// Package message contains natural-language delivery dates for message domain
+package message
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// MaxDateExpressionLength bounds the length of a date expression
+const MaxDateExpressionLength = 200
+
+// ResolvedDate is a delivery date expression resolved to an instant
+type ResolvedDate struct {
+	At          time.Time                      // Delivery time in UTC
+	Description string                         // Normalized form of the expression, such as "next New Year's Eve at 21:00"
+	Anchor      common.Option[MilestoneAnchor] // Set when the date is counted from a milestone
+}
+
+// namedDay is a yearly date that can be named in an expression
+type namedDay struct {
+	name  string
+	month time.Month
+	day   int
+}
+
+var namedDays = map[string]namedDay{
+	"new year":        {"New Year's Day", time.January, 1},
+	"new year's":      {"New Year's Day", time.January, 1},
+	"new year's day":  {"New Year's Day", time.January, 1},
+	"new years day":   {"New Year's Day", time.January, 1},
+	"valentine's day": {"Valentine's Day", time.February, 14},
+	"valentines day":  {"Valentine's Day", time.February, 14},
+	"halloween":       {"Halloween", time.October, 31},
+	"christmas eve":   {"Christmas Eve", time.December, 24},
+	"christmas":       {"Christmas Day", time.December, 25},
+	"christmas day":   {"Christmas Day", time.December, 25},
+	"new year's eve":  {"New Year's Eve", time.December, 31},
+	"new years eve":   {"New Year's Eve", time.December, 31},
+}
+
+var numberWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+const unitPattern = `(minute|hour|day|week|month|year|decade)s?`
+
+var (
+	inRegex        = regexp.MustCompile(`^in (\S+) ` + unitPattern + `$`)
+	fromNowRegex   = regexp.MustCompile(`^(\S+) ` + unitPattern + ` from now$`)
+	relativeRegex  = regexp.MustCompile(`^(\S+) ` + unitPattern + ` (after|before) (.+)$`)
+	isoDateRegex   = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	clockRegex     = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))? ?(am|pm)?$`)
+	milestoneRegex = regexp.MustCompile(`^(?:(?:my|our) )?(?:(next|\d+(?:st|nd|rd|th)) )?(.+?)(?: anniversary)?$`)
+)
+
+// offset is a calendar distance from a base date
+type offset struct {
+	months  int
+	days    int
+	exact   time.Duration // Hours and minutes, which keep the clock time
+	phrase  string
+	forward bool
+}
+
+// ResolveDateExpression resolves a delivery date such as "in 5 years",
+// "next new year's eve at 9pm" or "3 months after my birthday" against the
+// current time in the user's timezone. Milestones are the user's profile
+// milestones; dates counted from one of them carry an anchor so the letter
+// can follow the milestone if it is corrected. Named days without a time
+// are delivered at DefaultAnchorTime.
+func ResolveDateExpression(expression string, now time.Time, timezone string, milestones []user.Milestone) common.Result[ResolvedDate] {
+	if len(expression) > MaxDateExpressionLength {
+		return common.Err[ResolvedDate](fmt.Errorf("date expression is too long (max %d characters)", MaxDateExpressionLength))
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return common.Err[ResolvedDate](errors.New("invalid timezone: " + timezone))
+	}
+	now = now.In(loc).Truncate(time.Minute)
+
+	text := normalizeDateExpression(expression)
+	if text == "" {
+		return common.Err[ResolvedDate](errors.New("date expression cannot be empty"))
+	}
+
+	// A trailing "at <time>" sets the clock time of the day found before it
+	base, clock := text, common.None[user.TimeOfDay]()
+	if i := strings.LastIndex(" "+text, " at "); i >= 0 {
+		parsed := parseClock(text[i+3:])
+		if parsed.IsErr() {
+			return common.Err[ResolvedDate](parsed.Error())
+		}
+		base, clock = strings.TrimSpace(text[:i]), common.Some(parsed.Value())
+	}
+
+	resolved := resolveBase(strings.TrimPrefix(base, "on "), now, clock, milestones)
+	if resolved.IsErr() {
+		return resolved
+	}
+
+	if result := validateDeliveryDate(resolved.Value().At, timezone, false); result.IsErr() {
+		return common.Err[ResolvedDate](fmt.Errorf("%q is not a valid delivery date: %w", expression, result.Error()))
+	}
+	return resolved
+}
+
+func normalizeDateExpression(expression string) string {
+	text := strings.ToLower(strings.ReplaceAll(expression, "’", "'"))
+	text = strings.Trim(strings.Join(strings.Fields(text), " "), " .!,")
+	return text
+}
+
+func resolveBase(base string, now time.Time, clock common.Option[user.TimeOfDay], milestones []user.Milestone) common.Result[ResolvedDate] {
+	loc := now.Location()
+	at := clock.ValueOr(DefaultAnchorTime)
+
+	switch {
+	case base == "":
+		// Only a time: its next occurrence
+		day := atClock(now, at)
+		if !day.After(now) {
+			day = atClock(now.AddDate(0, 0, 1), at)
+		}
+		return resolvedAt(day, "at "+at.String())
+
+	case base == "today", base == "tonight":
+		if base == "tonight" && clock.IsNone() {
+			at = user.TimeOfDay(20 * 60)
+		}
+		return resolvedAt(atClock(now, at), base+" at "+at.String())
+
+	case base == "tomorrow":
+		return resolvedAt(atClock(now.AddDate(0, 0, 1), at), "tomorrow at "+at.String())
+	}
+
+	if match := inRegex.FindStringSubmatch(base); match != nil {
+		return resolveDuration(match[1], match[2], now, clock)
+	}
+	if match := fromNowRegex.FindStringSubmatch(base); match != nil {
+		return resolveDuration(match[1], match[2], now, clock)
+	}
+
+	if match := isoDateRegex.FindStringSubmatch(base); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		dayOfMonth, _ := strconv.Atoi(match[3])
+		day := time.Date(year, time.Month(month), dayOfMonth, int(at)/60, int(at)%60, 0, 0, loc)
+		if day.Month() != time.Month(month) || day.Day() != dayOfMonth {
+			return common.Err[ResolvedDate](fmt.Errorf("invalid date %q", base))
+		}
+		return resolvedAt(day, "on "+day.Format("2006-01-02")+" at "+at.String())
+	}
+
+	qualifier, name := splitQualifier(base)
+	if weekday, ok := weekdays[name]; ok {
+		days := (int(weekday) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return resolvedAt(atClock(now.AddDate(0, 0, days), at), "next "+weekday.String()+" at "+at.String())
+	}
+	if h, ok := namedDays[name]; ok {
+		if qualifier == "this" {
+			day := time.Date(now.Year(), h.month, h.day, int(at)/60, int(at)%60, 0, 0, loc)
+			return resolvedAt(day, "this "+h.name+" at "+at.String())
+		}
+		return resolveNamedDay(h, offset{forward: true}, now, at)
+	}
+
+	if match := relativeRegex.FindStringSubmatch(base); match != nil {
+		distance := parseOffset(match[1], match[2], match[3] == "after")
+		if distance.IsErr() {
+			return common.Err[ResolvedDate](distance.Error())
+		}
+		if distance.Value().exact > 0 {
+			return common.Err[ResolvedDate](errors.New("named days can only be moved by days, weeks, months or years"))
+		}
+
+		_, target := splitQualifier(match[4])
+		if h, ok := namedDays[target]; ok {
+			return resolveNamedDay(h, distance.Value(), now, at)
+		}
+		return resolveMilestone(match[4], distance.Value(), now, at, milestones)
+	}
+
+	return resolveMilestone(base, offset{forward: true}, now, at, milestones)
+}
+
+// resolveDuration resolves "in 5 years" style expressions. Days and longer
+// keep the current clock time unless a time is given.
+func resolveDuration(count, unit string, now time.Time, clock common.Option[user.TimeOfDay]) common.Result[ResolvedDate] {
+	distance := parseOffset(count, unit, true)
+	if distance.IsErr() {
+		return common.Err[ResolvedDate](distance.Error())
+	}
+
+	d := distance.Value()
+	if d.exact > 0 {
+		if clock.IsSome() {
+			return common.Err[ResolvedDate](errors.New("a time of day cannot be combined with hours or minutes"))
+		}
+		return resolvedAt(now.Add(d.exact), "in "+d.phrase)
+	}
+
+	day := now.AddDate(0, d.months, d.days)
+	if clock.IsSome() {
+		return resolvedAt(atClock(day, clock.Value()), "in "+d.phrase+" at "+clock.Value().String())
+	}
+	return resolvedAt(day, "in "+d.phrase)
+}
+
+// resolveNamedDay resolves the next occurrence of a named day, moved by the
+// offset, that is still ahead
+func resolveNamedDay(h namedDay, distance offset, now time.Time, at user.TimeOfDay) common.Result[ResolvedDate] {
+	months, days := distance.signed()
+	description := "next " + h.name
+	if distance.phrase != "" {
+		description = distance.describe(h.name)
+	}
+
+	// The offset can move the day by years, so look that far either way
+	span := max(months, -months)/12 + max(days, -days)/365 + 1
+	for year := now.Year() - span; year <= now.Year()+span; year++ {
+		day := time.Date(year, h.month, h.day, int(at)/60, int(at)%60, 0, 0, now.Location()).AddDate(0, months, days)
+		if day.After(now) {
+			return resolvedAt(day, description+" at "+at.String())
+		}
+	}
+	return common.Err[ResolvedDate](fmt.Errorf("no upcoming %s", h.name))
+}
+
+// resolveMilestone resolves a date counted from one of the user's
+// milestones, such as "my 40th birthday" or "2 weeks before our wedding
+// anniversary". Without an ordinal the next anniversary still ahead is used.
+func resolveMilestone(reference string, distance offset, now time.Time, at user.TimeOfDay, milestones []user.Milestone) common.Result[ResolvedDate] {
+	match := milestoneRegex.FindStringSubmatch(reference)
+	if match == nil {
+		return common.Err[ResolvedDate](fmt.Errorf("could not understand %q", reference))
+	}
+
+	milestone := findMilestone(match[2], milestones)
+	if milestone.IsNone() {
+		return common.Err[ResolvedDate](fmt.Errorf("could not understand %q; milestones have to be added to your profile first", reference))
+	}
+
+	months, days := distance.signed()
+	timezone := now.Location().String()
+	anchorAt := func(occurrence int) common.Result[MilestoneAnchor] {
+		return NewMilestoneAnchor(milestone.Value(), occurrence, months, days, at)
+	}
+
+	var anchor MilestoneAnchor
+	if ordinal := match[1]; ordinal != "" && ordinal != "next" {
+		occurrence, _ := strconv.Atoi(strings.TrimRight(ordinal, "stndrh"))
+		result := anchorAt(occurrence)
+		if result.IsErr() {
+			return common.Err[ResolvedDate](result.Error())
+		}
+		anchor = result.Value()
+	} else {
+		found := false
+		// Start far enough back that a positive offset cannot skip an anniversary
+		start := now.Year() - milestone.Value().Date().Year() - 1 - max(months/12, 0) - max(days/365, 0)
+		for occurrence := max(start, 0); occurrence <= maxAnchorOccurrence; occurrence++ {
+			result := anchorAt(occurrence)
+			if result.IsErr() {
+				return common.Err[ResolvedDate](result.Error())
+			}
+			if deliveryDate := result.Value().Resolve(timezone); deliveryDate.IsOk() && deliveryDate.Value().After(now) {
+				anchor, found = result.Value(), true
+				break
+			}
+		}
+		if !found {
+			return common.Err[ResolvedDate](fmt.Errorf("no upcoming anniversary of %s", milestone.Value().Name()))
+		}
+	}
+
+	deliveryDate := anchor.Resolve(timezone)
+	if deliveryDate.IsErr() {
+		return common.Err[ResolvedDate](deliveryDate.Error())
+	}
+
+	description := "on " + describeMilestone(milestone.Value(), anchor.Occurrence())
+	if distance.phrase != "" {
+		description = distance.describe(describeMilestone(milestone.Value(), anchor.Occurrence()))
+	}
+
+	return common.Ok(ResolvedDate{
+		At:          deliveryDate.Value(),
+		Description: description + " at " + at.String(),
+		Anchor:      common.Some(anchor),
+	})
+}
+
+// findMilestone matches a milestone by key or name, ignoring a leading
+// "my" or "our" in the name
+func findMilestone(reference string, milestones []user.Milestone) common.Option[user.Milestone] {
+	for _, milestone := range milestones {
+		name := strings.ToLower(milestone.Name())
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "my "), "our ")
+		if reference == milestone.Key() || reference == name {
+			return common.Some(milestone)
+		}
+	}
+	return common.None[user.Milestone]()
+}
+
+func describeMilestone(milestone user.Milestone, occurrence int) string {
+	if milestone.Key() == user.MilestoneBirthday {
+		if occurrence == 0 {
+			return "my birthday"
+		}
+		return "my " + ordinal(occurrence) + " birthday"
+	}
+	if occurrence == 0 {
+		return milestone.Name()
+	}
+	return "the " + ordinal(occurrence) + " anniversary of " + milestone.Name()
+}
+
+// signed returns the calendar offset, negative when counting backwards
+func (d offset) signed() (int, int) {
+	if d.forward {
+		return d.months, d.days
+	}
+	return -d.months, -d.days
+}
+
+// describe phrases the offset from a named day, such as "3 days before Christmas Day"
+func (d offset) describe(target string) string {
+	if d.forward {
+		return d.phrase + " after " + target
+	}
+	return d.phrase + " before " + target
+}
+
+// parseOffset parses a count and unit such as "3 months"
+func parseOffset(count, unit string, forward bool) common.Result[offset] {
+	n, ok := numberWords[count]
+	if !ok {
+		parsed, err := strconv.Atoi(count)
+		if err != nil || parsed <= 0 || parsed > 1000 {
+			return common.Err[offset](fmt.Errorf("invalid number %q", count))
+		}
+		n = parsed
+	}
+
+	phrase := strconv.Itoa(n) + " " + unit
+	if n != 1 {
+		phrase += "s"
+	}
+
+	d := offset{phrase: phrase, forward: forward}
+	switch unit {
+	case "minute":
+		d.exact = time.Duration(n) * time.Minute
+	case "hour":
+		d.exact = time.Duration(n) * time.Hour
+	case "day":
+		d.days = n
+	case "week":
+		d.days = 7 * n
+	case "month":
+		d.months = n
+	case "year":
+		d.months = 12 * n
+	case "decade":
+		d.months = 120 * n
+	}
+	return common.Ok(d)
+}
+
+// parseClock parses a time of day such as "9pm", "9:30 am", "21:00" or "noon"
+func parseClock(value string) common.Result[user.TimeOfDay] {
+	switch value {
+	case "noon":
+		return common.Ok(user.TimeOfDay(12 * 60))
+	case "midnight":
+		return common.Ok(user.TimeOfDay(0))
+	}
+
+	match := clockRegex.FindStringSubmatch(value)
+	if match == nil {
+		return common.Err[user.TimeOfDay](fmt.Errorf("invalid time %q", value))
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	switch match[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return common.Err[user.TimeOfDay](fmt.Errorf("invalid time %q", value))
+		}
+		hour %= 12
+		if match[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return common.Err[user.TimeOfDay](fmt.Errorf("invalid time %q", value))
+	}
+	return common.Ok(user.TimeOfDay(hour*60 + minute))
+}
+
+// splitQualifier splits a leading "next" or "this" from a name
+func splitQualifier(base string) (string, string) {
+	for _, qualifier := range []string{"next", "this"} {
+		if name, ok := strings.CutPrefix(base, qualifier+" "); ok {
+			return qualifier, name
+		}
+	}
+	return "", base
+}
+
+// atClock returns the calendar day of t at a wall-clock time in t's location
+func atClock(t time.Time, at user.TimeOfDay) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), int(at)/60, int(at)%60, 0, 0, t.Location())
+}
+
+func resolvedAt(t time.Time, description string) common.Result[ResolvedDate] {
+	return common.Ok(ResolvedDate{
+		At:          t.UTC(),
+		Description: description,
+		Anchor:      common.None[MilestoneAnchor](),
+	})
+}
+
+// ordinal formats 1 as "1st", 2 as "2nd" and so on
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
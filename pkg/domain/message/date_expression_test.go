@@ -0,0 +1,106 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+func TestResolveDateExpression(t *testing.T) {
+	year := time.Now().Year() + 1
+	loc, _ := time.LoadLocation("Asia/Ho_Chi_Minh") // UTC+7
+	now := time.Date(year, time.March, 10, 10, 15, 0, 0, loc)
+
+	milestones := []user.Milestone{
+		user.NewMilestone(user.MilestoneBirthday, "", time.Date(year-30, time.June, 1, 0, 0, 0, 0, time.UTC)).Value(),
+		user.NewMilestone("wedding", "Our wedding", time.Date(year-6, time.April, 20, 0, 0, 0, 0, time.UTC)).Value(),
+	}
+
+	tests := []struct {
+		expression  string
+		want        time.Time
+		description string
+		anchored    bool
+	}{
+		{"in 5 years", time.Date(year+5, time.March, 10, 10, 15, 0, 0, loc), "in 5 years", false},
+		{"a week from now", time.Date(year, time.March, 17, 10, 15, 0, 0, loc), "in 1 week", false},
+		{"in 2 hours", time.Date(year, time.March, 10, 12, 15, 0, 0, loc), "in 2 hours", false},
+		{"Next New Year’s Eve at 9pm.", time.Date(year, time.December, 31, 21, 0, 0, 0, loc), "next New Year's Eve at 21:00", false},
+		{"tomorrow at 7:30 am", time.Date(year, time.March, 11, 7, 30, 0, 0, loc), "tomorrow at 07:30", false},
+		{"3 days before christmas", time.Date(year, time.December, 22, 9, 0, 0, 0, loc), "3 days before Christmas Day at 09:00", false},
+		{"on " + time.Date(year+2, time.May, 4, 0, 0, 0, 0, loc).Format("2006-01-02") + " at 18:45", time.Date(year+2, time.May, 4, 18, 45, 0, 0, loc), "on " + time.Date(year+2, time.May, 4, 0, 0, 0, 0, loc).Format("2006-01-02") + " at 18:45", false},
+		{"3 months after my birthday", time.Date(year, time.September, 1, 9, 0, 0, 0, loc), "3 months after my 30th birthday at 09:00", true},
+		{"on my 40th birthday at noon", time.Date(year+10, time.June, 1, 12, 0, 0, 0, loc), "on my 40th birthday at 12:00", true},
+		{"2 weeks before our wedding anniversary", time.Date(year, time.April, 6, 9, 0, 0, 0, loc), "2 weeks before the 6th anniversary of Our wedding at 09:00", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			result := ResolveDateExpression(tt.expression, now, "Asia/Ho_Chi_Minh", milestones)
+			if result.IsErr() {
+				t.Fatalf("ResolveDateExpression() error = %v", result.Error())
+			}
+
+			resolved := result.Value()
+			if !resolved.At.Equal(tt.want) || resolved.At.Location() != time.UTC {
+				t.Errorf("At = %v, want %v in UTC", resolved.At, tt.want.UTC())
+			}
+			if resolved.Description != tt.description {
+				t.Errorf("Description = %q, want %q", resolved.Description, tt.description)
+			}
+			if resolved.Anchor.IsSome() != tt.anchored {
+				t.Errorf("Anchor set = %v, want %v", resolved.Anchor.IsSome(), tt.anchored)
+			}
+		})
+	}
+
+	// The anchor resolves to the same instant, so the letter can follow a corrected milestone
+	anchored := ResolveDateExpression("3 months after my birthday", now, "Asia/Ho_Chi_Minh", milestones).Value()
+	anchor := anchored.Anchor.Value()
+	if anchor.Occurrence() != 30 || anchor.OffsetMonths() != 3 || !anchor.Resolve("Asia/Ho_Chi_Minh").Value().Equal(anchored.At) {
+		t.Errorf("unexpected anchor %+v", anchor)
+	}
+
+	invalid := []string{
+		"",
+		"someday",
+		"in 2 hours at 9pm",
+		"tomorrow at 25:00",
+		"in 0 days",
+		"a year after my graduation",
+		"on 2001-01-01",
+		"in 80 years",
+	}
+	for _, expression := range invalid {
+		if ResolveDateExpression(expression, now, "Asia/Ho_Chi_Minh", milestones).IsOk() {
+			t.Errorf("ResolveDateExpression(%q) should fail", expression)
+		}
+	}
+
+	if ResolveDateExpression("in 5 years", now, "Mars/Olympus", nil).IsOk() {
+		t.Error("invalid timezones should be rejected")
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := map[string]user.TimeOfDay{
+		"9pm":      21 * 60,
+		"12am":     0,
+		"12 pm":    12 * 60,
+		"9:05 am":  9*60 + 5,
+		"21:30":    21*60 + 30,
+		"midnight": 0,
+	}
+	for value, want := range tests {
+		if got := parseClock(value); got.IsErr() || got.Value() != want {
+			t.Errorf("parseClock(%q) = %v, want %v", value, got.Value(), want)
+		}
+	}
+
+	for _, value := range []string{"13pm", "0am", "9:60", "noonish"} {
+		if parseClock(value).IsOk() {
+			t.Errorf("parseClock(%q) should fail", value)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package message contains the weekly digest for message domain
+package message
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// DigestPeriod is how far ahead and back the weekly digest looks
+const DigestPeriod = 7 * 24 * time.Hour
+
+// WeeklyDigest summarizes a user's letters for the week
+type WeeklyDigest struct {
+	Upcoming       []Message // Letters arriving within DigestPeriod, soonest first
+	RecurringSent  []Message // Recurring letters delivered within the past DigestPeriod
+	NeedsAttention []Message // Letters whose delivery failed
+}
+
+// BuildWeeklyDigest summarizes a user's messages as of now. Recurring
+// letters go back to scheduled after each delivery, so the receipts of the
+// deliveries tell which were sent this week. Letters with a hidden delivery
+// date are not listed as upcoming, which would spoil the surprise.
+func BuildWeeklyDigest(messages []Message, receipts []DeliveryReceipt, now time.Time) WeeklyDigest {
+	upcoming := common.FilterSlice(GetUpcomingMessages(messages, now, DigestPeriod), func(m Message) bool {
+		return !m.IsDeliveryDateHidden()
+	})
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].DeliveryDate().Before(upcoming[j].DeliveryDate())
+	})
+
+	sent := make(map[uuid.UUID]bool)
+	for _, receipt := range receipts {
+		if receipt.DeliveredAt().After(now.Add(-DigestPeriod)) && !receipt.DeliveredAt().After(now) {
+			sent[receipt.MessageID()] = true
+		}
+	}
+
+	return WeeklyDigest{
+		Upcoming: upcoming,
+		RecurringSent: common.FilterSlice(messages, func(m Message) bool {
+			return m.HasRecurrence() && sent[m.ID()]
+		}),
+		NeedsAttention: common.FilterSlice(messages, func(m Message) bool {
+			return m.Status() == StatusFailed
+		}),
+	}
+}
+
+// IsEmpty returns true if the digest has nothing to report
+func (d WeeklyDigest) IsEmpty() bool {
+	return len(d.Upcoming) == 0 && len(d.RecurringSent) == 0 && len(d.NeedsAttention) == 0
+}
@@ -0,0 +1,71 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildWeeklyDigest(t *testing.T) {
+	now := time.Now()
+	userID := uuid.New()
+	newMessage := func(title string, deliveryDate time.Time, recurrence RecurrencePattern) Message {
+		msgResult := NewMessage(CreateMessageRequest{
+			UserID:         userID,
+			Title:          title,
+			Content:        "See you soon.",
+			DeliveryDate:   deliveryDate,
+			Timezone:       "UTC",
+			DeliveryMethod: DeliveryEmail,
+			Recurrence:     recurrence,
+		})
+		if msgResult.IsErr() {
+			t.Fatalf("NewMessage(%q) error = %v", title, msgResult.Error())
+		}
+		return msgResult.Value()
+	}
+
+	later := newMessage("Thursday", now.Add(3*24*time.Hour), RecurrenceNone)
+	sooner := newMessage("Tomorrow", now.Add(24*time.Hour), RecurrenceNone)
+	nextMonth := newMessage("Next month", now.Add(30*24*time.Hour), RecurrenceNone)
+	weekly := newMessage("Every week", now.Add(6*24*time.Hour), RecurrenceWeekly)
+	monthly := newMessage("Every month", now.Add(20*24*time.Hour), RecurrenceMonthly)
+	failed := newMessage("Bounced", now.Add(48*time.Hour), RecurrenceNone).WithStatus(StatusFailed).Value()
+
+	receipts := []DeliveryReceipt{
+		NewDeliveryReceipt(weekly.ID(), "me@example.com", now.Add(-24*time.Hour)).Value(),
+		NewDeliveryReceipt(weekly.ID(), "friend@example.com", now.Add(-24*time.Hour)).Value(),
+		NewDeliveryReceipt(monthly.ID(), "me@example.com", now.Add(-10*24*time.Hour)).Value(),
+	}
+
+	digest := BuildWeeklyDigest([]Message{later, nextMonth, sooner, weekly, monthly, failed}, receipts, now)
+
+	if len(digest.Upcoming) != 3 || digest.Upcoming[0].ID() != sooner.ID() || digest.Upcoming[1].ID() != later.ID() || digest.Upcoming[2].ID() != weekly.ID() {
+		t.Errorf("Upcoming = %v, want tomorrow, Thursday and the weekly letter in that order", titles(digest.Upcoming))
+	}
+	if len(digest.RecurringSent) != 1 || digest.RecurringSent[0].ID() != weekly.ID() {
+		t.Errorf("RecurringSent = %v, want only the weekly letter", titles(digest.RecurringSent))
+	}
+	if len(digest.NeedsAttention) != 1 || digest.NeedsAttention[0].ID() != failed.ID() {
+		t.Errorf("NeedsAttention = %v, want only the failed letter", titles(digest.NeedsAttention))
+	}
+
+	if !BuildWeeklyDigest([]Message{nextMonth}, nil, now).IsEmpty() {
+		t.Error("a digest without letters this week should be empty")
+	}
+
+	// The digest is built as of the run, not the wall clock
+	runAt := now.Add(25 * 24 * time.Hour)
+	if upcoming := BuildWeeklyDigest([]Message{sooner, nextMonth}, nil, runAt).Upcoming; len(upcoming) != 1 || upcoming[0].ID() != nextMonth.ID() {
+		t.Errorf("Upcoming as of %v = %v, want only next month's letter", runAt, titles(upcoming))
+	}
+}
+
+func titles(messages []Message) []string {
+	result := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, msg.Title())
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+// Package message contains holiday-targeted delivery for message domain
+package message
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/holiday"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// HolidayTarget delivers a message on a public holiday of a country, such as
+// "Christmas morning" or "the first day of Tết", at a wall-clock time in the
+// message's timezone. Yearly messages follow the holiday to its date in each
+// year, which moves for holidays such as Easter or Tết.
+type HolidayTarget struct {
+	country string
+	holiday string
+	at      user.TimeOfDay
+}
+
+// StoredHolidayTarget represents a persisted holiday target
+type StoredHolidayTarget struct {
+	Country string
+	Holiday string
+	At      user.TimeOfDay
+}
+
+// NewHolidayTarget targets the holiday with the key in a country's calendar
+func NewHolidayTarget(country, key string, at user.TimeOfDay) common.Result[HolidayTarget] {
+	return RestoreHolidayTarget(StoredHolidayTarget{Country: country, Holiday: key, At: at})
+}
+
+// RestoreHolidayTarget rebuilds a holiday target from stored data
+func RestoreHolidayTarget(data StoredHolidayTarget) common.Result[HolidayTarget] {
+	calendar := holiday.ForCountry(data.Country)
+	if calendar.IsErr() {
+		return common.Err[HolidayTarget](calendar.Error())
+	}
+	if calendar.Value().HolidayName(data.Holiday).IsNone() {
+		return common.Err[HolidayTarget](fmt.Errorf("unknown holiday %q for %s", data.Holiday, calendar.Value().Name()))
+	}
+	if data.At < 0 || data.At >= 24*60 {
+		return common.Err[HolidayTarget](errors.New("holiday time must be between 00:00 and 23:59"))
+	}
+
+	return common.Ok(HolidayTarget{
+		country: calendar.Value().Country(),
+		holiday: data.Holiday,
+		at:      data.At,
+	})
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the holiday calendar
+func (h HolidayTarget) Country() string {
+	return h.country
+}
+
+// Holiday returns the key of the holiday in the country's calendar
+func (h HolidayTarget) Holiday() string {
+	return h.holiday
+}
+
+// Name returns the name of the holiday
+func (h HolidayTarget) Name() string {
+	return holiday.ForCountry(h.country).Value().HolidayName(h.holiday).ValueOr(h.holiday)
+}
+
+// At returns the wall-clock delivery time
+func (h HolidayTarget) At() user.TimeOfDay {
+	return h.at
+}
+
+// Next returns the first delivery time on the holiday strictly after a time,
+// in the timezone
+func (h HolidayTarget) Next(after time.Time, timezone string) common.Result[time.Time] {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return common.Err[time.Time](errors.New("invalid timezone"))
+	}
+	calendar := holiday.ForCountry(h.country)
+	if calendar.IsErr() {
+		return common.Err[time.Time](calendar.Error())
+	}
+
+	from := after.In(loc)
+	// The holiday of the current day may already have passed its time, and
+	// the one after it is at most a year later
+	for i := 0; i < 2; i++ {
+		occurrence := calendar.Value().Next(h.holiday, from)
+		if occurrence.IsErr() {
+			return common.Err[time.Time](occurrence.Error())
+		}
+
+		day := occurrence.Value().Date()
+		deliveryDate := time.Date(day.Year(), day.Month(), day.Day(), int(h.at)/60, int(h.at)%60, 0, 0, loc)
+		if deliveryDate.After(after) {
+			return common.Ok(deliveryDate.UTC())
+		}
+		from = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+	}
+	return common.Err[time.Time](fmt.Errorf("no upcoming %s", h.Name()))
+}
+
+// HolidayTarget returns the holiday the message is delivered on
+func (m Message) HolidayTarget() common.Option[HolidayTarget] {
+	return m.holiday
+}
+
+// WithHolidayTarget returns a new Message delivered on the next occurrence of
+// the holiday. Only one-off and yearly messages can target a holiday.
+func (m Message) WithHolidayTarget(target HolidayTarget) common.Result[Message] {
+	if m.checkIn.IsSome() || m.surprise.IsSome() || m.anchor.IsSome() {
+		return common.Err[Message](errors.New("check-in, surprise and anchored messages cannot target a holiday"))
+	}
+	if m.recurrence != RecurrenceNone && m.recurrence != RecurrenceYearly {
+		return common.Err[Message](errors.New("only yearly messages can target a holiday"))
+	}
+
+	deliveryDate := resolveHolidayTarget(target, time.Now(), m.timezone)
+	if deliveryDate.IsErr() {
+		return common.Err[Message](deliveryDate.Error())
+	}
+
+	updated := m
+	updated.holiday = common.Some(target)
+	updated.deliveryDate = deliveryDate.Value()
+	updated.intendedDate = common.None[time.Time]()
+	updated.updatedAt = time.Now()
+	return common.Ok(updated)
+}
+
+// resolveHolidayTarget returns the validated delivery time of the next
+// occurrence of the holiday after a time
+func resolveHolidayTarget(target HolidayTarget, after time.Time, timezone string) common.Result[time.Time] {
+	deliveryDate := target.Next(after, timezone)
+	if deliveryDate.IsErr() {
+		return deliveryDate
+	}
+	if result := validateDeliveryDate(deliveryDate.Value(), timezone, false); result.IsErr() {
+		return common.Err[time.Time](fmt.Errorf("%s: %w", target.Name(), result.Error()))
+	}
+	return deliveryDate
+}
@@ -0,0 +1,91 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+func TestHolidayTargetNext(t *testing.T) {
+	// The first day of Tết at 08:00 in Ho Chi Minh City (UTC+7)
+	tet := NewHolidayTarget("vn", "tet", user.TimeOfDay(8*60))
+	if tet.IsErr() {
+		t.Fatalf("NewHolidayTarget() error = %v", tet.Error())
+	}
+	if tet.Value().Country() != "VN" {
+		t.Errorf("Country() = %q, want VN", tet.Value().Country())
+	}
+
+	tests := []struct {
+		name  string
+		after time.Time
+		want  time.Time
+	}{
+		{"before the holiday", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, time.February, 17, 1, 0, 0, 0, time.UTC)},
+		{"on the holiday before its time", time.Date(2026, time.February, 17, 0, 30, 0, 0, time.UTC), time.Date(2026, time.February, 17, 1, 0, 0, 0, time.UTC)},
+		{"on the holiday after its time", time.Date(2026, time.February, 17, 1, 0, 0, 0, time.UTC), time.Date(2027, time.February, 6, 1, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := tet.Value().Next(tt.after, "Asia/Ho_Chi_Minh")
+			if next.IsErr() || !next.Value().Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", next.Value(), tt.want)
+			}
+		})
+	}
+
+	if NewHolidayTarget("VN", "thanksgiving", DefaultAnchorTime).IsOk() {
+		t.Error("holidays of other countries should be rejected")
+	}
+}
+
+func TestYearlyHolidayMessage(t *testing.T) {
+	christmas := NewHolidayTarget("GB", "christmas", user.TimeOfDay(8*60)).Value()
+
+	msgResult := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Merry Christmas",
+		Content:        "Another year together.",
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+		Recurrence:     RecurrenceYearly,
+		Holiday:        common.Some(christmas),
+	})
+	if msgResult.IsErr() {
+		t.Fatalf("NewMessage() error = %v", msgResult.Error())
+	}
+	msg := msgResult.Value()
+
+	if d := msg.DeliveryDate(); d.Month() != time.December || d.Day() != 25 || d.Hour() != 8 {
+		t.Errorf("DeliveryDate() = %v, want Christmas at 08:00", d)
+	}
+
+	// Each occurrence keeps the holiday
+	next := msg.WithNextRecurrence()
+	if next.IsErr() {
+		t.Fatalf("WithNextRecurrence() error = %v", next.Error())
+	}
+	if want := msg.DeliveryDate().AddDate(1, 0, 0); !next.Value().DeliveryDate().Equal(want) || next.Value().HolidayTarget().IsNone() {
+		t.Errorf("next DeliveryDate() = %v, want %v", next.Value().DeliveryDate(), want)
+	}
+
+	// Avoiding holidays does not move a letter aimed at one
+	avoid := user.DefaultDeliveryPreferences().WithHolidays("GB", true).Value()
+	applied := ApplyDeliveryPreferences(msg, newRecipient(t, avoid))
+	if applied.IsErr() || !applied.Value().DeliveryDate().Equal(msg.DeliveryDate()) {
+		t.Errorf("ApplyDeliveryPreferences() moved the letter to %v", applied.Value().DeliveryDate())
+	}
+
+	if msg.WithRecurrence(RecurrenceMonthly).IsOk() {
+		t.Error("holiday messages should only recur yearly")
+	}
+
+	// An explicit delivery date replaces the holiday
+	moved := msg.WithDeliveryDate(time.Now().AddDate(0, 2, 0), "UTC")
+	if moved.IsErr() || moved.Value().HolidayTarget().IsSome() {
+		t.Error("WithDeliveryDate() should clear the holiday")
+	}
+}
@@ -0,0 +1,200 @@
+// Package message contains listing options for message domain
+package message
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// ListSortField represents the field a message listing is ordered by
+type ListSortField string
+
+const (
+	SortByCreatedAt    ListSortField = "created_at"
+	SortByDeliveryDate ListSortField = "delivery_date"
+)
+
+// SortDirection represents the ordering direction of a listing
+type SortDirection string
+
+const (
+	SortAscending  SortDirection = "asc"
+	SortDescending SortDirection = "desc"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 100
+)
+
+// ListFilter narrows a message listing. Unset options match everything.
+type ListFilter struct {
+	Status         common.Option[MessageStatus]
+	DeliveryMethod common.Option[DeliveryMethod]
+	Recurrence     common.Option[RecurrencePattern]
+	From           common.Option[time.Time] // Inclusive lower bound on the visible delivery window
+	To             common.Option[time.Time] // Inclusive upper bound on the visible delivery window
+	Tags           []string                 // Tag keys; a message matches if it has any of them
+}
+
+// ListCursor marks the position after the last message of a page. It holds
+// the sort key and ID of that message so the next page is stable even when
+// earlier messages change status.
+type ListCursor struct {
+	Sort      ListSortField `json:"s"`
+	Direction SortDirection `json:"d"`
+	Value     time.Time     `json:"v"`
+	ID        uuid.UUID     `json:"id"`
+}
+
+// ListOptions describes a validated page request
+type ListOptions struct {
+	Filter    ListFilter
+	Sort      ListSortField
+	Direction SortDirection
+	Cursor    common.Option[ListCursor]
+	Limit     int
+}
+
+// NewListOptions validates listing input and applies defaults
+func NewListOptions(filter ListFilter, sort ListSortField, direction SortDirection, cursor string, limit int) common.Result[ListOptions] {
+	validFilter := validateListFilter(filter)
+	if validFilter.IsErr() {
+		return common.Err[ListOptions](validFilter.Error())
+	}
+
+	switch sort {
+	case "":
+		sort = SortByDeliveryDate
+	case SortByCreatedAt, SortByDeliveryDate:
+	default:
+		return common.Err[ListOptions](errors.New("invalid sort field (must be 'created_at' or 'delivery_date')"))
+	}
+
+	switch direction {
+	case "":
+		direction = SortDescending
+	case SortAscending, SortDescending:
+	default:
+		return common.Err[ListOptions](errors.New("invalid sort direction (must be 'asc' or 'desc')"))
+	}
+
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	options := ListOptions{
+		Filter:    validFilter.Value(),
+		Sort:      sort,
+		Direction: direction,
+		Cursor:    common.None[ListCursor](),
+		Limit:     limit,
+	}
+
+	if cursor != "" {
+		decoded := DecodeListCursor(cursor)
+		if decoded.IsErr() {
+			return common.Err[ListOptions](decoded.Error())
+		}
+		if decoded.Value().Sort != sort || decoded.Value().Direction != direction {
+			return common.Err[ListOptions](errors.New("cursor does not match the requested sort order"))
+		}
+		options.Cursor = common.Some(decoded.Value())
+	}
+
+	return common.Ok(options)
+}
+
+// validateListFilter validates listing filters
+func validateListFilter(filter ListFilter) common.Result[ListFilter] {
+	if filter.Status.IsSome() {
+		if result := validateMessageStatus(filter.Status.Value()); result.IsErr() {
+			return common.Err[ListFilter](result.Error())
+		}
+	}
+
+	if filter.DeliveryMethod.IsSome() {
+		if filter.DeliveryMethod.Value() == "" {
+			return common.Err[ListFilter](errors.New("invalid delivery method"))
+		}
+		if result := validateDeliveryMethod(filter.DeliveryMethod.Value()); result.IsErr() {
+			return common.Err[ListFilter](result.Error())
+		}
+	}
+
+	if filter.Recurrence.IsSome() {
+		if filter.Recurrence.Value() == "" {
+			return common.Err[ListFilter](errors.New("invalid recurrence pattern"))
+		}
+		if result := validateRecurrence(filter.Recurrence.Value()); result.IsErr() {
+			return common.Err[ListFilter](result.Error())
+		}
+	}
+
+	if filter.From.IsSome() && filter.To.IsSome() && filter.From.Value().After(filter.To.Value()) {
+		return common.Err[ListFilter](errors.New("'from' date must be before 'to' date"))
+	}
+
+	if len(filter.Tags) > 0 {
+		validTags := NormalizeTagNames(filter.Tags)
+		if validTags.IsErr() {
+			return common.Err[ListFilter](validTags.Error())
+		}
+		keys := make([]string, len(validTags.Value()))
+		for i, name := range validTags.Value() {
+			keys[i] = TagKey(name)
+		}
+		filter.Tags = keys
+	}
+
+	return common.Ok(filter)
+}
+
+// SortValue returns the value of the listing sort key for a message. Hidden
+// surprise messages sort by the start of their window.
+func (o ListOptions) SortValue(m Message) time.Time {
+	if o.Sort == SortByCreatedAt {
+		return m.CreatedAt()
+	}
+	earliest, _ := m.VisibleDeliveryWindow()
+	return earliest
+}
+
+// CursorAfter builds the cursor that continues a listing after the message
+func (o ListOptions) CursorAfter(m Message) ListCursor {
+	return ListCursor{
+		Sort:      o.Sort,
+		Direction: o.Direction,
+		Value:     o.SortValue(m),
+		ID:        m.ID(),
+	}
+}
+
+// Encode returns the opaque string form of the cursor
+func (c ListCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeListCursor parses a cursor produced by ListCursor.Encode
+func DecodeListCursor(encoded string) common.Result[ListCursor] {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return common.Err[ListCursor](errors.New("invalid cursor"))
+	}
+
+	var cursor ListCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil || cursor.Value.IsZero() {
+		return common.Err[ListCursor](errors.New("invalid cursor"))
+	}
+
+	return common.Ok(cursor)
+}
@@ -0,0 +1,85 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+func TestNewListOptionsCursor(t *testing.T) {
+	cursor := ListCursor{
+		Sort:      SortByCreatedAt,
+		Direction: SortAscending,
+		Value:     time.Date(2030, 1, 2, 3, 4, 5, 6000, time.UTC),
+		ID:        uuid.New(),
+	}
+	encoded := cursor.Encode()
+
+	tests := []struct {
+		name        string
+		sort        ListSortField
+		direction   SortDirection
+		cursor      string
+		expectError bool
+	}{
+		{name: "matching cursor", sort: SortByCreatedAt, direction: SortAscending, cursor: encoded},
+		{name: "cursor from another sort", sort: SortByDeliveryDate, direction: SortAscending, cursor: encoded, expectError: true},
+		{name: "cursor from another direction", sort: SortByCreatedAt, direction: SortDescending, cursor: encoded, expectError: true},
+		{name: "garbage cursor", sort: SortByCreatedAt, direction: SortAscending, cursor: "not-a-cursor", expectError: true},
+		{name: "invalid sort", sort: "title", direction: SortAscending, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewListOptions(ListFilter{}, tt.sort, tt.direction, tt.cursor, 0)
+
+			if tt.expectError {
+				if result.IsOk() {
+					t.Fatalf("expected error but got none")
+				}
+				return
+			}
+
+			if result.IsErr() {
+				t.Fatalf("expected no error but got: %v", result.Error())
+			}
+
+			options := result.Value()
+			if options.Limit != DefaultListLimit {
+				t.Errorf("expected default limit %d, got %d", DefaultListLimit, options.Limit)
+			}
+			if !options.Cursor.IsSome() {
+				t.Fatalf("expected cursor to be decoded")
+			}
+			decoded := options.Cursor.Value()
+			if decoded.ID != cursor.ID || !decoded.Value.Equal(cursor.Value) {
+				t.Errorf("cursor did not round-trip: got %+v, want %+v", decoded, cursor)
+			}
+		})
+	}
+}
+
+func TestNewListOptionsFilter(t *testing.T) {
+	from := time.Now().Add(48 * time.Hour)
+	to := time.Now()
+
+	result := NewListOptions(ListFilter{From: common.Some(from), To: common.Some(to)}, "", "", "", 500)
+	if result.IsOk() {
+		t.Fatalf("expected error for inverted date range")
+	}
+
+	result = NewListOptions(ListFilter{Recurrence: common.Some(RecurrencePattern("hourly"))}, "", "", "", 500)
+	if result.IsOk() {
+		t.Fatalf("expected error for invalid recurrence")
+	}
+
+	result = NewListOptions(ListFilter{}, "", "", "", 500)
+	if result.IsErr() {
+		t.Fatalf("expected no error but got: %v", result.Error())
+	}
+	if result.Value().Limit != MaxListLimit || result.Value().Sort != SortByDeliveryDate || result.Value().Direction != SortDescending {
+		t.Errorf("unexpected defaults: %+v", result.Value())
+	}
+}
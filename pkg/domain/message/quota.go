@@ -0,0 +1,58 @@
+// Package message contains plan quota checks for message domain
+package message
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+var (
+	// ErrQuotaExceeded is returned when a plan limit has been used up
+	ErrQuotaExceeded = errors.New("plan quota exceeded")
+
+	// ErrFeatureNotInPlan is returned when a plan does not include a feature
+	ErrFeatureNotInPlan = errors.New("feature not available on current plan")
+)
+
+// IsAwaitingDelivery returns true if the message counts against the
+// scheduled message limit of its author's plan
+func (m Message) IsAwaitingDelivery() bool {
+	return m.status == StatusScheduled || m.status == StatusArmed || m.status == StatusFailed
+}
+
+// ValidateNewMessageForPlan checks a new message against the limits of its
+// author's plan and current usage
+func ValidateNewMessageForPlan(msg Message, limits user.PlanLimits, usage user.Usage) common.Result[Message] {
+	if features := ValidateMessageFeaturesForPlan(msg, limits); features.IsErr() {
+		return features
+	}
+
+	if msg.IsAwaitingDelivery() && !limits.AllowsScheduledMessages(usage, 1) {
+		return common.Err[Message](fmt.Errorf("%w: your plan allows %d scheduled messages", ErrQuotaExceeded, limits.MaxScheduledMessages))
+	}
+
+	return common.Ok(msg)
+}
+
+// ValidateMessageFeaturesForPlan checks that a message only uses features
+// included in its author's plan
+func ValidateMessageFeaturesForPlan(msg Message, limits user.PlanLimits) common.Result[Message] {
+	if msg.recurrence != RecurrenceNone && !limits.Recurrence {
+		return common.Err[Message](fmt.Errorf("%w: recurring messages", ErrFeatureNotInPlan))
+	}
+
+	return common.Ok(msg)
+}
+
+// ValidateAttachmentForPlan checks that an attachment of size bytes fits in
+// the storage left on its author's plan
+func ValidateAttachmentForPlan(size int64, limits user.PlanLimits, usage user.Usage) common.Result[int64] {
+	if !limits.AllowsAttachmentBytes(usage, size) {
+		return common.Err[int64](fmt.Errorf("%w: your plan allows %d bytes of attachments", ErrQuotaExceeded, limits.MaxAttachmentBytes))
+	}
+
+	return common.Ok(size)
+}
@@ -0,0 +1,56 @@
+package message
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+func TestValidateNewMessageForPlan(t *testing.T) {
+	free := user.PlanLimits{MaxScheduledMessages: 2, MaxAttachmentBytes: 1024}
+
+	msgResult := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Weekly note",
+		Content:        "See you next week",
+		DeliveryDate:   time.Now().Add(7 * 24 * time.Hour),
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+		Recurrence:     RecurrenceWeekly,
+	})
+	if msgResult.IsErr() {
+		t.Fatalf("NewMessage() error = %v", msgResult.Error())
+	}
+	recurring := msgResult.Value()
+
+	if result := ValidateNewMessageForPlan(recurring, free, user.Usage{}); !errors.Is(result.Error(), ErrFeatureNotInPlan) {
+		t.Errorf("recurring message on a plan without recurrence: error = %v, want ErrFeatureNotInPlan", result.Error())
+	}
+
+	oneOff := recurring.WithRecurrence(RecurrenceNone).Value()
+	if result := ValidateNewMessageForPlan(oneOff, free, user.Usage{ScheduledMessages: 1}); result.IsErr() {
+		t.Errorf("message within quota: error = %v", result.Error())
+	}
+	if result := ValidateNewMessageForPlan(oneOff, free, user.Usage{ScheduledMessages: 2}); !errors.Is(result.Error(), ErrQuotaExceeded) {
+		t.Errorf("message over quota: error = %v, want ErrQuotaExceeded", result.Error())
+	}
+
+	unlimited := user.PlanLimits{Recurrence: true}
+	if result := ValidateNewMessageForPlan(recurring, unlimited, user.Usage{ScheduledMessages: 10000}); result.IsErr() {
+		t.Errorf("zero limits should be unlimited, got error = %v", result.Error())
+	}
+}
+
+func TestValidateAttachmentForPlan(t *testing.T) {
+	limits := user.PlanLimits{MaxAttachmentBytes: 1024}
+
+	if ValidateAttachmentForPlan(512, limits, user.Usage{AttachmentBytes: 512}).IsErr() {
+		t.Error("attachment filling the quota exactly should be allowed")
+	}
+	if result := ValidateAttachmentForPlan(513, limits, user.Usage{AttachmentBytes: 512}); !errors.Is(result.Error(), ErrQuotaExceeded) {
+		t.Errorf("attachment over quota: error = %v, want ErrQuotaExceeded", result.Error())
+	}
+}
@@ -0,0 +1,166 @@
+// Package message contains delivery receipts for message domain
+package message
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// ReceiptTrackingPeriod is how long after delivery the receipt links of an
+// email keep working
+const ReceiptTrackingPeriod = 365 * 24 * time.Hour
+
+// DeliveryReceipt records what happened to a message after it was handed to
+// one recipient's mail server: when the email was opened and when the
+// recipient marked it as read
+type DeliveryReceipt struct {
+	id          uuid.UUID
+	messageID   uuid.UUID
+	recipient   string
+	deliveredAt time.Time
+	openedAt    common.Option[time.Time]
+	readAt      common.Option[time.Time]
+}
+
+// StoredDeliveryReceipt represents persisted receipt data
+type StoredDeliveryReceipt struct {
+	ID          uuid.UUID
+	MessageID   uuid.UUID
+	Recipient   string
+	DeliveredAt time.Time
+	OpenedAt    common.Option[time.Time]
+	ReadAt      common.Option[time.Time]
+}
+
+// NewDeliveryReceipt creates the receipt of a delivery to recipient
+func NewDeliveryReceipt(messageID uuid.UUID, recipient string, deliveredAt time.Time) common.Result[DeliveryReceipt] {
+	return RestoreDeliveryReceipt(StoredDeliveryReceipt{
+		ID:          uuid.New(),
+		MessageID:   messageID,
+		Recipient:   recipient,
+		DeliveredAt: deliveredAt,
+		OpenedAt:    common.None[time.Time](),
+		ReadAt:      common.None[time.Time](),
+	})
+}
+
+// RestoreDeliveryReceipt rebuilds a receipt from stored data
+func RestoreDeliveryReceipt(data StoredDeliveryReceipt) common.Result[DeliveryReceipt] {
+	if data.ID == uuid.Nil || data.MessageID == uuid.Nil {
+		return common.Err[DeliveryReceipt](errors.New("delivery receipt needs an ID and a message ID"))
+	}
+
+	recipient := strings.TrimSpace(strings.ToLower(data.Recipient))
+	if recipient == "" {
+		return common.Err[DeliveryReceipt](errors.New("delivery receipt needs a recipient"))
+	}
+
+	return common.Ok(DeliveryReceipt{
+		id:          data.ID,
+		messageID:   data.MessageID,
+		recipient:   recipient,
+		deliveredAt: data.DeliveredAt,
+		openedAt:    data.OpenedAt,
+		readAt:      data.ReadAt,
+	})
+}
+
+func (r DeliveryReceipt) ID() uuid.UUID {
+	return r.id
+}
+
+func (r DeliveryReceipt) MessageID() uuid.UUID {
+	return r.messageID
+}
+
+func (r DeliveryReceipt) Recipient() string {
+	return r.recipient
+}
+
+func (r DeliveryReceipt) DeliveredAt() time.Time {
+	return r.deliveredAt
+}
+
+func (r DeliveryReceipt) OpenedAt() common.Option[time.Time] {
+	return r.openedAt
+}
+
+func (r DeliveryReceipt) ReadAt() common.Option[time.Time] {
+	return r.readAt
+}
+
+// TrackingExpiresAt returns when the receipt links stop working
+func (r DeliveryReceipt) TrackingExpiresAt() time.Time {
+	return r.deliveredAt.Add(ReceiptTrackingPeriod)
+}
+
+// WithOpened returns the receipt with the email marked as opened. Only the
+// first open is recorded.
+func (r DeliveryReceipt) WithOpened(at time.Time) DeliveryReceipt {
+	if r.openedAt.IsSome() {
+		return r
+	}
+
+	updated := r
+	updated.openedAt = common.Some(at)
+	return updated
+}
+
+// WithRead returns the receipt with the message marked as read. Reading a
+// message implies opening it; only the first read is recorded.
+func (r DeliveryReceipt) WithRead(at time.Time) DeliveryReceipt {
+	updated := r.WithOpened(at)
+	if updated.readAt.IsNone() {
+		updated.readAt = common.Some(at)
+	}
+	return updated
+}
+
+// TimelineEventKind identifies a step in the life of a message
+type TimelineEventKind string
+
+const (
+	TimelineCreated   TimelineEventKind = "created"
+	TimelineDelivered TimelineEventKind = "delivered"
+	TimelineOpened    TimelineEventKind = "opened"
+	TimelineRead      TimelineEventKind = "read"
+)
+
+// TimelineEvent is one step in the delivery timeline of a message. Recipient
+// is empty for events that concern the message as a whole.
+type TimelineEvent struct {
+	Kind      TimelineEventKind
+	At        time.Time
+	Recipient string
+}
+
+// BuildDeliveryTimeline lists the steps of a message from creation to the
+// last receipt, oldest first
+func BuildDeliveryTimeline(msg Message, receipts []DeliveryReceipt) []TimelineEvent {
+	events := []TimelineEvent{{Kind: TimelineCreated, At: msg.CreatedAt()}}
+
+	if len(receipts) == 0 && msg.DeliveredAt().IsSome() {
+		// Delivered before receipts were recorded
+		events = append(events, TimelineEvent{Kind: TimelineDelivered, At: msg.DeliveredAt().Value()})
+	}
+
+	for _, receipt := range receipts {
+		events = append(events, TimelineEvent{Kind: TimelineDelivered, At: receipt.DeliveredAt(), Recipient: receipt.Recipient()})
+		if receipt.OpenedAt().IsSome() {
+			events = append(events, TimelineEvent{Kind: TimelineOpened, At: receipt.OpenedAt().Value(), Recipient: receipt.Recipient()})
+		}
+		if receipt.ReadAt().IsSome() {
+			events = append(events, TimelineEvent{Kind: TimelineRead, At: receipt.ReadAt().Value(), Recipient: receipt.Recipient()})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
+	return events
+}
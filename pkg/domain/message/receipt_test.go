@@ -0,0 +1,38 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestDeliveryReceipt(t *testing.T) {
+	deliveredAt := time.Date(2030, time.January, 1, 9, 0, 0, 0, time.UTC)
+	receiptResult := NewDeliveryReceipt(uuid.New(), " Future@Example.com ", deliveredAt)
+	if receiptResult.IsErr() {
+		t.Fatalf("NewDeliveryReceipt() error = %v", receiptResult.Error())
+	}
+	receipt := receiptResult.Value()
+
+	if receipt.Recipient() != "future@example.com" {
+		t.Errorf("Recipient() = %q, want normalized address", receipt.Recipient())
+	}
+
+	// Reading without a recorded open counts as an open too
+	readAt := deliveredAt.Add(2 * time.Hour)
+	read := receipt.WithRead(readAt)
+	if !read.OpenedAt().ValueOr(time.Time{}).Equal(readAt) || !read.ReadAt().ValueOr(time.Time{}).Equal(readAt) {
+		t.Errorf("WithRead() opened %v read %v, want both %v", read.OpenedAt(), read.ReadAt(), readAt)
+	}
+
+	// Later opens and reads keep the first times
+	again := read.WithOpened(readAt.Add(time.Hour)).WithRead(readAt.Add(time.Hour))
+	if !again.ReadAt().Value().Equal(readAt) || !again.OpenedAt().Value().Equal(readAt) {
+		t.Error("WithOpened() and WithRead() should keep the first recorded times")
+	}
+
+	if NewDeliveryReceipt(uuid.New(), "", deliveredAt).IsOk() {
+		t.Error("receipts without a recipient should be rejected")
+	}
+}
@@ -0,0 +1,69 @@
+// Package message contains search helpers for message domain
+package message
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// SearchCriteria describes a validated search over a user's messages
+type SearchCriteria struct {
+	Query  string
+	Status common.Option[MessageStatus]
+	From   common.Option[time.Time] // Inclusive lower bound on the visible delivery window
+	To     common.Option[time.Time] // Inclusive upper bound on the visible delivery window
+}
+
+// NewSearchCriteria validates and normalizes search input
+func NewSearchCriteria(query string, status common.Option[MessageStatus], from, to common.Option[time.Time]) common.Result[SearchCriteria] {
+	validQuery := validateSearchQuery(query)
+	if validQuery.IsErr() {
+		return common.Err[SearchCriteria](validQuery.Error())
+	}
+
+	if status.IsSome() {
+		validStatus := validateMessageStatus(status.Value())
+		if validStatus.IsErr() {
+			return common.Err[SearchCriteria](validStatus.Error())
+		}
+	}
+
+	if from.IsSome() && to.IsSome() && from.Value().After(to.Value()) {
+		return common.Err[SearchCriteria](errors.New("search 'from' date must be before 'to' date"))
+	}
+
+	return common.Ok(SearchCriteria{
+		Query:  validQuery.Value(),
+		Status: status,
+		From:   from,
+		To:     to,
+	})
+}
+
+// ParseMessageStatus converts an external status string into a MessageStatus
+func ParseMessageStatus(status string) common.Result[MessageStatus] {
+	normalized := MessageStatus(strings.ToLower(strings.TrimSpace(status)))
+	if normalized == "sent" {
+		normalized = StatusDelivered // Storage and older clients call delivered messages "sent"
+	}
+	return validateMessageStatus(normalized)
+}
+
+// validateSearchQuery validates a free-text search query
+func validateSearchQuery(query string) common.Result[string] {
+	query = strings.Join(strings.Fields(query), " ")
+
+	if query == "" {
+		return common.Err[string](errors.New("search query cannot be empty"))
+	}
+
+	if utf8.RuneCountInString(query) > 200 {
+		return common.Err[string](errors.New("search query is too long (max 200 characters)"))
+	}
+
+	return common.Ok(query)
+}
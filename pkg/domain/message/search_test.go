@@ -0,0 +1,98 @@
+package message
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+func TestNewSearchCriteria(t *testing.T) {
+	from := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name        string
+		query       string
+		status      common.Option[MessageStatus]
+		from        common.Option[time.Time]
+		to          common.Option[time.Time]
+		wantQuery   string
+		expectError bool
+	}{
+		{name: "plain query", query: "birthday", wantQuery: "birthday"},
+		{name: "whitespace is collapsed", query: "  happy \t birthday\n", wantQuery: "happy birthday"},
+		{name: "empty query", query: "", expectError: true},
+		{name: "blank query", query: " \t\n ", expectError: true},
+		{name: "query at the limit", query: strings.Repeat("a", 200), wantQuery: strings.Repeat("a", 200)},
+		{name: "query over the limit", query: strings.Repeat("a", 201), expectError: true},
+		{name: "limit counts characters", query: strings.Repeat("ư", 200), wantQuery: strings.Repeat("ư", 200)},
+		{name: "valid status", query: "trip", status: common.Some(StatusDelivered), wantQuery: "trip"},
+		{name: "invalid status", query: "trip", status: common.Some(MessageStatus("archived")), expectError: true},
+		{name: "date range", query: "trip", from: common.Some(from), to: common.Some(to), wantQuery: "trip"},
+		{name: "single day range", query: "trip", from: common.Some(from), to: common.Some(from), wantQuery: "trip"},
+		{name: "open ended range", query: "trip", from: common.Some(to), wantQuery: "trip"},
+		{name: "reversed range", query: "trip", from: common.Some(to), to: common.Some(from), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewSearchCriteria(tt.query, tt.status, tt.from, tt.to)
+
+			if tt.expectError {
+				if result.IsOk() {
+					t.Fatalf("expected error but got none")
+				}
+				return
+			}
+			if result.IsErr() {
+				t.Fatalf("unexpected error: %v", result.Error())
+			}
+
+			criteria := result.Value()
+			if criteria.Query != tt.wantQuery {
+				t.Errorf("Query = %q, want %q", criteria.Query, tt.wantQuery)
+			}
+			if criteria.Status.IsSome() != tt.status.IsSome() || criteria.From.IsSome() != tt.from.IsSome() || criteria.To.IsSome() != tt.to.IsSome() {
+				t.Errorf("filters were not kept: %+v", criteria)
+			}
+		})
+	}
+}
+
+func TestParseMessageStatus(t *testing.T) {
+	tests := []struct {
+		input       string
+		want        MessageStatus
+		expectError bool
+	}{
+		{input: "scheduled", want: StatusScheduled},
+		{input: "delivered", want: StatusDelivered},
+		{input: "sent", want: StatusDelivered},
+		{input: " Failed ", want: StatusFailed},
+		{input: "CANCELLED", want: StatusCancelled},
+		{input: "armed", want: StatusArmed},
+		{input: "", expectError: true},
+		{input: "archived", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result := ParseMessageStatus(tt.input)
+
+			if tt.expectError {
+				if result.IsOk() {
+					t.Fatalf("expected error but got %s", result.Value())
+				}
+				return
+			}
+			if result.IsErr() {
+				t.Fatalf("unexpected error: %v", result.Error())
+			}
+			if result.Value() != tt.want {
+				t.Errorf("ParseMessageStatus(%q) = %s, want %s", tt.input, result.Value(), tt.want)
+			}
+		})
+	}
+}
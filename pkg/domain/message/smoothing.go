@@ -0,0 +1,134 @@
+// Package message contains delivery smoothing for message domain
+package message
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// DeliveryClass groups messages by how long their delivery can be delayed
+// to spread a burst of letters due at the same moment
+type DeliveryClass string
+
+const (
+	ClassScheduled DeliveryClass = "scheduled" // One-off letters
+	ClassRecurring DeliveryClass = "recurring" // Each occurrence of a recurring letter
+	ClassOccasion  DeliveryClass = "occasion"  // Letters for a milestone or holiday, where the moment matters most
+	ClassSurprise  DeliveryClass = "surprise"  // Letters at a hidden time, which is random anyway
+)
+
+// DeliveryClasses lists the delivery classes
+var DeliveryClasses = []DeliveryClass{ClassScheduled, ClassRecurring, ClassOccasion, ClassSurprise}
+
+// maxSmoothingDelay bounds the configurable delay of any class
+const maxSmoothingDelay = 24 * time.Hour
+
+// DefaultSmoothingDelays returns the longest acceptable delay of each class
+// when none is configured
+func DefaultSmoothingDelays() map[DeliveryClass]time.Duration {
+	return map[DeliveryClass]time.Duration{
+		ClassScheduled: 15 * time.Minute,
+		ClassRecurring: 30 * time.Minute,
+		ClassOccasion:  5 * time.Minute,
+		ClassSurprise:  2 * time.Hour,
+	}
+}
+
+// DeliveryClass returns the class the message is smoothed as
+func (m Message) DeliveryClass() DeliveryClass {
+	switch {
+	case m.surprise.IsSome():
+		return ClassSurprise
+	case m.anchor.IsSome() || m.holiday.IsSome():
+		return ClassOccasion
+	case m.HasRecurrence():
+		return ClassRecurring
+	default:
+		return ClassScheduled
+	}
+}
+
+// SmoothingPolicy spreads deliveries so no more than a number of them start
+// in any minute, delaying each by at most the maximum of its class
+type SmoothingPolicy struct {
+	perMinute int
+	maxDelay  map[DeliveryClass]time.Duration
+}
+
+// NewSmoothingPolicy creates a policy allowing perMinute deliveries to start
+// per minute. Classes without a maximum delay use the default one.
+func NewSmoothingPolicy(perMinute int, maxDelay map[DeliveryClass]time.Duration) common.Result[SmoothingPolicy] {
+	if perMinute <= 0 {
+		return common.Err[SmoothingPolicy](errors.New("deliveries per minute must be positive"))
+	}
+
+	delays := DefaultSmoothingDelays()
+	for class, delay := range maxDelay {
+		if _, ok := delays[class]; !ok {
+			return common.Err[SmoothingPolicy](fmt.Errorf("unknown delivery class %q", class))
+		}
+		if delay < 0 || delay > maxSmoothingDelay {
+			return common.Err[SmoothingPolicy](fmt.Errorf("maximum delay of %s messages must be between 0 and %s", class, maxSmoothingDelay))
+		}
+		delays[class] = delay
+	}
+
+	return common.Ok(SmoothingPolicy{perMinute: perMinute, maxDelay: delays})
+}
+
+// PerMinute returns how many deliveries may start in a minute
+func (p SmoothingPolicy) PerMinute() int {
+	return p.perMinute
+}
+
+// MaxDelay returns the longest acceptable delay of a class
+func (p SmoothingPolicy) MaxDelay(class DeliveryClass) time.Duration {
+	return p.maxDelay[class]
+}
+
+// Window returns the first and last moment Slot may pick for a delivery
+// requested at a time: from the start of the requested minute up to its
+// maximum delay
+func (p SmoothingPolicy) Window(requested time.Time, class DeliveryClass) (first, last time.Time) {
+	requested = requested.UTC()
+	return requested.Truncate(time.Minute), requested.Add(p.maxDelay[class])
+}
+
+// Slot returns when a delivery requested at a time should start, given the
+// deliveries already booked in each minute (keyed by the start of the minute
+// in UTC). Deliveries fill the minutes from the requested one onward, evenly
+// spaced within each minute, up to the maximum delay of the class. When
+// every minute in that range is full, the least booked one is used and ok is
+// false.
+func (p SmoothingPolicy) Slot(requested time.Time, class DeliveryClass, booked map[time.Time]int) (slot time.Time, ok bool) {
+	requested = requested.UTC()
+	first, last := p.Window(requested, class)
+
+	best, bestCount := first, booked[first]
+	for minute := first; !minute.After(last); minute = minute.Add(time.Minute) {
+		count := booked[minute]
+		if count < p.perMinute {
+			return p.within(minute, count, requested, last), true
+		}
+		if count < bestCount {
+			best, bestCount = minute, count
+		}
+	}
+	return p.within(best, bestCount%p.perMinute, requested, last), false
+}
+
+// within returns the start of the nth delivery of a minute, kept between the
+// requested time and the latest acceptable one
+func (p SmoothingPolicy) within(minute time.Time, n int, requested, last time.Time) time.Time {
+	slot := minute.Add(time.Duration(n) * time.Minute / time.Duration(p.perMinute))
+	if slot.Before(requested) {
+		return requested
+	}
+	if slot.After(last) {
+		return last
+	}
+	return slot
+}
@@ -0,0 +1,73 @@
+package message
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSmoothingPolicySlot(t *testing.T) {
+	policy := NewSmoothingPolicy(4, map[DeliveryClass]time.Duration{ClassScheduled: 2 * time.Minute})
+	if policy.IsErr() {
+		t.Fatalf("NewSmoothingPolicy() error = %v", policy.Error())
+	}
+
+	midnight := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	booked := make(map[time.Time]int)
+	book := func() (time.Time, bool) {
+		slot, ok := policy.Value().Slot(midnight, ClassScheduled, booked)
+		booked[slot.Truncate(time.Minute)]++
+		return slot, ok
+	}
+
+	// Four deliveries per minute, 15 seconds apart, over the next minutes
+	want := []time.Duration{0, 15 * time.Second, 30 * time.Second, 45 * time.Second, time.Minute, 75 * time.Second}
+	for i, offset := range want {
+		slot, ok := book()
+		if !ok || !slot.Equal(midnight.Add(offset)) {
+			t.Errorf("delivery %d: Slot() = %v, %v, want %v", i, slot, ok, midnight.Add(offset))
+		}
+	}
+
+	// Once every minute up to the maximum delay is full, deliveries go to the
+	// least booked minute and report being over capacity
+	for i := 0; i < 6; i++ {
+		book()
+	}
+	slot, ok := book()
+	if ok {
+		t.Error("Slot() should report no capacity left")
+	}
+	if slot.Before(midnight) || slot.After(midnight.Add(2*time.Minute)) {
+		t.Errorf("Slot() = %v, want within the maximum delay", slot)
+	}
+}
+
+func TestSmoothingPolicyWindow(t *testing.T) {
+	policy := NewSmoothingPolicy(4, map[DeliveryClass]time.Duration{ClassScheduled: 15 * time.Minute}).Value()
+
+	requested := time.Date(2027, time.January, 1, 7, 0, 30, 0, time.FixedZone("ICT", 7*60*60))
+	first, last := policy.Window(requested, ClassScheduled)
+	if want := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC); !first.Equal(want) || first.Location() != time.UTC {
+		t.Errorf("Window() first = %v, want %v in UTC", first, want)
+	}
+	if want := time.Date(2027, time.January, 1, 0, 15, 30, 0, time.UTC); !last.Equal(want) {
+		t.Errorf("Window() last = %v, want %v", last, want)
+	}
+}
+
+func TestNewSmoothingPolicy(t *testing.T) {
+	if NewSmoothingPolicy(0, nil).IsOk() {
+		t.Error("a policy without capacity should be rejected")
+	}
+	if NewSmoothingPolicy(10, map[DeliveryClass]time.Duration{"urgent": time.Minute}).IsOk() {
+		t.Error("unknown delivery classes should be rejected")
+	}
+	if NewSmoothingPolicy(10, map[DeliveryClass]time.Duration{ClassSurprise: 48 * time.Hour}).IsOk() {
+		t.Error("delays over a day should be rejected")
+	}
+
+	policy := NewSmoothingPolicy(10, map[DeliveryClass]time.Duration{ClassOccasion: 0}).Value()
+	if policy.MaxDelay(ClassOccasion) != 0 || policy.MaxDelay(ClassScheduled) != DefaultSmoothingDelays()[ClassScheduled] {
+		t.Error("MaxDelay() should use the configured delay and default the others")
+	}
+}
@@ -0,0 +1,140 @@
+// Package message contains "surprise me" delivery for message domain
+package message
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// MinSurpriseRange is the shortest range a surprise delivery can be drawn
+// from; anything shorter would give the delivery day away
+const MinSurpriseRange = 24 * time.Hour
+
+// SurpriseWindow is the range a "surprise me" message is delivered in. The
+// concrete delivery time is drawn from the seed when the message is sealed
+// and is kept from the author until the message is delivered.
+type SurpriseWindow struct {
+	earliest time.Time
+	latest   time.Time
+	seed     uint64
+}
+
+// StoredSurpriseWindow represents a persisted surprise window
+type StoredSurpriseWindow struct {
+	Earliest time.Time
+	Latest   time.Time
+	Seed     uint64
+}
+
+// NewSurpriseWindow validates the bounds of a surprise delivery and picks a
+// fresh random seed for it
+func NewSurpriseWindow(earliest, latest time.Time) common.Result[SurpriseWindow] {
+	var seed [8]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		return common.Err[SurpriseWindow](errors.New("failed to seed surprise delivery"))
+	}
+
+	return RestoreSurpriseWindow(StoredSurpriseWindow{
+		Earliest: earliest,
+		Latest:   latest,
+		Seed:     binary.BigEndian.Uint64(seed[:]),
+	})
+}
+
+// RestoreSurpriseWindow rebuilds a surprise window from stored data
+func RestoreSurpriseWindow(data StoredSurpriseWindow) common.Result[SurpriseWindow] {
+	if data.Earliest.IsZero() || data.Latest.IsZero() {
+		return common.Err[SurpriseWindow](errors.New("surprise delivery needs an earliest and latest date"))
+	}
+	if data.Latest.Sub(data.Earliest) < MinSurpriseRange {
+		return common.Err[SurpriseWindow](errors.New("surprise delivery range must span at least one day"))
+	}
+
+	return common.Ok(SurpriseWindow{
+		earliest: data.Earliest,
+		latest:   data.Latest,
+		seed:     data.Seed,
+	})
+}
+
+func (w SurpriseWindow) Earliest() time.Time {
+	return w.earliest
+}
+
+func (w SurpriseWindow) Latest() time.Time {
+	return w.latest
+}
+
+func (w SurpriseWindow) Seed() uint64 {
+	return w.seed
+}
+
+// Draw returns the delivery time selected by the seed. The same window
+// always draws the same time.
+func (w SurpriseWindow) Draw() time.Time {
+	span := int64(w.latest.Sub(w.earliest) / time.Second)
+	rng := rand.New(rand.NewPCG(w.seed, w.seed>>32|w.seed<<32))
+	return w.earliest.Add(time.Duration(rng.Int64N(span+1)) * time.Second).UTC()
+}
+
+// Surprise returns the range of a "surprise me" message
+func (m Message) Surprise() common.Option[SurpriseWindow] {
+	return m.surprise
+}
+
+// IsDeliveryDateHidden returns true while the delivery time of a surprise
+// message must be kept from its author
+func (m Message) IsDeliveryDateHidden() bool {
+	return m.surprise.IsSome() && m.status != StatusDelivered
+}
+
+// VisibleDeliveryWindow returns the range the author may know the message is
+// delivered in: the surprise window while the drawn time is hidden,
+// otherwise the delivery date as both bounds. Listings sort, filter and page
+// by it so they cannot give the drawn time away.
+func (m Message) VisibleDeliveryWindow() (time.Time, time.Time) {
+	if m.IsDeliveryDateHidden() {
+		window := m.surprise.Value()
+		return window.earliest, window.latest
+	}
+	return m.deliveryDate, m.deliveryDate
+}
+
+// WithSurpriseWindow returns a new Message delivered at a time drawn from a
+// new range. Replacing the range reseals the message with a new draw.
+func (m Message) WithSurpriseWindow(window SurpriseWindow) common.Result[Message] {
+	if m.checkIn.IsSome() {
+		return common.Err[Message](errors.New("check-in messages cannot use surprise delivery"))
+	}
+	if m.recurrence != RecurrenceNone {
+		return common.Err[Message](errors.New("recurring messages cannot use surprise delivery"))
+	}
+	if err := validateSurpriseWindow(window, m.timezone); err != nil {
+		return common.Err[Message](err)
+	}
+
+	updated := m
+	updated.surprise = common.Some(window)
+	updated.deliveryDate = window.Draw()
+	updated.intendedDate = common.None[time.Time]()
+	updated.anchor = common.None[MilestoneAnchor]()
+	updated.holiday = common.None[HolidayTarget]()
+	updated.updatedAt = time.Now()
+	return common.Ok(updated)
+}
+
+// validateSurpriseWindow checks that both bounds are valid delivery dates
+func validateSurpriseWindow(window SurpriseWindow, timezone string) error {
+	if result := validateDeliveryDate(window.earliest, timezone, false); result.IsErr() {
+		return result.Error()
+	}
+	if result := validateDeliveryDate(window.latest, timezone, false); result.IsErr() {
+		return result.Error()
+	}
+	return nil
+}
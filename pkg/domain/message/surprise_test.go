@@ -0,0 +1,98 @@
+package message
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+func TestSurpriseWindowDraw(t *testing.T) {
+	earliest := time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC)
+	latest := time.Date(2030, time.May, 31, 0, 0, 0, 0, time.UTC)
+
+	window := RestoreSurpriseWindow(StoredSurpriseWindow{Earliest: earliest, Latest: latest, Seed: 42})
+	if window.IsErr() {
+		t.Fatalf("RestoreSurpriseWindow() error = %v", window.Error())
+	}
+
+	drawn := window.Value().Draw()
+	if drawn.Before(earliest) || drawn.After(latest) {
+		t.Errorf("Draw() = %v, want within [%v, %v]", drawn, earliest, latest)
+	}
+	if again := window.Value().Draw(); !again.Equal(drawn) {
+		t.Errorf("Draw() is not reproducible: %v then %v", drawn, again)
+	}
+
+	if RestoreSurpriseWindow(StoredSurpriseWindow{Earliest: earliest, Latest: earliest.Add(time.Hour)}).IsOk() {
+		t.Error("ranges shorter than a day should be rejected")
+	}
+}
+
+func TestNewMessageWithSurprise(t *testing.T) {
+	earliest := time.Now().Add(30 * 24 * time.Hour)
+	latest := earliest.Add(60 * 24 * time.Hour)
+
+	window := NewSurpriseWindow(earliest, latest)
+	if window.IsErr() {
+		t.Fatalf("NewSurpriseWindow() error = %v", window.Error())
+	}
+
+	msgResult := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Sometime next spring",
+		Content:        "Surprise!",
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+		Surprise:       common.Some(window.Value()),
+	})
+	if msgResult.IsErr() {
+		t.Fatalf("NewMessage() error = %v", msgResult.Error())
+	}
+	msg := msgResult.Value()
+
+	if !msg.DeliveryDate().Equal(window.Value().Draw()) {
+		t.Errorf("DeliveryDate() = %v, want the drawn time %v", msg.DeliveryDate(), window.Value().Draw())
+	}
+	if !msg.IsDeliveryDateHidden() {
+		t.Error("delivery date should be hidden until delivery")
+	}
+	listing := ListOptions{Sort: SortByDeliveryDate}
+	if cursor := listing.CursorAfter(msg); !cursor.Value.Equal(window.Value().Earliest()) {
+		t.Errorf("cursor should hold the start of the window instead of the drawn time, got %v", cursor.Value)
+	}
+	if _, visibleLatest := msg.VisibleDeliveryWindow(); !visibleLatest.Equal(window.Value().Latest()) {
+		t.Errorf("VisibleDeliveryWindow() should end with the window, got %v", visibleLatest)
+	}
+
+	preview := BuildPreviewInfo(msg, newRecipient(t, user.DefaultDeliveryPreferences()))
+	if preview.IsErr() {
+		t.Fatalf("BuildPreviewInfo() error = %v", preview.Error())
+	}
+	if !preview.Value().ScheduledTime.IsZero() || strings.Contains(preview.Value().Body, "Scheduled for delivery") {
+		t.Error("the preview should not show the drawn time")
+	}
+
+	if msg.WithDeliveryDate(earliest, "UTC").IsOk() {
+		t.Error("WithDeliveryDate() should be rejected for surprise messages")
+	}
+
+	record := NewTransferRecord(msg, nil)
+	if record.DeliveryDate != earliest.UTC().Format(time.RFC3339) || record.SurpriseUntil == "" {
+		t.Errorf("export should contain the range instead of the drawn time, got %q until %q", record.DeliveryDate, record.SurpriseUntil)
+	}
+
+	delivered := msg.WithStatus(StatusDelivered)
+	if delivered.IsErr() {
+		t.Fatalf("WithStatus() error = %v", delivered.Error())
+	}
+	if delivered.Value().IsDeliveryDateHidden() {
+		t.Error("delivery date should be revealed once delivered")
+	}
+	if visible, _ := delivered.Value().VisibleDeliveryWindow(); !visible.Equal(msg.DeliveryDate()) {
+		t.Errorf("VisibleDeliveryWindow() = %v after delivery, want the delivery date", visible)
+	}
+}
@@ -0,0 +1,169 @@
+// Package message contains tag types and business logic for message domain
+package message
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// MaxTagsPerMessage limits how many tags a single message can carry
+const MaxTagsPerMessage = 20
+
+// ErrTagExists is returned when a user already has a tag with the same name
+var ErrTagExists = errors.New("tag already exists")
+
+// Tag represents an immutable user-defined label for grouping messages
+type Tag struct {
+	id        uuid.UUID
+	userID    uuid.UUID
+	name      string
+	createdAt time.Time
+}
+
+// StoredTag represents persisted tag data
+type StoredTag struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
+
+// NewTag creates a new Tag with validation
+func NewTag(userID uuid.UUID, name string) common.Result[Tag] {
+	if userID == uuid.Nil {
+		return common.Err[Tag](errors.New("user ID cannot be nil"))
+	}
+
+	validName := validateTagName(name)
+	if validName.IsErr() {
+		return common.Err[Tag](validName.Error())
+	}
+
+	return common.Ok(Tag{
+		id:        uuid.New(),
+		userID:    userID,
+		name:      validName.Value(),
+		createdAt: time.Now(),
+	})
+}
+
+// RestoreTag rebuilds a Tag from stored data
+func RestoreTag(data StoredTag) common.Result[Tag] {
+	validName := validateTagName(data.Name)
+	if validName.IsErr() {
+		return common.Err[Tag](validName.Error())
+	}
+
+	return common.Ok(Tag{
+		id:        data.ID,
+		userID:    data.UserID,
+		name:      validName.Value(),
+		createdAt: data.CreatedAt,
+	})
+}
+
+// Getters for Tag
+func (t Tag) ID() uuid.UUID {
+	return t.id
+}
+
+func (t Tag) UserID() uuid.UUID {
+	return t.userID
+}
+
+func (t Tag) Name() string {
+	return t.name
+}
+
+func (t Tag) CreatedAt() time.Time {
+	return t.createdAt
+}
+
+// Key returns the case-insensitive identity of the tag name
+func (t Tag) Key() string {
+	return TagKey(t.name)
+}
+
+// WithName returns a new Tag with the given name
+func (t Tag) WithName(name string) common.Result[Tag] {
+	validName := validateTagName(name)
+	if validName.IsErr() {
+		return common.Err[Tag](validName.Error())
+	}
+
+	return common.Ok(Tag{
+		id:        t.id,
+		userID:    t.userID,
+		name:      validName.Value(),
+		createdAt: t.createdAt,
+	})
+}
+
+// TagKey normalizes a tag name for case-insensitive comparison
+func TagKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// NormalizeTagNames validates a list of tag names, dropping case-insensitive
+// duplicates while keeping the first spelling of each
+func NormalizeTagNames(names []string) common.Result[[]string] {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+
+	for _, name := range names {
+		validName := validateTagName(name)
+		if validName.IsErr() {
+			return common.Err[[]string](validName.Error())
+		}
+
+		key := TagKey(validName.Value())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, validName.Value())
+	}
+
+	if len(normalized) > MaxTagsPerMessage {
+		return common.Err[[]string](errors.New("too many tags (max 20 per message)"))
+	}
+
+	return common.Ok(normalized)
+}
+
+// ValidateTagMerge checks that source can be merged into target
+func ValidateTagMerge(source, target Tag) common.Result[bool] {
+	if source.id == target.id {
+		return common.Err[bool](errors.New("cannot merge a tag into itself"))
+	}
+
+	if source.userID != target.userID {
+		return common.Err[bool](errors.New("tags belong to different users"))
+	}
+
+	return common.Ok(true)
+}
+
+// validateTagName validates and normalizes whitespace in a tag name
+func validateTagName(name string) common.Result[string] {
+	name = strings.Join(strings.Fields(name), " ")
+
+	if name == "" {
+		return common.Err[string](errors.New("tag name cannot be empty"))
+	}
+
+	if utf8.RuneCountInString(name) > 50 {
+		return common.Err[string](errors.New("tag name is too long (max 50 characters)"))
+	}
+
+	if strings.ContainsAny(name, ",#") {
+		return common.Err[string](errors.New("tag name cannot contain ',' or '#'"))
+	}
+
+	return common.Ok(name)
+}
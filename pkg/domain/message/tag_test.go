@@ -0,0 +1,75 @@
+package message
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNormalizeTagNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []string
+		expected    []string
+		expectError bool
+	}{
+		{
+			name:     "keeps first spelling of case-insensitive duplicates",
+			input:    []string{"Travel", " travel ", "Family  Trips"},
+			expected: []string{"Travel", "Family Trips"},
+		},
+		{
+			name:     "empty list",
+			input:    nil,
+			expected: []string{},
+		},
+		{
+			name:        "blank name",
+			input:       []string{"   "},
+			expectError: true,
+		},
+		{
+			name:        "reserved characters",
+			input:       []string{"a,b"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NormalizeTagNames(tt.input)
+
+			if tt.expectError {
+				if result.IsOk() {
+					t.Fatalf("expected error but got %v", result.Value())
+				}
+				return
+			}
+
+			if result.IsErr() {
+				t.Fatalf("expected no error but got: %v", result.Error())
+			}
+			if !reflect.DeepEqual(result.Value(), tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result.Value())
+			}
+		})
+	}
+}
+
+func TestValidateTagMerge(t *testing.T) {
+	userID := uuid.New()
+	source := NewTag(userID, "work").Value()
+	target := NewTag(userID, "Work stuff").Value()
+	other := NewTag(uuid.New(), "work").Value()
+
+	if result := ValidateTagMerge(source, target); result.IsErr() {
+		t.Errorf("expected merge to be valid, got: %v", result.Error())
+	}
+	if result := ValidateTagMerge(source, source); result.IsOk() {
+		t.Error("expected merging a tag into itself to fail")
+	}
+	if result := ValidateTagMerge(source, other); result.IsOk() {
+		t.Error("expected merging tags of different users to fail")
+	}
+}
@@ -0,0 +1,108 @@
+// Package message contains reply threads for message domain
+package message
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// MaxThreadDepth limits how many earlier letters are quoted in a delivery
+const MaxThreadDepth = 20
+
+// ThreadEntry is an earlier letter of a thread, rendered for quoting
+type ThreadEntry struct {
+	MessageID   uuid.UUID
+	Title       string
+	HTMLContent string
+	TextContent string
+	WrittenAt   time.Time
+	DeliveredAt time.Time
+}
+
+// NewReply creates a letter answering a delivered one. The reply belongs to
+// the same user and links to its parent, forming a thread across years.
+func NewReply(parent Message, req CreateMessageRequest) common.Result[Message] {
+	if parent.Status() != StatusDelivered {
+		return common.Err[Message](errors.New("can only reply to delivered messages"))
+	}
+	if parent.UserID() != req.UserID {
+		return common.Err[Message](errors.New("can only reply to your own messages"))
+	}
+
+	req.ParentID = common.Some(parent.ID())
+	return NewMessage(req)
+}
+
+// ParentID returns the message this message replies to
+func (m Message) ParentID() common.Option[uuid.UUID] {
+	return m.parentID
+}
+
+// IsReply returns true if the message answers an earlier one
+func (m Message) IsReply() bool {
+	return m.parentID.IsSome()
+}
+
+// BuildThreadHistory renders the earlier letters of a thread for quoting.
+// Ancestors are ordered from the direct parent backwards; the history is
+// cut at MaxThreadDepth entries.
+func BuildThreadHistory(ancestors []Message) common.Result[[]ThreadEntry] {
+	if len(ancestors) > MaxThreadDepth {
+		ancestors = ancestors[:MaxThreadDepth]
+	}
+
+	history := make([]ThreadEntry, 0, len(ancestors))
+	for _, ancestor := range ancestors {
+		rendered := ancestor.RenderedContent()
+		if rendered.IsErr() {
+			return common.Err[[]ThreadEntry](rendered.Error())
+		}
+
+		history = append(history, ThreadEntry{
+			MessageID:   ancestor.ID(),
+			Title:       ancestor.Title(),
+			HTMLContent: rendered.Value().HTML,
+			TextContent: rendered.Value().Text,
+			WrittenAt:   ancestor.CreatedAt(),
+			DeliveredAt: ancestor.DeliveredAt().ValueOr(ancestor.DeliveryDate()),
+		})
+	}
+
+	return common.Ok(history)
+}
+
+// WithThread returns the delivery info with the quoted history of the
+// thread appended to the plain-text body
+func (mdi MessageDeliveryInfo) WithThread(history []ThreadEntry) MessageDeliveryInfo {
+	if len(history) == 0 {
+		return mdi
+	}
+
+	updated := mdi
+	updated.Thread = history
+	updated.Body = mdi.Body + "\n\n" + quoteThread(history)
+	return updated
+}
+
+// quoteThread formats the history as nested plain-text quotes, the direct
+// parent with one level of quoting and each earlier letter one level deeper
+func quoteThread(history []ThreadEntry) string {
+	var b strings.Builder
+	for depth, entry := range history {
+		prefix := strings.Repeat(">", depth+1) + " "
+		b.WriteString(prefix + "On " + entry.WrittenAt.Format("January 2, 2006") + " you wrote:\n")
+		if entry.Title != "" {
+			b.WriteString(prefix + entry.Title + "\n")
+			b.WriteString(strings.TrimRight(prefix, " ") + "\n")
+		}
+		for _, line := range strings.Split(strings.TrimRight(entry.TextContent, "\n"), "\n") {
+			b.WriteString(strings.TrimRight(prefix+line, " ") + "\n")
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
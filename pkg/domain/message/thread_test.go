@@ -0,0 +1,65 @@
+package message
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewReply(t *testing.T) {
+	userID := uuid.New()
+	req := CreateMessageRequest{
+		UserID:         userID,
+		Title:          "Dear future me",
+		Content:        "Did you finish the marathon?",
+		DeliveryDate:   time.Now().Add(48 * time.Hour),
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+	}
+
+	original := NewMessage(req)
+	if original.IsErr() {
+		t.Fatalf("NewMessage() error = %v", original.Error())
+	}
+	if NewReply(original.Value(), req).IsOk() {
+		t.Error("replying to an undelivered message should be rejected")
+	}
+
+	delivered := original.Value().WithStatus(StatusDelivered)
+	if delivered.IsErr() {
+		t.Fatalf("WithStatus() error = %v", delivered.Error())
+	}
+
+	other := req
+	other.UserID = uuid.New()
+	if NewReply(delivered.Value(), other).IsOk() {
+		t.Error("replying to another user's message should be rejected")
+	}
+
+	reply := NewReply(delivered.Value(), CreateMessageRequest{
+		UserID:         userID,
+		Title:          "Re: Dear future me",
+		Content:        "I did.\nNext: a triathlon?",
+		DeliveryDate:   time.Now().Add(72 * time.Hour),
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+	})
+	if reply.IsErr() {
+		t.Fatalf("NewReply() error = %v", reply.Error())
+	}
+	if parentID := reply.Value().ParentID(); parentID.IsNone() || parentID.Value() != delivered.Value().ID() {
+		t.Errorf("ParentID() = %v, want %v", parentID, delivered.Value().ID())
+	}
+
+	history := BuildThreadHistory([]Message{delivered.Value()})
+	if history.IsErr() {
+		t.Fatalf("BuildThreadHistory() error = %v", history.Error())
+	}
+
+	info := MessageDeliveryInfo{Body: "I did."}.WithThread(history.Value())
+	if len(info.Thread) != 1 || !strings.Contains(info.Body, "> Did you finish the marathon?") {
+		t.Errorf("expected the parent to be quoted, got %q", info.Body)
+	}
+}
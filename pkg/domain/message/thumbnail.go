@@ -0,0 +1,66 @@
+// Package message contains the thumbnails of image attachments
+package message
+
+// ThumbnailSize names a thumbnail generated for image attachments
+type ThumbnailSize string
+
+const (
+	ThumbnailSmall  ThumbnailSize = "small"  // Attachment lists
+	ThumbnailMedium ThumbnailSize = "medium" // Previews
+)
+
+// ThumbnailSizes lists the thumbnails generated for every image, smallest first
+var ThumbnailSizes = []ThumbnailSize{ThumbnailSmall, ThumbnailMedium}
+
+// MaxDimension returns the longest side of the thumbnail in pixels, or 0
+// for unknown sizes
+func (s ThumbnailSize) MaxDimension() int {
+	switch s {
+	case ThumbnailSmall:
+		return 160
+	case ThumbnailMedium:
+		return 640
+	default:
+		return 0
+	}
+}
+
+// CanThumbnail reports whether thumbnails can be generated for a file type.
+// Every image type except SVG, which is not a raster image, qualifies.
+func CanThumbnail(fileType string) bool {
+	return IsImageFile(fileType) && fileType != "image/svg+xml"
+}
+
+// WithThumbnail returns the attachment with the storage key of a thumbnail
+func (ma MessageAttachment) WithThumbnail(size ThumbnailSize, key string) MessageAttachment {
+	thumbnails := make(map[ThumbnailSize]string, len(ma.thumbnails)+1)
+	for existing, existingKey := range ma.thumbnails {
+		thumbnails[existing] = existingKey
+	}
+	thumbnails[size] = key
+
+	updated := ma
+	updated.thumbnails = thumbnails
+	return updated
+}
+
+// Thumbnails returns the storage keys of the generated thumbnails by size
+func (ma MessageAttachment) Thumbnails() map[ThumbnailSize]string {
+	thumbnails := make(map[ThumbnailSize]string, len(ma.thumbnails))
+	for size, key := range ma.thumbnails {
+		thumbnails[size] = key
+	}
+	return thumbnails
+}
+
+// StorageKeys returns the keys of every stored file of the attachment: the
+// original followed by its thumbnails, smallest first
+func (ma MessageAttachment) StorageKeys() []string {
+	keys := []string{ma.s3Key}
+	for _, size := range ThumbnailSizes {
+		if key, ok := ma.thumbnails[size]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,61 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCanThumbnail(t *testing.T) {
+	for fileType, want := range map[string]bool{
+		"image/jpeg":      true,
+		"image/webp":      true,
+		"image/svg+xml":   false,
+		"application/pdf": false,
+	} {
+		if got := CanThumbnail(fileType); got != want {
+			t.Errorf("CanThumbnail(%q) = %v, want %v", fileType, got, want)
+		}
+	}
+}
+
+func TestAttachmentThumbnails(t *testing.T) {
+	restored := RestoreMessageAttachment(StoredMessageAttachment{
+		ID:         uuid.New(),
+		MessageID:  uuid.New(),
+		FileName:   "beach.jpg",
+		FileType:   "image/jpeg",
+		StorageKey: "uploads/beach.jpg",
+		FileSize:   2048,
+		UploadedAt: time.Now(),
+		Thumbnails: map[ThumbnailSize]string{
+			ThumbnailMedium: "uploads/beach-medium.jpg",
+			"huge":          "uploads/beach-huge.jpg",
+		},
+	})
+	if restored.IsErr() {
+		t.Fatalf("RestoreMessageAttachment() error = %v", restored.Error())
+	}
+
+	original := restored.Value()
+	if _, ok := original.Thumbnails()["huge"]; ok {
+		t.Error("unknown thumbnail sizes should be dropped")
+	}
+
+	updated := original.WithThumbnail(ThumbnailSmall, "uploads/beach-small.jpg")
+	if len(original.Thumbnails()) != 1 {
+		t.Errorf("WithThumbnail() changed the original attachment: %v", original.Thumbnails())
+	}
+
+	keys := updated.StorageKeys()
+	want := []string{"uploads/beach.jpg", "uploads/beach-small.jpg", "uploads/beach-medium.jpg"}
+	if len(keys) != len(want) {
+		t.Fatalf("StorageKeys() = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("StorageKeys()[%d] = %q, want %q", i, keys[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package message contains timezone moves for message domain
+package message
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// TimezonePolicy decides what happens to scheduled letters when their
+// author moves to another timezone
+type TimezonePolicy string
+
+const (
+	// TimezoneKeepInstant keeps the moment of delivery; the local delivery
+	// time changes with the zone
+	TimezoneKeepInstant TimezonePolicy = "keep_instant"
+	// TimezoneKeepLocalTime keeps the wall-clock delivery time, so a letter
+	// due at 09:00 is delivered at 09:00 in the new zone
+	TimezoneKeepLocalTime TimezonePolicy = "keep_local_time"
+)
+
+// ParseTimezonePolicy validates a timezone policy. The default keeps the
+// instant, which is how letters behaved before a policy could be chosen.
+func ParseTimezonePolicy(value string) common.Result[TimezonePolicy] {
+	switch policy := TimezonePolicy(value); policy {
+	case TimezoneKeepInstant, TimezoneKeepLocalTime:
+		return common.Ok(policy)
+	case "":
+		return common.Ok(TimezoneKeepInstant)
+	default:
+		return common.Err[TimezonePolicy](fmt.Errorf("invalid timezone policy %q (use %s or %s)", value, TimezoneKeepInstant, TimezoneKeepLocalTime))
+	}
+}
+
+// WithTimezoneMoved returns the message moved to another timezone under the
+// policy. Keeping the local time moves the delivery, the surprise range and
+// the milestone anchor to the same wall-clock time in the new zone; a letter
+// that would then be due in the past keeps its instant instead. Keeping the
+// instant only changes the zone, and re-expresses an anchor so it still
+// resolves to the same moment.
+func (m Message) WithTimezoneMoved(timezone string, policy TimezonePolicy) common.Result[Message] {
+	to, err := time.LoadLocation(timezone)
+	if err != nil {
+		return common.Err[Message](errors.New("invalid timezone: " + timezone))
+	}
+	from, err := time.LoadLocation(m.timezone)
+	if err != nil {
+		from = time.UTC
+	}
+	if !m.IsEditable() {
+		return common.Err[Message](errors.New("only scheduled messages can be moved to another timezone"))
+	}
+
+	if policy == TimezoneKeepLocalTime && m.checkIn.IsNone() {
+		if moved := m.withLocalTimeKept(from, to, timezone); moved.IsOk() {
+			return moved
+		}
+	}
+
+	updated := m
+	updated.timezone = timezone
+	updated.updatedAt = time.Now()
+	if m.anchor.IsSome() {
+		intended := m.intendedDate.ValueOr(m.deliveryDate)
+		updated.anchor = common.Some(m.anchor.Value().withInstantKept(intended, to))
+	}
+	return common.Ok(updated)
+}
+
+// withLocalTimeKept moves the delivery to the same wall-clock time in to. It
+// fails if the moved delivery is no longer a valid future date.
+func (m Message) withLocalTimeKept(from, to *time.Location, timezone string) common.Result[Message] {
+	updated := m
+	updated.timezone = timezone
+	updated.updatedAt = time.Now()
+
+	switch {
+	case m.anchor.IsSome():
+		deliveryDate := resolveAnchor(m.anchor.Value(), timezone)
+		if deliveryDate.IsErr() {
+			return common.Err[Message](deliveryDate.Error())
+		}
+		updated.deliveryDate = deliveryDate.Value()
+
+	case m.surprise.IsSome():
+		window := m.surprise.Value()
+		window.earliest = sameWallClock(window.earliest, from, to)
+		window.latest = sameWallClock(window.latest, from, to)
+		if err := validateSurpriseWindow(window, timezone); err != nil {
+			return common.Err[Message](err)
+		}
+		updated.surprise = common.Some(window)
+		updated.deliveryDate = window.Draw()
+
+	default:
+		// Preferences shifted the delivery away from the author's time;
+		// scheduling shifts the moved time again if it needs to
+		deliveryDate := sameWallClock(m.intendedDate.ValueOr(m.deliveryDate), from, to)
+		if result := validateDeliveryDate(deliveryDate, timezone, false); result.IsErr() {
+			return common.Err[Message](result.Error())
+		}
+		updated.deliveryDate = deliveryDate
+	}
+
+	updated.intendedDate = common.None[time.Time]()
+	return common.Ok(updated)
+}
+
+// MoveMessagesToTimezone moves the author's scheduled letters from one
+// timezone to another under the policy and returns the messages that
+// changed. Letters written for a different zone than the one the author
+// leaves are kept as they are.
+func MoveMessagesToTimezone(messages []Message, from, to string, policy TimezonePolicy) common.Result[[]Message] {
+	if from == to {
+		return common.Ok([]Message{})
+	}
+
+	moved := []Message{}
+	for _, msg := range messages {
+		if msg.timezone != from || !msg.IsEditable() {
+			continue
+		}
+
+		result := msg.WithTimezoneMoved(to, policy)
+		if result.IsErr() {
+			return common.Err[[]Message](fmt.Errorf("letter %q: %w", msg.title, result.Error()))
+		}
+		moved = append(moved, result.Value())
+	}
+	return common.Ok(moved)
+}
+
+// withInstantKept returns the anchor re-expressed in another zone so that it
+// resolves to the same instant: the time of day becomes the local time there
+// and a change of calendar day is absorbed by the day offset
+func (a MilestoneAnchor) withInstantKept(instant time.Time, loc *time.Location) MilestoneAnchor {
+	local := instant.In(loc)
+	day := a.milestone.Anniversary(a.occurrence).AddDate(0, a.offsetMonths, a.offsetDays)
+	localDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	updated := a
+	updated.offsetDays += int(localDay.Sub(day).Hours() / 24)
+	updated.at = user.TimeOfDay(local.Hour()*60 + local.Minute())
+	return updated
+}
+
+// sameWallClock returns the instant showing the same wall-clock time in to
+// as t shows in from
+func sameWallClock(t time.Time, from, to *time.Location) time.Time {
+	local := t.In(from)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), to).UTC()
+}
@@ -0,0 +1,115 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+func TestWithTimezoneMoved(t *testing.T) {
+	saigon, _ := time.LoadLocation("Asia/Ho_Chi_Minh")
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	year := time.Now().Year() + 1
+
+	msg := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Morning",
+		Content:        "Coffee first.",
+		DeliveryDate:   time.Date(year, time.July, 1, 9, 0, 0, 0, saigon),
+		Timezone:       "Asia/Ho_Chi_Minh",
+		DeliveryMethod: DeliveryEmail,
+		Recurrence:     RecurrenceYearly,
+	}).Value()
+
+	local := msg.WithTimezoneMoved("Europe/Berlin", TimezoneKeepLocalTime)
+	if local.IsErr() {
+		t.Fatalf("WithTimezoneMoved() error = %v", local.Error())
+	}
+	if want := time.Date(year, time.July, 1, 9, 0, 0, 0, berlin); !local.Value().DeliveryDate().Equal(want) {
+		t.Errorf("keep local time: DeliveryDate() = %v, want %v", local.Value().DeliveryDate(), want)
+	}
+	if local.Value().Timezone() != "Europe/Berlin" || local.Value().Recurrence() != RecurrenceYearly {
+		t.Error("keep local time should move the zone and keep the recurrence")
+	}
+
+	instant := msg.WithTimezoneMoved("Europe/Berlin", TimezoneKeepInstant)
+	if instant.IsErr() || !instant.Value().DeliveryDate().Equal(msg.DeliveryDate()) || instant.Value().Timezone() != "Europe/Berlin" {
+		t.Errorf("keep instant should only change the zone, got %v in %s", instant.Value().DeliveryDate(), instant.Value().Timezone())
+	}
+
+	if msg.WithTimezoneMoved("Mars/Olympus", TimezoneKeepInstant).IsOk() {
+		t.Error("invalid timezones should be rejected")
+	}
+}
+
+func TestWithTimezoneMovedAnchored(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	birthday := user.NewMilestone(user.MilestoneBirthday, "", time.Date(time.Now().Year()-30, time.March, 10, 0, 0, 0, 0, time.UTC)).Value()
+	anchor := NewMilestoneAnchor(birthday, 40, 0, 0, user.TimeOfDay(8*60)).Value()
+
+	msg := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Forty",
+		Content:        "Happy birthday.",
+		Timezone:       "Asia/Ho_Chi_Minh",
+		DeliveryMethod: DeliveryEmail,
+		Anchor:         common.Some(anchor),
+	}).Value()
+
+	// 08:00 in Ho Chi Minh City is the evening before in New York
+	instant := msg.WithTimezoneMoved("America/New_York", TimezoneKeepInstant).Value()
+	moved := instant.Anchor().Value()
+	if !instant.DeliveryDate().Equal(msg.DeliveryDate()) || !moved.Resolve("America/New_York").Value().Equal(msg.DeliveryDate()) {
+		t.Errorf("keep instant: anchor resolves to %v, want %v", moved.Resolve("America/New_York").Value(), msg.DeliveryDate())
+	}
+	if moved.OffsetDays() != -1 || moved.At() != user.TimeOfDay(21*60) {
+		t.Errorf("keep instant: anchor = %+d days at %s, want -1 days at 21:00", moved.OffsetDays(), moved.At())
+	}
+
+	local := msg.WithTimezoneMoved("America/New_York", TimezoneKeepLocalTime).Value()
+	if want := time.Date(birthday.Anniversary(40).Year(), time.March, 10, 8, 0, 0, 0, newYork); !local.DeliveryDate().Equal(want) {
+		t.Errorf("keep local time: DeliveryDate() = %v, want %v", local.DeliveryDate(), want)
+	}
+	if local.Anchor().Value().At() != user.TimeOfDay(8*60) {
+		t.Error("keep local time should keep the anchor time")
+	}
+}
+
+func TestMoveMessagesToTimezone(t *testing.T) {
+	deliveryDate := time.Now().Add(24 * time.Hour)
+	newMessage := func(timezone string) Message {
+		return NewMessage(CreateMessageRequest{
+			UserID:         uuid.New(),
+			Title:          "Letter",
+			Content:        "Hello.",
+			DeliveryDate:   deliveryDate,
+			Timezone:       timezone,
+			DeliveryMethod: DeliveryEmail,
+		}).Value()
+	}
+
+	followed := newMessage("Asia/Ho_Chi_Minh")
+	pinned := newMessage("Asia/Tokyo")
+
+	result := MoveMessagesToTimezone([]Message{followed, pinned}, "Asia/Ho_Chi_Minh", "Europe/Berlin", TimezoneKeepInstant)
+	if result.IsErr() {
+		t.Fatalf("MoveMessagesToTimezone() error = %v", result.Error())
+	}
+	if len(result.Value()) != 1 || result.Value()[0].ID() != followed.ID() {
+		t.Fatalf("expected only the letter in the old zone to move, got %d", len(result.Value()))
+	}
+
+	if moved := MoveMessagesToTimezone([]Message{followed}, "Asia/Ho_Chi_Minh", "Asia/Ho_Chi_Minh", TimezoneKeepLocalTime); len(moved.Value()) != 0 {
+		t.Error("staying in the same zone should move nothing")
+	}
+
+	if policy := ParseTimezonePolicy(""); policy.Value() != TimezoneKeepInstant {
+		t.Errorf("default policy = %s, want %s", policy.Value(), TimezoneKeepInstant)
+	}
+	if ParseTimezonePolicy("keep_vibes").IsOk() {
+		t.Error("unknown policies should be rejected")
+	}
+}
@@ -0,0 +1,901 @@
+// Package message contains import and export formats for message domain
+package message
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// TransferFormat represents a bulk import/export file format
+type TransferFormat string
+
+const (
+	TransferJSONLines TransferFormat = "jsonl"
+	TransferCSV       TransferFormat = "csv"
+	TransferMbox      TransferFormat = "mbox"
+)
+
+// MaxImportRows limits how many messages a single import may contain
+const MaxImportRows = 1000
+
+// maxTransferLineBytes bounds a single JSON Lines entry
+const maxTransferLineBytes = 1 << 20
+
+// transferColumns is the CSV header written on export and recognised on import
+var transferColumns = []string{
+	"title", "content", "content_format", "delivery_date", "timezone",
+	"delivery_method", "recurrence", "reminder_minutes", "tags", "status",
+	"surprise_until", "milestone", "milestone_date", "milestone_occurrence",
+	"milestone_offset_months", "milestone_offset_days", "holiday_country",
+	"holiday", "check_in_interval_days", "check_in_grace_days",
+	"check_in_recipients",
+}
+
+// Headers carrying message settings in mbox exports
+const (
+	mboxHeaderDeliveryDate   = "X-Dear-Future-Delivery-Date"
+	mboxHeaderTimezone       = "X-Dear-Future-Timezone"
+	mboxHeaderDeliveryMethod = "X-Dear-Future-Delivery-Method"
+	mboxHeaderRecurrence     = "X-Dear-Future-Recurrence"
+	mboxHeaderReminder       = "X-Dear-Future-Reminder-Minutes"
+	mboxHeaderContentFormat  = "X-Dear-Future-Content-Format"
+	mboxHeaderTags           = "X-Dear-Future-Tags"
+	mboxHeaderStatus         = "X-Dear-Future-Status"
+	mboxHeaderSurpriseUntil  = "X-Dear-Future-Surprise-Until"
+	mboxHeaderMilestone      = "X-Dear-Future-Milestone"
+	mboxHeaderMilestoneDate  = "X-Dear-Future-Milestone-Date"
+	mboxHeaderOccurrence     = "X-Dear-Future-Milestone-Occurrence"
+	mboxHeaderOffsetMonths   = "X-Dear-Future-Milestone-Offset-Months"
+	mboxHeaderOffsetDays     = "X-Dear-Future-Milestone-Offset-Days"
+	mboxHeaderHolidayCountry = "X-Dear-Future-Holiday-Country"
+	mboxHeaderHoliday        = "X-Dear-Future-Holiday"
+	mboxHeaderCheckInEvery   = "X-Dear-Future-Check-In-Interval-Days"
+	mboxHeaderCheckInGrace   = "X-Dear-Future-Check-In-Grace-Days"
+	mboxHeaderCheckInTo      = "X-Dear-Future-Check-In-Recipients"
+)
+
+// mboxFromLine matches body lines that need ">" quoting in mboxrd format
+var mboxFromLine = regexp.MustCompile(`^>*From `)
+
+// TransferRecord is the portable representation of a message. On import,
+// delivered and cancelled records are restored as archived letters with
+// their past delivery date; any other status is imported as a new letter,
+// see ToMessage. For surprise messages the
+// delivery date is the earliest bound and SurpriseUntil the latest; the
+// drawn time is not exported and importing draws a new one.
+//
+// Letters delivered on a milestone or a holiday carry it, and their delivery
+// time of day is the local time of the delivery date; the date is resolved
+// again on import. Check-in letters carry their settings and start a new
+// check-in period when imported.
+type TransferRecord struct {
+	Title                 string   `json:"title"`
+	Content               string   `json:"content"`
+	ContentFormat         string   `json:"content_format,omitempty"`
+	DeliveryDate          string   `json:"delivery_date"`
+	Timezone              string   `json:"timezone,omitempty"`
+	DeliveryMethod        string   `json:"delivery_method,omitempty"`
+	Recurrence            string   `json:"recurrence,omitempty"`
+	ReminderMinutes       *int     `json:"reminder_minutes,omitempty"`
+	Tags                  []string `json:"tags,omitempty"`
+	Status                string   `json:"status,omitempty"`
+	SurpriseUntil         string   `json:"surprise_until,omitempty"`
+	Milestone             string   `json:"milestone,omitempty"`
+	MilestoneDate         string   `json:"milestone_date,omitempty"` // YYYY-MM-DD the delivery date was counted from
+	MilestoneOccurrence   int      `json:"milestone_occurrence,omitempty"`
+	MilestoneOffsetMonths int      `json:"milestone_offset_months,omitempty"`
+	MilestoneOffsetDays   int      `json:"milestone_offset_days,omitempty"`
+	HolidayCountry        string   `json:"holiday_country,omitempty"`
+	Holiday               string   `json:"holiday,omitempty"`
+	CheckInIntervalDays   int      `json:"check_in_interval_days,omitempty"`
+	CheckInGraceDays      int      `json:"check_in_grace_days,omitempty"`
+	CheckInRecipients     []string `json:"check_in_recipients,omitempty"`
+}
+
+// TransferRow is one decoded entry of an import file. Row is the 1-based
+// line number for JSON Lines and CSV, and the message number for mbox.
+type TransferRow struct {
+	Row    int
+	Record TransferRecord
+	Err    error
+}
+
+// ParseTransferFormat parses a format name, accepting common aliases
+func ParseTransferFormat(name string) common.Result[TransferFormat] {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "jsonl", "ndjson", "json":
+		return common.Ok(TransferJSONLines)
+	case "csv":
+		return common.Ok(TransferCSV)
+	case "mbox":
+		return common.Ok(TransferMbox)
+	default:
+		return common.Err[TransferFormat](errors.New("invalid format (must be 'jsonl', 'csv' or 'mbox')"))
+	}
+}
+
+// ContentType returns the MIME type used when serving an export
+func (f TransferFormat) ContentType() string {
+	switch f {
+	case TransferCSV:
+		return "text/csv; charset=utf-8"
+	case TransferMbox:
+		return "application/mbox"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// NewTransferRecord converts a message and its tag names into a portable record
+func NewTransferRecord(m Message, tags []string) TransferRecord {
+	record := TransferRecord{
+		Title:          m.Title(),
+		Content:        m.Content(),
+		ContentFormat:  string(m.ContentFormat()),
+		DeliveryDate:   m.DeliveryDate().UTC().Format(time.RFC3339),
+		Timezone:       m.Timezone(),
+		DeliveryMethod: string(m.DeliveryMethod()),
+		Recurrence:     string(m.Recurrence()),
+		Tags:           tags,
+		Status:         string(m.Status()),
+	}
+
+	if m.ReminderMinutes().IsSome() {
+		minutes := m.ReminderMinutes().Value()
+		record.ReminderMinutes = &minutes
+	}
+
+	if m.IsDeliveryDateHidden() {
+		window := m.Surprise().Value()
+		record.DeliveryDate = window.Earliest().UTC().Format(time.RFC3339)
+		record.SurpriseUntil = window.Latest().UTC().Format(time.RFC3339)
+	}
+
+	if anchor := m.Anchor(); anchor.IsSome() {
+		record.Milestone = anchor.Value().Milestone()
+		record.MilestoneDate = anchor.Value().MilestoneDate().Format(transferDayLayout)
+		record.MilestoneOccurrence = anchor.Value().Occurrence()
+		record.MilestoneOffsetMonths = anchor.Value().OffsetMonths()
+		record.MilestoneOffsetDays = anchor.Value().OffsetDays()
+	}
+
+	if target := m.HolidayTarget(); target.IsSome() {
+		record.HolidayCountry = target.Value().Country()
+		record.Holiday = target.Value().Holiday()
+	}
+
+	if checkIn := m.CheckIn(); checkIn.IsSome() {
+		record.CheckInIntervalDays = int(checkIn.Value().Interval() / (24 * time.Hour))
+		record.CheckInGraceDays = int(checkIn.Value().GracePeriod() / (24 * time.Hour))
+		record.CheckInRecipients = checkIn.Value().Recipients()
+	}
+
+	return record
+}
+
+// transferDayLayout formats milestone dates
+const transferDayLayout = "2006-01-02"
+
+// ToCreateRequest converts a record into a create request, applying the same
+// defaults as the API. The result still has to pass NewMessage validation.
+func (r TransferRecord) ToCreateRequest(userID uuid.UUID) common.Result[CreateMessageRequest] {
+	if strings.TrimSpace(r.DeliveryDate) == "" {
+		return common.Err[CreateMessageRequest](errors.New("delivery_date is required"))
+	}
+
+	timezone := r.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	deliveryDate := parseTransferDate(r.DeliveryDate, timezone)
+	if deliveryDate.IsErr() {
+		return common.Err[CreateMessageRequest](deliveryDate.Error())
+	}
+
+	method := DeliveryMethod(r.DeliveryMethod)
+	if method == "" {
+		method = DeliveryEmail
+	}
+
+	recurrence := RecurrencePattern(r.Recurrence)
+	if recurrence == "" {
+		recurrence = RecurrenceNone
+	}
+
+	reminder := common.None[int]()
+	if r.ReminderMinutes != nil {
+		reminder = common.Some(*r.ReminderMinutes)
+	}
+
+	surprise := common.None[SurpriseWindow]()
+	if strings.TrimSpace(r.SurpriseUntil) != "" {
+		latest := parseTransferDate(r.SurpriseUntil, timezone)
+		if latest.IsErr() {
+			return common.Err[CreateMessageRequest](errors.New("invalid surprise_until: " + latest.Error().Error()))
+		}
+		window := NewSurpriseWindow(deliveryDate.Value(), latest.Value())
+		if window.IsErr() {
+			return common.Err[CreateMessageRequest](window.Error())
+		}
+		surprise = common.Some(window.Value())
+	}
+
+	// Milestone and holiday letters are delivered at the time of day of
+	// their exported delivery date
+	at := DefaultAnchorTime
+	if loc, err := time.LoadLocation(timezone); err == nil {
+		local := deliveryDate.Value().In(loc)
+		at = user.TimeOfDay(local.Hour()*60 + local.Minute())
+	}
+
+	anchor := common.None[MilestoneAnchor]()
+	if strings.TrimSpace(r.Milestone) != "" {
+		milestoneDate, err := time.Parse(transferDayLayout, strings.TrimSpace(r.MilestoneDate))
+		if err != nil {
+			return common.Err[CreateMessageRequest](errors.New("invalid milestone_date (use YYYY-MM-DD)"))
+		}
+		restored := RestoreMilestoneAnchor(StoredMilestoneAnchor{
+			Milestone:     r.Milestone,
+			Occurrence:    r.MilestoneOccurrence,
+			OffsetMonths:  r.MilestoneOffsetMonths,
+			OffsetDays:    r.MilestoneOffsetDays,
+			At:            at,
+			MilestoneDate: milestoneDate,
+		})
+		if restored.IsErr() {
+			return common.Err[CreateMessageRequest](restored.Error())
+		}
+		anchor = common.Some(restored.Value())
+	}
+
+	holidayTarget := common.None[HolidayTarget]()
+	if strings.TrimSpace(r.Holiday) != "" {
+		target := NewHolidayTarget(r.HolidayCountry, r.Holiday, at)
+		if target.IsErr() {
+			return common.Err[CreateMessageRequest](target.Error())
+		}
+		holidayTarget = common.Some(target.Value())
+	}
+
+	checkIn := common.None[CheckInSettings]()
+	if r.CheckInIntervalDays != 0 || r.CheckInGraceDays != 0 {
+		settings := NewCheckInSettings(time.Duration(r.CheckInIntervalDays)*24*time.Hour, time.Duration(r.CheckInGraceDays)*24*time.Hour, r.CheckInRecipients)
+		if settings.IsErr() {
+			return common.Err[CreateMessageRequest](settings.Error())
+		}
+		checkIn = common.Some(settings.Value())
+	}
+
+	return common.Ok(CreateMessageRequest{
+		UserID:          userID,
+		Title:           r.Title,
+		Content:         r.Content,
+		ContentFormat:   ContentFormat(r.ContentFormat),
+		DeliveryDate:    deliveryDate.Value(),
+		Timezone:        timezone,
+		DeliveryMethod:  method,
+		Recurrence:      recurrence,
+		ReminderMinutes: reminder,
+		CheckIn:         checkIn,
+		Surprise:        surprise,
+		Anchor:          anchor,
+		Holiday:         holidayTarget,
+	})
+}
+
+// ToMessage converts a record into a message of userID. Delivered and
+// cancelled records are restored as archived letters with the past delivery
+// date they were exported with, so a whole export can be imported again.
+// Every other record becomes a new letter through NewMessage and needs a
+// delivery date in the future.
+func (r TransferRecord) ToMessage(userID uuid.UUID) common.Result[Message] {
+	req := r.ToCreateRequest(userID)
+	if req.IsErr() {
+		return common.Err[Message](req.Error())
+	}
+
+	if status := ParseMessageStatus(r.Status); status.IsOk() {
+		switch status.Value() {
+		case StatusDelivered, StatusCancelled:
+			return archivedMessage(req.Value(), status.Value())
+		}
+	}
+	return NewMessage(req.Value())
+}
+
+// archivedMessage creates a letter that is not sent again. Its content is
+// validated like a new letter's; anchors, holidays, check-ins and surprise
+// ranges are dropped as they only schedule future deliveries.
+func archivedMessage(req CreateMessageRequest, status MessageStatus) common.Result[Message] {
+	title := validateTitle(req.Title)
+	if title.IsErr() {
+		return common.Err[Message](title.Error())
+	}
+	content := validateContent(req.Content)
+	if content.IsErr() {
+		return common.Err[Message](content.Error())
+	}
+	format := validateContentFormat(req.ContentFormat)
+	if format.IsErr() {
+		return common.Err[Message](format.Error())
+	}
+	method := validateDeliveryMethod(req.DeliveryMethod)
+	if method.IsErr() {
+		return common.Err[Message](method.Error())
+	}
+	recurrence := validateRecurrence(req.Recurrence)
+	if recurrence.IsErr() {
+		return common.Err[Message](recurrence.Error())
+	}
+	reminder := validateReminderMinutes(req.ReminderMinutes)
+	if reminder.IsErr() {
+		return common.Err[Message](reminder.Error())
+	}
+
+	deliveredAt := common.None[time.Time]()
+	if status == StatusDelivered {
+		deliveredAt = common.Some(req.DeliveryDate)
+	}
+
+	now := time.Now()
+	return RestoreMessage(StoredMessage{
+		ID:              uuid.New(),
+		UserID:          req.UserID,
+		Title:           title.Value(),
+		Content:         content.Value(),
+		ContentFormat:   format.Value(),
+		DeliveryDate:    req.DeliveryDate,
+		Timezone:        req.Timezone,
+		Status:          status,
+		DeliveryMethod:  method.Value(),
+		Recurrence:      recurrence.Value(),
+		ReminderMinutes: reminder.Value(),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		DeliveredAt:     deliveredAt,
+	})
+}
+
+// parseTransferDate accepts RFC3339, or a date and optional time that is
+// interpreted in the record's timezone (as spreadsheets usually store them)
+func parseTransferDate(value, timezone string) common.Result[time.Time] {
+	value = strings.TrimSpace(value)
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return common.Ok(parsed)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return common.Err[time.Time](errors.New("invalid timezone: " + timezone))
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if parsed, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return common.Ok(parsed)
+		}
+	}
+
+	return common.Err[time.Time](errors.New("invalid delivery_date format (use RFC3339 or YYYY-MM-DD HH:MM)"))
+}
+
+// DecodeTransfer reads every entry of an import file. Problems with a single
+// entry are reported on its row; only an unreadable file is an error.
+func DecodeTransfer(format TransferFormat, r io.Reader) common.Result[[]TransferRow] {
+	var rows common.Result[[]TransferRow]
+	switch format {
+	case TransferJSONLines:
+		rows = decodeJSONLines(r)
+	case TransferCSV:
+		rows = decodeCSV(r)
+	case TransferMbox:
+		rows = decodeMbox(r)
+	default:
+		return common.Err[[]TransferRow](errors.New("unsupported format: " + string(format)))
+	}
+
+	if rows.IsOk() && len(rows.Value()) > MaxImportRows {
+		return common.Err[[]TransferRow](fmt.Errorf("too many messages (max %d per import)", MaxImportRows))
+	}
+	return rows
+}
+
+// EncodeTransfer writes records in the given format and returns how many were written
+func EncodeTransfer(format TransferFormat, w io.Writer, records []TransferRecord) common.Result[int] {
+	var err error
+	switch format {
+	case TransferJSONLines:
+		err = encodeJSONLines(w, records)
+	case TransferCSV:
+		err = encodeCSV(w, records)
+	case TransferMbox:
+		err = encodeMbox(w, records)
+	default:
+		err = errors.New("unsupported format: " + string(format))
+	}
+
+	if err != nil {
+		return common.Err[int](err)
+	}
+	return common.Ok(len(records))
+}
+
+func decodeJSONLines(r io.Reader) common.Result[[]TransferRow] {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxTransferLineBytes)
+
+	rows := []TransferRow{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		row := TransferRow{Row: line}
+		if err := json.Unmarshal([]byte(text), &row.Record); err != nil {
+			row.Err = errors.New("invalid JSON: " + err.Error())
+		}
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return common.Err[[]TransferRow](fmt.Errorf("failed to read JSON Lines: %w", err))
+	}
+	return common.Ok(rows)
+}
+
+func encodeJSONLines(w io.Writer, records []TransferRecord) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write JSON Lines: %w", err)
+		}
+	}
+	return nil
+}
+
+func decodeCSV(r io.Reader) common.Result[[]TransferRow] {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return common.Err[[]TransferRow](fmt.Errorf("failed to read CSV header: %w", err))
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"title", "content", "delivery_date"} {
+		if _, ok := columns[required]; !ok {
+			return common.Err[[]TransferRow](errors.New("CSV header is missing required column: " + required))
+		}
+	}
+
+	field := func(fields []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	rows := []TransferRow{}
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := TransferRow{Row: line}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return common.Err[[]TransferRow](fmt.Errorf("failed to read CSV: %w", err))
+			}
+			row.Row = parseErr.StartLine
+			row.Err = errors.New("invalid CSV row: " + parseErr.Err.Error())
+			rows = append(rows, row)
+			continue
+		}
+
+		row.Record = TransferRecord{
+			Title:             field(fields, "title"),
+			Content:           field(fields, "content"),
+			ContentFormat:     field(fields, "content_format"),
+			DeliveryDate:      field(fields, "delivery_date"),
+			Timezone:          field(fields, "timezone"),
+			DeliveryMethod:    field(fields, "delivery_method"),
+			Recurrence:        field(fields, "recurrence"),
+			Tags:              splitTransferTags(field(fields, "tags")),
+			Status:            field(fields, "status"),
+			SurpriseUntil:     field(fields, "surprise_until"),
+			Milestone:         field(fields, "milestone"),
+			MilestoneDate:     field(fields, "milestone_date"),
+			HolidayCountry:    field(fields, "holiday_country"),
+			Holiday:           field(fields, "holiday"),
+			CheckInRecipients: splitTransferTags(field(fields, "check_in_recipients")),
+		}
+
+		if reminder := strings.TrimSpace(field(fields, "reminder_minutes")); reminder != "" {
+			minutes, err := strconv.Atoi(reminder)
+			if err != nil {
+				row.Err = errors.New("invalid reminder_minutes: " + reminder)
+			} else {
+				row.Record.ReminderMinutes = &minutes
+			}
+		}
+
+		for _, number := range []struct {
+			column string
+			value  *int
+		}{
+			{"milestone_occurrence", &row.Record.MilestoneOccurrence},
+			{"milestone_offset_months", &row.Record.MilestoneOffsetMonths},
+			{"milestone_offset_days", &row.Record.MilestoneOffsetDays},
+			{"check_in_interval_days", &row.Record.CheckInIntervalDays},
+			{"check_in_grace_days", &row.Record.CheckInGraceDays},
+		} {
+			if err := parseTransferInt(field(fields, number.column), number.value); err != nil && row.Err == nil {
+				row.Err = errors.New("invalid " + number.column + ": " + field(fields, number.column))
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return common.Ok(rows)
+}
+
+func encodeCSV(w io.Writer, records []TransferRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(transferColumns); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	for _, record := range records {
+		reminder := ""
+		if record.ReminderMinutes != nil {
+			reminder = strconv.Itoa(*record.ReminderMinutes)
+		}
+
+		err := writer.Write([]string{
+			record.Title,
+			record.Content,
+			record.ContentFormat,
+			record.DeliveryDate,
+			record.Timezone,
+			record.DeliveryMethod,
+			record.Recurrence,
+			reminder,
+			strings.Join(record.Tags, ","),
+			record.Status,
+			record.SurpriseUntil,
+			record.Milestone,
+			record.MilestoneDate,
+			formatTransferInt(record.MilestoneOccurrence, record.Milestone != ""),
+			formatTransferInt(record.MilestoneOffsetMonths, false),
+			formatTransferInt(record.MilestoneOffsetDays, false),
+			record.HolidayCountry,
+			record.Holiday,
+			formatTransferInt(record.CheckInIntervalDays, false),
+			formatTransferInt(record.CheckInGraceDays, false),
+			strings.Join(record.CheckInRecipients, ","),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// parseTransferInt parses an optional number column, leaving dest alone when
+// the value is empty
+func parseTransferInt(value string, dest *int) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*dest = parsed
+	return nil
+}
+
+// formatTransferInt formats an optional number column, empty for zero
+// unless the zero is meaningful
+func formatTransferInt(value int, keepZero bool) string {
+	if value == 0 && !keepZero {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+// splitTransferTags splits a comma separated list, such as tags or check-in
+// recipients; tag names cannot contain commas
+func splitTransferTags(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// decodeMbox reads an mboxrd/mboxo file. Messages exported by this system
+// carry their settings in X-Dear-Future headers; other mail falls back to the
+// Date header for delivery and the text/plain body for content.
+func decodeMbox(r io.Reader) common.Result[[]TransferRow] {
+	reader := bufio.NewReader(r)
+
+	rows := []TransferRow{}
+	var current *bytes.Buffer
+	flush := func() {
+		if current == nil {
+			return
+		}
+		row := TransferRow{Row: len(rows) + 1}
+		row.Record, row.Err = parseMboxMessage(current.Bytes())
+		rows = append(rows, row)
+		current = nil
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			switch {
+			case strings.HasPrefix(line, "From "):
+				flush()
+				current = &bytes.Buffer{}
+			case current == nil:
+				if strings.TrimSpace(line) != "" {
+					return common.Err[[]TransferRow](errors.New("invalid mbox: file must start with a 'From ' line"))
+				}
+			case mboxFromLine.MatchString(line):
+				current.WriteString(line[1:])
+			default:
+				current.WriteString(line)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return common.Err[[]TransferRow](fmt.Errorf("failed to read mbox: %w", err))
+		}
+	}
+	flush()
+
+	return common.Ok(rows)
+}
+
+func parseMboxMessage(raw []byte) (TransferRecord, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return TransferRecord{}, errors.New("invalid message: " + err.Error())
+	}
+
+	decoder := &mime.WordDecoder{}
+	header := func(name string) string {
+		value := msg.Header.Get(name)
+		if decoded, err := decoder.DecodeHeader(value); err == nil {
+			return decoded
+		}
+		return value
+	}
+
+	record := TransferRecord{
+		Title:             header("Subject"),
+		DeliveryDate:      header(mboxHeaderDeliveryDate),
+		Timezone:          header(mboxHeaderTimezone),
+		DeliveryMethod:    header(mboxHeaderDeliveryMethod),
+		Recurrence:        header(mboxHeaderRecurrence),
+		ContentFormat:     header(mboxHeaderContentFormat),
+		Tags:              splitTransferTags(header(mboxHeaderTags)),
+		Status:            header(mboxHeaderStatus),
+		SurpriseUntil:     header(mboxHeaderSurpriseUntil),
+		Milestone:         header(mboxHeaderMilestone),
+		MilestoneDate:     header(mboxHeaderMilestoneDate),
+		HolidayCountry:    header(mboxHeaderHolidayCountry),
+		Holiday:           header(mboxHeaderHoliday),
+		CheckInRecipients: splitTransferTags(header(mboxHeaderCheckInTo)),
+	}
+
+	if record.DeliveryDate == "" {
+		if date, err := msg.Header.Date(); err == nil {
+			record.DeliveryDate = date.Format(time.RFC3339)
+		}
+	}
+
+	if reminder := header(mboxHeaderReminder); reminder != "" {
+		minutes, err := strconv.Atoi(reminder)
+		if err != nil {
+			return record, errors.New("invalid reminder minutes: " + reminder)
+		}
+		record.ReminderMinutes = &minutes
+	}
+
+	for _, number := range []struct {
+		header string
+		value  *int
+	}{
+		{mboxHeaderOccurrence, &record.MilestoneOccurrence},
+		{mboxHeaderOffsetMonths, &record.MilestoneOffsetMonths},
+		{mboxHeaderOffsetDays, &record.MilestoneOffsetDays},
+		{mboxHeaderCheckInEvery, &record.CheckInIntervalDays},
+		{mboxHeaderCheckInGrace, &record.CheckInGraceDays},
+	} {
+		if err := parseTransferInt(header(number.header), number.value); err != nil {
+			return record, errors.New("invalid " + number.header + " header: " + header(number.header))
+		}
+	}
+
+	contentType, body, err := mboxTextBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return record, err
+	}
+	if record.ContentFormat == "" && contentType == "text/markdown" {
+		record.ContentFormat = string(FormatMarkdown)
+	}
+	record.Content = strings.TrimRight(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+
+	return record, nil
+}
+
+// mboxTextBody returns the decoded text/plain or text/markdown body of a
+// message, looking inside multipart messages for the first such part
+func mboxTextBody(contentType, encoding string, body io.Reader) (string, string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := parts.NextRawPart()
+			if err == io.EOF {
+				return "", "", errors.New("message has no text body")
+			}
+			if err != nil {
+				return "", "", errors.New("invalid multipart message: " + err.Error())
+			}
+
+			partType, text, err := mboxTextBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err == nil {
+				return partType, text, nil
+			}
+		}
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/markdown" {
+		return "", "", errors.New("unsupported content type: " + mediaType)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, &newlineStripper{r: body})
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", "", errors.New("failed to decode message body: " + err.Error())
+	}
+	return mediaType, string(data), nil
+}
+
+// newlineStripper drops line breaks so wrapped base64 bodies can be decoded
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (s *newlineStripper) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	kept := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+func encodeMbox(w io.Writer, records []TransferRecord) error {
+	writer := bufio.NewWriter(w)
+
+	for _, record := range records {
+		deliveryDate, err := time.Parse(time.RFC3339, record.DeliveryDate)
+		if err != nil {
+			deliveryDate = time.Unix(0, 0).UTC()
+		}
+
+		mediaType := "text/plain"
+		if record.ContentFormat == string(FormatMarkdown) {
+			mediaType = "text/markdown"
+		}
+
+		fmt.Fprintf(writer, "From dear-future %s\n", deliveryDate.UTC().Format(time.ANSIC))
+		fmt.Fprintf(writer, "Subject: %s\n", mime.QEncoding.Encode("utf-8", record.Title))
+		fmt.Fprintf(writer, "Date: %s\n", deliveryDate.Format(time.RFC1123Z))
+		fmt.Fprintf(writer, "%s: %s\n", mboxHeaderDeliveryDate, record.DeliveryDate)
+		writeMboxHeader(writer, mboxHeaderTimezone, record.Timezone)
+		writeMboxHeader(writer, mboxHeaderDeliveryMethod, record.DeliveryMethod)
+		writeMboxHeader(writer, mboxHeaderRecurrence, record.Recurrence)
+		if record.ReminderMinutes != nil {
+			writeMboxHeader(writer, mboxHeaderReminder, strconv.Itoa(*record.ReminderMinutes))
+		}
+		writeMboxHeader(writer, mboxHeaderContentFormat, record.ContentFormat)
+		writeMboxHeader(writer, mboxHeaderTags, mime.QEncoding.Encode("utf-8", strings.Join(record.Tags, ",")))
+		writeMboxHeader(writer, mboxHeaderStatus, record.Status)
+		writeMboxHeader(writer, mboxHeaderSurpriseUntil, record.SurpriseUntil)
+		writeMboxHeader(writer, mboxHeaderMilestone, record.Milestone)
+		writeMboxHeader(writer, mboxHeaderMilestoneDate, record.MilestoneDate)
+		writeMboxHeader(writer, mboxHeaderOccurrence, formatTransferInt(record.MilestoneOccurrence, record.Milestone != ""))
+		writeMboxHeader(writer, mboxHeaderOffsetMonths, formatTransferInt(record.MilestoneOffsetMonths, false))
+		writeMboxHeader(writer, mboxHeaderOffsetDays, formatTransferInt(record.MilestoneOffsetDays, false))
+		writeMboxHeader(writer, mboxHeaderHolidayCountry, record.HolidayCountry)
+		writeMboxHeader(writer, mboxHeaderHoliday, record.Holiday)
+		writeMboxHeader(writer, mboxHeaderCheckInEvery, formatTransferInt(record.CheckInIntervalDays, false))
+		writeMboxHeader(writer, mboxHeaderCheckInGrace, formatTransferInt(record.CheckInGraceDays, false))
+		writeMboxHeader(writer, mboxHeaderCheckInTo, strings.Join(record.CheckInRecipients, ","))
+		writer.WriteString("MIME-Version: 1.0\n")
+		fmt.Fprintf(writer, "Content-Type: %s; charset=utf-8\n", mediaType)
+		writer.WriteString("Content-Transfer-Encoding: 8bit\n\n")
+
+		for _, line := range strings.Split(strings.ReplaceAll(record.Content, "\r\n", "\n"), "\n") {
+			if mboxFromLine.MatchString(line) {
+				writer.WriteString(">")
+			}
+			writer.WriteString(line)
+			writer.WriteString("\n")
+		}
+		writer.WriteString("\n")
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write mbox: %w", err)
+	}
+	return nil
+}
+
+func writeMboxHeader(w *bufio.Writer, name, value string) {
+	if value != "" {
+		fmt.Fprintf(w, "%s: %s\n", name, value)
+	}
+}
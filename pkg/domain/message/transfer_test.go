@@ -0,0 +1,239 @@
+package message
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+func TestTransferRoundTrip(t *testing.T) {
+	userID := uuid.New()
+	msg := NewMessage(CreateMessageRequest{
+		UserID:          userID,
+		Title:           "Dear future me, ça va?",
+		Content:         "First line\nFrom the past, with love\n>From quoted",
+		ContentFormat:   FormatMarkdown,
+		DeliveryDate:    time.Now().Add(48 * time.Hour).Truncate(time.Second),
+		Timezone:        "Asia/Ho_Chi_Minh",
+		DeliveryMethod:  DeliveryEmail,
+		Recurrence:      RecurrenceYearly,
+		ReminderMinutes: common.Some(60),
+	})
+	if msg.IsErr() {
+		t.Fatalf("failed to create message: %v", msg.Error())
+	}
+
+	record := NewTransferRecord(msg.Value(), []string{"Family", "Hopes & dreams"})
+
+	for _, format := range []TransferFormat{TransferJSONLines, TransferCSV, TransferMbox} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if result := EncodeTransfer(format, &buf, []TransferRecord{record, record}); result.IsErr() {
+				t.Fatalf("encode failed: %v", result.Error())
+			}
+
+			rows := DecodeTransfer(format, &buf)
+			if rows.IsErr() {
+				t.Fatalf("decode failed: %v", rows.Error())
+			}
+			if len(rows.Value()) != 2 {
+				t.Fatalf("expected 2 rows, got %d", len(rows.Value()))
+			}
+
+			row := rows.Value()[1]
+			if row.Err != nil {
+				t.Fatalf("unexpected row error: %v", row.Err)
+			}
+			if !reflect.DeepEqual(row.Record, record) {
+				t.Errorf("record mismatch\nexpected %+v\ngot      %+v", record, row.Record)
+			}
+
+			req := row.Record.ToCreateRequest(userID)
+			if req.IsErr() {
+				t.Fatalf("unexpected conversion error: %v", req.Error())
+			}
+			if imported := NewMessage(req.Value()); imported.IsErr() {
+				t.Errorf("re-imported record failed validation: %v", imported.Error())
+			}
+		})
+	}
+}
+
+func TestTransferRoundTripSchedules(t *testing.T) {
+	userID := uuid.New()
+	birthday := user.NewMilestone("birthday", "My birthday", time.Date(1990, time.June, 1, 0, 0, 0, 0, time.UTC)).Value()
+	at := user.TimeOfDay(8*60 + 30)
+	base := CreateMessageRequest{
+		UserID:         userID,
+		Title:          "Scheduled",
+		Content:        "Hello",
+		DeliveryDate:   time.Now().Add(48 * time.Hour),
+		Timezone:       "Asia/Ho_Chi_Minh",
+		DeliveryMethod: DeliveryEmail,
+		Recurrence:     RecurrenceNone,
+	}
+
+	anchored := base
+	anchored.Anchor = common.Some(NewMilestoneAnchor(birthday, 80, 0, -7, at).Value())
+	onHoliday := base
+	onHoliday.Recurrence = RecurrenceYearly
+	onHoliday.Holiday = common.Some(NewHolidayTarget("VN", "tet", at).Value())
+	checkIn := base
+	checkIn.CheckIn = common.Some(NewCheckInSettings(7*24*time.Hour, 3*24*time.Hour, []string{"friend@example.com"}).Value())
+
+	for name, req := range map[string]CreateMessageRequest{"milestone": anchored, "holiday": onHoliday, "check-in": checkIn} {
+		msg := NewMessage(req)
+		if msg.IsErr() {
+			t.Fatalf("%s: failed to create message: %v", name, msg.Error())
+		}
+		record := NewTransferRecord(msg.Value(), nil)
+
+		for _, format := range []TransferFormat{TransferJSONLines, TransferCSV, TransferMbox} {
+			t.Run(name+"/"+string(format), func(t *testing.T) {
+				var buf bytes.Buffer
+				if result := EncodeTransfer(format, &buf, []TransferRecord{record}); result.IsErr() {
+					t.Fatalf("encode failed: %v", result.Error())
+				}
+				rows := DecodeTransfer(format, &buf)
+				if rows.IsErr() || len(rows.Value()) != 1 || rows.Value()[0].Err != nil {
+					t.Fatalf("decode failed: %+v", rows)
+				}
+				if !reflect.DeepEqual(rows.Value()[0].Record, record) {
+					t.Errorf("record mismatch\nexpected %+v\ngot      %+v", record, rows.Value()[0].Record)
+				}
+
+				imported := common.Bind(rows.Value()[0].Record.ToCreateRequest(userID), NewMessage)
+				if imported.IsErr() {
+					t.Fatalf("re-imported record failed validation: %v", imported.Error())
+				}
+				got := imported.Value()
+				switch {
+				case req.Anchor.IsSome():
+					anchor := got.Anchor()
+					if anchor.IsNone() || anchor.Value() != req.Anchor.Value() {
+						t.Errorf("Anchor() = %+v, want %+v", anchor, req.Anchor.Value())
+					}
+				case req.Holiday.IsSome():
+					target := got.HolidayTarget()
+					if target.IsNone() || target.Value() != req.Holiday.Value() {
+						t.Errorf("HolidayTarget() = %+v, want %+v", target, req.Holiday.Value())
+					}
+				case req.CheckIn.IsSome():
+					settings := got.CheckIn()
+					if settings.IsNone() || settings.Value().Interval() != 7*24*time.Hour || settings.Value().GracePeriod() != 3*24*time.Hour ||
+						!reflect.DeepEqual(settings.Value().Recipients(), []string{"friend@example.com"}) {
+						t.Errorf("CheckIn() = %+v, want the exported settings", settings)
+					}
+				}
+				if !got.DeliveryDate().Equal(msg.Value().DeliveryDate()) && req.CheckIn.IsNone() {
+					t.Errorf("DeliveryDate() = %v, want %v", got.DeliveryDate(), msg.Value().DeliveryDate())
+				}
+			})
+		}
+	}
+}
+
+func TestTransferRoundTripArchived(t *testing.T) {
+	userID := uuid.New()
+	deliveredAt := time.Now().AddDate(-1, 0, 0).Truncate(time.Second)
+
+	for _, status := range []MessageStatus{StatusDelivered, StatusCancelled} {
+		stored := RestoreMessage(StoredMessage{
+			ID:             uuid.New(),
+			UserID:         userID,
+			Title:          "Last year",
+			Content:        "Hello from **last** year",
+			ContentFormat:  FormatMarkdown,
+			DeliveryDate:   deliveredAt,
+			Timezone:       "Asia/Ho_Chi_Minh",
+			Status:         status,
+			DeliveryMethod: DeliveryEmail,
+			Recurrence:     RecurrenceNone,
+			CreatedAt:      deliveredAt.AddDate(-1, 0, 0),
+			UpdatedAt:      deliveredAt,
+			DeliveredAt:    common.Some(deliveredAt),
+		})
+		if stored.IsErr() {
+			t.Fatalf("%s: failed to restore message: %v", status, stored.Error())
+		}
+		record := NewTransferRecord(stored.Value(), nil)
+
+		for _, format := range []TransferFormat{TransferJSONLines, TransferCSV, TransferMbox} {
+			t.Run(string(status)+"/"+string(format), func(t *testing.T) {
+				var buf bytes.Buffer
+				if result := EncodeTransfer(format, &buf, []TransferRecord{record}); result.IsErr() {
+					t.Fatalf("encode failed: %v", result.Error())
+				}
+				rows := DecodeTransfer(format, &buf)
+				if rows.IsErr() || len(rows.Value()) != 1 || rows.Value()[0].Err != nil {
+					t.Fatalf("decode failed: %+v", rows)
+				}
+
+				imported := rows.Value()[0].Record.ToMessage(userID)
+				if imported.IsErr() {
+					t.Fatalf("re-imported record failed validation: %v", imported.Error())
+				}
+				got := imported.Value()
+				if got.Status() != status || got.IsAwaitingDelivery() {
+					t.Errorf("Status() = %s, want archived %s", got.Status(), status)
+				}
+				if !got.DeliveryDate().Equal(deliveredAt) {
+					t.Errorf("DeliveryDate() = %v, want %v", got.DeliveryDate(), deliveredAt)
+				}
+				if got.Content() != stored.Value().Content() || got.ContentFormat() != FormatMarkdown {
+					t.Errorf("content = %q (%s), want the exported content", got.Content(), got.ContentFormat())
+				}
+			})
+		}
+	}
+
+	// Letters still waiting for delivery are created anew and need a future date
+	pending := TransferRecord{Title: "Late", Content: "Too late", DeliveryDate: deliveredAt.Format(time.RFC3339), Status: string(StatusScheduled)}
+	if imported := pending.ToMessage(userID); imported.IsOk() {
+		t.Error("a scheduled record with a past delivery date should fail validation")
+	}
+}
+
+func TestDecodeTransferReportsRowErrors(t *testing.T) {
+	input := "title,content,delivery_date,reminder_minutes\n" +
+		"ok,hello,2099-01-02,\n" +
+		"bad,hello,2099-01-02,soon\n"
+
+	rows := DecodeTransfer(TransferCSV, strings.NewReader(input))
+	if rows.IsErr() {
+		t.Fatalf("decode failed: %v", rows.Error())
+	}
+	if len(rows.Value()) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows.Value()))
+	}
+	if rows.Value()[0].Err != nil || rows.Value()[0].Row != 2 {
+		t.Errorf("expected row 2 to decode cleanly, got %+v", rows.Value()[0])
+	}
+	if rows.Value()[1].Err == nil || rows.Value()[1].Row != 3 {
+		t.Errorf("expected an error on row 3, got %+v", rows.Value()[1])
+	}
+
+	if result := DecodeTransfer(TransferCSV, strings.NewReader("title,content\n")); result.IsOk() {
+		t.Error("expected missing delivery_date column to fail")
+	}
+}
+
+func TestTransferRecordDateInTimezone(t *testing.T) {
+	record := TransferRecord{Title: "t", Content: "c", DeliveryDate: "2099-03-04 09:30", Timezone: "America/New_York"}
+
+	req := record.ToCreateRequest(uuid.New())
+	if req.IsErr() {
+		t.Fatalf("unexpected error: %v", req.Error())
+	}
+
+	expected := time.Date(2099, 3, 4, 14, 30, 0, 0, time.UTC)
+	if !req.Value().DeliveryDate.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, req.Value().DeliveryDate.UTC())
+	}
+}
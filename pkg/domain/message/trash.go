@@ -0,0 +1,60 @@
+// Package message contains the trash for message domain
+package message
+
+import (
+	"errors"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// DefaultTrashRetention is how long a deleted message stays in the trash
+// before it is purged for good
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// DeletedAt returns when the message was moved to the trash
+func (m Message) DeletedAt() common.Option[time.Time] {
+	return m.deletedAt
+}
+
+// IsDeleted returns true if the message is in the trash
+func (m Message) IsDeleted() bool {
+	return m.deletedAt.IsSome()
+}
+
+// WithDeleted returns a new Message moved to the trash. Messages in the
+// trash are never delivered and can be restored until they are purged.
+func (m Message) WithDeleted(at time.Time) common.Result[Message] {
+	if m.IsDeleted() {
+		return common.Err[Message](errors.New("message is already in the trash"))
+	}
+	if !m.IsDeletable() {
+		return common.Err[Message](errors.New("message cannot be deleted (already delivered)"))
+	}
+
+	updated := m
+	updated.deletedAt = common.Some(at)
+	updated.updatedAt = at
+	return common.Ok(updated)
+}
+
+// WithRestored returns a new Message taken out of the trash. A message whose
+// delivery date passed while it was in the trash is delivered right away.
+func (m Message) WithRestored() common.Result[Message] {
+	if !m.IsDeleted() {
+		return common.Err[Message](errors.New("message is not in the trash"))
+	}
+
+	updated := m
+	updated.deletedAt = common.None[time.Time]()
+	updated.updatedAt = time.Now()
+	return common.Ok(updated)
+}
+
+// PurgeAt returns when a message in the trash is deleted for good
+func (m Message) PurgeAt(retention time.Duration) common.Option[time.Time] {
+	if !m.IsDeleted() {
+		return common.None[time.Time]()
+	}
+	return common.Some(m.deletedAt.Value().Add(retention))
+}
@@ -0,0 +1,55 @@
+package message
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMessageTrash(t *testing.T) {
+	msgResult := NewMessage(CreateMessageRequest{
+		UserID:         uuid.New(),
+		Title:          "Keep or toss",
+		Content:        "Maybe later.",
+		DeliveryDate:   time.Now().Add(24 * time.Hour),
+		Timezone:       "UTC",
+		DeliveryMethod: DeliveryEmail,
+	})
+	if msgResult.IsErr() {
+		t.Fatalf("NewMessage() error = %v", msgResult.Error())
+	}
+	msg := msgResult.Value()
+
+	if msg.IsDeleted() || msg.PurgeAt(DefaultTrashRetention).IsSome() {
+		t.Fatal("new messages should not be in the trash")
+	}
+
+	deletedAt := time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)
+	deleted := msg.WithDeleted(deletedAt)
+	if deleted.IsErr() {
+		t.Fatalf("WithDeleted() error = %v", deleted.Error())
+	}
+	if purgeAt := deleted.Value().PurgeAt(DefaultTrashRetention); !purgeAt.ValueOr(time.Time{}).Equal(deletedAt.Add(DefaultTrashRetention)) {
+		t.Errorf("PurgeAt() = %v, want %v", purgeAt, deletedAt.Add(DefaultTrashRetention))
+	}
+	if deleted.Value().WithDeleted(deletedAt).IsOk() {
+		t.Error("deleting a message already in the trash should be rejected")
+	}
+
+	restored := deleted.Value().WithRestored()
+	if restored.IsErr() {
+		t.Fatalf("WithRestored() error = %v", restored.Error())
+	}
+	if restored.Value().IsDeleted() || restored.Value().Status() != StatusScheduled {
+		t.Errorf("restored message: deleted = %v, status = %s", restored.Value().IsDeleted(), restored.Value().Status())
+	}
+	if msg.WithRestored().IsOk() {
+		t.Error("restoring a message outside the trash should be rejected")
+	}
+
+	delivered := msg.WithStatus(StatusDelivered).Value()
+	if delivered.WithDeleted(deletedAt).IsOk() {
+		t.Error("delivered messages should not be deletable")
+	}
+}
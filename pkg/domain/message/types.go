@@ -17,6 +17,7 @@ const (
 	StatusDelivered MessageStatus = "delivered"
 	StatusFailed    MessageStatus = "failed"
 	StatusCancelled MessageStatus = "cancelled"
+	StatusArmed     MessageStatus = "armed" // Held until the author misses a check-in
 )
 
 // DeliveryMethod represents how the message will be delivered
@@ -44,6 +45,7 @@ type Message struct {
 	userID         uuid.UUID
 	title          string
 	content        string
+	contentFormat  ContentFormat
 	deliveryDate   time.Time
 	timezone       string
 	status         MessageStatus
@@ -53,6 +55,14 @@ type Message struct {
 	createdAt      time.Time
 	updatedAt      time.Time
 	deliveredAt    common.Option[time.Time]
+	checkIn        common.Option[CheckInSettings]
+	intendedDate   common.Option[time.Time]
+	surprise       common.Option[SurpriseWindow]
+	parentID       common.Option[uuid.UUID]
+	deletedAt      common.Option[time.Time]
+	version        int64
+	anchor         common.Option[MilestoneAnchor]
+	holiday        common.Option[HolidayTarget]
 }
 
 // MessageAttachment represents a file attached to a message
@@ -64,6 +74,7 @@ type MessageAttachment struct {
 	s3Key      string
 	fileSize   int64
 	uploadedAt time.Time
+	thumbnails map[ThumbnailSize]string // Storage keys of the generated thumbnails
 }
 
 // CreateMessageRequest contains data needed to create a new message
@@ -71,21 +82,32 @@ type CreateMessageRequest struct {
 	UserID          uuid.UUID
 	Title           string
 	Content         string
+	ContentFormat   ContentFormat
 	DeliveryDate    time.Time
 	Timezone        string
 	DeliveryMethod  DeliveryMethod
 	Recurrence      RecurrencePattern
 	ReminderMinutes common.Option[int]
+	CheckIn         common.Option[CheckInSettings] // Hold the message until the user stops checking in
+	Surprise        common.Option[SurpriseWindow]  // Deliver at a hidden time drawn from the window
+	ParentID        common.Option[uuid.UUID]       // Delivered message this letter replies to
+	Anchor          common.Option[MilestoneAnchor] // Derive the delivery date from a personal milestone
+	Holiday         common.Option[HolidayTarget]   // Deliver on a public holiday, every year if recurring yearly
 }
 
 // UpdateMessageRequest contains data for updating a message
 type UpdateMessageRequest struct {
 	Title           common.Option[string]
 	Content         common.Option[string]
+	ContentFormat   common.Option[ContentFormat]
 	DeliveryDate    common.Option[time.Time]
 	Timezone        common.Option[string]
 	Recurrence      common.Option[RecurrencePattern]
 	ReminderMinutes common.Option[int]
+	CheckIn         common.Option[CheckInSettings]
+	Surprise        common.Option[SurpriseWindow]
+	Anchor          common.Option[MilestoneAnchor]
+	Holiday         common.Option[HolidayTarget]
 }
 
 // StoredMessage represents persisted message data used to reconstruct domain entities
@@ -94,6 +116,7 @@ type StoredMessage struct {
 	UserID          uuid.UUID
 	Title           string
 	Content         string
+	ContentFormat   ContentFormat
 	DeliveryDate    time.Time
 	Timezone        string
 	Status          MessageStatus
@@ -103,6 +126,14 @@ type StoredMessage struct {
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	DeliveredAt     common.Option[time.Time]
+	CheckIn         common.Option[CheckInSettings]
+	IntendedDate    common.Option[time.Time]
+	Surprise        common.Option[SurpriseWindow]
+	ParentID        common.Option[uuid.UUID]
+	DeletedAt       common.Option[time.Time]
+	Version         int64 // Incremented by the database on every update
+	Anchor          common.Option[MilestoneAnchor]
+	Holiday         common.Option[HolidayTarget]
 }
 
 // RestoreMessage rebuilds a Message from stored data
@@ -112,6 +143,7 @@ func RestoreMessage(data StoredMessage) common.Result[Message] {
 		userID:         data.UserID,
 		title:          data.Title,
 		content:        data.Content,
+		contentFormat:  data.ContentFormat,
 		deliveryDate:   data.DeliveryDate,
 		timezone:       data.Timezone,
 		status:         data.Status,
@@ -121,6 +153,14 @@ func RestoreMessage(data StoredMessage) common.Result[Message] {
 		createdAt:      data.CreatedAt,
 		updatedAt:      data.UpdatedAt,
 		deliveredAt:    data.DeliveredAt,
+		checkIn:        data.CheckIn,
+		intendedDate:   data.IntendedDate,
+		surprise:       data.Surprise,
+		parentID:       data.ParentID,
+		deletedAt:      data.DeletedAt,
+		version:        data.Version,
+		anchor:         data.Anchor,
+		holiday:        data.Holiday,
 	}
 
 	validMessage := validateMessage(message)
@@ -167,6 +207,7 @@ func NewMessage(req CreateMessageRequest) common.Result[Message] {
 		userID:         validReq.Value().UserID,
 		title:          validReq.Value().Title,
 		content:        validReq.Value().Content,
+		contentFormat:  validReq.Value().ContentFormat,
 		deliveryDate:   validReq.Value().DeliveryDate,
 		timezone:       validReq.Value().Timezone,
 		status:         StatusScheduled,
@@ -176,6 +217,30 @@ func NewMessage(req CreateMessageRequest) common.Result[Message] {
 		createdAt:      now,
 		updatedAt:      now,
 		deliveredAt:    common.None[time.Time](),
+		checkIn:        common.None[CheckInSettings](),
+		intendedDate:   common.None[time.Time](),
+		surprise:       common.None[SurpriseWindow](),
+		parentID:       validReq.Value().ParentID,
+		deletedAt:      common.None[time.Time](),
+		version:        0,
+		anchor:         validReq.Value().Anchor,
+		holiday:        validReq.Value().Holiday,
+	}
+
+	// Surprise messages are sealed here: the delivery time is drawn once
+	// and only changes if the author picks a new range
+	if validReq.Value().Surprise.IsSome() {
+		message.surprise = validReq.Value().Surprise
+		message.deliveryDate = validReq.Value().Surprise.Value().Draw()
+	}
+
+	// Check-in messages are armed instead of scheduled; their delivery date
+	// is the release time if the author stops checking in
+	if validReq.Value().CheckIn.IsSome() {
+		settings := validReq.Value().CheckIn.Value().checkedIn(now)
+		message.status = StatusArmed
+		message.deliveryDate = settings.ReleaseAt()
+		message.checkIn = common.Some(settings)
 	}
 
 	// Validate and normalize the message
@@ -234,6 +299,7 @@ type StoredMessageAttachment struct {
 	StorageKey string
 	FileSize   int64
 	UploadedAt time.Time
+	Thumbnails map[ThumbnailSize]string
 }
 
 // RestoreMessageAttachment rebuilds an attachment entity from stored data
@@ -247,6 +313,11 @@ func RestoreMessageAttachment(data StoredMessageAttachment) common.Result[Messag
 		fileSize:   data.FileSize,
 		uploadedAt: data.UploadedAt,
 	}
+	for size, key := range data.Thumbnails {
+		if size.MaxDimension() > 0 && key != "" {
+			attachment = attachment.WithThumbnail(size, key)
+		}
+	}
 
 	// Reuse validation to ensure attachment integrity
 	validFileName := validateFileName(attachment.fileName)
@@ -278,6 +349,10 @@ func (m Message) Content() string {
 	return m.content
 }
 
+func (m Message) ContentFormat() ContentFormat {
+	return m.contentFormat
+}
+
 func (m Message) DeliveryDate() time.Time {
 	return m.deliveryDate
 }
@@ -329,6 +404,12 @@ func (m Message) DeliveredAt() common.Option[time.Time] {
 	return m.deliveredAt
 }
 
+// IntendedDeliveryDate returns the delivery time chosen by the author when the
+// delivery was moved out of the recipient's quiet hours
+func (m Message) IntendedDeliveryDate() common.Option[time.Time] {
+	return m.intendedDate
+}
+
 // Getters for MessageAttachment
 func (ma MessageAttachment) ID() uuid.UUID {
 	return ma.id
@@ -389,6 +470,7 @@ func (m Message) WithTitle(title string) common.Result[Message] {
 		userID:         m.userID,
 		title:          validTitle.Value(),
 		content:        m.content,
+		contentFormat:  m.contentFormat,
 		deliveryDate:   m.deliveryDate,
 		timezone:       m.timezone,
 		status:         m.status,
@@ -398,6 +480,14 @@ func (m Message) WithTitle(title string) common.Result[Message] {
 		createdAt:      m.createdAt,
 		updatedAt:      time.Now(),
 		deliveredAt:    m.deliveredAt,
+		checkIn:        m.checkIn,
+		intendedDate:   m.intendedDate,
+		surprise:       m.surprise,
+		parentID:       m.parentID,
+		deletedAt:      m.deletedAt,
+		version:        m.version,
+		anchor:         m.anchor,
+		holiday:        m.holiday,
 	}
 	return common.Ok(updated)
 }
@@ -414,6 +504,7 @@ func (m Message) WithContent(content string) common.Result[Message] {
 		userID:         m.userID,
 		title:          m.title,
 		content:        validContent.Value(),
+		contentFormat:  m.contentFormat,
 		deliveryDate:   m.deliveryDate,
 		timezone:       m.timezone,
 		status:         m.status,
@@ -423,12 +514,61 @@ func (m Message) WithContent(content string) common.Result[Message] {
 		createdAt:      m.createdAt,
 		updatedAt:      time.Now(),
 		deliveredAt:    m.deliveredAt,
+		checkIn:        m.checkIn,
+		intendedDate:   m.intendedDate,
+		surprise:       m.surprise,
+		parentID:       m.parentID,
+		deletedAt:      m.deletedAt,
+		version:        m.version,
+		anchor:         m.anchor,
+		holiday:        m.holiday,
+	}
+	return common.Ok(updated)
+}
+
+// WithContentFormat returns a new Message with updated content format
+func (m Message) WithContentFormat(format ContentFormat) common.Result[Message] {
+	validFormat := validateContentFormat(format)
+	if validFormat.IsErr() {
+		return common.Err[Message](validFormat.Error())
+	}
+
+	updated := Message{
+		id:             m.id,
+		userID:         m.userID,
+		title:          m.title,
+		content:        m.content,
+		contentFormat:  validFormat.Value(),
+		deliveryDate:   m.deliveryDate,
+		timezone:       m.timezone,
+		status:         m.status,
+		deliveryMethod: m.deliveryMethod,
+		recurrence:     m.recurrence,
+		reminderOffset: m.reminderOffset,
+		createdAt:      m.createdAt,
+		updatedAt:      time.Now(),
+		deliveredAt:    m.deliveredAt,
+		checkIn:        m.checkIn,
+		intendedDate:   m.intendedDate,
+		surprise:       m.surprise,
+		parentID:       m.parentID,
+		deletedAt:      m.deletedAt,
+		version:        m.version,
+		anchor:         m.anchor,
+		holiday:        m.holiday,
 	}
 	return common.Ok(updated)
 }
 
 // WithDeliveryDate returns a new Message with updated delivery date
 func (m Message) WithDeliveryDate(deliveryDate time.Time, timezone string) common.Result[Message] {
+	if m.checkIn.IsSome() {
+		return common.Err[Message](errors.New("check-in messages are delivered when a check-in is missed"))
+	}
+	if m.surprise.IsSome() {
+		return common.Err[Message](errors.New("surprise messages are delivered at a hidden time within their range"))
+	}
+
 	validDelivery := validateDeliveryDate(deliveryDate, timezone, false)
 	if validDelivery.IsErr() {
 		return common.Err[Message](validDelivery.Error())
@@ -439,6 +579,7 @@ func (m Message) WithDeliveryDate(deliveryDate time.Time, timezone string) commo
 		userID:         m.userID,
 		title:          m.title,
 		content:        m.content,
+		contentFormat:  m.contentFormat,
 		deliveryDate:   deliveryDate,
 		timezone:       timezone,
 		status:         m.status,
@@ -448,10 +589,33 @@ func (m Message) WithDeliveryDate(deliveryDate time.Time, timezone string) commo
 		createdAt:      m.createdAt,
 		updatedAt:      time.Now(),
 		deliveredAt:    m.deliveredAt,
+		checkIn:        m.checkIn,
+		intendedDate:   common.None[time.Time](),
+		surprise:       m.surprise,
+		parentID:       m.parentID,
+		deletedAt:      m.deletedAt,
+		version:        m.version,
+		anchor:         common.None[MilestoneAnchor](),
+		holiday:        common.None[HolidayTarget](),
 	}
 	return common.Ok(updated)
 }
 
+// WithShiftedDelivery returns a new Message delivered at a different time
+// than the author intended, keeping the intended time for display
+func (m Message) WithShiftedDelivery(deliveryDate time.Time) Message {
+	updated := m
+	if updated.intendedDate.IsNone() {
+		updated.intendedDate = common.Some(m.deliveryDate)
+	}
+	if updated.intendedDate.Value().Equal(deliveryDate) {
+		updated.intendedDate = common.None[time.Time]()
+	}
+	updated.deliveryDate = deliveryDate
+	updated.updatedAt = time.Now()
+	return updated
+}
+
 // WithStatus returns a new Message with updated status
 func (m Message) WithStatus(status MessageStatus) common.Result[Message] {
 	validStatus := validateStatusTransition(m.status, status)
@@ -472,6 +636,7 @@ func (m Message) WithStatus(status MessageStatus) common.Result[Message] {
 		userID:         m.userID,
 		title:          m.title,
 		content:        m.content,
+		contentFormat:  m.contentFormat,
 		deliveryDate:   m.deliveryDate,
 		timezone:       m.timezone,
 		status:         status,
@@ -481,6 +646,14 @@ func (m Message) WithStatus(status MessageStatus) common.Result[Message] {
 		createdAt:      m.createdAt,
 		updatedAt:      now,
 		deliveredAt:    deliveredAt,
+		checkIn:        m.checkIn,
+		intendedDate:   m.intendedDate,
+		surprise:       m.surprise,
+		parentID:       m.parentID,
+		deletedAt:      m.deletedAt,
+		version:        m.version,
+		anchor:         m.anchor,
+		holiday:        m.holiday,
 	}
 	return common.Ok(updated)
 }
@@ -491,12 +664,25 @@ func (m Message) WithRecurrence(pattern RecurrencePattern) common.Result[Message
 	if validRecurrence.IsErr() {
 		return common.Err[Message](validRecurrence.Error())
 	}
+	if m.checkIn.IsSome() && validRecurrence.Value() != RecurrenceNone {
+		return common.Err[Message](errors.New("check-in messages cannot be recurring"))
+	}
+	if m.surprise.IsSome() && validRecurrence.Value() != RecurrenceNone {
+		return common.Err[Message](errors.New("surprise messages cannot be recurring"))
+	}
+	if m.anchor.IsSome() && validRecurrence.Value() != RecurrenceNone {
+		return common.Err[Message](errors.New("messages anchored to a milestone cannot be recurring"))
+	}
+	if m.holiday.IsSome() && validRecurrence.Value() != RecurrenceNone && validRecurrence.Value() != RecurrenceYearly {
+		return common.Err[Message](errors.New("only yearly messages can target a holiday"))
+	}
 
 	updated := Message{
 		id:             m.id,
 		userID:         m.userID,
 		title:          m.title,
 		content:        m.content,
+		contentFormat:  m.contentFormat,
 		deliveryDate:   m.deliveryDate,
 		timezone:       m.timezone,
 		status:         m.status,
@@ -506,6 +692,14 @@ func (m Message) WithRecurrence(pattern RecurrencePattern) common.Result[Message
 		createdAt:      m.createdAt,
 		updatedAt:      time.Now(),
 		deliveredAt:    m.deliveredAt,
+		checkIn:        m.checkIn,
+		intendedDate:   m.intendedDate,
+		surprise:       m.surprise,
+		parentID:       m.parentID,
+		deletedAt:      m.deletedAt,
+		version:        m.version,
+		anchor:         m.anchor,
+		holiday:        m.holiday,
 	}
 
 	return common.Ok(updated)
@@ -523,6 +717,7 @@ func (m Message) WithReminderMinutes(reminder common.Option[int]) common.Result[
 		userID:         m.userID,
 		title:          m.title,
 		content:        m.content,
+		contentFormat:  m.contentFormat,
 		deliveryDate:   m.deliveryDate,
 		timezone:       m.timezone,
 		status:         m.status,
@@ -532,6 +727,14 @@ func (m Message) WithReminderMinutes(reminder common.Option[int]) common.Result[
 		createdAt:      m.createdAt,
 		updatedAt:      time.Now(),
 		deliveredAt:    m.deliveredAt,
+		checkIn:        m.checkIn,
+		intendedDate:   m.intendedDate,
+		surprise:       m.surprise,
+		parentID:       m.parentID,
+		deletedAt:      m.deletedAt,
+		version:        m.version,
+		anchor:         m.anchor,
+		holiday:        m.holiday,
 	}
 
 	return common.Ok(updated)
@@ -539,8 +742,8 @@ func (m Message) WithReminderMinutes(reminder common.Option[int]) common.Result[
 
 // UpdateMessage applies updates to a message
 func (m Message) UpdateMessage(req UpdateMessageRequest) common.Result[Message] {
-	// Can only update scheduled messages
-	if m.status != StatusScheduled {
+	// Can only update scheduled or armed messages
+	if !m.IsEditable() {
 		return common.Err[Message](errors.New("can only update scheduled messages"))
 	}
 
@@ -560,6 +763,13 @@ func (m Message) UpdateMessage(req UpdateMessageRequest) common.Result[Message]
 		})
 	}
 
+	// Apply content format update if provided
+	if req.ContentFormat.IsSome() {
+		result = common.Bind(result, func(message Message) common.Result[Message] {
+			return message.WithContentFormat(req.ContentFormat.Value())
+		})
+	}
+
 	// Apply delivery date update if provided
 	if req.DeliveryDate.IsSome() {
 		timezone := m.timezone
@@ -571,6 +781,20 @@ func (m Message) UpdateMessage(req UpdateMessageRequest) common.Result[Message]
 		})
 	}
 
+	// Apply milestone anchor update if provided
+	if req.Anchor.IsSome() {
+		result = common.Bind(result, func(message Message) common.Result[Message] {
+			return message.WithMilestoneAnchor(req.Anchor.Value())
+		})
+	}
+
+	// Apply holiday target update if provided
+	if req.Holiday.IsSome() {
+		result = common.Bind(result, func(message Message) common.Result[Message] {
+			return message.WithHolidayTarget(req.Holiday.Value())
+		})
+	}
+
 	// Apply recurrence update if provided
 	if req.Recurrence.IsSome() {
 		result = common.Bind(result, func(message Message) common.Result[Message] {
@@ -585,6 +809,20 @@ func (m Message) UpdateMessage(req UpdateMessageRequest) common.Result[Message]
 		})
 	}
 
+	// Apply check-in settings update if provided
+	if req.CheckIn.IsSome() {
+		result = common.Bind(result, func(message Message) common.Result[Message] {
+			return message.WithCheckInSettings(req.CheckIn.Value())
+		})
+	}
+
+	// Apply surprise range update if provided
+	if req.Surprise.IsSome() {
+		result = common.Bind(result, func(message Message) common.Result[Message] {
+			return message.WithSurpriseWindow(req.Surprise.Value())
+		})
+	}
+
 	return result
 }
 
@@ -592,12 +830,12 @@ func (m Message) UpdateMessage(req UpdateMessageRequest) common.Result[Message]
 
 // IsEditable returns true if the message can be edited
 func (m Message) IsEditable() bool {
-	return m.status == StatusScheduled
+	return m.status == StatusScheduled || m.status == StatusArmed
 }
 
 // IsDeletable returns true if the message can be deleted
 func (m Message) IsDeletable() bool {
-	return m.status == StatusScheduled || m.status == StatusFailed
+	return m.status == StatusScheduled || m.status == StatusFailed || m.status == StatusArmed
 }
 
 // IsDeliverable returns true if the message is ready for delivery
@@ -625,16 +863,24 @@ func (m Message) NextRecurrenceTime() common.Result[time.Time] {
 		return common.Err[time.Time](errors.New("message is not recurring"))
 	}
 
+	// Recur from the intended time so a shifted delivery does not drift
+	base := m.intendedDate.ValueOr(m.deliveryDate)
+
+	// Holidays such as Easter or Tết move from year to year
+	if m.holiday.IsSome() && m.recurrence == RecurrenceYearly {
+		return m.holiday.Value().Next(base, m.timezone)
+	}
+
 	var next time.Time
 	switch m.recurrence {
 	case RecurrenceDaily:
-		next = m.deliveryDate.Add(24 * time.Hour)
+		next = base.Add(24 * time.Hour)
 	case RecurrenceWeekly:
-		next = m.deliveryDate.Add(7 * 24 * time.Hour)
+		next = base.Add(7 * 24 * time.Hour)
 	case RecurrenceMonthly:
-		next = m.deliveryDate.AddDate(0, 1, 0)
+		next = base.AddDate(0, 1, 0)
 	case RecurrenceYearly:
-		next = m.deliveryDate.AddDate(1, 0, 0)
+		next = base.AddDate(1, 0, 0)
 	default:
 		return common.Err[time.Time](errors.New("unsupported recurrence pattern"))
 	}
@@ -642,6 +888,23 @@ func (m Message) NextRecurrenceTime() common.Result[time.Time] {
 	return common.Ok(next)
 }
 
+// WithNextRecurrence returns the message rescheduled for its next
+// occurrence, keeping the holiday it targets
+func (m Message) WithNextRecurrence() common.Result[Message] {
+	next := m.NextRecurrenceTime()
+	if next.IsErr() {
+		return common.Err[Message](next.Error())
+	}
+
+	updated := m.WithDeliveryDate(next.Value(), m.timezone)
+	if updated.IsErr() {
+		return updated
+	}
+	rescheduled := updated.Value()
+	rescheduled.holiday = m.holiday
+	return common.Ok(rescheduled)
+}
+
 // HasAttachments returns true if the message has attachments
 func (mwa MessageWithAttachments) HasAttachments() bool {
 	return len(mwa.attachments) > 0
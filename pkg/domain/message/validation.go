@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
 )
 
 // validateCreateMessageRequest validates the create message request
@@ -25,10 +26,38 @@ func validateCreateMessageRequest(req CreateMessageRequest) common.Result[Create
 		return common.Err[CreateMessageRequest](contentResult.Error())
 	}
 
-	// Validate delivery date
-	deliveryResult := validateDeliveryDate(req.DeliveryDate, req.Timezone, false)
-	if deliveryResult.IsErr() {
-		return common.Err[CreateMessageRequest](deliveryResult.Error())
+	// Validate content format
+	formatResult := validateContentFormat(req.ContentFormat)
+	if formatResult.IsErr() {
+		return common.Err[CreateMessageRequest](formatResult.Error())
+	}
+
+	// Validate delivery date; check-in messages derive theirs from the
+	// settings, surprise messages draw theirs from the range, anchored
+	// messages count it from the milestone and holiday messages take the
+	// next occurrence of the holiday
+	deliveryDate := req.DeliveryDate
+	if req.Holiday.IsSome() {
+		resolved := resolveHolidayTarget(req.Holiday.Value(), time.Now(), req.Timezone)
+		if resolved.IsErr() {
+			return common.Err[CreateMessageRequest](resolved.Error())
+		}
+		deliveryDate = resolved.Value()
+	} else if req.Anchor.IsSome() {
+		resolved := resolveAnchor(req.Anchor.Value(), req.Timezone)
+		if resolved.IsErr() {
+			return common.Err[CreateMessageRequest](resolved.Error())
+		}
+		deliveryDate = resolved.Value()
+	} else if req.Surprise.IsSome() {
+		if err := validateSurpriseWindow(req.Surprise.Value(), req.Timezone); err != nil {
+			return common.Err[CreateMessageRequest](err)
+		}
+	} else if req.CheckIn.IsNone() {
+		deliveryResult := validateDeliveryDate(req.DeliveryDate, req.Timezone, false)
+		if deliveryResult.IsErr() {
+			return common.Err[CreateMessageRequest](deliveryResult.Error())
+		}
 	}
 
 	// Validate delivery method
@@ -49,15 +78,46 @@ func validateCreateMessageRequest(req CreateMessageRequest) common.Result[Create
 		return common.Err[CreateMessageRequest](reminderResult.Error())
 	}
 
+	// Check-in messages are released once and cannot recur
+	if req.CheckIn.IsSome() && recurrenceResult.Value() != RecurrenceNone {
+		return common.Err[CreateMessageRequest](errors.New("check-in messages cannot be recurring"))
+	}
+
+	// Surprise messages are delivered once, at a time drawn from the range
+	if req.Surprise.IsSome() && (req.CheckIn.IsSome() || recurrenceResult.Value() != RecurrenceNone) {
+		return common.Err[CreateMessageRequest](errors.New("surprise delivery cannot be combined with recurrence or check-ins"))
+	}
+
+	// Anchored messages are delivered once, on the day counted from the milestone
+	if req.Anchor.IsSome() && (req.CheckIn.IsSome() || req.Surprise.IsSome() || recurrenceResult.Value() != RecurrenceNone) {
+		return common.Err[CreateMessageRequest](errors.New("milestone anchors cannot be combined with recurrence, check-ins or surprise delivery"))
+	}
+
+	// Holiday messages are delivered on the holiday, once or every year
+	if req.Holiday.IsSome() {
+		if req.CheckIn.IsSome() || req.Surprise.IsSome() || req.Anchor.IsSome() {
+			return common.Err[CreateMessageRequest](errors.New("holiday delivery cannot be combined with check-ins, surprise delivery or milestone anchors"))
+		}
+		if recurrenceResult.Value() != RecurrenceNone && recurrenceResult.Value() != RecurrenceYearly {
+			return common.Err[CreateMessageRequest](errors.New("only yearly messages can target a holiday"))
+		}
+	}
+
 	return common.Ok(CreateMessageRequest{
 		UserID:          req.UserID,
 		Title:           titleResult.Value(),
 		Content:         contentResult.Value(),
-		DeliveryDate:    req.DeliveryDate,
+		ContentFormat:   formatResult.Value(),
+		DeliveryDate:    deliveryDate,
 		Timezone:        req.Timezone,
 		DeliveryMethod:  methodResult.Value(),
 		Recurrence:      recurrenceResult.Value(),
 		ReminderMinutes: reminderResult.Value(),
+		CheckIn:         req.CheckIn,
+		Surprise:        req.Surprise,
+		ParentID:        req.ParentID,
+		Anchor:          req.Anchor,
+		Holiday:         req.Holiday,
 	})
 }
 
@@ -278,6 +338,12 @@ func validateMessage(message Message) common.Result[Message] {
 		return common.Err[Message](contentResult.Error())
 	}
 
+	// Validate content format
+	formatResult := validateContentFormat(message.contentFormat)
+	if formatResult.IsErr() {
+		return common.Err[Message](formatResult.Error())
+	}
+
 	// Validate delivery date
 	deliveryResult := validateDeliveryDate(message.deliveryDate, message.timezone, true)
 	if deliveryResult.IsErr() {
@@ -308,13 +374,18 @@ func validateMessage(message Message) common.Result[Message] {
 		return common.Err[Message](reminderResult.Error())
 	}
 
+	// Armed messages need check-in settings
+	if message.status == StatusArmed && message.checkIn.IsNone() {
+		return common.Err[Message](errors.New("armed message is missing check-in settings"))
+	}
+
 	return common.Ok(message)
 }
 
 // validateMessageStatus validates message status
 func validateMessageStatus(status MessageStatus) common.Result[MessageStatus] {
 	switch status {
-	case StatusScheduled, StatusDelivered, StatusFailed, StatusCancelled:
+	case StatusScheduled, StatusDelivered, StatusFailed, StatusCancelled, StatusArmed:
 		return common.Ok(status)
 	default:
 		return common.Err[MessageStatus](errors.New("invalid message status"))
@@ -329,6 +400,7 @@ func validateStatusTransition(currentStatus, newStatus MessageStatus) common.Res
 		StatusDelivered: {},                                 // No transitions from delivered
 		StatusFailed:    {StatusScheduled, StatusCancelled}, // Can retry or cancel
 		StatusCancelled: {},                                 // No transitions from cancelled
+		StatusArmed:     {StatusDelivered, StatusFailed, StatusCancelled},
 	}
 
 	allowedNextStates, exists := validTransitions[currentStatus]
@@ -351,6 +423,7 @@ func normalizeMessage(message Message) common.Result[Message] {
 	normalizedContent := strings.TrimSpace(message.content)
 	normalizedTimezone := strings.TrimSpace(message.timezone)
 	normalizedRecurrence := message.recurrence
+	normalizedFormat := message.contentFormat
 
 	if normalizedTimezone == "" {
 		normalizedTimezone = "UTC"
@@ -358,12 +431,16 @@ func normalizeMessage(message Message) common.Result[Message] {
 	if normalizedRecurrence == "" {
 		normalizedRecurrence = RecurrenceNone
 	}
+	if normalizedFormat == "" {
+		normalizedFormat = FormatPlain
+	}
 
 	normalized := Message{
 		id:             message.id,
 		userID:         message.userID,
 		title:          normalizedTitle,
 		content:        normalizedContent,
+		contentFormat:  normalizedFormat,
 		deliveryDate:   message.deliveryDate,
 		timezone:       normalizedTimezone,
 		status:         message.status,
@@ -373,6 +450,14 @@ func normalizeMessage(message Message) common.Result[Message] {
 		createdAt:      message.createdAt,
 		updatedAt:      message.updatedAt,
 		deliveredAt:    message.deliveredAt,
+		checkIn:        message.checkIn,
+		intendedDate:   message.intendedDate,
+		surprise:       message.surprise,
+		parentID:       message.parentID,
+		deletedAt:      message.deletedAt,
+		version:        message.version,
+		anchor:         message.anchor,
+		holiday:        message.holiday,
 	}
 
 	return common.Ok(normalized)
@@ -380,49 +465,26 @@ func normalizeMessage(message Message) common.Result[Message] {
 
 // Business validation functions
 
-// IsValidDeliveryTime checks if the delivery time is optimal
-func IsValidDeliveryTime(deliveryDate time.Time, timezone string) bool {
+// IsValidDeliveryTime checks if the delivery time falls outside the
+// recipient's quiet hours and inside their delivery windows
+func IsValidDeliveryTime(deliveryDate time.Time, timezone string, prefs user.DeliveryPreferences) bool {
 	loc, err := time.LoadLocation(timezone)
 	if err != nil {
 		return false
 	}
 
-	deliveryInTz := deliveryDate.In(loc)
-	hour := deliveryInTz.Hour()
-
-	// Reasonable delivery hours (8 AM to 10 PM)
-	return hour >= 8 && hour <= 22
+	return prefs.IsAllowed(deliveryDate, loc)
 }
 
-// GetOptimalDeliveryTime suggests an optimal delivery time based on the requested time
-func GetOptimalDeliveryTime(requestedTime time.Time, timezone string) common.Result[time.Time] {
+// GetOptimalDeliveryTime returns the requested time if delivery is allowed
+// then, otherwise the start of the next allowed slot in the timezone
+func GetOptimalDeliveryTime(requestedTime time.Time, timezone string, prefs user.DeliveryPreferences) common.Result[time.Time] {
 	loc, err := time.LoadLocation(timezone)
 	if err != nil {
 		return common.Err[time.Time](err)
 	}
 
-	requestedInTz := requestedTime.In(loc)
-	hour := requestedInTz.Hour()
-
-	// If it's a reasonable time, return as-is
-	if hour >= 8 && hour <= 22 {
-		return common.Ok(requestedTime)
-	}
-
-	// Adjust to reasonable time
-	var adjustedTime time.Time
-	if hour < 8 {
-		// Move to 9 AM same day
-		adjustedTime = time.Date(requestedInTz.Year(), requestedInTz.Month(), requestedInTz.Day(),
-			9, 0, 0, 0, loc)
-	} else {
-		// Move to 9 AM next day
-		nextDay := requestedInTz.AddDate(0, 0, 1)
-		adjustedTime = time.Date(nextDay.Year(), nextDay.Month(), nextDay.Day(),
-			9, 0, 0, 0, loc)
-	}
-
-	return common.Ok(adjustedTime.UTC())
+	return common.Ok(prefs.NextAllowedTime(requestedTime, loc).UTC())
 }
 
 // ValidateAttachmentLimits checks if attachment limits are respected
@@ -0,0 +1,27 @@
+// Package message contains optimistic concurrency for message domain
+package message
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// ErrVersionConflict is returned when a message changed after it was read
+var ErrVersionConflict = errors.New("message was modified by another request")
+
+// Version returns the stored revision of the message. New messages that
+// were never saved have version 0.
+func (m Message) Version() int64 {
+	return m.version
+}
+
+// ExpectVersion returns the message if it is still at the version the
+// caller read, so a stale edit cannot overwrite a newer one
+func (m Message) ExpectVersion(expected int64) common.Result[Message] {
+	if m.version != expected {
+		return common.Err[Message](fmt.Errorf("%w: expected version %d, current version is %d", ErrVersionConflict, expected, m.version))
+	}
+	return common.Ok(m)
+}
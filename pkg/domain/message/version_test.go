@@ -0,0 +1,41 @@
+package message
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestExpectVersion(t *testing.T) {
+	msgResult := RestoreMessage(StoredMessage{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		Title:          "Two tabs",
+		Content:        "Which edit wins?",
+		DeliveryDate:   time.Now().Add(24 * time.Hour),
+		Timezone:       "UTC",
+		Status:         StatusScheduled,
+		DeliveryMethod: DeliveryEmail,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		Version:        3,
+	})
+	if msgResult.IsErr() {
+		t.Fatalf("RestoreMessage() error = %v", msgResult.Error())
+	}
+	msg := msgResult.Value()
+
+	if msg.ExpectVersion(3).IsErr() {
+		t.Error("ExpectVersion() should accept the current version")
+	}
+	if result := msg.ExpectVersion(2); !errors.Is(result.Error(), ErrVersionConflict) {
+		t.Errorf("ExpectVersion() with a stale version: error = %v, want ErrVersionConflict", result.Error())
+	}
+
+	// Domain updates keep the version; the database increments it on save
+	if edited := msg.WithTitle("Mine"); edited.IsErr() || edited.Value().Version() != 3 {
+		t.Errorf("WithTitle() changed the version to %d", edited.Value().Version())
+	}
+}
@@ -0,0 +1,299 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/holiday"
+)
+
+// MaxScheduleRules bounds the quiet hours and delivery windows of a profile
+const MaxScheduleRules = 28
+
+// maxHolidaySkips bounds how often a delivery is moved past a holiday and
+// back out of quiet hours before the search gives up
+const maxHolidaySkips = 31
+
+// TimeOfDay is a wall-clock time in minutes after midnight
+type TimeOfDay int
+
+// ScheduleRule is a daily time range on a set of weekdays. A range whose end
+// is before its start wraps past midnight; the part after midnight belongs to
+// the rule's day, so 22:00–07:00 on Friday covers Friday night until Saturday
+// morning.
+type ScheduleRule struct {
+	Days  []time.Weekday // Empty means every day
+	Start TimeOfDay
+	End   TimeOfDay
+}
+
+// DeliveryPreferences controls when messages may be delivered to a user.
+// Deliveries never happen during quiet hours. When delivery windows are
+// configured, deliveries only happen inside one of them; otherwise any time
+// outside quiet hours is allowed. Deliveries can also avoid the public
+// holidays of the user's country, moving to the first working day after.
+type DeliveryPreferences struct {
+	quietHours    []ScheduleRule
+	windows       []ScheduleRule
+	country       string
+	avoidHolidays bool
+}
+
+// NewDeliveryPreferences validates quiet hours and delivery windows. The
+// combination must leave at least one delivery slot each week.
+func NewDeliveryPreferences(quietHours, windows []ScheduleRule) common.Result[DeliveryPreferences] {
+	if len(quietHours)+len(windows) > MaxScheduleRules {
+		return common.Err[DeliveryPreferences](fmt.Errorf("at most %d quiet hours and delivery windows are allowed", MaxScheduleRules))
+	}
+
+	for _, rule := range append(append([]ScheduleRule{}, quietHours...), windows...) {
+		if err := validateScheduleRule(rule); err != nil {
+			return common.Err[DeliveryPreferences](err)
+		}
+	}
+
+	prefs := DeliveryPreferences{
+		quietHours: quietHours,
+		windows:    windows,
+	}
+
+	// Any week will do; the check only needs to find one allowed slot
+	reference := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if _, ok := prefs.nextAllowed(reference); !ok {
+		return common.Err[DeliveryPreferences](errors.New("quiet hours and delivery windows leave no time for deliveries"))
+	}
+
+	return common.Ok(prefs)
+}
+
+// DefaultDeliveryPreferences allows deliveries at any time
+func DefaultDeliveryPreferences() DeliveryPreferences {
+	return DeliveryPreferences{}
+}
+
+func validateScheduleRule(rule ScheduleRule) error {
+	if rule.Start < 0 || rule.Start >= 24*60 || rule.End < 0 || rule.End >= 24*60 {
+		return errors.New("schedule times must be between 00:00 and 23:59")
+	}
+	if rule.Start == rule.End {
+		return errors.New("schedule start and end must differ")
+	}
+	for _, day := range rule.Days {
+		if day < time.Sunday || day > time.Saturday {
+			return errors.New("invalid weekday")
+		}
+	}
+	return nil
+}
+
+func (p DeliveryPreferences) QuietHours() []ScheduleRule {
+	return p.quietHours
+}
+
+func (p DeliveryPreferences) Windows() []ScheduleRule {
+	return p.windows
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country whose public
+// holidays apply to the user, or "" if none is set
+func (p DeliveryPreferences) Country() string {
+	return p.country
+}
+
+// AvoidsHolidays returns true if deliveries move off public holidays
+func (p DeliveryPreferences) AvoidsHolidays() bool {
+	return p.avoidHolidays
+}
+
+// WithHolidays returns the preferences with the user's country and whether
+// deliveries avoid its public holidays. Avoiding holidays needs a country
+// with a bundled holiday calendar.
+func (p DeliveryPreferences) WithHolidays(country string, avoid bool) common.Result[DeliveryPreferences] {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country != "" {
+		if calendar := holiday.ForCountry(country); calendar.IsErr() {
+			return common.Err[DeliveryPreferences](calendar.Error())
+		}
+	} else if avoid {
+		return common.Err[DeliveryPreferences](errors.New("a country is needed to avoid public holidays"))
+	}
+
+	updated := p
+	updated.country = country
+	updated.avoidHolidays = avoid
+	return common.Ok(updated)
+}
+
+// WithoutHolidayAvoidance returns the preferences with deliveries allowed on
+// public holidays, for letters meant to arrive on one
+func (p DeliveryPreferences) WithoutHolidayAvoidance() DeliveryPreferences {
+	updated := p
+	updated.avoidHolidays = false
+	return updated
+}
+
+// IsRestricted returns true if any quiet hours, delivery windows or holiday
+// avoidance are set
+func (p DeliveryPreferences) IsRestricted() bool {
+	return len(p.quietHours) > 0 || len(p.windows) > 0 || p.avoidHolidays
+}
+
+// IsAllowed reports whether a delivery may happen at t in loc
+func (p DeliveryPreferences) IsAllowed(t time.Time, loc *time.Location) bool {
+	if p.isHoliday(t.In(loc)) {
+		return false
+	}
+	return p.allowedByRules(t, loc)
+}
+
+// allowedByRules applies quiet hours and delivery windows only
+func (p DeliveryPreferences) allowedByRules(t time.Time, loc *time.Location) bool {
+	local := t.In(loc)
+	for _, rule := range p.quietHours {
+		if rule.covers(local) {
+			return false
+		}
+	}
+
+	if len(p.windows) == 0 {
+		return true
+	}
+	for _, rule := range p.windows {
+		if rule.covers(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextAllowedTime returns t if a delivery may happen then, otherwise the start
+// of the next allowed slot in loc. A slot on a public holiday that is avoided
+// moves to the same time on the first working day after it, and then out of
+// quiet hours again.
+func (p DeliveryPreferences) NextAllowedTime(t time.Time, loc *time.Location) time.Time {
+	if !p.IsRestricted() {
+		return t
+	}
+
+	next := t.In(loc)
+	for skips := 0; skips < maxHolidaySkips; skips++ {
+		allowed, ok := p.nextAllowed(next)
+		if !ok {
+			return t
+		}
+		if !p.isHoliday(allowed) {
+			return allowed.In(t.Location())
+		}
+		next = p.calendar().FirstWorkingDayAfter(allowed)
+	}
+	return t
+}
+
+// isHoliday reports whether local falls on an avoided public holiday
+func (p DeliveryPreferences) isHoliday(local time.Time) bool {
+	return p.avoidHolidays && p.calendar().On(local).IsSome()
+}
+
+// calendar returns the holiday calendar of the user's country, which was
+// validated when it was set
+func (p DeliveryPreferences) calendar() holiday.Calendar {
+	return holiday.ForCountry(p.country).Value()
+}
+
+// nextAllowed searches the week after t. The earliest allowed time is t
+// itself, the start of a delivery window or the end of a quiet period, so
+// only those instants need checking.
+func (p DeliveryPreferences) nextAllowed(t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	candidates := []time.Time{t}
+	for offset := 0; offset <= 7; offset++ {
+		day := t.AddDate(0, 0, offset)
+		for _, rule := range p.windows {
+			candidates = append(candidates, rule.Start.on(day, loc))
+		}
+		for _, rule := range p.quietHours {
+			candidates = append(candidates, rule.End.on(day, loc))
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	for _, candidate := range candidates {
+		if !candidate.Before(t) && p.allowedByRules(candidate, loc) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// covers reports whether the rule applies at local time t
+func (r ScheduleRule) covers(t time.Time) bool {
+	minute := TimeOfDay(t.Hour()*60 + t.Minute())
+	if r.Start < r.End {
+		return r.appliesOn(t.Weekday()) && minute >= r.Start && minute < r.End
+	}
+
+	// Wrapping range: the evening belongs to today, the early morning to yesterday
+	if minute >= r.Start {
+		return r.appliesOn(t.Weekday())
+	}
+	return minute < r.End && r.appliesOn((t.Weekday()+6)%7)
+}
+
+func (r ScheduleRule) appliesOn(day time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, d := range r.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// on returns the time of day on the calendar day of date in loc
+func (t TimeOfDay) on(date time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), int(t)/60, int(t)%60, 0, 0, loc)
+}
+
+// String formats the time as HH:MM
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d", int(t)/60, int(t)%60)
+}
+
+// ParseTimeOfDay parses an HH:MM wall-clock time
+func ParseTimeOfDay(value string) common.Result[TimeOfDay] {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return common.Err[TimeOfDay](fmt.Errorf("invalid time %q (use HH:MM)", value))
+	}
+	return common.Ok(TimeOfDay(parsed.Hour()*60 + parsed.Minute()))
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseWeekday parses a weekday name such as "mon" or "Monday"
+func ParseWeekday(value string) common.Result[time.Weekday] {
+	day, ok := weekdayNames[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return common.Err[time.Weekday](fmt.Errorf("invalid weekday %q", value))
+	}
+	return common.Ok(day)
+}
+
+// WeekdayName returns the short lowercase name of a weekday, as accepted by ParseWeekday
+func WeekdayName(day time.Weekday) string {
+	return strings.ToLower(day.String()[:3])
+}
@@ -0,0 +1,150 @@
+package user
+
+import (
+	"testing"
+	"time"
+)
+
+func mustTimeOfDay(t *testing.T, value string) TimeOfDay {
+	t.Helper()
+	result := ParseTimeOfDay(value)
+	if result.IsErr() {
+		t.Fatalf("ParseTimeOfDay(%q) error = %v", value, result.Error())
+	}
+	return result.Value()
+}
+
+func TestNextAllowedTime(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// Quiet every night 22:00–07:00, and all of Sunday morning until 10:00
+	prefsResult := NewDeliveryPreferences([]ScheduleRule{
+		{Start: mustTimeOfDay(t, "22:00"), End: mustTimeOfDay(t, "07:00")},
+		{Days: []time.Weekday{time.Sunday}, Start: mustTimeOfDay(t, "00:00"), End: mustTimeOfDay(t, "10:00")},
+	}, nil)
+	if prefsResult.IsErr() {
+		t.Fatalf("NewDeliveryPreferences() error = %v", prefsResult.Error())
+	}
+	prefs := prefsResult.Value()
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{
+			name: "allowed time is kept",
+			at:   time.Date(2024, time.March, 5, 12, 30, 0, 0, loc),
+			want: time.Date(2024, time.March, 5, 12, 30, 0, 0, loc),
+		},
+		{
+			name: "late evening moves to next morning",
+			at:   time.Date(2024, time.March, 5, 23, 15, 0, 0, loc),
+			want: time.Date(2024, time.March, 6, 7, 0, 0, 0, loc),
+		},
+		{
+			name: "early morning moves to end of quiet hours",
+			at:   time.Date(2024, time.March, 6, 3, 0, 0, 0, loc),
+			want: time.Date(2024, time.March, 6, 7, 0, 0, 0, loc),
+		},
+		{
+			name: "weekday rule extends Sunday quiet hours",
+			at:   time.Date(2024, time.March, 9, 23, 0, 0, 0, loc), // Saturday
+			want: time.Date(2024, time.March, 10, 10, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := prefs.NextAllowedTime(tt.at.UTC(), loc)
+			if !got.Equal(tt.want) {
+				t.Errorf("NextAllowedTime() = %v, want %v", got.In(loc), tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryWindows(t *testing.T) {
+	// Weekend mornings only
+	prefsResult := NewDeliveryPreferences(nil, []ScheduleRule{
+		{Days: []time.Weekday{time.Saturday, time.Sunday}, Start: mustTimeOfDay(t, "09:00"), End: mustTimeOfDay(t, "11:00")},
+	})
+	if prefsResult.IsErr() {
+		t.Fatalf("NewDeliveryPreferences() error = %v", prefsResult.Error())
+	}
+
+	monday := time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+	want := time.Date(2024, time.March, 9, 9, 0, 0, 0, time.UTC)
+	if got := prefsResult.Value().NextAllowedTime(monday, time.UTC); !got.Equal(want) {
+		t.Errorf("NextAllowedTime() = %v, want %v", got, want)
+	}
+}
+
+func TestNewDeliveryPreferencesValidation(t *testing.T) {
+	allDay := []ScheduleRule{
+		{Start: mustTimeOfDay(t, "00:00"), End: mustTimeOfDay(t, "12:00")},
+		{Start: mustTimeOfDay(t, "12:00"), End: mustTimeOfDay(t, "00:00")},
+	}
+	if NewDeliveryPreferences(allDay, nil).IsOk() {
+		t.Error("quiet hours covering the whole week should be rejected")
+	}
+
+	empty := []ScheduleRule{{Start: mustTimeOfDay(t, "09:00"), End: mustTimeOfDay(t, "09:00")}}
+	if NewDeliveryPreferences(nil, empty).IsOk() {
+		t.Error("empty range should be rejected")
+	}
+
+	if ParseTimeOfDay("25:00").IsOk() {
+		t.Error("ParseTimeOfDay() should reject invalid times")
+	}
+}
+
+func TestNextAllowedTimeAvoidsHolidays(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	quiet := NewDeliveryPreferences([]ScheduleRule{
+		{Start: mustTimeOfDay(t, "22:00"), End: mustTimeOfDay(t, "07:00")},
+	}, nil).Value()
+
+	avoiding := quiet.WithHolidays("vn", true)
+	if avoiding.IsErr() {
+		t.Fatalf("WithHolidays() error = %v", avoiding.Error())
+	}
+	prefs := avoiding.Value()
+	if prefs.Country() != "VN" || !prefs.AvoidsHolidays() {
+		t.Fatalf("unexpected holiday settings %q %v", prefs.Country(), prefs.AvoidsHolidays())
+	}
+
+	// Tết 2026 runs from the eve on Monday 16 February to Thursday the 19th;
+	// the first working day after it is Friday, once the quiet night is over
+	at := time.Date(2026, time.February, 16, 0, 0, 0, 0, loc)
+	want := time.Date(2026, time.February, 20, 7, 0, 0, 0, loc)
+	if got := prefs.NextAllowedTime(at, loc); !got.Equal(want) {
+		t.Errorf("NextAllowedTime() = %v, want %v", got, want)
+	}
+	if prefs.IsAllowed(time.Date(2026, time.February, 17, 12, 0, 0, 0, loc), loc) {
+		t.Error("deliveries should not be allowed on avoided holidays")
+	}
+
+	// Working days and letters meant for the holiday are not moved
+	workday := time.Date(2026, time.February, 24, 9, 0, 0, 0, loc)
+	if got := prefs.NextAllowedTime(workday, loc); !got.Equal(workday) {
+		t.Errorf("NextAllowedTime() on a working day = %v, want %v", got, workday)
+	}
+	if got := prefs.WithoutHolidayAvoidance().NextAllowedTime(at, loc); !got.Equal(time.Date(2026, time.February, 16, 7, 0, 0, 0, loc)) {
+		t.Errorf("NextAllowedTime() without avoidance = %v", got)
+	}
+
+	if quiet.WithHolidays("", true).IsOk() {
+		t.Error("avoiding holidays without a country should be rejected")
+	}
+	if quiet.WithHolidays("XX", false).IsOk() {
+		t.Error("countries without a calendar should be rejected")
+	}
+}
@@ -0,0 +1,30 @@
+// Package user contains the weekly digest schedule for user domain
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// The weekly digest is sent on DigestWeekday from DigestHour in the user's
+// timezone
+const (
+	DigestWeekday = time.Monday
+	DigestHour    = 9
+)
+
+// WeeklyDigestDue returns the week whose digest is due for the user at now,
+// as an ISO week such as "2026-W43". The digest is due from DigestHour until
+// midnight on DigestWeekday in the user's timezone, so each user gets at most
+// one digest per week whenever the job runs that day.
+func (up UserProfile) WeeklyDigestDue(now time.Time) common.Option[string] {
+	local := now.In(up.user.Location())
+	if local.Weekday() != DigestWeekday || local.Hour() < DigestHour {
+		return common.None[string]()
+	}
+
+	year, week := local.ISOWeek()
+	return common.Some(fmt.Sprintf("%d-W%02d", year, week))
+}
@@ -0,0 +1,33 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestWeeklyDigestDue(t *testing.T) {
+	profile := NewUserProfile(User{id: uuid.New(), email: "me@example.com", timezone: "Asia/Ho_Chi_Minh"})
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		// 02:00 UTC is 09:00 on Monday in Ho Chi Minh City
+		{"monday morning", time.Date(2026, time.October, 19, 2, 0, 0, 0, time.UTC), "2026-W43"},
+		{"before the digest hour", time.Date(2026, time.October, 19, 1, 59, 0, 0, time.UTC), ""},
+		// 23:59 on Monday in Ho Chi Minh City
+		{"late monday", time.Date(2026, time.October, 19, 16, 59, 0, 0, time.UTC), "2026-W43"},
+		{"tuesday", time.Date(2026, time.October, 19, 17, 0, 0, 0, time.UTC), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profile.WeeklyDigestDue(tt.now).ValueOr(""); got != tt.want {
+				t.Errorf("WeeklyDigestDue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
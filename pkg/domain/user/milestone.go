@@ -0,0 +1,133 @@
+// Package user contains personal milestones for user domain
+package user
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// MaxMilestones bounds the number of milestones on a profile
+const MaxMilestones = 20
+
+// MilestoneBirthday is the key of the user's birthday
+const MilestoneBirthday = "birthday"
+
+// milestoneKeyRegex allows short lowercase keys such as "wedding" or "first-job"
+var milestoneKeyRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// Milestone is a personal date letters can be anchored to, such as a
+// birthday or a wedding day. Only the calendar date matters; anniversaries
+// are counted from it.
+type Milestone struct {
+	key  string
+	name string
+	date time.Time
+}
+
+// NewMilestone validates a milestone. The name defaults to the key.
+func NewMilestone(key, name string, date time.Time) common.Result[Milestone] {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if !milestoneKeyRegex.MatchString(key) {
+		return common.Err[Milestone](fmt.Errorf("invalid milestone key %q (use lowercase letters, digits, - and _)", key))
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = key
+	}
+	if len(name) > 100 {
+		return common.Err[Milestone](errors.New("milestone name is too long (max 100 characters)"))
+	}
+
+	if date.IsZero() {
+		return common.Err[Milestone](fmt.Errorf("milestone %q needs a date", key))
+	}
+
+	return common.Ok(Milestone{
+		key:  key,
+		name: name,
+		date: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+	})
+}
+
+func (m Milestone) Key() string {
+	return m.key
+}
+
+func (m Milestone) Name() string {
+	return m.name
+}
+
+// Date returns the calendar date of the milestone as midnight UTC
+func (m Milestone) Date() time.Time {
+	return m.date
+}
+
+// Anniversary returns the calendar date n years after the milestone. A
+// milestone on February 29 falls on February 28 in other years.
+func (m Milestone) Anniversary(n int) time.Time {
+	year := m.date.Year() + n
+	day := m.date.Day()
+	if last := time.Date(year, m.date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > last {
+		day = last
+	}
+	return time.Date(year, m.date.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// Milestones returns the personal milestones of the user
+func (up UserProfile) Milestones() []Milestone {
+	return up.milestones
+}
+
+// Milestone returns the milestone with the given key
+func (up UserProfile) Milestone(key string) common.Option[Milestone] {
+	key = strings.ToLower(strings.TrimSpace(key))
+	for _, milestone := range up.milestones {
+		if milestone.key == key {
+			return common.Some(milestone)
+		}
+	}
+	return common.None[Milestone]()
+}
+
+// WithMilestones returns a new UserProfile with its milestones replaced
+func (up UserProfile) WithMilestones(milestones []Milestone) common.Result[UserProfile] {
+	if len(milestones) > MaxMilestones {
+		return common.Err[UserProfile](fmt.Errorf("at most %d milestones are allowed", MaxMilestones))
+	}
+
+	seen := make(map[string]bool, len(milestones))
+	for _, milestone := range milestones {
+		if seen[milestone.key] {
+			return common.Err[UserProfile](fmt.Errorf("duplicate milestone %q", milestone.key))
+		}
+		seen[milestone.key] = true
+	}
+
+	updated := up
+	updated.milestones = append([]Milestone(nil), milestones...)
+	return common.Ok(updated)
+}
+
+// CorrectedMilestones returns the milestones of after whose date differs
+// from the milestone with the same key in before. Added and removed
+// milestones are not corrections.
+func CorrectedMilestones(before, after []Milestone) []Milestone {
+	previous := make(map[string]time.Time, len(before))
+	for _, milestone := range before {
+		previous[milestone.key] = milestone.date
+	}
+
+	var corrected []Milestone
+	for _, milestone := range after {
+		if date, ok := previous[milestone.key]; ok && !date.Equal(milestone.date) {
+			corrected = append(corrected, milestone)
+		}
+	}
+	return corrected
+}
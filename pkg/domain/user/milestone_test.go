@@ -0,0 +1,60 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMilestoneAnniversary(t *testing.T) {
+	leapDay := NewMilestone("Birthday", "", time.Date(1996, time.February, 29, 18, 30, 0, 0, time.UTC))
+	if leapDay.IsErr() {
+		t.Fatalf("NewMilestone() error = %v", leapDay.Error())
+	}
+	if leapDay.Value().Key() != MilestoneBirthday || leapDay.Value().Name() != MilestoneBirthday {
+		t.Errorf("key, name = %q, %q, want the lowercase key for both", leapDay.Value().Key(), leapDay.Value().Name())
+	}
+
+	tests := []struct {
+		years int
+		want  time.Time
+	}{
+		{0, time.Date(1996, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{4, time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{40, time.Date(2036, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{41, time.Date(2037, time.February, 28, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := leapDay.Value().Anniversary(tt.years); !got.Equal(tt.want) {
+			t.Errorf("Anniversary(%d) = %v, want %v", tt.years, got, tt.want)
+		}
+	}
+
+	if NewMilestone("our wedding", "", time.Now()).IsOk() {
+		t.Error("keys with spaces should be rejected")
+	}
+}
+
+func TestProfileMilestones(t *testing.T) {
+	profile := NewUserProfile(User{id: uuid.New(), email: "me@example.com", timezone: "UTC"})
+	birthday := NewMilestone(MilestoneBirthday, "My birthday", time.Date(1990, time.May, 4, 0, 0, 0, 0, time.UTC)).Value()
+	wedding := NewMilestone("wedding", "Our wedding", time.Date(2020, time.June, 20, 0, 0, 0, 0, time.UTC)).Value()
+
+	updated := profile.WithMilestones([]Milestone{birthday, wedding})
+	if updated.IsErr() {
+		t.Fatalf("WithMilestones() error = %v", updated.Error())
+	}
+	if found := updated.Value().Milestone("Wedding"); found.IsNone() || found.Value().Name() != "Our wedding" {
+		t.Errorf("Milestone(wedding) = %v", found)
+	}
+	if profile.WithMilestones([]Milestone{birthday, birthday}).IsOk() {
+		t.Error("duplicate milestones should be rejected")
+	}
+
+	correctedBirthday := NewMilestone(MilestoneBirthday, "My birthday", time.Date(1990, time.May, 14, 0, 0, 0, 0, time.UTC)).Value()
+	corrected := CorrectedMilestones([]Milestone{birthday, wedding}, []Milestone{correctedBirthday, wedding})
+	if len(corrected) != 1 || corrected[0].Key() != MilestoneBirthday {
+		t.Errorf("CorrectedMilestones() = %v, want only the birthday", corrected)
+	}
+}
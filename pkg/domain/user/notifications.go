@@ -0,0 +1,163 @@
+// Package user contains notification preferences for user domain
+package user
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+// NotificationCategory is a kind of email a user can unsubscribe from
+type NotificationCategory string
+
+const (
+	NotifyEmail             NotificationCategory = "email"              // Every email, including delivered letters
+	NotifyDeliveryReminders NotificationCategory = "delivery_reminders" // Reminders about upcoming deliveries; check-in warnings are always sent
+	NotifyMarketing         NotificationCategory = "marketing"          // News and offers
+	NotifySecurityAlerts    NotificationCategory = "security_alerts"    // Sign-ins and account changes
+	NotifyWeeklyDigest      NotificationCategory = "weekly_digest"      // Weekly summary of upcoming letters
+)
+
+// NotificationCategories lists the notification categories
+var NotificationCategories = []NotificationCategory{
+	NotifyEmail,
+	NotifyDeliveryReminders,
+	NotifyMarketing,
+	NotifySecurityAlerts,
+	NotifyWeeklyDigest,
+}
+
+// ParseNotificationCategory parses a notification category name
+func ParseNotificationCategory(value string) (NotificationCategory, error) {
+	category := NotificationCategory(strings.ToLower(strings.TrimSpace(value)))
+	for _, known := range NotificationCategories {
+		if category == known {
+			return category, nil
+		}
+	}
+	return "", errors.New("unknown notification category: " + value)
+}
+
+// OneClick reports whether emails in the category may carry a one-click
+// unsubscribe link. Only optional categories qualify: turning email off
+// fails every letter still to be delivered, and security alerts must not
+// be stopped by a link anyone holding the email can follow.
+func (c NotificationCategory) OneClick() bool {
+	switch c {
+	case NotifyDeliveryReminders, NotifyMarketing, NotifyWeeklyDigest:
+		return true
+	default:
+		return false
+	}
+}
+
+// NotificationSettings are the values of notification preferences
+type NotificationSettings struct {
+	EmailEnabled      bool // Turns every email off when false
+	PushEnabled       bool
+	WebhookURL        common.Option[string] // HTTPS endpoint notified of deliveries
+	DeliveryReminders bool
+	MarketingEmails   bool
+	SecurityAlerts    bool
+	WeeklyDigest      bool
+}
+
+// NotificationPreferences controls which notifications a user receives.
+// Email in a category is only sent when email is enabled and the category
+// is too.
+type NotificationPreferences struct {
+	settings NotificationSettings
+}
+
+// NewNotificationPreferences validates notification settings
+func NewNotificationPreferences(settings NotificationSettings) common.Result[NotificationPreferences] {
+	if settings.WebhookURL.IsSome() {
+		parsed, err := url.Parse(settings.WebhookURL.Value())
+		if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			return common.Err[NotificationPreferences](errors.New("webhook URL must be an absolute https URL"))
+		}
+	}
+	return common.Ok(NotificationPreferences{settings: settings})
+}
+
+// DefaultNotificationPreferences sends letters, reminders and security
+// alerts by email; everything else needs opting in
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{settings: NotificationSettings{
+		EmailEnabled:      true,
+		WebhookURL:        common.None[string](),
+		DeliveryReminders: true,
+		SecurityAlerts:    true,
+	}}
+}
+
+// Settings returns the values of the preferences
+func (p NotificationPreferences) Settings() NotificationSettings {
+	return p.settings
+}
+
+// AllowsEmail returns true if email in the category may be sent
+func (p NotificationPreferences) AllowsEmail(category NotificationCategory) bool {
+	if !p.settings.EmailEnabled {
+		return false
+	}
+
+	switch category {
+	case NotifyDeliveryReminders:
+		return p.settings.DeliveryReminders
+	case NotifyMarketing:
+		return p.settings.MarketingEmails
+	case NotifySecurityAlerts:
+		return p.settings.SecurityAlerts
+	case NotifyWeeklyDigest:
+		return p.settings.WeeklyDigest
+	default:
+		return true
+	}
+}
+
+// WithoutCategory returns the preferences unsubscribed from a category.
+// Unsubscribing from email turns every email off.
+func (p NotificationPreferences) WithoutCategory(category NotificationCategory) NotificationPreferences {
+	updated := p
+	switch category {
+	case NotifyEmail:
+		updated.settings.EmailEnabled = false
+	case NotifyDeliveryReminders:
+		updated.settings.DeliveryReminders = false
+	case NotifyMarketing:
+		updated.settings.MarketingEmails = false
+	case NotifySecurityAlerts:
+		updated.settings.SecurityAlerts = false
+	case NotifyWeeklyDigest:
+		updated.settings.WeeklyDigest = false
+	}
+	return updated
+}
+
+// withEmail returns the preferences with every email turned on or off
+func (p NotificationPreferences) withEmail(enabled bool) NotificationPreferences {
+	updated := p
+	updated.settings.EmailEnabled = enabled
+	return updated
+}
+
+// NotificationPreferences returns which notifications the user receives
+func (up UserProfile) NotificationPreferences() NotificationPreferences {
+	return up.notifications
+}
+
+// WithNotificationPreferences returns a new UserProfile with updated
+// notification preferences
+func (up UserProfile) WithNotificationPreferences(prefs NotificationPreferences) UserProfile {
+	updated := up
+	updated.notifications = prefs
+	return updated
+}
+
+// AllowsEmail returns true if the user receives email in the category
+func (up UserProfile) AllowsEmail(category NotificationCategory) bool {
+	return up.notifications.AllowsEmail(category)
+}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+)
+
+func TestNotificationPreferences(t *testing.T) {
+	prefs := DefaultNotificationPreferences()
+	if !prefs.AllowsEmail(NotifyEmail) || !prefs.AllowsEmail(NotifyDeliveryReminders) || prefs.AllowsEmail(NotifyMarketing) {
+		t.Error("defaults should send letters and reminders but no marketing")
+	}
+
+	// Unsubscribing from a category leaves the others alone
+	noReminders := prefs.WithoutCategory(NotifyDeliveryReminders)
+	if noReminders.AllowsEmail(NotifyDeliveryReminders) || !noReminders.AllowsEmail(NotifySecurityAlerts) {
+		t.Error("WithoutCategory() should only turn off its category")
+	}
+
+	// Turning email off stops every category
+	noEmail := prefs.WithoutCategory(NotifyEmail)
+	for _, category := range NotificationCategories {
+		if noEmail.AllowsEmail(category) {
+			t.Errorf("AllowsEmail(%s) should be false with email off", category)
+		}
+	}
+
+	settings := prefs.Settings()
+	settings.WebhookURL = common.Some("http://example.com/hook")
+	if NewNotificationPreferences(settings).IsOk() {
+		t.Error("non-https webhooks should be rejected")
+	}
+
+	if _, err := ParseNotificationCategory(" Weekly_Digest "); err != nil {
+		t.Errorf("ParseNotificationCategory() error = %v", err)
+	}
+	if _, err := ParseNotificationCategory("newsletter"); err == nil {
+		t.Error("unknown categories should be rejected")
+	}
+
+	if NotifyEmail.OneClick() || NotifySecurityAlerts.OneClick() || !NotifyWeeklyDigest.OneClick() {
+		t.Error("only optional categories should allow one-click unsubscribing")
+	}
+}
+
+func TestProfileEmailNotifications(t *testing.T) {
+	profile := NewUserProfile(User{id: uuid.New(), email: "me@example.com", timezone: "UTC"})
+	settings := profile.NotificationPreferences().Settings()
+	settings.WeeklyDigest = true
+	profile = profile.WithNotificationPreferences(NewNotificationPreferences(settings).Value())
+
+	// The email switch keeps the categories for when email is turned back on
+	off := profile.WithEmailNotifications(false)
+	if off.IsEmailNotificationsEnabled() || off.AllowsEmail(NotifyWeeklyDigest) {
+		t.Error("email should be off")
+	}
+	if on := off.WithEmailNotifications(true); !on.AllowsEmail(NotifyWeeklyDigest) {
+		t.Error("turning email back on should restore the weekly digest")
+	}
+}
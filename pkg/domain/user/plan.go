@@ -0,0 +1,65 @@
+// Package user contains subscription plans for user domain
+package user
+
+import (
+	"errors"
+	"strings"
+)
+
+// Plan is the subscription plan of a user
+type Plan string
+
+const (
+	PlanFree Plan = "free"
+	PlanPro  Plan = "pro"
+)
+
+// PlanLimits are the limits of a plan. Zero counts and sizes mean unlimited.
+type PlanLimits struct {
+	MaxScheduledMessages int   // Messages waiting for delivery: scheduled, armed or failed
+	MaxAttachmentBytes   int64 // Total size of all attachments
+	Recurrence           bool  // Whether messages may recur
+}
+
+// Usage is what a user currently consumes of their plan's limits
+type Usage struct {
+	ScheduledMessages int
+	AttachmentBytes   int64
+}
+
+// ParsePlan parses a plan name
+func ParsePlan(value string) (Plan, error) {
+	switch plan := Plan(strings.ToLower(strings.TrimSpace(value))); plan {
+	case PlanFree, PlanPro:
+		return plan, nil
+	default:
+		return "", errors.New("unknown plan: " + value)
+	}
+}
+
+// orFree returns the plan, treating an unset plan as free
+func (p Plan) orFree() Plan {
+	if p == "" {
+		return PlanFree
+	}
+	return p
+}
+
+// WithPlan returns a new UserProfile on a different plan
+func (up UserProfile) WithPlan(plan Plan) UserProfile {
+	updated := up
+	updated.plan = plan.orFree()
+	return updated
+}
+
+// AllowsScheduledMessages reports whether count more waiting messages fit
+// within the limits
+func (l PlanLimits) AllowsScheduledMessages(usage Usage, count int) bool {
+	return l.MaxScheduledMessages <= 0 || usage.ScheduledMessages+count <= l.MaxScheduledMessages
+}
+
+// AllowsAttachmentBytes reports whether size more attachment bytes fit
+// within the limits
+func (l PlanLimits) AllowsAttachmentBytes(usage Usage, size int64) bool {
+	return l.MaxAttachmentBytes <= 0 || usage.AttachmentBytes+size <= l.MaxAttachmentBytes
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
 )
 
 // User represents an immutable user entity
@@ -15,16 +16,31 @@ type User struct {
 	email     string
 	name      string
 	timezone  string
+	locale    i18n.Locale // Language of emails sent to the user
 	createdAt time.Time
 	updatedAt time.Time
 }
 
 // UserProfile represents the user's profile information
 type UserProfile struct {
-	user               User
-	profilePictureURL  common.Option[string]
-	emailNotifications bool
-	notificationEmail  common.Option[string]
+	user              User
+	profilePictureURL common.Option[string]
+	notifications     NotificationPreferences
+	notificationEmail common.Option[string]
+	delivery          DeliveryPreferences
+	plan              Plan
+	milestones        []Milestone
+}
+
+// StoredUserProfile represents persisted profile data used to reconstruct a profile
+type StoredUserProfile struct {
+	User                User
+	ProfilePictureURL   common.Option[string]
+	Notifications       NotificationPreferences
+	NotificationEmail   common.Option[string]
+	DeliveryPreferences DeliveryPreferences
+	Plan                Plan
+	Milestones          []Milestone
 }
 
 // CreateUserRequest contains data needed to create a new user
@@ -32,6 +48,7 @@ type CreateUserRequest struct {
 	Email    string
 	Name     string
 	Timezone string
+	Locale   string // Defaults to i18n.DefaultLocale when empty
 	UserID   uuid.UUID
 }
 
@@ -39,6 +56,7 @@ type CreateUserRequest struct {
 type UpdateUserRequest struct {
 	Name     common.Option[string]
 	Timezone common.Option[string]
+	Locale   common.Option[string]
 }
 
 // UpdateProfileRequest contains data for updating user profile
@@ -46,6 +64,8 @@ type UpdateProfileRequest struct {
 	ProfilePictureURL  common.Option[string]
 	EmailNotifications common.Option[bool]
 	NotificationEmail  common.Option[string]
+	Delivery           common.Option[DeliveryPreferences]
+	Milestones         common.Option[[]Milestone]
 }
 
 // NewUser creates a new User instance with validation
@@ -63,6 +83,7 @@ func NewUser(req CreateUserRequest) common.Result[User] {
 		email:     validReq.Value().Email,
 		name:      validReq.Value().Name,
 		timezone:  validReq.Value().Timezone,
+		locale:    i18n.Locale(validReq.Value().Locale),
 		createdAt: now,
 		updatedAt: now,
 	}
@@ -79,10 +100,26 @@ func NewUser(req CreateUserRequest) common.Result[User] {
 // NewUserProfile creates a new UserProfile with default settings
 func NewUserProfile(user User) UserProfile {
 	return UserProfile{
-		user:               user,
-		profilePictureURL:  common.None[string](),
-		emailNotifications: true,
-		notificationEmail:  common.None[string](),
+		user:              user,
+		profilePictureURL: common.None[string](),
+		notifications:     DefaultNotificationPreferences(),
+		notificationEmail: common.None[string](),
+		delivery:          DefaultDeliveryPreferences(),
+		plan:              PlanFree,
+		milestones:        nil,
+	}
+}
+
+// RestoreUserProfile rebuilds a profile from stored data
+func RestoreUserProfile(data StoredUserProfile) UserProfile {
+	return UserProfile{
+		user:              data.User,
+		profilePictureURL: data.ProfilePictureURL,
+		notifications:     data.Notifications,
+		notificationEmail: data.NotificationEmail,
+		delivery:          data.DeliveryPreferences,
+		plan:              data.Plan.orFree(),
+		milestones:        data.Milestones,
 	}
 }
 
@@ -103,6 +140,20 @@ func (u User) Timezone() string {
 	return u.timezone
 }
 
+// Location returns the user's timezone, or UTC if it cannot be loaded
+func (u User) Location() *time.Location {
+	loc, err := time.LoadLocation(u.timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Locale returns the language of emails sent to the user
+func (u User) Locale() i18n.Locale {
+	return u.locale
+}
+
 func (u User) CreatedAt() time.Time {
 	return u.createdAt
 }
@@ -121,13 +172,21 @@ func (up UserProfile) ProfilePictureURL() common.Option[string] {
 }
 
 func (up UserProfile) EmailNotifications() bool {
-	return up.emailNotifications
+	return up.notifications.settings.EmailEnabled
 }
 
 func (up UserProfile) NotificationEmail() common.Option[string] {
 	return up.notificationEmail
 }
 
+func (up UserProfile) DeliveryPreferences() DeliveryPreferences {
+	return up.delivery
+}
+
+func (up UserProfile) Plan() Plan {
+	return up.plan
+}
+
 // Pure transformation functions (return new instances)
 
 // WithName returns a new User with updated name
@@ -141,6 +200,7 @@ func (u User) WithName(name string) common.Result[User] {
 		email:     u.email,
 		name:      name,
 		timezone:  u.timezone,
+		locale:    u.locale,
 		createdAt: u.createdAt,
 		updatedAt: time.Now(),
 	}
@@ -159,12 +219,26 @@ func (u User) WithTimezone(timezone string) common.Result[User] {
 		email:     u.email,
 		name:      u.name,
 		timezone:  validTz.Value(),
+		locale:    u.locale,
 		createdAt: u.createdAt,
 		updatedAt: time.Now(),
 	}
 	return common.Ok(updated)
 }
 
+// WithLocale returns a new User with updated locale
+func (u User) WithLocale(locale string) common.Result[User] {
+	validLocale := validateLocale(locale)
+	if validLocale.IsErr() {
+		return common.Err[User](validLocale.Error())
+	}
+
+	updated := u
+	updated.locale = validLocale.Value()
+	updated.updatedAt = time.Now()
+	return common.Ok(updated)
+}
+
 // UpdateUser applies updates to a user
 func (u User) UpdateUser(req UpdateUserRequest) common.Result[User] {
 	result := common.Ok(u)
@@ -183,6 +257,13 @@ func (u User) UpdateUser(req UpdateUserRequest) common.Result[User] {
 		})
 	}
 
+	// Apply locale update if provided
+	if req.Locale.IsSome() {
+		result = common.Bind(result, func(user User) common.Result[User] {
+			return user.WithLocale(req.Locale.Value())
+		})
+	}
+
 	return result
 }
 
@@ -194,10 +275,13 @@ func (up UserProfile) WithProfilePicture(url string) common.Result[UserProfile]
 	}
 
 	updated := UserProfile{
-		user:               up.user,
-		profilePictureURL:  common.Some(validURL.Value()),
-		emailNotifications: up.emailNotifications,
-		notificationEmail:  up.notificationEmail,
+		user:              up.user,
+		profilePictureURL: common.Some(validURL.Value()),
+		notifications:     up.notifications,
+		notificationEmail: up.notificationEmail,
+		delivery:          up.delivery,
+		plan:              up.plan,
+		milestones:        up.milestones,
 	}
 	return common.Ok(updated)
 }
@@ -205,10 +289,13 @@ func (up UserProfile) WithProfilePicture(url string) common.Result[UserProfile]
 // WithEmailNotifications returns a new UserProfile with updated notification settings
 func (up UserProfile) WithEmailNotifications(enabled bool) UserProfile {
 	return UserProfile{
-		user:               up.user,
-		profilePictureURL:  up.profilePictureURL,
-		emailNotifications: enabled,
-		notificationEmail:  up.notificationEmail,
+		user:              up.user,
+		profilePictureURL: up.profilePictureURL,
+		notifications:     up.notifications.withEmail(enabled),
+		notificationEmail: up.notificationEmail,
+		delivery:          up.delivery,
+		plan:              up.plan,
+		milestones:        up.milestones,
 	}
 }
 
@@ -220,14 +307,43 @@ func (up UserProfile) WithNotificationEmail(email string) common.Result[UserProf
 	}
 
 	updated := UserProfile{
-		user:               up.user,
-		profilePictureURL:  up.profilePictureURL,
-		emailNotifications: up.emailNotifications,
-		notificationEmail:  common.Some(validEmail.Value()),
+		user:              up.user,
+		profilePictureURL: up.profilePictureURL,
+		notifications:     up.notifications,
+		notificationEmail: common.Some(validEmail.Value()),
+		delivery:          up.delivery,
+		plan:              up.plan,
+		milestones:        up.milestones,
 	}
 	return common.Ok(updated)
 }
 
+// WithUser returns a new UserProfile for an updated user
+func (up UserProfile) WithUser(u User) UserProfile {
+	return UserProfile{
+		user:              u,
+		profilePictureURL: up.profilePictureURL,
+		notifications:     up.notifications,
+		notificationEmail: up.notificationEmail,
+		delivery:          up.delivery,
+		plan:              up.plan,
+		milestones:        up.milestones,
+	}
+}
+
+// WithDeliveryPreferences returns a new UserProfile with updated quiet hours and delivery windows
+func (up UserProfile) WithDeliveryPreferences(prefs DeliveryPreferences) UserProfile {
+	return UserProfile{
+		user:              up.user,
+		profilePictureURL: up.profilePictureURL,
+		notifications:     up.notifications,
+		notificationEmail: up.notificationEmail,
+		delivery:          prefs,
+		plan:              up.plan,
+		milestones:        up.milestones,
+	}
+}
+
 // UpdateProfile applies updates to a user profile
 func (up UserProfile) UpdateProfile(req UpdateProfileRequest) common.Result[UserProfile] {
 	result := common.Ok(up)
@@ -253,6 +369,20 @@ func (up UserProfile) UpdateProfile(req UpdateProfileRequest) common.Result[User
 		})
 	}
 
+	// Apply delivery preferences update if provided
+	if req.Delivery.IsSome() {
+		result = common.Map(result, func(profile UserProfile) UserProfile {
+			return profile.WithDeliveryPreferences(req.Delivery.Value())
+		})
+	}
+
+	// Apply milestones update if provided
+	if req.Milestones.IsSome() {
+		result = common.Bind(result, func(profile UserProfile) common.Result[UserProfile] {
+			return profile.WithMilestones(req.Milestones.Value())
+		})
+	}
+
 	return result
 }
 
@@ -271,5 +401,5 @@ func (up UserProfile) GetEffectiveEmail() string {
 
 // IsEmailNotificationsEnabled returns true if email notifications are enabled
 func (up UserProfile) IsEmailNotificationsEnabled() bool {
-	return up.emailNotifications
+	return up.notifications.AllowsEmail(NotifyEmail)
 }
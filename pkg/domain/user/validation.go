@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
 )
 
 // Email validation regex pattern
@@ -34,10 +35,17 @@ func validateCreateUserRequest(req CreateUserRequest) common.Result[CreateUserRe
 		return common.Err[CreateUserRequest](timezoneResult.Error())
 	}
 
+	// Validate locale
+	localeResult := validateLocale(req.Locale)
+	if localeResult.IsErr() {
+		return common.Err[CreateUserRequest](localeResult.Error())
+	}
+
 	return common.Ok(CreateUserRequest{
 		Email:    emailResult.Value(),
 		Name:     nameResult.Value(),
 		Timezone: timezoneResult.Value(),
+		Locale:   string(localeResult.Value()),
 		UserID:   req.UserID,
 	})
 }
@@ -103,6 +111,19 @@ func validateTimezone(timezone string) common.Result[string] {
 	return common.Ok(timezone)
 }
 
+// validateLocale validates a locale, defaulting to i18n.DefaultLocale
+func validateLocale(locale string) common.Result[i18n.Locale] {
+	if strings.TrimSpace(locale) == "" {
+		return common.Ok(i18n.DefaultLocale)
+	}
+
+	parsed, err := i18n.ParseLocale(locale)
+	if err != nil {
+		return common.Err[i18n.Locale](err)
+	}
+	return common.Ok(parsed)
+}
+
 // validateProfilePictureURL validates profile picture URL
 func validateProfilePictureURL(pictureURL string) common.Result[string] {
 	if pictureURL == "" {
@@ -190,6 +211,7 @@ func normalizeUser(user User) common.Result[User] {
 		email:     normalizedEmail,
 		name:      normalizedName,
 		timezone:  normalizedTimezone,
+		locale:    user.locale.OrDefault(),
 		createdAt: user.createdAt,
 		updatedAt: user.updatedAt,
 	}
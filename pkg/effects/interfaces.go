@@ -4,10 +4,12 @@ package effects
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
 )
@@ -25,21 +27,41 @@ type Database interface {
 	SaveUserProfile(ctx context.Context, profile user.UserProfile) common.Result[user.UserProfile]
 	FindUserProfile(ctx context.Context, userID uuid.UUID) common.Result[user.UserProfile]
 	UpdateUserProfile(ctx context.Context, profile user.UserProfile) common.Result[user.UserProfile]
+	FindUserUsage(ctx context.Context, userID uuid.UUID) common.Result[user.Usage]
 
 	// Message operations
 	SaveMessage(ctx context.Context, msg message.Message) common.Result[message.Message]
 	FindMessageByID(ctx context.Context, messageID uuid.UUID) common.Result[message.Message]
 	FindMessagesByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) common.Result[[]message.Message]
+	FindMessagesPage(ctx context.Context, userID uuid.UUID, options message.ListOptions) common.Result[MessagePage]
 	FindMessagesByStatus(ctx context.Context, status message.MessageStatus, limit int) common.Result[[]message.Message]
 	FindDueMessages(ctx context.Context, before time.Time, limit int) common.Result[[]message.Message]
+	FindPendingMessages(ctx context.Context, userID uuid.UUID) common.Result[[]message.Message]
 	UpdateMessage(ctx context.Context, msg message.Message) common.Result[message.Message]
 	DeleteMessage(ctx context.Context, messageID uuid.UUID) common.Result[bool]
+	SearchMessages(ctx context.Context, query MessageSearchQuery) common.Result[[]MessageSearchResult]
+
+	// Message trash operations. DeleteMessage removes a message for good;
+	// trashing and restoring go through UpdateMessage.
+	FindDeletedMessages(ctx context.Context, userID uuid.UUID, limit, offset int) common.Result[[]message.Message]
+	FindPurgeableMessages(ctx context.Context, deletedBefore time.Time, limit int) common.Result[[]message.Message]
 
 	// Message attachment operations
 	SaveMessageAttachment(ctx context.Context, attachment message.MessageAttachment) common.Result[message.MessageAttachment]
 	FindAttachmentsByMessageID(ctx context.Context, messageID uuid.UUID) common.Result[[]message.MessageAttachment]
 	DeleteAttachment(ctx context.Context, attachmentID uuid.UUID) common.Result[bool]
 
+	// Tag operations
+	SaveTag(ctx context.Context, tag message.Tag) common.Result[message.Tag]
+	FindTagByID(ctx context.Context, tagID uuid.UUID) common.Result[message.Tag]
+	FindTagsByUserID(ctx context.Context, userID uuid.UUID) common.Result[[]TagUsage]
+	UpdateTag(ctx context.Context, tag message.Tag) common.Result[message.Tag]
+	DeleteTag(ctx context.Context, tagID uuid.UUID) common.Result[bool]
+	MergeTags(ctx context.Context, sourceID, targetID uuid.UUID) common.Result[bool]
+	EnsureTags(ctx context.Context, userID uuid.UUID, names []string) common.Result[[]message.Tag]
+	SetMessageTags(ctx context.Context, messageID uuid.UUID, tagIDs []uuid.UUID) common.Result[bool]
+	FindTagsForMessages(ctx context.Context, messageIDs []uuid.UUID) common.Result[map[uuid.UUID][]message.Tag]
+
 	// Delivery log operations
 	SaveDeliveryLog(ctx context.Context, log DeliveryLog) common.Result[DeliveryLog]
 	FindDeliveryLogsByMessageID(ctx context.Context, messageID uuid.UUID) common.Result[[]DeliveryLog]
@@ -56,10 +78,90 @@ type EmailService interface {
 	ValidateEmailConfiguration(ctx context.Context) common.Result[bool]
 }
 
+// EmailPreviewer is implemented by email services that can render a message
+// without sending it
+type EmailPreviewer interface {
+	PreviewMessage(ctx context.Context, deliveryInfo message.MessageDeliveryInfo) common.Result[EmailPreview]
+}
+
+// CheckInNotifier is implemented by email services that can warn a user
+// about a missed check-in
+type CheckInNotifier interface {
+	SendCheckInWarning(ctx context.Context, warning CheckInWarning) common.Result[EmailResult]
+}
+
+// DigestNotifier is implemented by email services that can send the weekly
+// digest
+type DigestNotifier interface {
+	SendWeeklyDigest(ctx context.Context, digest WeeklyDigestEmail) common.Result[EmailResult]
+}
+
+// DeliveryReceiptStore is implemented by databases that record whether
+// delivered messages were opened and read
+type DeliveryReceiptStore interface {
+	SaveDeliveryReceipt(ctx context.Context, receipt message.DeliveryReceipt) common.Result[message.DeliveryReceipt]
+	UpdateDeliveryReceipt(ctx context.Context, receipt message.DeliveryReceipt) common.Result[message.DeliveryReceipt]
+	FindDeliveryReceiptByID(ctx context.Context, receiptID uuid.UUID) common.Result[message.DeliveryReceipt]
+	FindDeliveryReceiptsByMessageID(ctx context.Context, messageID uuid.UUID) common.Result[[]message.DeliveryReceipt]
+}
+
+// IdempotencyStore is implemented by databases that remember the responses
+// of requests sent with an Idempotency-Key, so retries are not run twice
+type IdempotencyStore interface {
+	// ReserveIdempotencyKey claims a key for a request until the record's
+	// ExpiresAt. When the key is already held and not expired, the existing
+	// record is returned instead.
+	ReserveIdempotencyKey(ctx context.Context, record IdempotencyRecord) common.Result[common.Option[IdempotencyRecord]]
+	// CompleteIdempotencyKey stores the response of a reserved request and
+	// keeps it until the record's ExpiresAt. The record's CreatedAt is the
+	// lease: a reservation taken over after it expired is left alone.
+	CompleteIdempotencyKey(ctx context.Context, record IdempotencyRecord) common.Result[IdempotencyRecord]
+	// ReleaseIdempotencyKey forgets a reservation still in progress, matched
+	// by the record's CreatedAt like CompleteIdempotencyKey
+	ReleaseIdempotencyKey(ctx context.Context, record IdempotencyRecord) common.Result[bool]
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) common.Result[int]
+}
+
+// WeeklyDigestStore is implemented by databases that can list the users
+// subscribed to the weekly digest and remember which digests were sent
+type WeeklyDigestStore interface {
+	// FindWeeklyDigestRecipients returns up to limit users subscribed to the
+	// weekly digest whose ID sorts after the given one, ordered by ID
+	FindWeeklyDigestRecipients(ctx context.Context, after uuid.UUID, limit int) common.Result[[]user.UserProfile]
+	// FindWeeklyDigestMessages returns up to limit letters of a user the
+	// digest reports on as of now: scheduled letters due within period,
+	// failed letters and recurring letters delivered within period before now
+	FindWeeklyDigestMessages(ctx context.Context, userID uuid.UUID, now time.Time, period time.Duration, limit int) common.Result[[]message.Message]
+	// ClaimWeeklyDigest records that the digest of a week is sent to a user.
+	// It returns false when the digest was already claimed.
+	ClaimWeeklyDigest(ctx context.Context, userID uuid.UUID, week string, claimedAt time.Time) common.Result[bool]
+	ReleaseWeeklyDigest(ctx context.Context, userID uuid.UUID, week string) common.Result[bool]
+}
+
+// DeliveryLoadStore is implemented by databases that can count the
+// deliveries due in each minute, so delivery smoothing sees the letters
+// booked by every scheduler process and survives restarts
+type DeliveryLoadStore interface {
+	// CountDeliveriesPerMinute counts the pending deliveries due from from up
+	// to but excluding to, keyed by the start of their minute in UTC and
+	// leaving out the given message
+	CountDeliveriesPerMinute(ctx context.Context, from, to time.Time, exclude uuid.UUID) common.Result[map[time.Time]int]
+}
+
+// AttachmentThumbnailStore is implemented by databases that can record the
+// thumbnails of an attachment after it was saved
+type AttachmentThumbnailStore interface {
+	// SaveAttachmentThumbnails stores the thumbnail keys of a saved
+	// attachment. It returns false when the attachment no longer exists.
+	SaveAttachmentThumbnails(ctx context.Context, attachment message.MessageAttachment) common.Result[bool]
+}
+
 // StorageService interface defines file storage operations
 type StorageService interface {
 	UploadFile(ctx context.Context, fileData FileUpload) common.Result[FileUploadResult]
 	DownloadFile(ctx context.Context, key string) common.Result[FileDownload]
+	// OpenFile opens a file for streaming; the caller closes it
+	OpenFile(ctx context.Context, key string) common.Result[io.ReadCloser]
 	GeneratePresignedURL(ctx context.Context, key string, expiration time.Duration) common.Result[string]
 	DeleteFile(ctx context.Context, key string) common.Result[bool]
 	GetFileMetadata(ctx context.Context, key string) common.Result[FileMetadata]
@@ -85,6 +187,12 @@ type SchedulingService interface {
 	GetScheduledMessages(ctx context.Context, from, to time.Time) common.Result[[]ScheduledMessage]
 }
 
+// SchedulerMetricsProvider is implemented by scheduling services that report
+// how they spread deliveries
+type SchedulerMetricsProvider interface {
+	Metrics() SchedulerMetrics
+}
+
 // NotificationService interface defines notification operations
 type NotificationService interface {
 	SendPushNotification(ctx context.Context, userID uuid.UUID, notification PushNotification) common.Result[NotificationResult]
@@ -104,6 +212,21 @@ type CacheService interface {
 
 // Data structures for side effects
 
+// IdempotencyRecord is a request made with an Idempotency-Key and, once the
+// handler finished, its response. StatusCode is 0 while the request runs.
+// CreatedAt identifies the reservation, so it is kept to the microsecond the
+// database stores.
+type IdempotencyRecord struct {
+	UserID      uuid.UUID
+	Key         string
+	RequestHash string
+	StatusCode  int
+	Headers     map[string]string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
 // DeliveryLog represents a log entry for message delivery attempts
 type DeliveryLog struct {
 	ID          uuid.UUID
@@ -114,6 +237,36 @@ type DeliveryLog struct {
 	Metadata    map[string]interface{}
 }
 
+// MessagePage represents one page of a filtered, sorted message listing
+type MessagePage struct {
+	Messages   []message.Message
+	NextCursor common.Option[message.ListCursor] // None on the last page
+	Total      int                               // Matching messages across all pages
+}
+
+// TagUsage represents a tag together with the number of messages using it
+type TagUsage struct {
+	Tag          message.Tag
+	MessageCount int
+}
+
+// MessageSearchQuery represents a full-text search over a user's messages
+type MessageSearchQuery struct {
+	UserID   uuid.UUID
+	Criteria message.SearchCriteria
+	Limit    int
+	Offset   int
+}
+
+// MessageSearchResult represents a ranked search hit. Snippets are
+// HTML-escaped with matched terms wrapped in <mark> tags.
+type MessageSearchResult struct {
+	Message        message.Message
+	Rank           float64
+	TitleSnippet   string
+	ContentSnippet string
+}
+
 // EmailResult represents the result of an email operation
 type EmailResult struct {
 	MessageID string
@@ -124,6 +277,37 @@ type EmailResult struct {
 	Subject   string
 }
 
+// EmailPreview represents a rendered email that has not been sent
+type EmailPreview struct {
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// CheckInWarning describes a missed check-in. The tokens are signed action
+// tokens for the check-in and cancel links.
+type CheckInWarning struct {
+	RecipientEmail string
+	MessageID      uuid.UUID
+	MessageTitle   string
+	ReleaseAt      time.Time
+	CheckInToken   string
+	CancelToken    string
+	Locale         i18n.Locale    // Language the warning is written in
+	Location       *time.Location // Timezone the release date is shown in
+}
+
+// WeeklyDigestEmail is the weekly digest of one user
+type WeeklyDigestEmail struct {
+	RecipientEmail   string
+	RecipientName    string
+	Week             string         // ISO week of the digest, such as 2026-W43
+	Locale           i18n.Locale    // Language the digest is written in
+	Location         *time.Location // Timezone the delivery dates are shown in
+	Digest           message.WeeklyDigest
+	UnsubscribeToken string // Token for the one-click link unsubscribing from the digest
+}
+
 // EmailStatus represents the status of an email delivery
 type EmailStatus string
 
@@ -197,6 +381,27 @@ type ScheduleResult struct {
 	Status       ScheduleStatus
 }
 
+// SchedulerMetrics represents the state of a scheduling service
+type SchedulerMetrics struct {
+	Scheduler       string           `json:"scheduler"` // "simple" or "river"
+	BatchProcessing bool             `json:"batch_processing"`
+	Smoothing       SmoothingMetrics `json:"smoothing"`
+}
+
+// SmoothingMetrics represents the delivery smoothing policy and what it did
+// since the scheduler started
+type SmoothingMetrics struct {
+	Enabled             bool              `json:"enabled"`
+	PerMinute           int               `json:"per_minute,omitempty"`
+	MaxDelay            map[string]string `json:"max_delay,omitempty"` // Longest delay per message class
+	Booked              int64             `json:"booked"`              // Deliveries given a slot
+	Delayed             int64             `json:"delayed"`             // Deliveries moved after their requested time
+	OverCapacity        int64             `json:"over_capacity"`       // Deliveries with no free slot within their maximum delay
+	TotalDelaySeconds   float64           `json:"-"`
+	AverageDelaySeconds float64           `json:"average_delay_seconds"`
+	LongestDelaySeconds float64           `json:"longest_delay_seconds"`
+}
+
 // ScheduleStatus represents the status of a scheduled message
 type ScheduleStatus string
 
@@ -242,16 +447,9 @@ const (
 	NotificationStatusPending NotificationStatus = "pending"
 )
 
-// NotificationPreferences represents user notification preferences
-type NotificationPreferences struct {
-	EmailEnabled      bool
-	PushEnabled       bool
-	WebhookURL        common.Option[string]
-	DeliveryReminders bool
-	MarketingEmails   bool
-	SecurityAlerts    bool
-	WeeklyDigest      bool
-}
+// NotificationPreferences represents user notification preferences; they
+// are stored with the user profile
+type NotificationPreferences = user.NotificationSettings
 
 // HealthCheckResult represents the result of a health check
 type HealthCheckResult struct {
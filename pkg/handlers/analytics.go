@@ -22,6 +22,14 @@ type AnalyticsSummaryResponse struct {
 	Upcoming       *UpcomingSummary  `json:"upcoming,omitempty"`
 	RecentMessages []MessageOverview `json:"recent_messages"`
 	AttachmentCount int              `json:"attachment_count"`
+	Tags            []TagCount       `json:"tags"`
+}
+
+// TagCount reports how many messages carry a tag.
+type TagCount struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
 }
 
 // AnalyticsTotals groups message counts by state.
@@ -80,6 +88,18 @@ func (h *AnalyticsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	tagCounts := []TagCount{}
+	tagsResult := h.app.Database().FindTagsByUserID(r.Context(), userID)
+	if tagsResult.IsOk() {
+		for _, usage := range tagsResult.Value() {
+			tagCounts = append(tagCounts, TagCount{
+				ID:    usage.Tag.ID().String(),
+				Name:  usage.Tag.Name(),
+				Count: usage.MessageCount,
+			})
+		}
+	}
+
 	recent := buildRecentOverview(messages)
 	upcoming := buildUpcomingSummary(messages)
 
@@ -95,6 +115,7 @@ func (h *AnalyticsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 		Upcoming:       upcoming,
 		RecentMessages: recent,
 		AttachmentCount: attachmentTotal,
+		Tags:            tagCounts,
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
@@ -119,7 +140,7 @@ func buildRecentOverview(messages []message.Message) []MessageOverview {
 			ID:            msg.ID().String(),
 			Title:         msg.Title(),
 			Status:        string(msg.Status()),
-			DeliveryDate:  msg.DeliveryDate().Format(time.RFC3339),
+			DeliveryDate:  visibleDeliveryDate(msg),
 			DeliveryMethod: string(msg.DeliveryMethod()),
 		})
 	}
@@ -130,7 +151,8 @@ func buildRecentOverview(messages []message.Message) []MessageOverview {
 func buildUpcomingSummary(messages []message.Message) *UpcomingSummary {
 	var upcoming *message.Message
 	for _, msg := range messages {
-		if msg.Status() != message.StatusScheduled {
+		// Surprise messages would give their delivery time away here
+		if msg.Status() != message.StatusScheduled || msg.IsDeliveryDateHidden() {
 			continue
 		}
 		if msg.DeliveryDate().Before(time.Now()) {
@@ -13,27 +13,35 @@ import (
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
 	"github.com/thanhphuchuynh/dear-future/pkg/effects"
 	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+	"github.com/thanhphuchuynh/dear-future/pkg/services/thumbnail"
 )
 
 // AttachmentHandler manages file attachments for messages.
 type AttachmentHandler struct {
-	app *composition.App
+	app        *composition.App
+	thumbnails *thumbnail.Service
 }
 
 // AttachmentResponse represents attachment metadata returned to clients.
 type AttachmentResponse struct {
-	ID           string `json:"id"`
-	MessageID    string `json:"message_id"`
-	FileName     string `json:"file_name"`
-	FileType     string `json:"file_type"`
-	FileSize     int64  `json:"file_size"`
-	DownloadURL  string `json:"download_url,omitempty"`
-	UploadedAt   string `json:"uploaded_at"`
+	ID          string                           `json:"id"`
+	MessageID   string                           `json:"message_id"`
+	FileName    string                           `json:"file_name"`
+	FileType    string                           `json:"file_type"`
+	FileSize    int64                            `json:"file_size"`
+	DownloadURL string                           `json:"download_url,omitempty"`
+	UploadedAt  string                           `json:"uploaded_at"`
+	Thumbnails  map[message.ThumbnailSize]string `json:"thumbnails,omitempty"` // Presigned image thumbnail URLs by size
 }
 
 // NewAttachmentHandler creates a new handler instance.
 func NewAttachmentHandler(app *composition.App) *AttachmentHandler {
-	return &AttachmentHandler{app: app}
+	handler := &AttachmentHandler{app: app}
+	if fileHandler := app.FileHandler(); fileHandler != nil {
+		store, _ := app.Database().(effects.AttachmentThumbnailStore)
+		handler.thumbnails = thumbnail.NewService(fileHandler.Storage(), store)
+	}
+	return handler
 }
 
 // Upload handles POST /api/v1/messages/attachments
@@ -89,6 +97,17 @@ func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Attachments count against the storage of the user's plan
+	planResult := loadPlanUsage(r.Context(), h.app, userID)
+	if planResult.IsErr() {
+		respondWithError(w, http.StatusInternalServerError, "failed to load plan usage")
+		return
+	}
+	if allowed := message.ValidateAttachmentForPlan(int64(len(data)), planResult.Value().limits, planResult.Value().usage); allowed.IsErr() {
+		respondWithPlanError(w, allowed.Error())
+		return
+	}
+
 	contentType := header.Header.Get("Content-Type")
 	if contentType == "" {
 		contentType = http.DetectContentType(data)
@@ -129,6 +148,9 @@ func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Images get thumbnails so lists don't download the original
+	h.thumbnails.Start(r.Context(), saveResult.Value(), data)
+
 	response := attachmentToResponse(r.Context(), h.app, saveResult.Value())
 	respondWithJSON(w, http.StatusCreated, response)
 }
@@ -214,10 +236,12 @@ func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	storage := h.app.FileHandler().Storage()
 	if storage != nil {
-		deleteResult := storage.DeleteFile(r.Context(), target.S3Key())
-		if deleteResult.IsErr() {
-			respondWithError(w, http.StatusInternalServerError, "failed to remove file from storage")
-			return
+		for _, key := range target.StorageKeys() {
+			deleteResult := storage.DeleteFile(r.Context(), key)
+			if deleteResult.IsErr() {
+				respondWithError(w, http.StatusInternalServerError, "failed to remove file from storage")
+				return
+			}
 		}
 	}
 
@@ -231,7 +255,7 @@ func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AttachmentHandler) verifyMessageOwnership(r *http.Request, userID uuid.UUID, messageID uuid.UUID) common.Result[message.Message] {
-	msgResult := h.app.Database().FindMessageByID(r.Context(), messageID)
+	msgResult := findLiveMessage(r.Context(), h.app, messageID)
 	if msgResult.IsErr() {
 		return common.Err[message.Message](msgResult.Error())
 	}
@@ -260,6 +284,16 @@ func attachmentToResponse(ctx context.Context, app *composition.App, attachment
 			if urlResult.IsOk() {
 				response.DownloadURL = urlResult.Value()
 			}
+
+			for size, key := range attachment.Thumbnails() {
+				thumbnailResult := storage.GeneratePresignedURL(ctx, key, 15*time.Minute)
+				if thumbnailResult.IsOk() {
+					if response.Thumbnails == nil {
+						response.Thumbnails = make(map[message.ThumbnailSize]string)
+					}
+					response.Thumbnails[size] = thumbnailResult.Value()
+				}
+			}
 		}
 	}
 
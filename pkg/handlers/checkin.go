@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/auth"
+	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+	"github.com/thanhphuchuynh/dear-future/pkg/services/scheduler"
+)
+
+// CheckInHandler handles check-ins for armed messages and cancelling their release.
+type CheckInHandler struct {
+	app *composition.App
+}
+
+// CheckInSettingsRequest configures a check-in message on create and update
+type CheckInSettingsRequest struct {
+	IntervalDays int      `json:"interval_days"`
+	GraceDays    int      `json:"grace_days"`
+	Recipients   []string `json:"recipients"` // Defaults to the author
+}
+
+// CheckInSettingsResponse represents a message's check-in settings
+type CheckInSettingsResponse struct {
+	IntervalDays  int      `json:"interval_days"`
+	GraceDays     int      `json:"grace_days"`
+	Recipients    []string `json:"recipients"`
+	LastCheckInAt string   `json:"last_check_in_at"`
+	WarnedAt      *string  `json:"warned_at,omitempty"`
+	NextCheckInBy string   `json:"next_check_in_by"`
+	ReleaseAt     string   `json:"release_at"`
+}
+
+// CheckInResponse reports the outcome of a check-in
+type CheckInResponse struct {
+	CheckedIn     int      `json:"checked_in"`
+	NextCheckInBy *string  `json:"next_check_in_by,omitempty"`
+	Failed        []string `json:"failed,omitempty"` // IDs of letters that keep their previous timer
+}
+
+// errNoLongerArmed is returned by check-in transitions for letters that were
+// released or cancelled after they were loaded
+var errNoLongerArmed = errors.New("message is not waiting for check-ins")
+
+// NewCheckInHandler creates a new handler instance.
+func NewCheckInHandler(app *composition.App) *CheckInHandler {
+	return &CheckInHandler{app: app}
+}
+
+// CheckIn handles POST /api/v1/checkin, restarting the timer of every armed
+// message of the authenticated user
+func (h *CheckInHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	response, err := h.checkInUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "failed to check in")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// ConfirmCheckIn handles /api/v1/checkin/confirm?token={token}, the check-in
+// link sent with a missed check-in warning. GET shows a confirmation page
+// and only POST checks in, so mail scanners opening the link change nothing.
+func (h *CheckInHandler) ConfirmCheckIn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID := h.tokens().Verify(auth.PurposeCheckIn, r.URL.Query().Get("token"))
+	if userID.IsErr() {
+		respondWithError(w, http.StatusUnauthorized, userID.Error().Error())
+		return
+	}
+
+	locale := middleware.LocaleOf(w)
+	if r.Method == http.MethodGet {
+		respondWithPage(w, http.StatusOK, confirmationPage{
+			Heading: locale.T("checkin.heading"),
+			Text:    locale.T("checkin.confirm"),
+			Button:  locale.T("checkin.button"),
+			Action:  r.URL.RequestURI(),
+		})
+		return
+	}
+
+	response, err := h.checkInUser(r.Context(), userID.Value())
+	switch {
+	case err != nil:
+		respondWithPage(w, http.StatusInternalServerError, confirmationPage{
+			Heading: locale.T("checkin.heading"),
+			Text:    locale.T("checkin.failed"),
+			Button:  locale.T("checkin.button"),
+			Action:  r.URL.RequestURI(),
+		})
+	case len(response.Failed) > 0:
+		respondWithPage(w, http.StatusOK, confirmationPage{
+			Heading: locale.T("checkin.heading"),
+			Text:    locale.T("checkin.partial", response.CheckedIn, response.CheckedIn+len(response.Failed)),
+			Button:  locale.T("checkin.button"),
+			Action:  r.URL.RequestURI(),
+		})
+	default:
+		respondWithPage(w, http.StatusOK, confirmationPage{
+			Heading: locale.T("checkin.heading"),
+			Text:    locale.T("checkin.done"),
+		})
+	}
+}
+
+// CancelRelease handles POST /api/v1/messages/cancel-release?id={id}
+func (h *CheckInHandler) CancelRelease(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	messageID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message id")
+		return
+	}
+
+	cancelled := h.cancelRelease(r.Context(), messageID, common.Some(userID))
+	if cancelled.IsErr() {
+		respondWithCancelError(w, messageID, cancelled.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, buildMessageResponse(cancelled.Value()))
+}
+
+// CancelReleaseByToken handles /api/v1/checkin/cancel?token={token}, the
+// cancel link sent with a missed check-in warning. Like ConfirmCheckIn, GET
+// shows a confirmation page and only POST cancels the release.
+func (h *CheckInHandler) CancelReleaseByToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	messageID := h.tokens().Verify(auth.PurposeCancelRelease, r.URL.Query().Get("token"))
+	if messageID.IsErr() {
+		respondWithError(w, http.StatusUnauthorized, messageID.Error().Error())
+		return
+	}
+
+	locale := middleware.LocaleOf(w)
+	if r.Method == http.MethodGet {
+		respondWithPage(w, http.StatusOK, confirmationPage{
+			Heading: locale.T("cancel_release.heading"),
+			Text:    locale.T("cancel_release.confirm"),
+			Button:  locale.T("cancel_release.button"),
+			Action:  r.URL.RequestURI(),
+		})
+		return
+	}
+
+	cancelled := h.cancelRelease(r.Context(), messageID.Value(), common.None[uuid.UUID]())
+	switch {
+	case cancelled.IsOk():
+		respondWithPage(w, http.StatusOK, confirmationPage{
+			Heading: locale.T("cancel_release.heading"),
+			Text:    locale.T("cancel_release.done"),
+		})
+	case errors.Is(cancelled.Error(), errNoLongerArmed):
+		respondWithPage(w, http.StatusConflict, confirmationPage{
+			Heading: locale.T("cancel_release.heading"),
+			Text:    locale.T("cancel_release.not_armed"),
+		})
+	default:
+		respondWithCancelError(w, messageID.Value(), cancelled.Error())
+	}
+}
+
+// checkInUser restarts the timer of every armed message of a user. Letters
+// that cannot be saved are reported in the response and the others are
+// still checked in; an error is only returned when none could be saved.
+func (h *CheckInHandler) checkInUser(ctx context.Context, userID uuid.UUID) (CheckInResponse, error) {
+	armedResult := h.armedMessages(ctx, userID)
+	if armedResult.IsErr() {
+		slog.Error("Failed to load armed messages", "user_id", userID, "error", armedResult.Error())
+		return CheckInResponse{}, armedResult.Error()
+	}
+
+	now := time.Now()
+	checkIn := func(msg message.Message) (message.Message, error) {
+		if !msg.IsArmed() {
+			return msg, errNoLongerArmed
+		}
+		checkedIn := msg.WithCheckedIn(now)
+		if checkedIn.IsErr() {
+			return msg, checkedIn.Error()
+		}
+		return checkedIn.Value(), nil
+	}
+
+	response := CheckInResponse{}
+	var nextCheckInBy time.Time
+	var lastErr error
+	for _, msg := range armedResult.Value() {
+		// Saved like the scheduler's transitions, so an edit made meanwhile
+		// is checked in rather than failing the whole check-in
+		saveResult := scheduler.ApplyTransition(ctx, h.app.Database(), msg, checkIn)
+		if saveResult.IsErr() {
+			if errors.Is(saveResult.Error(), errNoLongerArmed) {
+				continue
+			}
+			slog.Error("Failed to save check-in", "message_id", msg.ID(), "error", saveResult.Error())
+			response.Failed = append(response.Failed, msg.ID().String())
+			lastErr = saveResult.Error()
+			continue
+		}
+
+		response.CheckedIn++
+		warnAt := saveResult.Value().CheckIn().Value().WarnAt()
+		if nextCheckInBy.IsZero() || warnAt.Before(nextCheckInBy) {
+			nextCheckInBy = warnAt
+		}
+	}
+
+	if response.CheckedIn == 0 && lastErr != nil {
+		return response, lastErr
+	}
+
+	if !nextCheckInBy.IsZero() {
+		formatted := nextCheckInBy.Format(time.RFC3339)
+		response.NextCheckInBy = &formatted
+	}
+
+	return response, nil
+}
+
+// Errors of cancelRelease answered with a status other than 500
+var (
+	errReleaseNotFound = errors.New("message not found")
+	errReleaseDenied   = errors.New("access denied")
+)
+
+// cancelRelease cancels an armed message. The owner is checked when known;
+// token requests are authorized by the token itself.
+func (h *CheckInHandler) cancelRelease(ctx context.Context, messageID uuid.UUID, owner common.Option[uuid.UUID]) common.Result[message.Message] {
+	msgResult := findLiveMessage(ctx, h.app, messageID)
+	if msgResult.IsErr() {
+		return common.Err[message.Message](errReleaseNotFound)
+	}
+
+	msg := msgResult.Value()
+	if owner.IsSome() && msg.UserID() != owner.Value() {
+		return common.Err[message.Message](errReleaseDenied)
+	}
+
+	return scheduler.ApplyTransition(ctx, h.app.Database(), msg, func(msg message.Message) (message.Message, error) {
+		if !msg.IsArmed() {
+			return msg, errNoLongerArmed
+		}
+		cancelled := msg.WithStatus(message.StatusCancelled)
+		if cancelled.IsErr() {
+			return msg, cancelled.Error()
+		}
+		return cancelled.Value(), nil
+	})
+}
+
+// respondWithCancelError answers a failed cancelRelease
+func respondWithCancelError(w http.ResponseWriter, messageID uuid.UUID, err error) {
+	switch {
+	case errors.Is(err, errReleaseNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, errReleaseDenied):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, errNoLongerArmed):
+		respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		slog.Error("Failed to cancel message release", "message_id", messageID, "error", err)
+		respondWithError(w, http.StatusInternalServerError, "failed to cancel release")
+	}
+}
+
+// armedMessages loads all armed messages of a user
+func (h *CheckInHandler) armedMessages(ctx context.Context, userID uuid.UUID) common.Result[[]message.Message] {
+	filter := message.ListFilter{Status: common.Some(message.StatusArmed)}
+	armed := []message.Message{}
+	cursor := ""
+
+	for {
+		options := message.NewListOptions(filter, message.SortByDeliveryDate, message.SortAscending, cursor, message.MaxListLimit)
+		if options.IsErr() {
+			return common.Err[[]message.Message](options.Error())
+		}
+
+		pageResult := h.app.Database().FindMessagesPage(ctx, userID, options.Value())
+		if pageResult.IsErr() {
+			return common.Err[[]message.Message](pageResult.Error())
+		}
+
+		armed = append(armed, pageResult.Value().Messages...)
+		if pageResult.Value().NextCursor.IsNone() {
+			return common.Ok(armed)
+		}
+		cursor = pageResult.Value().NextCursor.Value().Encode()
+	}
+}
+
+func (h *CheckInHandler) tokens() *auth.ActionTokenService {
+	return auth.NewActionTokenService(h.app.Config().JWTSecret)
+}
+
+// toDomain converts the API request into domain check-in settings
+func (req CheckInSettingsRequest) toDomain() common.Result[message.CheckInSettings] {
+	if req.IntervalDays <= 0 || req.GraceDays <= 0 {
+		return common.Err[message.CheckInSettings](errors.New("check_in requires interval_days and grace_days"))
+	}
+
+	day := 24 * time.Hour
+	return message.NewCheckInSettings(time.Duration(req.IntervalDays)*day, time.Duration(req.GraceDays)*day, req.Recipients)
+}
+
+func buildCheckInResponse(settings message.CheckInSettings) *CheckInSettingsResponse {
+	day := 24 * time.Hour
+	response := &CheckInSettingsResponse{
+		IntervalDays:  int(settings.Interval() / day),
+		GraceDays:     int(settings.GracePeriod() / day),
+		Recipients:    settings.Recipients(),
+		LastCheckInAt: settings.LastCheckInAt().Format(time.RFC3339),
+		NextCheckInBy: settings.WarnAt().Format(time.RFC3339),
+		ReleaseAt:     settings.ReleaseAt().Format(time.RFC3339),
+	}
+	if response.Recipients == nil {
+		response.Recipients = []string{}
+	}
+	if settings.WarnedAt().IsSome() {
+		warnedAt := settings.WarnedAt().Value().Format(time.RFC3339)
+		response.WarnedAt = &warnedAt
+	}
+	return response
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+)
+
+// ResolveDateRequest represents a natural-language delivery date, such as
+// "next new year's eve at 9pm" or "3 months after my birthday"
+type ResolveDateRequest struct {
+	Expression string `json:"expression"`
+	Timezone   string `json:"timezone,omitempty"` // Defaults to the profile timezone
+}
+
+// ResolveDateResponse represents a delivery date expression resolved to a
+// time. When the date is counted from a milestone, the anchor can be sent
+// as the anchor of a new message so it follows corrections of the milestone.
+type ResolveDateResponse struct {
+	Expression   string                   `json:"expression"`
+	Description  string                   `json:"description"`
+	DeliveryDate time.Time                `json:"delivery_date"`
+	LocalTime    string                   `json:"local_time"` // Delivery time in the timezone, RFC 3339
+	Timezone     string                   `json:"timezone"`
+	Anchor       *MilestoneAnchorResponse `json:"anchor,omitempty"`
+}
+
+// ResolveDate handles POST /api/v1/messages/resolve-date. It only resolves
+// the expression; nothing is stored.
+func (h *MessageHandler) ResolveDate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req ResolveDateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	profileResult := loadUserProfile(r.Context(), h.app.Database(), userID)
+	if profileResult.IsErr() {
+		slog.Error("Failed to load profile", "user_id", userID, "error", profileResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to resolve date")
+		return
+	}
+	profile := profileResult.Value()
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = profile.User().Timezone()
+	}
+
+	resolved := message.ResolveDateExpression(req.Expression, time.Now(), timezone, profile.Milestones())
+	if resolved.IsErr() {
+		respondWithError(w, http.StatusBadRequest, resolved.Error().Error())
+		return
+	}
+
+	// The timezone was validated by the parser
+	loc, _ := time.LoadLocation(timezone)
+	response := ResolveDateResponse{
+		Expression:   req.Expression,
+		Description:  resolved.Value().Description,
+		DeliveryDate: resolved.Value().At,
+		LocalTime:    resolved.Value().At.In(loc).Format(time.RFC3339),
+		Timezone:     timezone,
+	}
+	if anchor := resolved.Value().Anchor; anchor.IsSome() {
+		response.Anchor = buildAnchorResponse(anchor.Value())
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
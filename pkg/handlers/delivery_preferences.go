@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// DeliveryPreferencesRequest sets quiet hours, delivery windows and the
+// recipient's holiday calendar. Both lists replace the stored ones; empty
+// lists remove all restrictions. With avoid_holidays, letters falling on a
+// public holiday of the country move to the first working day after it.
+type DeliveryPreferencesRequest struct {
+	QuietHours    []ScheduleRulePayload `json:"quiet_hours"`
+	Windows       []ScheduleRulePayload `json:"windows"`
+	Country       string                `json:"country,omitempty"`
+	AvoidHolidays bool                  `json:"avoid_holidays,omitempty"`
+}
+
+// DeliveryPreferencesResponse represents a user's quiet hours, delivery
+// windows and holiday calendar
+type DeliveryPreferencesResponse struct {
+	QuietHours    []ScheduleRulePayload `json:"quiet_hours"`
+	Windows       []ScheduleRulePayload `json:"windows"`
+	Country       string                `json:"country,omitempty"`
+	AvoidHolidays bool                  `json:"avoid_holidays"`
+}
+
+// ScheduleRulePayload is a daily time range in requests and responses, for
+// example {"days": ["sat", "sun"], "start": "22:00", "end": "09:00"}. Empty
+// days means every day; a range ending before it starts runs past midnight.
+type ScheduleRulePayload struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+// toDomain converts the API request into domain delivery preferences
+func (req DeliveryPreferencesRequest) toDomain() common.Result[user.DeliveryPreferences] {
+	quietHours := scheduleRulesToDomain(req.QuietHours)
+	if quietHours.IsErr() {
+		return common.Err[user.DeliveryPreferences](quietHours.Error())
+	}
+
+	windows := scheduleRulesToDomain(req.Windows)
+	if windows.IsErr() {
+		return common.Err[user.DeliveryPreferences](windows.Error())
+	}
+
+	prefs := user.NewDeliveryPreferences(quietHours.Value(), windows.Value())
+	if prefs.IsErr() {
+		return prefs
+	}
+	return prefs.Value().WithHolidays(req.Country, req.AvoidHolidays)
+}
+
+func scheduleRulesToDomain(rules []ScheduleRulePayload) common.Result[[]user.ScheduleRule] {
+	converted := make([]user.ScheduleRule, 0, len(rules))
+	for _, rule := range rules {
+		start := user.ParseTimeOfDay(rule.Start)
+		if start.IsErr() {
+			return common.Err[[]user.ScheduleRule](start.Error())
+		}
+		end := user.ParseTimeOfDay(rule.End)
+		if end.IsErr() {
+			return common.Err[[]user.ScheduleRule](end.Error())
+		}
+
+		days := make([]time.Weekday, 0, len(rule.Days))
+		for _, name := range rule.Days {
+			day := user.ParseWeekday(name)
+			if day.IsErr() {
+				return common.Err[[]user.ScheduleRule](day.Error())
+			}
+			days = append(days, day.Value())
+		}
+
+		converted = append(converted, user.ScheduleRule{Days: days, Start: start.Value(), End: end.Value()})
+	}
+	return common.Ok(converted)
+}
+
+func buildDeliveryPreferencesResponse(prefs user.DeliveryPreferences) *DeliveryPreferencesResponse {
+	return &DeliveryPreferencesResponse{
+		QuietHours:    buildScheduleRules(prefs.QuietHours()),
+		Windows:       buildScheduleRules(prefs.Windows()),
+		Country:       prefs.Country(),
+		AvoidHolidays: prefs.AvoidsHolidays(),
+	}
+}
+
+func buildScheduleRules(rules []user.ScheduleRule) []ScheduleRulePayload {
+	payloads := make([]ScheduleRulePayload, 0, len(rules))
+	for _, rule := range rules {
+		days := make([]string, 0, len(rule.Days))
+		for _, day := range rule.Days {
+			days = append(days, user.WeekdayName(day))
+		}
+		payloads = append(payloads, ScheduleRulePayload{
+			Days:  days,
+			Start: rule.Start.String(),
+			End:   rule.End.String(),
+		})
+	}
+	return payloads
+}
+
+// loadUserProfile loads a user's profile, falling back to default settings
+// when the database does not store profiles
+func loadUserProfile(ctx context.Context, db effects.Database, userID uuid.UUID) common.Result[user.UserProfile] {
+	profileResult := db.FindUserProfile(ctx, userID)
+	if profileResult.IsOk() {
+		return profileResult
+	}
+
+	userResult := db.FindUserByID(ctx, userID)
+	if userResult.IsErr() {
+		return common.Err[user.UserProfile](userResult.Error())
+	}
+	return common.Ok(user.NewUserProfile(userResult.Value()))
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+)
+
+// messageETag returns the entity tag of a message's current version
+func messageETag(msg message.Message) string {
+	return `"` + strconv.FormatInt(msg.Version(), 10) + `"`
+}
+
+// setMessageETag sets the ETag header a client sends back in If-Match
+func setMessageETag(w http.ResponseWriter, msg message.Message) {
+	w.Header().Set("ETag", messageETag(msg))
+}
+
+// requireIfMatch checks the If-Match header of a write against the current
+// version of the message. It responds with 428 when the header is missing
+// and 412 when the client edited an older version.
+func requireIfMatch(w http.ResponseWriter, r *http.Request, msg message.Message) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		respondWithError(w, http.StatusPreconditionRequired, "If-Match header is required")
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+
+		// Weak tags never match: If-Match uses strong comparison
+		version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+		if err == nil && msg.ExpectVersion(version).IsOk() {
+			return true
+		}
+	}
+
+	setMessageETag(w, msg)
+	respondWithError(w, http.StatusPreconditionFailed, message.ErrVersionConflict.Error())
+	return false
+}
+
+// respondWithUpdateError reports a failed message update: 412 when the
+// message changed since it was read, otherwise a server error
+func respondWithUpdateError(w http.ResponseWriter, err error, failure string) {
+	if errors.Is(err, message.ErrVersionConflict) {
+		respondWithError(w, http.StatusPreconditionFailed, message.ErrVersionConflict.Error())
+		return
+	}
+	respondWithError(w, http.StatusInternalServerError, failure)
+}
+
+// storedMessage reloads a message that was saved again after msg was read,
+// for example by the scheduler, so responses carry its current version.
+// msg is returned when the message cannot be reloaded.
+func storedMessage(ctx context.Context, app *composition.App, msg message.Message) message.Message {
+	if reloaded := app.Database().FindMessageByID(ctx, msg.ID()); reloaded.IsOk() {
+		return reloaded.Value()
+	}
+	return msg
+}
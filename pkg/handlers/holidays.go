@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/holiday"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+)
+
+// HolidayTargetRequest delivers a message on a public holiday, for example
+// {"holiday": "christmas", "time": "08:00"} for Christmas morning
+type HolidayTargetRequest struct {
+	Holiday string `json:"holiday"`           // Key from GET /api/v1/holidays
+	Country string `json:"country,omitempty"` // Defaults to the country of the delivery preferences
+	Time    string `json:"time,omitempty"`    // HH:MM in the message timezone, default 09:00
+}
+
+// HolidayTargetResponse represents the holiday a message is delivered on
+type HolidayTargetResponse struct {
+	Holiday string `json:"holiday"`
+	Name    string `json:"name"`
+	Country string `json:"country"`
+	Time    string `json:"time"`
+}
+
+// HolidayResponse represents a public holiday in a year
+type HolidayResponse struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Date string `json:"date"` // YYYY-MM-DD
+}
+
+// HolidayCalendarResponse represents the public holidays of a country in a year
+type HolidayCalendarResponse struct {
+	Country   string            `json:"country"`
+	Name      string            `json:"name"`
+	Year      int               `json:"year"`
+	Holidays  []HolidayResponse `json:"holidays"`
+	Countries []string          `json:"countries"` // Countries with a bundled calendar
+}
+
+// toDomain converts the API request into a domain holiday target
+func (req HolidayTargetRequest) toDomain() common.Result[message.HolidayTarget] {
+	if req.Country == "" {
+		return common.Err[message.HolidayTarget](errors.New("holiday.country is required; set it here or in your delivery preferences"))
+	}
+
+	at := message.DefaultAnchorTime
+	if req.Time != "" {
+		parsed := user.ParseTimeOfDay(req.Time)
+		if parsed.IsErr() {
+			return common.Err[message.HolidayTarget](parsed.Error())
+		}
+		at = parsed.Value()
+	}
+
+	return message.NewHolidayTarget(req.Country, strings.ToLower(strings.TrimSpace(req.Holiday)), at)
+}
+
+func buildHolidayTargetResponse(target message.HolidayTarget) *HolidayTargetResponse {
+	return &HolidayTargetResponse{
+		Holiday: target.Holiday(),
+		Name:    target.Name(),
+		Country: target.Country(),
+		Time:    target.At().String(),
+	}
+}
+
+// fillHolidayCountry defaults the country of a holiday target to the one in
+// the user's delivery preferences. Requests without a target need none.
+func fillHolidayCountry(ctx context.Context, app *composition.App, userID uuid.UUID, target *HolidayTargetRequest) error {
+	if target == nil || target.Country != "" {
+		return nil
+	}
+
+	profileResult := loadUserProfile(ctx, app.Database(), userID)
+	if profileResult.IsErr() {
+		return errors.New("failed to load delivery preferences")
+	}
+	target.Country = profileResult.Value().DeliveryPreferences().Country()
+	return nil
+}
+
+// ListHolidays handles GET /api/v1/holidays?country=VN&year=2027, listing
+// the holidays a message can be delivered on. The country defaults to the
+// one in the delivery preferences and the year to the current one.
+func (h *MessageHandler) ListHolidays(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	target := &HolidayTargetRequest{Country: r.URL.Query().Get("country")}
+	if err := fillHolidayCountry(r.Context(), h.app, userID, target); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if target.Country == "" {
+		respondWithError(w, http.StatusBadRequest, "country is required")
+		return
+	}
+
+	calendar := holiday.ForCountry(target.Country)
+	if calendar.IsErr() {
+		respondWithError(w, http.StatusBadRequest, calendar.Error().Error())
+		return
+	}
+
+	year := time.Now().Year()
+	if raw := r.URL.Query().Get("year"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1900 || parsed > 2200 {
+			respondWithError(w, http.StatusBadRequest, "invalid year")
+			return
+		}
+		year = parsed
+	}
+
+	holidays := calendar.Value().InYear(year)
+	response := HolidayCalendarResponse{
+		Country:   calendar.Value().Country(),
+		Name:      calendar.Value().Name(),
+		Year:      year,
+		Holidays:  make([]HolidayResponse, 0, len(holidays)),
+		Countries: holiday.Countries(),
+	}
+	for _, day := range holidays {
+		response.Holidays = append(response.Holidays, HolidayResponse{
+			Key:  day.Key(),
+			Name: day.Name(),
+			Date: day.Date().Format("2006-01-02"),
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
@@ -3,15 +3,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/thanhphuchuynh/dear-future/pkg/composition"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
 	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
 )
 
@@ -29,31 +33,62 @@ func NewMessageHandler(app *composition.App) *MessageHandler {
 
 // CreateMessageRequest represents a message creation request
 type CreateMessageRequest struct {
-	Title           string `json:"title"`
-	Content         string `json:"content"`
-	DeliveryDate    string `json:"delivery_date"` // ISO 8601 format
-	Timezone        string `json:"timezone"`
-	DeliveryMethod  string `json:"delivery_method"`
-	Recurrence      string `json:"recurrence"`
-	ReminderMinutes *int   `json:"reminder_minutes"`
+	Title           string                  `json:"title"`
+	Content         string                  `json:"content"`
+	ContentFormat   string                  `json:"content_format"` // "plain" (default) or "markdown"
+	DeliveryDate    string                  `json:"delivery_date"`  // ISO 8601 format
+	Timezone        string                  `json:"timezone"`
+	DeliveryMethod  string                  `json:"delivery_method"`
+	Recurrence      string                  `json:"recurrence"`
+	ReminderMinutes *int                    `json:"reminder_minutes"`
+	Tags            []string                `json:"tags"`
+	CheckIn         *CheckInSettingsRequest `json:"check_in"` // Hold until the user misses a check-in
+	Surprise        *SurpriseRequest        `json:"surprise"` // Deliver at a hidden time within a range
+	Anchor          *MilestoneAnchorRequest `json:"anchor"`   // Derive the delivery date from a profile milestone
+	Holiday         *HolidayTargetRequest   `json:"holiday"`  // Deliver on a public holiday, every year if recurring yearly
+}
+
+// SurpriseRequest is the range a "surprise me" message is delivered in
+type SurpriseRequest struct {
+	Earliest string `json:"earliest"` // ISO 8601 format
+	Latest   string `json:"latest"`   // ISO 8601 format
+}
+
+// SurpriseResponse represents the range of a "surprise me" message; the
+// drawn delivery time is not included until delivery
+type SurpriseResponse struct {
+	Earliest string `json:"earliest"`
+	Latest   string `json:"latest"`
 }
 
 // MessageResponse represents a message in API responses
 type MessageResponse struct {
-	ID              string               `json:"id"`
-	UserID          string               `json:"user_id"`
-	Title           string               `json:"title"`
-	Content         string               `json:"content"`
-	DeliveryDate    string               `json:"delivery_date"`
-	Timezone        string               `json:"timezone"`
-	Status          string               `json:"status"`
-	DeliveryMethod  string               `json:"delivery_method"`
-	Recurrence      string               `json:"recurrence"`
-	ReminderMinutes *int                 `json:"reminder_minutes,omitempty"`
-	AttachmentCount int                  `json:"attachment_count"`
-	Attachments     []AttachmentResponse `json:"attachments,omitempty"`
-	CreatedAt       string               `json:"created_at"`
-	UpdatedAt       string               `json:"updated_at"`
+	ID              string                    `json:"id"`
+	UserID          string                    `json:"user_id"`
+	Title           string                    `json:"title"`
+	Content         string                    `json:"content"`
+	ContentFormat   string                    `json:"content_format"`
+	DeliveryDate    string                    `json:"delivery_date"`                    // Empty for surprise messages until delivery
+	IntendedDate    *string                   `json:"intended_delivery_date,omitempty"` // Set when moved out of quiet hours
+	Timezone        string                    `json:"timezone"`
+	Status          string                    `json:"status"`
+	DeliveryMethod  string                    `json:"delivery_method"`
+	Recurrence      string                    `json:"recurrence"`
+	ReminderMinutes *int                      `json:"reminder_minutes,omitempty"`
+	AttachmentCount int                       `json:"attachment_count"`
+	Attachments     []AttachmentResponse      `json:"attachments,omitempty"`
+	Tags            []string                  `json:"tags"`
+	CheckIn         *CheckInSettingsResponse  `json:"check_in,omitempty"`
+	Surprise        *SurpriseResponse         `json:"surprise,omitempty"`
+	ParentID        *string                   `json:"parent_message_id,omitempty"` // Delivered message this letter replies to
+	Receipts        []DeliveryReceiptResponse `json:"receipts,omitempty"`          // Per-recipient open and read times, single message only
+	DeletedAt       *string                   `json:"deleted_at,omitempty"`        // Set while the message is in the trash
+	Version         int64                     `json:"version"`                     // Sent back as If-Match when updating or deleting
+	PurgeAt         *string                   `json:"purge_at,omitempty"`          // When a message in the trash is deleted for good
+	Anchor          *MilestoneAnchorResponse  `json:"anchor,omitempty"`            // Milestone the delivery date is derived from
+	Holiday         *HolidayTargetResponse    `json:"holiday,omitempty"`           // Public holiday the message is delivered on
+	CreatedAt       string                    `json:"created_at"`
+	UpdatedAt       string                    `json:"updated_at"`
 }
 
 // CreateMessage creates a new message
@@ -71,19 +106,228 @@ func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate input
-	if req.Title == "" || req.Content == "" || req.DeliveryDate == "" {
-		respondWithError(w, http.StatusBadRequest, "title, content, and delivery_date are required")
+	milestones := loadAnchorMilestones(r.Context(), h.app, userID, req.Anchor)
+	if milestones.IsErr() {
+		respondWithError(w, http.StatusInternalServerError, milestones.Error().Error())
+		return
+	}
+	if err := fillHolidayCountry(r.Context(), h.app, userID, req.Holiday); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Parse delivery date
-	deliveryDate, err := time.Parse(time.RFC3339, req.DeliveryDate)
+	msgResult := common.Bind(req.toDomain(userID, milestones.Value()), message.NewMessage)
+	if msgResult.IsErr() {
+		respondWithError(w, http.StatusBadRequest, msgResult.Error().Error())
+		return
+	}
+
+	h.saveNewMessage(w, r, userID, msgResult.Value(), req.Tags)
+}
+
+// ReplyToMessage handles POST /api/v1/messages/reply?id={id}, creating a
+// future letter that answers a delivered message
+func (h *MessageHandler) ReplyToMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Get user ID from context
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	// Get the message being replied to from URL
+	parentIDStr := r.URL.Query().Get("id")
+	if parentIDStr == "" {
+		respondWithError(w, http.StatusBadRequest, "message id is required")
+		return
+	}
+
+	parentID, err := uuid.Parse(parentIDStr)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "invalid delivery_date format (use RFC3339)")
+		respondWithError(w, http.StatusBadRequest, "invalid message id")
 		return
 	}
 
+	parentResult := findLiveMessage(r.Context(), h.app, parentID)
+	if parentResult.IsErr() {
+		respondWithError(w, http.StatusNotFound, "message not found")
+		return
+	}
+
+	parent := parentResult.Value()
+
+	// Verify ownership
+	if parent.UserID() != userID {
+		respondWithError(w, http.StatusForbidden, "access denied")
+		return
+	}
+
+	if parent.Status() != message.StatusDelivered {
+		respondWithError(w, http.StatusConflict, "only delivered messages can be replied to")
+		return
+	}
+
+	var req CreateMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	milestones := loadAnchorMilestones(r.Context(), h.app, userID, req.Anchor)
+	if milestones.IsErr() {
+		respondWithError(w, http.StatusInternalServerError, milestones.Error().Error())
+		return
+	}
+	if err := fillHolidayCountry(r.Context(), h.app, userID, req.Holiday); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	msgResult := common.Bind(req.toDomain(userID, milestones.Value()), func(createReq message.CreateMessageRequest) common.Result[message.Message] {
+		return message.NewReply(parent, createReq)
+	})
+	if msgResult.IsErr() {
+		respondWithError(w, http.StatusBadRequest, msgResult.Error().Error())
+		return
+	}
+
+	h.saveNewMessage(w, r, userID, msgResult.Value(), req.Tags)
+}
+
+// saveNewMessage stores, tags and schedules a newly created message and
+// responds with it
+func (h *MessageHandler) saveNewMessage(w http.ResponseWriter, r *http.Request, userID uuid.UUID, newMsg message.Message, tags []string) {
+	tagNames := message.NormalizeTagNames(tags)
+	if tagNames.IsErr() {
+		respondWithError(w, http.StatusBadRequest, tagNames.Error().Error())
+		return
+	}
+
+	// Enforce the limits of the user's plan
+	planResult := loadPlanUsage(r.Context(), h.app, userID)
+	if planResult.IsErr() {
+		slog.Error("Failed to load plan usage", "user_id", userID, "error", planResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to create message")
+		return
+	}
+	if allowed := message.ValidateNewMessageForPlan(newMsg, planResult.Value().limits, planResult.Value().usage); allowed.IsErr() {
+		respondWithPlanError(w, allowed.Error())
+		return
+	}
+
+	// Save message to database
+	saveResult := h.app.Database().SaveMessage(r.Context(), newMsg)
+	if saveResult.IsErr() {
+		slog.Error("Failed to save message", "error", saveResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to create message")
+		return
+	}
+
+	savedMsg := saveResult.Value()
+
+	tagsResult := h.setMessageTags(r, userID, savedMsg.ID(), tagNames.Value())
+	if tagsResult.IsErr() {
+		slog.Error("Failed to tag message", "message_id", savedMsg.ID(), "error", tagsResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to save message tags")
+		return
+	}
+
+	// Schedule the message for delivery using River Queue; armed messages
+	// are released by the check-in evaluator instead
+	messageService := h.app.MessageService()
+	if !savedMsg.IsArmed() && messageService != nil && messageService.Scheduling() != nil {
+		scheduleResult := messageService.Scheduling().ScheduleMessage(
+			r.Context(),
+			savedMsg.ID(),
+			savedMsg.DeliveryDate(),
+		)
+		if scheduleResult.IsErr() {
+			slog.Error("Failed to schedule message", "message_id", savedMsg.ID(), "error", scheduleResult.Error())
+			// Don't fail the request - message is saved, scheduling can be retried
+		} else {
+			if scheduledFor := scheduleResult.Value().ScheduledFor; !scheduledFor.Equal(savedMsg.DeliveryDate()) {
+				// Moved out of the recipient's quiet hours
+				savedMsg = savedMsg.WithShiftedDelivery(scheduledFor)
+			}
+			// Scheduling saved the message again
+			savedMsg = storedMessage(r.Context(), h.app, savedMsg)
+			slog.Info("Message scheduled successfully",
+				"message_id", savedMsg.ID(),
+				"scheduled_for", savedMsg.DeliveryDate())
+		}
+	}
+
+	response := buildMessageResponse(savedMsg)
+	response.Tags = tagNamesOf(tagsResult.Value())
+	setMessageETag(w, savedMsg)
+
+	respondWithJSON(w, http.StatusCreated, response)
+}
+
+// toDomain converts the API request into a domain create request, applying
+// defaults. Anchors refer to one of the given milestones.
+func (req CreateMessageRequest) toDomain(userID uuid.UUID, milestones []user.Milestone) common.Result[message.CreateMessageRequest] {
+	// Check-in messages are released when a check-in is missed, so they
+	// have no delivery date of their own
+	checkIn := common.None[message.CheckInSettings]()
+	if req.CheckIn != nil {
+		settings := req.CheckIn.toDomain()
+		if settings.IsErr() {
+			return common.Err[message.CreateMessageRequest](settings.Error())
+		}
+		checkIn = common.Some(settings.Value())
+	}
+
+	// Surprise messages draw their delivery date from the range
+	surprise := common.None[message.SurpriseWindow]()
+	if req.Surprise != nil {
+		window := req.Surprise.toDomain()
+		if window.IsErr() {
+			return common.Err[message.CreateMessageRequest](window.Error())
+		}
+		surprise = common.Some(window.Value())
+	}
+
+	// Anchored messages count their delivery date from a milestone
+	anchor := common.None[message.MilestoneAnchor]()
+	if req.Anchor != nil {
+		converted := req.Anchor.toDomain(milestones)
+		if converted.IsErr() {
+			return common.Err[message.CreateMessageRequest](converted.Error())
+		}
+		anchor = common.Some(converted.Value())
+	}
+
+	// Holiday messages are delivered on the next occurrence of the holiday
+	target := common.None[message.HolidayTarget]()
+	if req.Holiday != nil {
+		converted := req.Holiday.toDomain()
+		if converted.IsErr() {
+			return common.Err[message.CreateMessageRequest](converted.Error())
+		}
+		target = common.Some(converted.Value())
+	}
+
+	// Validate input
+	if req.Title == "" || req.Content == "" || (req.DeliveryDate == "" && checkIn.IsNone() && surprise.IsNone() && anchor.IsNone() && target.IsNone()) {
+		return common.Err[message.CreateMessageRequest](errors.New("title, content, and delivery_date are required"))
+	}
+
+	// Parse delivery date
+	var deliveryDate time.Time
+	if req.DeliveryDate != "" {
+		parsed, err := time.Parse(time.RFC3339, req.DeliveryDate)
+		if err != nil {
+			return common.Err[message.CreateMessageRequest](errors.New("invalid delivery_date format (use RFC3339)"))
+		}
+		deliveryDate = parsed
+	}
+
 	// Set defaults
 	if req.Timezone == "" {
 		req.Timezone = "UTC"
@@ -106,62 +350,155 @@ func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 	case "push":
 		deliveryMethod = message.DeliveryPush
 	default:
-		respondWithError(w, http.StatusBadRequest, "invalid delivery_method (must be 'email' or 'push')")
-		return
+		return common.Err[message.CreateMessageRequest](errors.New("invalid delivery_method (must be 'email' or 'push')"))
 	}
 
-	// Create message in domain
-	createMsgReq := message.CreateMessageRequest{
+	return common.Ok(message.CreateMessageRequest{
 		UserID:          userID,
 		Title:           req.Title,
 		Content:         req.Content,
+		ContentFormat:   message.ContentFormat(req.ContentFormat),
 		DeliveryDate:    deliveryDate,
 		Timezone:        req.Timezone,
 		DeliveryMethod:  deliveryMethod,
 		Recurrence:      recurrence,
 		ReminderMinutes: reminderOption,
+		CheckIn:         checkIn,
+		Surprise:        surprise,
+		Anchor:          anchor,
+		Holiday:         target,
+	})
+}
+
+// toDomain converts the API request into a domain surprise window
+func (req SurpriseRequest) toDomain() common.Result[message.SurpriseWindow] {
+	earliest, err := time.Parse(time.RFC3339, req.Earliest)
+	if err != nil {
+		return common.Err[message.SurpriseWindow](errors.New("invalid surprise.earliest format (use RFC3339)"))
 	}
+	latest, err := time.Parse(time.RFC3339, req.Latest)
+	if err != nil {
+		return common.Err[message.SurpriseWindow](errors.New("invalid surprise.latest format (use RFC3339)"))
+	}
+	return message.NewSurpriseWindow(earliest, latest)
+}
 
-	msgResult := message.NewMessage(createMsgReq)
-	if msgResult.IsErr() {
-		respondWithError(w, http.StatusBadRequest, msgResult.Error().Error())
+// MessagePreviewResponse represents a rendered message preview
+type MessagePreviewResponse struct {
+	Subject     string            `json:"subject"`
+	ContentHTML string            `json:"content_html"`
+	ContentText string            `json:"content_text"`
+	Email       *EmailPreviewBody `json:"email,omitempty"`
+}
+
+// EmailPreviewBody represents the full email as it would be delivered
+type EmailPreviewBody struct {
+	HTML string `json:"html"`
+	Text string `json:"text"`
+}
+
+// PreviewMessage renders a message as it would be delivered. With ?id= it
+// previews a stored message; otherwise the body is a draft in the same shape
+// as a create request.
+func (h *MessageHandler) PreviewMessage(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	newMsg := msgResult.Value()
+	var msg message.Message
+	if messageIDStr := r.URL.Query().Get("id"); messageIDStr != "" {
+		messageID, err := uuid.Parse(messageIDStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid message id")
+			return
+		}
 
-	// Save message to database
-	saveResult := h.app.Database().SaveMessage(r.Context(), newMsg)
-	if saveResult.IsErr() {
-		slog.Error("Failed to save message", "error", saveResult.Error())
-		respondWithError(w, http.StatusInternalServerError, "failed to create message")
+		msgResult := findLiveMessage(r.Context(), h.app, messageID)
+		if msgResult.IsErr() {
+			respondWithError(w, http.StatusNotFound, "message not found")
+			return
+		}
+
+		// Verify ownership
+		if msgResult.Value().UserID() != userID {
+			respondWithError(w, http.StatusForbidden, "access denied")
+			return
+		}
+		msg = msgResult.Value()
+	} else {
+		var req CreateMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		milestones := loadAnchorMilestones(r.Context(), h.app, userID, req.Anchor)
+		if milestones.IsErr() {
+			respondWithError(w, http.StatusInternalServerError, milestones.Error().Error())
+			return
+		}
+		if err := fillHolidayCountry(r.Context(), h.app, userID, req.Holiday); err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		msgResult := common.Bind(req.toDomain(userID, milestones.Value()), message.NewMessage)
+		if msgResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, msgResult.Error().Error())
+			return
+		}
+		msg = msgResult.Value()
+	}
+
+	userResult := h.app.Database().FindUserByID(r.Context(), userID)
+	if userResult.IsErr() {
+		respondWithError(w, http.StatusNotFound, "user not found")
 		return
 	}
 
-	savedMsg := saveResult.Value()
+	deliveryInfoResult := message.BuildPreviewInfo(msg, user.NewUserProfile(userResult.Value()))
+	if deliveryInfoResult.IsErr() {
+		respondWithError(w, http.StatusBadRequest, deliveryInfoResult.Error().Error())
+		return
+	}
+	deliveryInfo := deliveryInfoResult.Value()
 
-	// Schedule the message for delivery using River Queue
-	messageService := h.app.MessageService()
-	if messageService != nil && messageService.Scheduling() != nil {
-		scheduleResult := messageService.Scheduling().ScheduleMessage(
-			r.Context(),
-			savedMsg.ID(),
-			savedMsg.DeliveryDate(),
-		)
-		if scheduleResult.IsErr() {
-			slog.Error("Failed to schedule message", "message_id", savedMsg.ID(), "error", scheduleResult.Error())
-			// Don't fail the request - message is saved, scheduling can be retried
-		} else {
-			slog.Info("Message scheduled successfully",
-				"message_id", savedMsg.ID(),
-				"scheduled_for", savedMsg.DeliveryDate())
+	response := MessagePreviewResponse{
+		Subject:     deliveryInfo.Subject,
+		ContentHTML: deliveryInfo.HTMLContent,
+		ContentText: deliveryInfo.TextContent,
+	}
+
+	if previewer, ok := h.app.MessageService().Email().(effects.EmailPreviewer); ok {
+		previewResult := previewer.PreviewMessage(r.Context(), deliveryInfo)
+		if previewResult.IsErr() {
+			slog.Error("Failed to render email preview", "error", previewResult.Error())
+			respondWithError(w, http.StatusInternalServerError, "failed to render preview")
+			return
+		}
+		response.Email = &EmailPreviewBody{
+			HTML: previewResult.Value().HTMLBody,
+			Text: previewResult.Value().TextBody,
 		}
 	}
 
-	respondWithJSON(w, http.StatusCreated, buildMessageResponse(savedMsg))
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// MessageListResponse represents a page of messages
+type MessageListResponse struct {
+	Messages   []MessageResponse `json:"messages"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Total      int               `json:"total"`
+	Limit      int               `json:"limit"`
 }
 
-// GetMessages returns all messages for the current user
+// GetMessages returns a page of the current user's messages. Supports
+// filtering by status, delivery_method, recurrence and a from/to delivery date
+// range, sorting by created_at or delivery_date, and cursor pagination.
 func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -170,46 +507,214 @@ func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse query parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	query := r.URL.Query()
 
-	limit := 50 // default
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
+	filter := message.ListFilter{
+		Status:         common.None[message.MessageStatus](),
+		DeliveryMethod: common.None[message.DeliveryMethod](),
+		Recurrence:     common.None[message.RecurrencePattern](),
+	}
+
+	if statusStr := query.Get("status"); statusStr != "" {
+		statusResult := message.ParseMessageStatus(statusStr)
+		if statusResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, "invalid status")
+			return
 		}
+		filter.Status = common.Some(statusResult.Value())
+	}
+	if method := query.Get("delivery_method"); method != "" {
+		filter.DeliveryMethod = common.Some(message.DeliveryMethod(method))
+	}
+	if recurrence := query.Get("recurrence"); recurrence != "" {
+		filter.Recurrence = common.Some(message.RecurrencePattern(recurrence))
+	}
+	for _, tagParam := range query["tag"] {
+		filter.Tags = append(filter.Tags, strings.Split(tagParam, ",")...)
 	}
 
-	offset := 0 // default
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
+	var err error
+	if filter.From, err = parseDateParam(query.Get("from"), false); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid from date (use RFC3339 or YYYY-MM-DD)")
+		return
+	}
+	if filter.To, err = parseDateParam(query.Get("to"), true); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid to date (use RFC3339 or YYYY-MM-DD)")
+		return
+	}
+
+	limit := 0 // domain default
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
 		}
 	}
 
+	optionsResult := message.NewListOptions(
+		filter,
+		message.ListSortField(query.Get("sort")),
+		message.SortDirection(query.Get("order")),
+		query.Get("cursor"),
+		limit,
+	)
+	if optionsResult.IsErr() {
+		respondWithError(w, http.StatusBadRequest, optionsResult.Error().Error())
+		return
+	}
+	options := optionsResult.Value()
+
 	// Get messages from database
-	messagesResult := h.app.Database().FindMessagesByUserID(r.Context(), userID, limit, offset)
-	if messagesResult.IsErr() {
+	pageResult := h.app.Database().FindMessagesPage(r.Context(), userID, options)
+	if pageResult.IsErr() {
+		slog.Error("Failed to list messages", "error", pageResult.Error())
 		respondWithError(w, http.StatusInternalServerError, "failed to retrieve messages")
 		return
 	}
-
-	messages := messagesResult.Value()
+	page := pageResult.Value()
 
 	// Convert to response format
-	var response []MessageResponse
-	for _, msg := range messages {
-		response = append(response, buildMessageResponse(msg))
+	response := MessageListResponse{
+		Messages: []MessageResponse{}, // Return empty array instead of null
+		Total:    page.Total,
+		Limit:    options.Limit,
+	}
+	tagNames := h.tagNamesByMessage(r, page.Messages)
+	for _, msg := range page.Messages {
+		msgResponse := buildMessageResponse(msg)
+		msgResponse.Tags = tagNames[msg.ID()]
+		response.Messages = append(response.Messages, msgResponse)
+	}
+	if page.NextCursor.IsSome() {
+		response.NextCursor = page.NextCursor.Value().Encode()
 	}
 
-	if response == nil {
-		response = []MessageResponse{} // Return empty array instead of null
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// SearchResultResponse represents a single search hit
+type SearchResultResponse struct {
+	Message        MessageResponse `json:"message"`
+	Rank           float64         `json:"rank"`
+	TitleSnippet   string          `json:"title_snippet"`   // HTML-escaped, matches wrapped in <mark>
+	ContentSnippet string          `json:"content_snippet"` // HTML-escaped, matches wrapped in <mark>
+}
+
+// SearchMessagesResponse represents the search endpoint payload
+type SearchMessagesResponse struct {
+	Query   string                 `json:"query"`
+	Results []SearchResultResponse `json:"results"`
+	Limit   int                    `json:"limit"`
+	Offset  int                    `json:"offset"`
+}
+
+// SearchMessages performs a full-text search over the current user's messages
+func (h *MessageHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from context
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+
+	status := common.None[message.MessageStatus]()
+	if statusStr := query.Get("status"); statusStr != "" {
+		statusResult := message.ParseMessageStatus(statusStr)
+		if statusResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, "invalid status")
+			return
+		}
+		status = common.Some(statusResult.Value())
+	}
+
+	from, err := parseDateParam(query.Get("from"), false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid from date (use RFC3339 or YYYY-MM-DD)")
+		return
+	}
+
+	to, err := parseDateParam(query.Get("to"), true)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid to date (use RFC3339 or YYYY-MM-DD)")
+		return
+	}
+
+	criteriaResult := message.NewSearchCriteria(query.Get("q"), status, from, to)
+	if criteriaResult.IsErr() {
+		respondWithError(w, http.StatusBadRequest, criteriaResult.Error().Error())
+		return
+	}
+
+	limit := 20 // default
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	offset := 0 // default
+	if o, err := strconv.Atoi(query.Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	searchResult := h.app.Database().SearchMessages(r.Context(), effects.MessageSearchQuery{
+		UserID:   userID,
+		Criteria: criteriaResult.Value(),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if searchResult.IsErr() {
+		slog.Error("Failed to search messages", "error", searchResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to search messages")
+		return
+	}
+
+	response := SearchMessagesResponse{
+		Query:   criteriaResult.Value().Query,
+		Results: []SearchResultResponse{},
+		Limit:   limit,
+		Offset:  offset,
+	}
+	hitMessages := make([]message.Message, len(searchResult.Value()))
+	for i, hit := range searchResult.Value() {
+		hitMessages[i] = hit.Message
+	}
+	tagNames := h.tagNamesByMessage(r, hitMessages)
+
+	for _, hit := range searchResult.Value() {
+		msgResponse := buildMessageResponse(hit.Message)
+		msgResponse.Tags = tagNames[hit.Message.ID()]
+		response.Results = append(response.Results, SearchResultResponse{
+			Message:        msgResponse,
+			Rank:           hit.Rank,
+			TitleSnippet:   hit.TitleSnippet,
+			ContentSnippet: hit.ContentSnippet,
+		})
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
 }
 
+// parseDateParam parses an optional RFC3339 or YYYY-MM-DD query value. Plain
+// dates used as an upper bound cover the whole day.
+func parseDateParam(value string, endOfDay bool) (common.Option[time.Time], error) {
+	if value == "" {
+		return common.None[time.Time](), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return common.Some(t), nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return common.None[time.Time](), err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return common.Some(t), nil
+}
+
 // GetMessage returns a specific message by ID
 func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
@@ -233,7 +738,7 @@ func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get message from database
-	msgResult := h.app.Database().FindMessageByID(r.Context(), messageID)
+	msgResult := findLiveMessage(r.Context(), h.app, messageID)
 	if msgResult.IsErr() {
 		respondWithError(w, http.StatusNotFound, "message not found")
 		return
@@ -248,6 +753,7 @@ func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := buildMessageResponse(msg)
+	response.Tags = h.tagNamesByMessage(r, []message.Message{msg})[msg.ID()]
 
 	attachmentsResult := h.app.Database().FindAttachmentsByMessageID(r.Context(), msg.ID())
 	if attachmentsResult.IsOk() {
@@ -258,6 +764,11 @@ func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	for _, receipt := range loadDeliveryReceipts(r, h.app, msg.ID()) {
+		response.Receipts = append(response.Receipts, buildDeliveryReceiptResponse(receipt))
+	}
+
+	setMessageETag(w, msg)
 	respondWithJSON(w, http.StatusOK, response)
 }
 
@@ -284,7 +795,7 @@ func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get current message
-	msgResult := h.app.Database().FindMessageByID(r.Context(), messageID)
+	msgResult := findLiveMessage(r.Context(), h.app, messageID)
 	if msgResult.IsErr() {
 		respondWithError(w, http.StatusNotFound, "message not found")
 		return
@@ -298,6 +809,11 @@ func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject edits of an older version, such as from another tab
+	if !requireIfMatch(w, r, currentMsg) {
+		return
+	}
+
 	// Check if message is editable
 	if !currentMsg.IsEditable() {
 		respondWithError(w, http.StatusBadRequest, "message cannot be edited (already delivered or cancelled)")
@@ -306,12 +822,18 @@ func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 
 	// Parse update request
 	var req struct {
-		Title           *string `json:"title"`
-		Content         *string `json:"content"`
-		DeliveryDate    *string `json:"delivery_date"`
-		Timezone        *string `json:"timezone"`
-		Recurrence      *string `json:"recurrence"`
-		ReminderMinutes *int    `json:"reminder_minutes"`
+		Title           *string                 `json:"title"`
+		Content         *string                 `json:"content"`
+		ContentFormat   *string                 `json:"content_format"`
+		DeliveryDate    *string                 `json:"delivery_date"`
+		Timezone        *string                 `json:"timezone"`
+		Recurrence      *string                 `json:"recurrence"`
+		ReminderMinutes *int                    `json:"reminder_minutes"`
+		Tags            *[]string               `json:"tags"`
+		CheckIn         *CheckInSettingsRequest `json:"check_in"`
+		Surprise        *SurpriseRequest        `json:"surprise"`
+		Anchor          *MilestoneAnchorRequest `json:"anchor"`
+		Holiday         *HolidayTargetRequest   `json:"holiday"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -322,6 +844,15 @@ func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 	// Apply updates
 	updatedMsg := currentMsg
 
+	if req.ContentFormat != nil {
+		updateResult := updatedMsg.WithContentFormat(message.ContentFormat(*req.ContentFormat))
+		if updateResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, updateResult.Error().Error())
+			return
+		}
+		updatedMsg = updateResult.Value()
+	}
+
 	if req.Title != nil {
 		updateResult := updatedMsg.WithTitle(*req.Title)
 		if updateResult.IsErr() {
@@ -385,18 +916,121 @@ func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 		updatedMsg = updateResult.Value()
 	}
 
-	// Save updated message
+	if req.CheckIn != nil {
+		settings := req.CheckIn.toDomain()
+		if settings.IsErr() {
+			respondWithError(w, http.StatusBadRequest, settings.Error().Error())
+			return
+		}
+
+		updateResult := updatedMsg.WithCheckInSettings(settings.Value())
+		if updateResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, updateResult.Error().Error())
+			return
+		}
+		updatedMsg = updateResult.Value()
+	}
+
+	if req.Surprise != nil {
+		window := req.Surprise.toDomain()
+		if window.IsErr() {
+			respondWithError(w, http.StatusBadRequest, window.Error().Error())
+			return
+		}
+
+		updateResult := updatedMsg.WithSurpriseWindow(window.Value())
+		if updateResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, updateResult.Error().Error())
+			return
+		}
+		updatedMsg = updateResult.Value()
+	}
+
+	if req.Anchor != nil {
+		milestones := loadAnchorMilestones(r.Context(), h.app, userID, req.Anchor)
+		if milestones.IsErr() {
+			respondWithError(w, http.StatusInternalServerError, milestones.Error().Error())
+			return
+		}
+
+		anchor := req.Anchor.toDomain(milestones.Value())
+		if anchor.IsErr() {
+			respondWithError(w, http.StatusBadRequest, anchor.Error().Error())
+			return
+		}
+
+		updateResult := updatedMsg.WithMilestoneAnchor(anchor.Value())
+		if updateResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, updateResult.Error().Error())
+			return
+		}
+		updatedMsg = updateResult.Value()
+	}
+
+	if req.Holiday != nil {
+		if err := fillHolidayCountry(r.Context(), h.app, userID, req.Holiday); err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		target := req.Holiday.toDomain()
+		if target.IsErr() {
+			respondWithError(w, http.StatusBadRequest, target.Error().Error())
+			return
+		}
+
+		updateResult := updatedMsg.WithHolidayTarget(target.Value())
+		if updateResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, updateResult.Error().Error())
+			return
+		}
+		updatedMsg = updateResult.Value()
+	}
+
+	var tagNames []string
+	if req.Tags != nil {
+		tagNamesResult := message.NormalizeTagNames(*req.Tags)
+		if tagNamesResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, tagNamesResult.Error().Error())
+			return
+		}
+		tagNames = tagNamesResult.Value()
+	}
+
+	// Features added by the update must be included in the user's plan
+	if updatedMsg.Recurrence() != currentMsg.Recurrence() {
+		planResult := loadPlanUsage(r.Context(), h.app, userID)
+		if planResult.IsErr() {
+			slog.Error("Failed to load plan usage", "user_id", userID, "error", planResult.Error())
+			respondWithError(w, http.StatusInternalServerError, "failed to update message")
+			return
+		}
+		if allowed := message.ValidateMessageFeaturesForPlan(updatedMsg, planResult.Value().limits); allowed.IsErr() {
+			respondWithPlanError(w, allowed.Error())
+			return
+		}
+	}
+
+	// Save updated message unless it changed since it was read
 	saveResult := h.app.Database().UpdateMessage(r.Context(), updatedMsg)
 	if saveResult.IsErr() {
-		respondWithError(w, http.StatusInternalServerError, "failed to update message")
+		respondWithUpdateError(w, saveResult.Error(), "failed to update message")
 		return
 	}
 
 	savedMsg := saveResult.Value()
 
+	if req.Tags != nil {
+		if tagsResult := h.setMessageTags(r, userID, savedMsg.ID(), tagNames); tagsResult.IsErr() {
+			slog.Error("Failed to tag message", "message_id", savedMsg.ID(), "error", tagsResult.Error())
+			respondWithError(w, http.StatusInternalServerError, "failed to save message tags")
+			return
+		}
+	}
+
 	// Reschedule the message if delivery date changed
 	messageService := h.app.MessageService()
-	if req.DeliveryDate != nil && messageService != nil && messageService.Scheduling() != nil {
+	if (req.DeliveryDate != nil || req.Surprise != nil || req.Anchor != nil || req.Holiday != nil) && messageService != nil && messageService.Scheduling() != nil {
 		rescheduleResult := messageService.Scheduling().RescheduleMessage(
 			r.Context(),
 			savedMsg.ID(),
@@ -406,16 +1040,26 @@ func (h *MessageHandler) UpdateMessage(w http.ResponseWriter, r *http.Request) {
 			slog.Error("Failed to reschedule message", "message_id", savedMsg.ID(), "error", rescheduleResult.Error())
 			// Don't fail the request - message is updated, rescheduling can be retried
 		} else {
+			if scheduledFor := rescheduleResult.Value().ScheduledFor; !scheduledFor.Equal(savedMsg.DeliveryDate()) {
+				savedMsg = savedMsg.WithShiftedDelivery(scheduledFor)
+			}
+			// Rescheduling saved the message again
+			savedMsg = storedMessage(r.Context(), h.app, savedMsg)
 			slog.Info("Message rescheduled successfully",
 				"message_id", savedMsg.ID(),
 				"new_delivery_date", savedMsg.DeliveryDate())
 		}
 	}
 
-	respondWithJSON(w, http.StatusOK, buildMessageResponse(savedMsg))
+	response := buildMessageResponse(savedMsg)
+	response.Tags = h.tagNamesByMessage(r, []message.Message{savedMsg})[savedMsg.ID()]
+
+	setMessageETag(w, savedMsg)
+	respondWithJSON(w, http.StatusOK, response)
 }
 
-// DeleteMessage deletes a message
+// DeleteMessage moves a message to the trash, where it can be restored until
+// it is purged
 func (h *MessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -438,7 +1082,7 @@ func (h *MessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get message to verify ownership
-	msgResult := h.app.Database().FindMessageByID(r.Context(), messageID)
+	msgResult := findLiveMessage(r.Context(), h.app, messageID)
 	if msgResult.IsErr() {
 		respondWithError(w, http.StatusNotFound, "message not found")
 		return
@@ -452,20 +1096,79 @@ func (h *MessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject deletes based on an older version
+	if !requireIfMatch(w, r, msg) {
+		return
+	}
+
 	// Check if message is deletable
-	if !msg.IsDeletable() {
-		respondWithError(w, http.StatusBadRequest, "message cannot be deleted (already delivered)")
+	deletedResult := msg.WithDeleted(time.Now())
+	if deletedResult.IsErr() {
+		respondWithError(w, http.StatusBadRequest, deletedResult.Error().Error())
 		return
 	}
 
-	// Delete message
-	deleteResult := h.app.Database().DeleteMessage(r.Context(), messageID)
-	if deleteResult.IsErr() {
-		respondWithError(w, http.StatusInternalServerError, "failed to delete message")
+	// Move message to the trash
+	saveResult := h.app.Database().UpdateMessage(r.Context(), deletedResult.Value())
+	if saveResult.IsErr() {
+		respondWithUpdateError(w, saveResult.Error(), "failed to delete message")
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{"message": "message deleted successfully"})
+	respondWithJSON(w, http.StatusOK, map[string]string{
+		"message":  "message moved to trash",
+		"purge_at": saveResult.Value().PurgeAt(trashRetention(h.app)).Value().Format(time.RFC3339),
+	})
+}
+
+// setMessageTags replaces a message's tags, creating tags that do not exist yet
+func (h *MessageHandler) setMessageTags(r *http.Request, userID, messageID uuid.UUID, names []string) common.Result[[]message.Tag] {
+	tagsResult := h.app.Database().EnsureTags(r.Context(), userID, names)
+	if tagsResult.IsErr() {
+		return tagsResult
+	}
+
+	tagIDs := make([]uuid.UUID, len(tagsResult.Value()))
+	for i, tag := range tagsResult.Value() {
+		tagIDs[i] = tag.ID()
+	}
+
+	if setResult := h.app.Database().SetMessageTags(r.Context(), messageID, tagIDs); setResult.IsErr() {
+		return common.Err[[]message.Tag](setResult.Error())
+	}
+
+	return tagsResult
+}
+
+// tagNamesByMessage loads tag names for a set of messages in one query.
+// Every message gets a non-nil slice so responses render [] rather than null.
+func (h *MessageHandler) tagNamesByMessage(r *http.Request, messages []message.Message) map[uuid.UUID][]string {
+	messageIDs := make([]uuid.UUID, len(messages))
+	names := make(map[uuid.UUID][]string, len(messages))
+	for i, msg := range messages {
+		messageIDs[i] = msg.ID()
+		names[msg.ID()] = []string{}
+	}
+
+	tagsResult := h.app.Database().FindTagsForMessages(r.Context(), messageIDs)
+	if tagsResult.IsErr() {
+		slog.Error("Failed to load message tags", "error", tagsResult.Error())
+		return names
+	}
+
+	for messageID, tags := range tagsResult.Value() {
+		names[messageID] = tagNamesOf(tags)
+	}
+	return names
+}
+
+// tagNamesOf returns the display names of tags
+func tagNamesOf(tags []message.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name()
+	}
+	return names
 }
 
 func buildMessageResponse(msg message.Message) MessageResponse {
@@ -474,7 +1177,8 @@ func buildMessageResponse(msg message.Message) MessageResponse {
 		UserID:          msg.UserID().String(),
 		Title:           msg.Title(),
 		Content:         msg.Content(),
-		DeliveryDate:    msg.DeliveryDate().Format(time.RFC3339),
+		ContentFormat:   string(msg.ContentFormat()),
+		DeliveryDate:    visibleDeliveryDate(msg),
 		Timezone:        msg.Timezone(),
 		Status:          string(msg.Status()),
 		DeliveryMethod:  string(msg.DeliveryMethod()),
@@ -482,6 +1186,7 @@ func buildMessageResponse(msg message.Message) MessageResponse {
 		AttachmentCount: 0,
 		CreatedAt:       msg.CreatedAt().Format(time.RFC3339),
 		UpdatedAt:       msg.UpdatedAt().Format(time.RFC3339),
+		Version:         msg.Version(),
 	}
 
 	if reminder := msg.ReminderMinutes(); reminder.IsSome() {
@@ -489,5 +1194,53 @@ func buildMessageResponse(msg message.Message) MessageResponse {
 		response.ReminderMinutes = &value
 	}
 
+	if intended := msg.IntendedDeliveryDate(); intended.IsSome() {
+		formatted := intended.Value().Format(time.RFC3339)
+		response.IntendedDate = &formatted
+	}
+
+	if surprise := msg.Surprise(); surprise.IsSome() {
+		response.Surprise = &SurpriseResponse{
+			Earliest: surprise.Value().Earliest().Format(time.RFC3339),
+			Latest:   surprise.Value().Latest().Format(time.RFC3339),
+		}
+	}
+
+	// The drawn time of a surprise message stays hidden until delivery
+	if msg.IsDeliveryDateHidden() {
+		response.IntendedDate = nil
+	}
+
+	if checkIn := msg.CheckIn(); checkIn.IsSome() {
+		response.CheckIn = buildCheckInResponse(checkIn.Value())
+	}
+
+	if parentID := msg.ParentID(); parentID.IsSome() {
+		formatted := parentID.Value().String()
+		response.ParentID = &formatted
+	}
+
+	if deletedAt := msg.DeletedAt(); deletedAt.IsSome() {
+		formatted := deletedAt.Value().Format(time.RFC3339)
+		response.DeletedAt = &formatted
+	}
+
+	if anchor := msg.Anchor(); anchor.IsSome() {
+		response.Anchor = buildAnchorResponse(anchor.Value())
+	}
+
+	if target := msg.HolidayTarget(); target.IsSome() {
+		response.Holiday = buildHolidayTargetResponse(target.Value())
+	}
+
 	return response
 }
+
+// visibleDeliveryDate formats the delivery date, or returns an empty string
+// while it is hidden from the author
+func visibleDeliveryDate(msg message.Message) string {
+	if msg.IsDeliveryDateHidden() {
+		return ""
+	}
+	return msg.DeliveryDate().Format(time.RFC3339)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// milestoneDateLayout is the date format of milestones in requests and responses
+const milestoneDateLayout = "2006-01-02"
+
+// MilestonePayload is a personal milestone in requests and responses, for
+// example {"key": "wedding", "name": "Our wedding", "date": "2020-06-20"}
+type MilestonePayload struct {
+	Key  string `json:"key"`
+	Name string `json:"name,omitempty"`
+	Date string `json:"date"` // YYYY-MM-DD
+}
+
+// MilestoneAnchorRequest anchors a delivery to a milestone on the profile,
+// for example {"milestone": "birthday", "occurrence": 40} for the 40th
+// birthday. Offsets move the delivery before or after the anniversary.
+type MilestoneAnchorRequest struct {
+	Milestone    string `json:"milestone"`
+	Occurrence   int    `json:"occurrence"`              // Anniversary number; 0 is the milestone day itself
+	OffsetMonths int    `json:"offset_months,omitempty"` // Negative for before the anniversary
+	OffsetDays   int    `json:"offset_days,omitempty"`   // Negative for before the anniversary
+	Time         string `json:"time,omitempty"`          // HH:MM in the message timezone, default 09:00
+}
+
+// MilestoneAnchorResponse represents the milestone a delivery date is derived from
+type MilestoneAnchorResponse struct {
+	Milestone     string `json:"milestone"`
+	Occurrence    int    `json:"occurrence"`
+	OffsetMonths  int    `json:"offset_months,omitempty"`
+	OffsetDays    int    `json:"offset_days,omitempty"`
+	Time          string `json:"time"`
+	MilestoneDate string `json:"milestone_date"`
+}
+
+// toDomain converts the API request into a domain anchor on one of the
+// user's milestones
+func (req MilestoneAnchorRequest) toDomain(milestones []user.Milestone) common.Result[message.MilestoneAnchor] {
+	at := message.DefaultAnchorTime
+	if req.Time != "" {
+		parsed := user.ParseTimeOfDay(req.Time)
+		if parsed.IsErr() {
+			return common.Err[message.MilestoneAnchor](parsed.Error())
+		}
+		at = parsed.Value()
+	}
+
+	key := strings.ToLower(strings.TrimSpace(req.Milestone))
+	for _, milestone := range milestones {
+		if milestone.Key() == key {
+			return message.NewMilestoneAnchor(milestone, req.Occurrence, req.OffsetMonths, req.OffsetDays, at)
+		}
+	}
+	return common.Err[message.MilestoneAnchor](fmt.Errorf("unknown milestone %q; add it to your profile first", req.Milestone))
+}
+
+func milestonesToDomain(payloads []MilestonePayload) common.Result[[]user.Milestone] {
+	milestones := make([]user.Milestone, 0, len(payloads))
+	for _, payload := range payloads {
+		date, err := time.Parse(milestoneDateLayout, payload.Date)
+		if err != nil {
+			return common.Err[[]user.Milestone](fmt.Errorf("invalid date for milestone %q (use YYYY-MM-DD)", payload.Key))
+		}
+
+		milestone := user.NewMilestone(payload.Key, payload.Name, date)
+		if milestone.IsErr() {
+			return common.Err[[]user.Milestone](milestone.Error())
+		}
+		milestones = append(milestones, milestone.Value())
+	}
+	return common.Ok(milestones)
+}
+
+func buildMilestonesResponse(milestones []user.Milestone) []MilestonePayload {
+	payloads := make([]MilestonePayload, 0, len(milestones))
+	for _, milestone := range milestones {
+		payloads = append(payloads, MilestonePayload{
+			Key:  milestone.Key(),
+			Name: milestone.Name(),
+			Date: milestone.Date().Format(milestoneDateLayout),
+		})
+	}
+	return payloads
+}
+
+func buildAnchorResponse(anchor message.MilestoneAnchor) *MilestoneAnchorResponse {
+	return &MilestoneAnchorResponse{
+		Milestone:     anchor.Milestone(),
+		Occurrence:    anchor.Occurrence(),
+		OffsetMonths:  anchor.OffsetMonths(),
+		OffsetDays:    anchor.OffsetDays(),
+		Time:          anchor.At().String(),
+		MilestoneDate: anchor.MilestoneDate().Format(milestoneDateLayout),
+	}
+}
+
+// loadAnchorMilestones loads the milestones an anchor can refer to.
+// Requests without an anchor need none.
+func loadAnchorMilestones(ctx context.Context, app *composition.App, userID uuid.UUID, anchor *MilestoneAnchorRequest) common.Result[[]user.Milestone] {
+	if anchor == nil {
+		return common.Ok([]user.Milestone{})
+	}
+
+	profileResult := loadUserProfile(ctx, app.Database(), userID)
+	if profileResult.IsErr() {
+		return common.Err[[]user.Milestone](errors.New("failed to load milestones"))
+	}
+	return common.Ok(profileResult.Value().Milestones())
+}
+
+// mergeMessages returns messages with the updated messages replacing those
+// with the same ID; updated messages not in messages are appended
+func mergeMessages(messages, updated []message.Message) []message.Message {
+	merged := append([]message.Message(nil), messages...)
+	for _, msg := range updated {
+		replaced := false
+		for i := range merged {
+			if merged[i].ID() == msg.ID() {
+				merged[i], replaced = msg, true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, msg)
+		}
+	}
+	return merged
+}
+
+// saveRescheduledMessages stores messages whose delivery date was
+// recomputed and moves their delivery jobs. Failures are logged and the
+// remaining messages are still updated; the number of moved messages is
+// returned.
+func saveRescheduledMessages(ctx context.Context, app *composition.App, messages []message.Message) int {
+	moved := 0
+	for _, msg := range messages {
+		saveResult := app.Database().UpdateMessage(ctx, msg)
+		if saveResult.IsErr() {
+			slog.Error("Failed to update rescheduled message", "message_id", msg.ID(), "error", saveResult.Error())
+			continue
+		}
+		moved++
+
+		// Armed messages are released by the check-in evaluator instead
+		messageService := app.MessageService()
+		if saveResult.Value().IsArmed() || messageService == nil || messageService.Scheduling() == nil {
+			continue
+		}
+		rescheduleResult := messageService.Scheduling().RescheduleMessage(ctx, msg.ID(), saveResult.Value().DeliveryDate())
+		if rescheduleResult.IsErr() {
+			slog.Error("Failed to reschedule message", "message_id", msg.ID(), "error", rescheduleResult.Error())
+		}
+	}
+	return moved
+}
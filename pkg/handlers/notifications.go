@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/auth"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+)
+
+// NotificationPreferencesRequest updates notification preferences. Omitted
+// fields keep their value; an empty webhook_url removes the webhook.
+type NotificationPreferencesRequest struct {
+	EmailEnabled      *bool   `json:"email_enabled"`
+	PushEnabled       *bool   `json:"push_enabled"`
+	WebhookURL        *string `json:"webhook_url"`
+	DeliveryReminders *bool   `json:"delivery_reminders"`
+	MarketingEmails   *bool   `json:"marketing_emails"`
+	SecurityAlerts    *bool   `json:"security_alerts"`
+	WeeklyDigest      *bool   `json:"weekly_digest"`
+}
+
+// NotificationPreferencesResponse represents which notifications a user receives
+type NotificationPreferencesResponse struct {
+	EmailEnabled      bool    `json:"email_enabled"`
+	PushEnabled       bool    `json:"push_enabled"`
+	WebhookURL        *string `json:"webhook_url,omitempty"`
+	DeliveryReminders bool    `json:"delivery_reminders"`
+	MarketingEmails   bool    `json:"marketing_emails"`
+	SecurityAlerts    bool    `json:"security_alerts"`
+	WeeklyDigest      bool    `json:"weekly_digest"`
+}
+
+// applyTo returns the preferences with the fields of the request applied
+func (req NotificationPreferencesRequest) applyTo(prefs user.NotificationPreferences) common.Result[user.NotificationPreferences] {
+	settings := prefs.Settings()
+	set := func(field *bool, value *bool) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	set(&settings.EmailEnabled, req.EmailEnabled)
+	set(&settings.PushEnabled, req.PushEnabled)
+	set(&settings.DeliveryReminders, req.DeliveryReminders)
+	set(&settings.MarketingEmails, req.MarketingEmails)
+	set(&settings.SecurityAlerts, req.SecurityAlerts)
+	set(&settings.WeeklyDigest, req.WeeklyDigest)
+
+	if req.WebhookURL != nil {
+		settings.WebhookURL = common.None[string]()
+		if webhookURL := strings.TrimSpace(*req.WebhookURL); webhookURL != "" {
+			settings.WebhookURL = common.Some(webhookURL)
+		}
+	}
+
+	return user.NewNotificationPreferences(settings)
+}
+
+func buildNotificationPreferencesResponse(prefs user.NotificationPreferences) NotificationPreferencesResponse {
+	settings := prefs.Settings()
+	response := NotificationPreferencesResponse{
+		EmailEnabled:      settings.EmailEnabled,
+		PushEnabled:       settings.PushEnabled,
+		DeliveryReminders: settings.DeliveryReminders,
+		MarketingEmails:   settings.MarketingEmails,
+		SecurityAlerts:    settings.SecurityAlerts,
+		WeeklyDigest:      settings.WeeklyDigest,
+	}
+	if settings.WebhookURL.IsSome() {
+		webhookURL := settings.WebhookURL.Value()
+		response.WebhookURL = &webhookURL
+	}
+	return response
+}
+
+// Notifications handles GET and PUT /api/v1/user/notifications
+func (h *UserHandler) Notifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		profileResult := loadUserProfile(r.Context(), h.app.Database(), userID)
+		if profileResult.IsErr() {
+			respondWithError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, buildNotificationPreferencesResponse(profileResult.Value().NotificationPreferences()))
+
+	case http.MethodPut:
+		var req NotificationPreferencesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+
+		profileResult := loadUserProfile(r.Context(), h.app.Database(), userID)
+		if profileResult.IsErr() {
+			respondWithError(w, http.StatusNotFound, "user not found")
+			return
+		}
+
+		profile := profileResult.Value()
+		prefsResult := req.applyTo(profile.NotificationPreferences())
+		if prefsResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, prefsResult.Error().Error())
+			return
+		}
+
+		saveResult := h.app.Database().UpdateUserProfile(r.Context(), profile.WithNotificationPreferences(prefsResult.Value()))
+		if saveResult.IsErr() {
+			slog.Error("Failed to save notification preferences", "user_id", userID, "error", saveResult.Error())
+			respondWithError(w, http.StatusInternalServerError, "failed to update notification preferences")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, buildNotificationPreferencesResponse(saveResult.Value().NotificationPreferences()))
+
+	default:
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// Unsubscribe handles GET and POST
+// /api/v1/notifications/unsubscribe?category={category}&token={token}, the
+// one-click unsubscribe link of optional emails. GET only asks for
+// confirmation; the preference changes on POST, sent by the confirmation
+// page or by mail clients from the List-Unsubscribe header (RFC 8058).
+func (h *UserHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	category, err := user.ParseNotificationCategory(r.URL.Query().Get("category"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !category.OneClick() {
+		respondWithError(w, http.StatusBadRequest, "this category cannot be unsubscribed from a link")
+		return
+	}
+
+	tokens := auth.NewActionTokenService(h.app.Config().JWTSecret)
+	userID := tokens.Verify(auth.UnsubscribePurpose(string(category)), r.URL.Query().Get("token"))
+	if userID.IsErr() {
+		respondWithError(w, http.StatusUnauthorized, userID.Error().Error())
+		return
+	}
+
+	locale := middleware.LocaleOf(w)
+	subject := locale.T("unsubscribe." + string(category))
+	if r.Method == http.MethodGet {
+		respondWithPage(w, http.StatusOK, confirmationPage{
+			Heading: locale.T("unsubscribe.heading"),
+			Text:    locale.T("unsubscribe.confirm", subject),
+			Button:  locale.T("unsubscribe.button"),
+			Action:  r.URL.RequestURI(),
+		})
+		return
+	}
+
+	profileResult := loadUserProfile(r.Context(), h.app.Database(), userID.Value())
+	if profileResult.IsErr() {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	profile := profileResult.Value()
+	updated := profile.NotificationPreferences().WithoutCategory(category)
+	if saveResult := h.app.Database().UpdateUserProfile(r.Context(), profile.WithNotificationPreferences(updated)); saveResult.IsErr() {
+		slog.Error("Failed to unsubscribe", "user_id", userID.Value(), "category", category, "error", saveResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to unsubscribe")
+		return
+	}
+
+	slog.Info("User unsubscribed", "user_id", userID.Value(), "category", category)
+	respondWithPage(w, http.StatusOK, confirmationPage{
+		Heading: locale.T("unsubscribe.heading"),
+		Text:    locale.T("unsubscribe.done", subject),
+	})
+}
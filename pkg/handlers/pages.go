@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+)
+
+// confirmationPage is a small HTML page for links followed from an email.
+// Links that change something show a button posting back to the same URL,
+// so mail scanners and link prefetchers, which only GET, change nothing.
+type confirmationPage struct {
+	Locale  i18n.Locale
+	Heading string
+	Text    string
+	Button  string // Label of the confirm button, empty once confirmed
+	Action  string // URL the button posts to
+}
+
+var confirmationPageTemplate = template.Must(template.New("confirmation").Parse(`<!DOCTYPE html>
+<html lang="{{.Locale.OrDefault}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <meta name="robots" content="noindex">
+    <title>{{.Heading}} - Dear Future</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 480px; margin: 60px auto; padding: 20px; text-align: center; }
+        button { background-color: #667eea; color: white; border: 0; padding: 12px 30px; border-radius: 5px; font-size: 16px; cursor: pointer; }
+    </style>
+</head>
+<body>
+    <h1>{{.Heading}}</h1>
+    <p>{{.Text}}</p>
+    {{if .Button}}
+    <form method="post" action="{{.Action}}">
+        <button type="submit">{{.Button}}</button>
+    </form>
+    {{end}}
+</body>
+</html>
+`))
+
+// respondWithPage renders a confirmation page in the locale of the response
+func respondWithPage(w http.ResponseWriter, code int, page confirmationPage) {
+	page.Locale = middleware.LocaleOf(w)
+
+	var buf bytes.Buffer
+	if err := confirmationPageTemplate.Execute(&buf, page); err != nil {
+		slog.Error("Failed to render page", "error", err)
+		respondWithError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/auth"
+	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+)
+
+// ReceiptHandler handles the open and read links of delivered messages and
+// the delivery timeline.
+type ReceiptHandler struct {
+	app *composition.App
+}
+
+// DeliveryReceiptResponse represents the receipt of one recipient
+type DeliveryReceiptResponse struct {
+	Recipient   string  `json:"recipient"`
+	DeliveredAt string  `json:"delivered_at"`
+	OpenedAt    *string `json:"opened_at,omitempty"`
+	ReadAt      *string `json:"read_at,omitempty"`
+}
+
+// TimelineEventResponse represents one step in a message's delivery timeline
+type TimelineEventResponse struct {
+	Kind      string `json:"kind"`
+	At        string `json:"at"`
+	Recipient string `json:"recipient,omitempty"`
+}
+
+// TimelineResponse lists the steps of a message from creation to the last receipt
+type TimelineResponse struct {
+	MessageID string                  `json:"message_id"`
+	Events    []TimelineEventResponse `json:"events"`
+}
+
+// trackingPixel is a transparent 1x1 GIF
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// NewReceiptHandler creates a new handler instance.
+func NewReceiptHandler(app *composition.App) *ReceiptHandler {
+	return &ReceiptHandler{app: app}
+}
+
+// TrackOpen handles GET /api/v1/receipts/open?token={token}, the tracking
+// pixel of a delivered message. The pixel is served even for invalid tokens
+// so mail clients never show a broken image.
+func (h *ReceiptHandler) TrackOpen(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if receipt := h.receiptFromToken(r); receipt.IsOk() {
+		opened := receipt.Value().WithOpened(time.Now())
+		if receipt.Value().OpenedAt().IsNone() {
+			if saved := h.store().UpdateDeliveryReceipt(r.Context(), opened); saved.IsErr() {
+				slog.Error("Failed to record message open", "receipt_id", opened.ID(), "error", saved.Error())
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate")
+	w.WriteHeader(http.StatusOK)
+	w.Write(trackingPixel)
+}
+
+// MarkRead handles /api/v1/receipts/read?token={token}, the "mark as read"
+// link of a delivered message. GET shows a confirmation page; the read is
+// only recorded on POST, so link scanners cannot mark letters as read.
+func (h *ReceiptHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.store() == nil {
+		respondWithError(w, http.StatusNotImplemented, "delivery receipts are not supported")
+		return
+	}
+
+	receipt := h.receiptFromToken(r)
+	if receipt.IsErr() {
+		respondWithError(w, http.StatusUnauthorized, receipt.Error().Error())
+		return
+	}
+
+	locale := middleware.LocaleOf(w)
+	if r.Method == http.MethodGet {
+		respondWithPage(w, http.StatusOK, confirmationPage{
+			Heading: locale.T("read.heading"),
+			Text:    locale.T("read.confirm"),
+			Button:  locale.T("read.button"),
+			Action:  r.URL.RequestURI(),
+		})
+		return
+	}
+
+	read := receipt.Value().WithRead(time.Now())
+	if saved := h.store().UpdateDeliveryReceipt(r.Context(), read); saved.IsErr() {
+		slog.Error("Failed to record message read", "receipt_id", read.ID(), "error", saved.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to mark message as read")
+		return
+	}
+
+	respondWithPage(w, http.StatusOK, confirmationPage{
+		Heading: locale.T("read.heading"),
+		Text:    locale.T("read.done"),
+	})
+}
+
+// GetTimeline handles GET /api/v1/messages/timeline?id={id}
+func (h *ReceiptHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	messageID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message id")
+		return
+	}
+
+	msgResult := findLiveMessage(r.Context(), h.app, messageID)
+	if msgResult.IsErr() {
+		respondWithError(w, http.StatusNotFound, "message not found")
+		return
+	}
+
+	msg := msgResult.Value()
+	if msg.UserID() != userID {
+		respondWithError(w, http.StatusForbidden, "access denied")
+		return
+	}
+
+	receipts := loadDeliveryReceipts(r, h.app, msg.ID())
+	response := TimelineResponse{
+		MessageID: msg.ID().String(),
+		Events:    []TimelineEventResponse{},
+	}
+	for _, event := range message.BuildDeliveryTimeline(msg, receipts) {
+		response.Events = append(response.Events, TimelineEventResponse{
+			Kind:      string(event.Kind),
+			At:        event.At.Format(time.RFC3339),
+			Recipient: event.Recipient,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// receiptFromToken loads the receipt a link token was issued for
+func (h *ReceiptHandler) receiptFromToken(r *http.Request) common.Result[message.DeliveryReceipt] {
+	store := h.store()
+	if store == nil {
+		return common.Err[message.DeliveryReceipt](errors.New("delivery receipts are not supported"))
+	}
+
+	tokens := auth.NewActionTokenService(h.app.Config().JWTSecret)
+	receiptID := tokens.Verify(auth.PurposeReceipt, r.URL.Query().Get("token"))
+	if receiptID.IsErr() {
+		return common.Err[message.DeliveryReceipt](receiptID.Error())
+	}
+
+	return store.FindDeliveryReceiptByID(r.Context(), receiptID.Value())
+}
+
+func (h *ReceiptHandler) store() effects.DeliveryReceiptStore {
+	store, _ := h.app.Database().(effects.DeliveryReceiptStore)
+	return store
+}
+
+// loadDeliveryReceipts returns the receipts of a message, or none when the
+// database does not record them
+func loadDeliveryReceipts(r *http.Request, app *composition.App, messageID uuid.UUID) []message.DeliveryReceipt {
+	store, ok := app.Database().(effects.DeliveryReceiptStore)
+	if !ok {
+		return nil
+	}
+
+	receipts := store.FindDeliveryReceiptsByMessageID(r.Context(), messageID)
+	if receipts.IsErr() {
+		slog.Error("Failed to load delivery receipts", "message_id", messageID, "error", receipts.Error())
+		return nil
+	}
+	return receipts.Value()
+}
+
+func buildDeliveryReceiptResponse(receipt message.DeliveryReceipt) DeliveryReceiptResponse {
+	response := DeliveryReceiptResponse{
+		Recipient:   receipt.Recipient(),
+		DeliveredAt: receipt.DeliveredAt().Format(time.RFC3339),
+	}
+
+	if openedAt := receipt.OpenedAt(); openedAt.IsSome() {
+		formatted := openedAt.Value().Format(time.RFC3339)
+		response.OpenedAt = &formatted
+	}
+	if readAt := receipt.ReadAt(); readAt.IsSome() {
+		formatted := readAt.Value().Format(time.RFC3339)
+		response.ReadAt = &formatted
+	}
+
+	return response
+}
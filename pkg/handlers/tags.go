@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+)
+
+// TagHandler manages user-defined message tags.
+type TagHandler struct {
+	app *composition.App
+}
+
+// TagResponse represents a tag returned to clients.
+type TagResponse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	MessageCount int    `json:"message_count"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// TagRequest represents the body of tag create and rename requests.
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+// MergeTagsRequest represents the body of a tag merge request.
+type MergeTagsRequest struct {
+	SourceID string `json:"source_id"`
+	TargetID string `json:"target_id"`
+}
+
+// NewTagHandler creates a new handler instance.
+func NewTagHandler(app *composition.App) *TagHandler {
+	return &TagHandler{app: app}
+}
+
+// List handles GET /api/v1/tags
+func (h *TagHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tagsResult := h.app.Database().FindTagsByUserID(r.Context(), userID)
+	if tagsResult.IsErr() {
+		respondWithError(w, http.StatusInternalServerError, "failed to load tags")
+		return
+	}
+
+	responses := make([]TagResponse, 0, len(tagsResult.Value()))
+	for _, usage := range tagsResult.Value() {
+		responses = append(responses, tagToResponse(usage.Tag, usage.MessageCount))
+	}
+
+	respondWithJSON(w, http.StatusOK, responses)
+}
+
+// Create handles POST /api/v1/tags
+func (h *TagHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	tagResult := message.NewTag(userID, req.Name)
+	if tagResult.IsErr() {
+		respondWithError(w, http.StatusBadRequest, tagResult.Error().Error())
+		return
+	}
+
+	saveResult := h.app.Database().SaveTag(r.Context(), tagResult.Value())
+	if saveResult.IsErr() {
+		if errors.Is(saveResult.Error(), message.ErrTagExists) {
+			respondWithError(w, http.StatusConflict, "a tag with this name already exists")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "failed to create tag")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, tagToResponse(saveResult.Value(), 0))
+}
+
+// Rename handles PUT /api/v1/tags?id={id}
+func (h *TagHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tagID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid tag ID")
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	tagResult := h.verifyTagOwnership(r, userID, tagID)
+	if tagResult.IsErr() {
+		respondWithError(w, http.StatusNotFound, "tag not found")
+		return
+	}
+
+	renamed := tagResult.Value().WithName(req.Name)
+	if renamed.IsErr() {
+		respondWithError(w, http.StatusBadRequest, renamed.Error().Error())
+		return
+	}
+
+	updateResult := h.app.Database().UpdateTag(r.Context(), renamed.Value())
+	if updateResult.IsErr() {
+		if errors.Is(updateResult.Error(), message.ErrTagExists) {
+			respondWithError(w, http.StatusConflict, "a tag with this name already exists; merge the tags instead")
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "failed to rename tag")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tagToResponse(updateResult.Value(), h.messageCount(r, userID, tagID)))
+}
+
+// Delete handles DELETE /api/v1/tags?id={id}
+func (h *TagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tagID, err := parseUUIDParam(r, "id")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid tag ID")
+		return
+	}
+
+	if tagResult := h.verifyTagOwnership(r, userID, tagID); tagResult.IsErr() {
+		respondWithError(w, http.StatusNotFound, "tag not found")
+		return
+	}
+
+	deleteResult := h.app.Database().DeleteTag(r.Context(), tagID)
+	if deleteResult.IsErr() {
+		respondWithError(w, http.StatusInternalServerError, "failed to delete tag")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// Merge handles POST /api/v1/tags/merge
+func (h *TagHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req MergeTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sourceID, err := uuid.Parse(req.SourceID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid source_id")
+		return
+	}
+
+	targetID, err := uuid.Parse(req.TargetID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid target_id")
+		return
+	}
+
+	source := h.verifyTagOwnership(r, userID, sourceID)
+	if source.IsErr() {
+		respondWithError(w, http.StatusNotFound, "source tag not found")
+		return
+	}
+
+	target := h.verifyTagOwnership(r, userID, targetID)
+	if target.IsErr() {
+		respondWithError(w, http.StatusNotFound, "target tag not found")
+		return
+	}
+
+	if valid := message.ValidateTagMerge(source.Value(), target.Value()); valid.IsErr() {
+		respondWithError(w, http.StatusBadRequest, valid.Error().Error())
+		return
+	}
+
+	mergeResult := h.app.Database().MergeTags(r.Context(), sourceID, targetID)
+	if mergeResult.IsErr() {
+		respondWithError(w, http.StatusInternalServerError, "failed to merge tags")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tagToResponse(target.Value(), h.messageCount(r, userID, targetID)))
+}
+
+func (h *TagHandler) verifyTagOwnership(r *http.Request, userID uuid.UUID, tagID uuid.UUID) common.Result[message.Tag] {
+	tagResult := h.app.Database().FindTagByID(r.Context(), tagID)
+	if tagResult.IsErr() {
+		return tagResult
+	}
+
+	if tagResult.Value().UserID() != userID {
+		return common.Err[message.Tag](errors.New("access denied"))
+	}
+
+	return tagResult
+}
+
+// messageCount returns how many messages carry the tag, or zero if unknown
+func (h *TagHandler) messageCount(r *http.Request, userID uuid.UUID, tagID uuid.UUID) int {
+	tagsResult := h.app.Database().FindTagsByUserID(r.Context(), userID)
+	if tagsResult.IsErr() {
+		return 0
+	}
+
+	for _, usage := range tagsResult.Value() {
+		if usage.Tag.ID() == tagID {
+			return usage.MessageCount
+		}
+	}
+	return 0
+}
+
+func tagToResponse(tag message.Tag, messageCount int) TagResponse {
+	return TagResponse{
+		ID:           tag.ID().String(),
+		Name:         tag.Name(),
+		MessageCount: messageCount,
+		CreatedAt:    tag.CreatedAt().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+	"github.com/thanhphuchuynh/dear-future/pkg/services/transfer"
+)
+
+// maxImportBytes bounds the size of an uploaded import file
+const maxImportBytes = 10 * 1024 * 1024
+
+// TransferHandler handles bulk import and export of messages.
+type TransferHandler struct {
+	app *composition.App
+}
+
+// NewTransferHandler creates a new handler instance.
+func NewTransferHandler(app *composition.App) *TransferHandler {
+	return &TransferHandler{app: app}
+}
+
+// Import handles POST /api/v1/messages/import?format={jsonl|csv|mbox}[&dry_run=true]
+// The file is sent either as the raw request body or as the "file" field of
+// a multipart form; without a format parameter it is inferred from the file name.
+func (h *TransferHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	body := io.Reader(r.Body)
+	fileName := ""
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxImportBytes); err != nil {
+			respondWithError(w, http.StatusBadRequest, "failed to parse upload form")
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "file field is required")
+			return
+		}
+		defer file.Close()
+
+		body = file
+		fileName = header.Filename
+	}
+
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = strings.TrimPrefix(filepath.Ext(fileName), ".")
+	}
+	format := message.ParseTransferFormat(formatName)
+	if format.IsErr() {
+		respondWithError(w, http.StatusBadRequest, format.Error().Error())
+		return
+	}
+
+	// Imported messages count against the user's plan like created ones
+	planResult := loadPlanUsage(r.Context(), h.app, userID)
+	if planResult.IsErr() {
+		slog.Error("Failed to load plan usage", "user_id", userID, "error", planResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to import messages")
+		return
+	}
+
+	reportResult := h.service().Import(r.Context(), transfer.ImportOptions{
+		UserID: userID,
+		Format: format.Value(),
+		DryRun: r.URL.Query().Get("dry_run") == "true",
+		Limits: planResult.Value().limits,
+		Usage:  planResult.Value().usage,
+	}, body)
+	if reportResult.IsErr() {
+		respondWithError(w, http.StatusBadRequest, reportResult.Error().Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reportResult.Value())
+}
+
+// Export handles GET /api/v1/messages/export?format={jsonl|csv|mbox}
+func (h *TransferHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	format := common.Ok(message.TransferJSONLines)
+	if formatName := r.URL.Query().Get("format"); formatName != "" {
+		format = message.ParseTransferFormat(formatName)
+	}
+	if format.IsErr() {
+		respondWithError(w, http.StatusBadRequest, format.Error().Error())
+		return
+	}
+
+	// Buffer the export so a failure part way through still yields a JSON error
+	var buf bytes.Buffer
+	exportResult := h.service().Export(r.Context(), userID, format.Value(), &buf)
+	if exportResult.IsErr() {
+		respondWithError(w, http.StatusInternalServerError, "failed to export messages")
+		return
+	}
+
+	w.Header().Set("Content-Type", format.Value().ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="dear-future-messages.%s"`, format.Value()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+func (h *TransferHandler) service() *transfer.Service {
+	var scheduling effects.SchedulingService
+	if messageService := h.app.MessageService(); messageService != nil {
+		scheduling = messageService.Scheduling()
+	}
+	return transfer.NewService(h.app.Database(), scheduling)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+)
+
+// TrashListResponse represents the messages in a user's trash
+type TrashListResponse struct {
+	Messages      []MessageResponse `json:"messages"`
+	RetentionDays int               `json:"retention_days"`
+	Limit         int               `json:"limit"`
+	Offset        int               `json:"offset"`
+}
+
+// ListTrash handles GET /api/v1/messages/trash, most recently deleted first
+func (h *MessageHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := 20 // default
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	offset := 0 // default
+	if o, err := strconv.Atoi(query.Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	deletedResult := h.app.Database().FindDeletedMessages(r.Context(), userID, limit, offset)
+	if deletedResult.IsErr() {
+		slog.Error("Failed to list trash", "user_id", userID, "error", deletedResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to list trash")
+		return
+	}
+
+	retention := trashRetention(h.app)
+	tags := h.tagNamesByMessage(r, deletedResult.Value())
+
+	response := TrashListResponse{
+		Messages:      []MessageResponse{},
+		RetentionDays: int(retention / (24 * time.Hour)),
+		Limit:         limit,
+		Offset:        offset,
+	}
+	for _, msg := range deletedResult.Value() {
+		item := buildTrashResponse(msg, retention)
+		item.Tags = tags[msg.ID()]
+		response.Messages = append(response.Messages, item)
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// RestoreMessage handles POST /api/v1/messages/restore?id={id}, taking a
+// message out of the trash. A restored message counts against the plan again.
+func (h *MessageHandler) RestoreMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	messageID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid message id")
+		return
+	}
+
+	msgResult := h.app.Database().FindMessageByID(r.Context(), messageID)
+	if msgResult.IsErr() {
+		respondWithError(w, http.StatusNotFound, "message not found")
+		return
+	}
+
+	msg := msgResult.Value()
+	if msg.UserID() != userID {
+		respondWithError(w, http.StatusForbidden, "access denied")
+		return
+	}
+
+	restoredResult := msg.WithRestored()
+	if restoredResult.IsErr() {
+		respondWithError(w, http.StatusConflict, restoredResult.Error().Error())
+		return
+	}
+
+	planResult := loadPlanUsage(r.Context(), h.app, userID)
+	if planResult.IsErr() {
+		slog.Error("Failed to load plan usage", "user_id", userID, "error", planResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to restore message")
+		return
+	}
+	current := planResult.Value()
+	if allowed := message.ValidateNewMessageForPlan(restoredResult.Value(), current.limits, current.usage); allowed.IsErr() {
+		respondWithPlanError(w, allowed.Error())
+		return
+	}
+
+	saveResult := h.app.Database().UpdateMessage(r.Context(), restoredResult.Value())
+	if saveResult.IsErr() {
+		respondWithError(w, http.StatusInternalServerError, "failed to restore message")
+		return
+	}
+
+	restored := saveResult.Value()
+	response := buildMessageResponse(restored)
+	response.Tags = h.tagNamesByMessage(r, []message.Message{restored})[restored.ID()]
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// findLiveMessage loads a message that is not in the trash. Messages in the
+// trash are only reachable through the trash endpoints.
+func findLiveMessage(ctx context.Context, app *composition.App, messageID uuid.UUID) common.Result[message.Message] {
+	msgResult := app.Database().FindMessageByID(ctx, messageID)
+	if msgResult.IsOk() && msgResult.Value().IsDeleted() {
+		return common.Err[message.Message](errors.New("message not found"))
+	}
+	return msgResult
+}
+
+// trashRetention returns how long deleted messages stay in the trash
+func trashRetention(app *composition.App) time.Duration {
+	if retention := app.Config().TrashRetention; retention > 0 {
+		return retention
+	}
+	return message.DefaultTrashRetention
+}
+
+func buildTrashResponse(msg message.Message, retention time.Duration) MessageResponse {
+	response := buildMessageResponse(msg)
+	if purgeAt := msg.PurgeAt(retention); purgeAt.IsSome() {
+		formatted := purgeAt.Value().Format(time.RFC3339)
+		response.PurgeAt = &formatted
+	}
+	return response
+}
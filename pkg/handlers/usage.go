@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
+)
+
+// PlanLimitsResponse represents the limits of a plan; zero means unlimited
+type PlanLimitsResponse struct {
+	MaxScheduledMessages int   `json:"max_scheduled_messages"`
+	MaxAttachmentBytes   int64 `json:"max_attachment_bytes"`
+	Recurrence           bool  `json:"recurrence"`
+}
+
+// UsageCountersResponse represents what a user consumes of their plan
+type UsageCountersResponse struct {
+	ScheduledMessages int   `json:"scheduled_messages"`
+	AttachmentBytes   int64 `json:"attachment_bytes"`
+}
+
+// UsageResponse represents a user's plan, its limits and current usage
+type UsageResponse struct {
+	Plan   string                `json:"plan"`
+	Limits PlanLimitsResponse    `json:"limits"`
+	Usage  UsageCountersResponse `json:"usage"`
+}
+
+// planUsage is a user's plan together with its limits and current usage
+type planUsage struct {
+	plan   user.Plan
+	limits user.PlanLimits
+	usage  user.Usage
+}
+
+// GetUsage handles GET /api/v1/user/usage
+func (h *UserHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	planResult := loadPlanUsage(r.Context(), h.app, userID)
+	if planResult.IsErr() {
+		slog.Error("Failed to load plan usage", "user_id", userID, "error", planResult.Error())
+		respondWithError(w, http.StatusInternalServerError, "failed to load usage")
+		return
+	}
+
+	current := planResult.Value()
+	respondWithJSON(w, http.StatusOK, UsageResponse{
+		Plan: string(current.plan),
+		Limits: PlanLimitsResponse{
+			MaxScheduledMessages: current.limits.MaxScheduledMessages,
+			MaxAttachmentBytes:   current.limits.MaxAttachmentBytes,
+			Recurrence:           current.limits.Recurrence,
+		},
+		Usage: UsageCountersResponse{
+			ScheduledMessages: current.usage.ScheduledMessages,
+			AttachmentBytes:   current.usage.AttachmentBytes,
+		},
+	})
+}
+
+// loadPlanUsage loads a user's plan, its configured limits and what the
+// user currently consumes
+func loadPlanUsage(ctx context.Context, app *composition.App, userID uuid.UUID) common.Result[planUsage] {
+	profileResult := loadUserProfile(ctx, app.Database(), userID)
+	if profileResult.IsErr() {
+		return common.Err[planUsage](profileResult.Error())
+	}
+
+	usageResult := app.Database().FindUserUsage(ctx, userID)
+	if usageResult.IsErr() {
+		return common.Err[planUsage](usageResult.Error())
+	}
+
+	plan := profileResult.Value().Plan()
+	return common.Ok(planUsage{
+		plan:   plan,
+		limits: app.Config().PlanLimits(plan),
+		usage:  usageResult.Value(),
+	})
+}
+
+// respondWithPlanError reports a plan violation: 402 when a quota is used
+// up and more can be bought, 403 when the plan lacks the feature
+func respondWithPlanError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, message.ErrQuotaExceeded):
+		respondWithError(w, http.StatusPaymentRequired, err.Error())
+	case errors.Is(err, message.ErrFeatureNotInPlan):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	}
+}
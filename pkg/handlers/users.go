@@ -3,10 +3,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
 
 	"github.com/thanhphuchuynh/dear-future/pkg/auth"
 	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
 	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
 )
@@ -33,6 +37,7 @@ type RegisterRequest struct {
 	Name     string `json:"name"`
 	Password string `json:"password"`
 	Timezone string `json:"timezone"`
+	Locale   string `json:"locale"` // Defaults to the request's Accept-Language
 }
 
 // LoginRequest represents a login request
@@ -51,11 +56,15 @@ type AuthResponse struct {
 
 // UserResponse represents a user in API responses
 type UserResponse struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	Name      string `json:"name"`
-	Timezone  string `json:"timezone"`
-	CreatedAt string `json:"created_at"`
+	ID                  string                       `json:"id"`
+	Email               string                       `json:"email"`
+	Name                string                       `json:"name"`
+	Timezone            string                       `json:"timezone"`
+	Locale              string                       `json:"locale"`
+	CreatedAt           string                       `json:"created_at"`
+	DeliveryPreferences *DeliveryPreferencesResponse `json:"delivery_preferences,omitempty"`
+	Plan                string                       `json:"plan,omitempty"`
+	Milestones          []MilestonePayload           `json:"milestones"`
 }
 
 // Register handles user registration
@@ -76,6 +85,10 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		req.Timezone = "UTC"
 	}
 
+	if req.Locale == "" {
+		req.Locale = string(i18n.FromContext(r.Context()))
+	}
+
 	// Validate password strength
 	validationResult := h.passwordHasher.ValidatePassword(req.Password)
 	if validationResult.IsErr() {
@@ -96,6 +109,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		Email:    req.Email,
 		Name:     req.Name,
 		Timezone: req.Timezone,
+		Locale:   req.Locale,
 	}
 
 	userResult := user.NewUser(createUserReq)
@@ -137,6 +151,7 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 			Email:     savedUser.Email(),
 			Name:      savedUser.Name(),
 			Timezone:  savedUser.Timezone(),
+			Locale:    string(savedUser.Locale()),
 			CreatedAt: savedUser.CreatedAt().Format("2006-01-02T15:04:05Z"),
 		},
 		AccessToken:  tokenPair.AccessToken,
@@ -192,6 +207,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 			Email:     foundUser.Email(),
 			Name:      foundUser.Name(),
 			Timezone:  foundUser.Timezone(),
+			Locale:    string(foundUser.Locale()),
 			CreatedAt: foundUser.CreatedAt().Format("2006-01-02T15:04:05Z"),
 		},
 		AccessToken:  tokenPair.AccessToken,
@@ -211,24 +227,14 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Find user
-	userResult := h.app.Database().FindUserByID(r.Context(), userID)
-	if userResult.IsErr() {
+	// Find user with profile settings
+	profileResult := loadUserProfile(r.Context(), h.app.Database(), userID)
+	if profileResult.IsErr() {
 		respondWithError(w, http.StatusNotFound, "user not found")
 		return
 	}
 
-	foundUser := userResult.Value()
-
-	response := UserResponse{
-		ID:        foundUser.ID().String(),
-		Email:     foundUser.Email(),
-		Name:      foundUser.Name(),
-		Timezone:  foundUser.Timezone(),
-		CreatedAt: foundUser.CreatedAt().Format("2006-01-02T15:04:05Z"),
-	}
-
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, http.StatusOK, buildProfileResponse(profileResult.Value()))
 }
 
 // UpdateProfile updates the current user's profile
@@ -241,8 +247,12 @@ func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		Name     *string `json:"name"`
-		Timezone *string `json:"timezone"`
+		Name                *string                     `json:"name"`
+		Timezone            *string                     `json:"timezone"`
+		TimezonePolicy      string                      `json:"timezone_policy"` // keep_instant (default) or keep_local_time
+		Locale              *string                     `json:"locale"`
+		DeliveryPreferences *DeliveryPreferencesRequest `json:"delivery_preferences"`
+		Milestones          *[]MilestonePayload         `json:"milestones"` // Replaces all milestones
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -250,14 +260,15 @@ func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Find user
-	userResult := h.app.Database().FindUserByID(r.Context(), userID)
-	if userResult.IsErr() {
+	// Find user with profile settings
+	profileResult := loadUserProfile(r.Context(), h.app.Database(), userID)
+	if profileResult.IsErr() {
 		respondWithError(w, http.StatusNotFound, "user not found")
 		return
 	}
 
-	currentUser := userResult.Value()
+	profile := profileResult.Value()
+	currentUser := profile.User()
 
 	// Apply updates
 	updatedUser := currentUser
@@ -279,24 +290,105 @@ func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		updatedUser = updateResult.Value()
 	}
 
-	// Save updated user
-	saveResult := h.app.Database().UpdateUser(r.Context(), updatedUser)
+	if req.Locale != nil {
+		updateResult := updatedUser.WithLocale(*req.Locale)
+		if updateResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, updateResult.Error().Error())
+			return
+		}
+		updatedUser = updateResult.Value()
+	}
+
+	profile = profile.WithUser(updatedUser)
+	if req.DeliveryPreferences != nil {
+		prefsResult := req.DeliveryPreferences.toDomain()
+		if prefsResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, prefsResult.Error().Error())
+			return
+		}
+		profile = profile.WithDeliveryPreferences(prefsResult.Value())
+	}
+
+	var corrected []user.Milestone
+	if req.Milestones != nil {
+		previous := profile.Milestones()
+		updateResult := common.Bind(milestonesToDomain(*req.Milestones), profile.WithMilestones)
+		if updateResult.IsErr() {
+			respondWithError(w, http.StatusBadRequest, updateResult.Error().Error())
+			return
+		}
+		profile = updateResult.Value()
+		corrected = user.CorrectedMilestones(previous, profile.Milestones())
+	}
+
+	policyResult := message.ParseTimezonePolicy(req.TimezonePolicy)
+	if policyResult.IsErr() {
+		respondWithError(w, http.StatusBadRequest, policyResult.Error().Error())
+		return
+	}
+
+	// Pending letters in the old timezone move with the author, and letters
+	// anchored to a corrected milestone move with it; all of them must still
+	// land in the future before anything is saved
+	var rescheduled []message.Message
+	timezoneChanged := updatedUser.Timezone() != currentUser.Timezone()
+	if timezoneChanged || len(corrected) > 0 {
+		pendingResult := h.app.Database().FindPendingMessages(r.Context(), userID)
+		if pendingResult.IsErr() {
+			slog.Error("Failed to load pending messages", "user_id", userID, "error", pendingResult.Error())
+			respondWithError(w, http.StatusInternalServerError, "failed to update user")
+			return
+		}
+		pending := pendingResult.Value()
+
+		if timezoneChanged {
+			movedResult := message.MoveMessagesToTimezone(pending, currentUser.Timezone(), updatedUser.Timezone(), policyResult.Value())
+			if movedResult.IsErr() {
+				respondWithError(w, http.StatusBadRequest, movedResult.Error().Error())
+				return
+			}
+			rescheduled = movedResult.Value()
+			pending = mergeMessages(pending, rescheduled)
+		}
+
+		if len(corrected) > 0 {
+			reanchoredResult := message.ReanchorMessages(pending, corrected)
+			if reanchoredResult.IsErr() {
+				respondWithError(w, http.StatusBadRequest, reanchoredResult.Error().Error())
+				return
+			}
+			rescheduled = mergeMessages(rescheduled, reanchoredResult.Value())
+		}
+	}
+
+	// Save updated profile
+	saveResult := h.app.Database().UpdateUserProfile(r.Context(), profile)
 	if saveResult.IsErr() {
 		respondWithError(w, http.StatusInternalServerError, "failed to update user")
 		return
 	}
 
-	savedUser := saveResult.Value()
-
-	response := UserResponse{
-		ID:        savedUser.ID().String(),
-		Email:     savedUser.Email(),
-		Name:      savedUser.Name(),
-		Timezone:  savedUser.Timezone(),
-		CreatedAt: savedUser.CreatedAt().Format("2006-01-02T15:04:05Z"),
+	if moved := saveRescheduledMessages(r.Context(), h.app, rescheduled); moved > 0 {
+		slog.Info("Messages moved with profile changes", "user_id", userID, "moved", moved, "timezone_policy", policyResult.Value())
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, http.StatusOK, buildProfileResponse(saveResult.Value()))
+}
+
+// buildProfileResponse converts a profile into its API representation
+func buildProfileResponse(profile user.UserProfile) UserResponse {
+	u := profile.User()
+	return UserResponse{
+		ID:                  u.ID().String(),
+		Email:               u.Email(),
+		Name:                u.Name(),
+		Timezone:            u.Timezone(),
+		Locale:              string(u.Locale()),
+		CreatedAt:           u.CreatedAt().Format("2006-01-02T15:04:05Z"),
+		DeliveryPreferences: buildDeliveryPreferencesResponse(profile.DeliveryPreferences()),
+		Plan:                string(profile.Plan()),
+		Milestones:          buildMilestonesResponse(profile.Milestones()),
+	}
 }
 
 // RefreshToken generates a new access token from a refresh token
@@ -335,8 +427,9 @@ func (h *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 
 // Helper functions
 
+// respondWithError sends a JSON error response in the negotiated locale
 func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
+	respondWithJSON(w, code, map[string]string{"error": middleware.LocaleOf(w).Error(message)})
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
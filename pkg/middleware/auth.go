@@ -103,7 +103,7 @@ func GetEmailFromContext(ctx context.Context) (string, bool) {
 
 // respondWithError sends a JSON error response
 func respondWithError(w http.ResponseWriter, code int, message string) {
-	respondWithJSON(w, code, map[string]string{"error": message})
+	respondWithJSON(w, code, map[string]string{"error": LocaleOf(w).Error(message)})
 }
 
 // respondWithJSON sends a JSON response
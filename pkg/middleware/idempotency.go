@@ -0,0 +1,203 @@
+// Package middleware provides HTTP middleware functions
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client's key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from a stored one
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// IdempotencyKeyTTL is how long a key and its response are kept
+	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyLeaseTTL is how long a key is held for a request still in
+	// progress. A server that crashed mid-request leaves its reservation
+	// behind; once the lease runs out the key can be used again.
+	IdempotencyLeaseTTL = 5 * time.Minute
+
+	maxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders are the response headers stored and replayed with the body
+var replayedHeaders = []string{"Content-Language", "Content-Type", "ETag", "Location"}
+
+// recordingWriter wraps http.ResponseWriter to keep a copy of the response
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if rw.statusCode == 0 {
+		rw.statusCode = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if rw.statusCode == 0 {
+		rw.statusCode = http.StatusOK
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// IdempotencyMiddleware makes POST requests sent with an Idempotency-Key
+// header safe to retry. The first request with a key runs the handler and
+// its response is stored; later requests with the same key and body get the
+// stored response back without running the handler again. Reusing a key for
+// a different request is a conflict.
+//
+// Handlers opt in by being wrapped after AuthMiddleware, since keys are
+// scoped per user. Without a store, or without the header, requests pass
+// through unchanged. Bodies of requests with a key are read to fingerprint
+// them, so bodies over maxBodyBytes are rejected.
+func IdempotencyMiddleware(store effects.IdempotencyStore, maxBodyBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader))
+			if store == nil || key == "" || r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				respondWithError(w, http.StatusBadRequest, "idempotency key is too long")
+				return
+			}
+
+			userID, ok := GetUserIDFromContext(r.Context())
+			if !ok {
+				respondWithError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+			r.Body.Close()
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					respondWithError(w, http.StatusRequestEntityTooLarge, "request body is too large")
+					return
+				}
+				respondWithError(w, http.StatusBadRequest, "failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// The reservation is matched by its creation time, which the
+			// database keeps to the microsecond
+			now := time.Now().Truncate(time.Microsecond)
+			record := effects.IdempotencyRecord{
+				UserID:      userID,
+				Key:         key,
+				RequestHash: hashRequest(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(IdempotencyLeaseTTL),
+			}
+
+			reserved := store.ReserveIdempotencyKey(r.Context(), record)
+			if reserved.IsErr() {
+				slog.Error("Failed to reserve idempotency key", "user_id", userID, "error", reserved.Error())
+				respondWithError(w, http.StatusInternalServerError, "failed to process idempotency key")
+				return
+			}
+
+			if existing := reserved.Value(); existing.IsSome() {
+				replayResponse(w, record, existing.Value())
+				return
+			}
+
+			// The outcome is stored even when the client already hung up,
+			// which is exactly when it is going to retry
+			storeCtx := context.WithoutCancel(r.Context())
+
+			rw := &recordingWriter{ResponseWriter: w}
+			completed := false
+			defer func() {
+				// A request that failed on the server, or panicked, can be
+				// retried with the same key
+				if !completed {
+					if released := store.ReleaseIdempotencyKey(storeCtx, record); released.IsErr() {
+						slog.Error("Failed to release idempotency key", "user_id", userID, "error", released.Error())
+					}
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+
+			if rw.statusCode == 0 || rw.statusCode >= http.StatusInternalServerError {
+				return
+			}
+
+			record.StatusCode = rw.statusCode
+			record.Headers = map[string]string{}
+			for _, name := range replayedHeaders {
+				if value := rw.Header().Get(name); value != "" {
+					record.Headers[name] = value
+				}
+			}
+			record.Body = rw.body.Bytes()
+			record.ExpiresAt = record.CreatedAt.Add(IdempotencyKeyTTL)
+
+			if saved := store.CompleteIdempotencyKey(storeCtx, record); saved.IsErr() {
+				slog.Error("Failed to store idempotent response", "user_id", userID, "error", saved.Error())
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// replayResponse answers a request whose key was already used
+func replayResponse(w http.ResponseWriter, request, stored effects.IdempotencyRecord) {
+	if stored.RequestHash != request.RequestHash {
+		respondWithError(w, http.StatusConflict, "idempotency key was already used for a different request")
+		return
+	}
+	if stored.StatusCode == 0 {
+		respondWithError(w, http.StatusConflict, "a request with this idempotency key is still in progress")
+		return
+	}
+
+	for name, value := range stored.Headers {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.StatusCode)
+	w.Write(stored.Body)
+}
+
+// hashRequest fingerprints a request by method, path, query and body.
+// Multipart boundaries are random per attempt, so they are left out of the
+// hash to let a retried upload match its first attempt.
+func hashRequest(r *http.Request, body []byte) string {
+	if mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil &&
+		strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
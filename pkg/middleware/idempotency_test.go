@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// memoryIdempotencyStore keeps idempotency records in memory, matching
+// reservations by their creation time like the PostgreSQL store
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]effects.IdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: map[string]effects.IdempotencyRecord{}}
+}
+
+func (s *memoryIdempotencyStore) ReserveIdempotencyKey(ctx context.Context, record effects.IdempotencyRecord) common.Result[common.Option[effects.IdempotencyRecord]] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := record.UserID.String() + "/" + record.Key
+	if existing, ok := s.records[id]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		return common.Ok(common.Some(existing))
+	}
+	s.records[id] = record
+	return common.Ok(common.None[effects.IdempotencyRecord]())
+}
+
+func (s *memoryIdempotencyStore) CompleteIdempotencyKey(ctx context.Context, record effects.IdempotencyRecord) common.Result[effects.IdempotencyRecord] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := record.UserID.String() + "/" + record.Key
+	existing, ok := s.records[id]
+	if !ok || !existing.CreatedAt.Equal(record.CreatedAt) || existing.StatusCode != 0 {
+		return common.Err[effects.IdempotencyRecord](errors.New("idempotency key reservation not found"))
+	}
+	s.records[id] = record
+	return common.Ok(record)
+}
+
+func (s *memoryIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, record effects.IdempotencyRecord) common.Result[bool] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := record.UserID.String() + "/" + record.Key
+	existing, ok := s.records[id]
+	if !ok || !existing.CreatedAt.Equal(record.CreatedAt) || existing.StatusCode != 0 {
+		return common.Ok(false)
+	}
+	delete(s.records, id)
+	return common.Ok(true)
+}
+
+func (s *memoryIdempotencyStore) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) common.Result[int] {
+	return common.Ok(0)
+}
+
+// idempotentRequest sends a POST with an Idempotency-Key through handler
+func idempotentRequest(handler http.Handler, userID uuid.UUID, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/messages", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     []int // Status the handler answers with on each run
+		bodies     []string
+		wantStatus []int
+		wantCalls  int
+		wantReplay []bool
+	}{
+		{
+			name:       "retry replays the stored response",
+			status:     []int{http.StatusCreated},
+			bodies:     []string{`{"title":"a"}`, `{"title":"a"}`},
+			wantStatus: []int{http.StatusCreated, http.StatusCreated},
+			wantCalls:  1,
+			wantReplay: []bool{false, true},
+		},
+		{
+			name:       "different body is a conflict",
+			status:     []int{http.StatusCreated},
+			bodies:     []string{`{"title":"a"}`, `{"title":"b"}`},
+			wantStatus: []int{http.StatusCreated, http.StatusConflict},
+			wantCalls:  1,
+			wantReplay: []bool{false, false},
+		},
+		{
+			name:       "client errors are replayed",
+			status:     []int{http.StatusBadRequest},
+			bodies:     []string{`{}`, `{}`},
+			wantStatus: []int{http.StatusBadRequest, http.StatusBadRequest},
+			wantCalls:  1,
+			wantReplay: []bool{false, true},
+		},
+		{
+			name:       "server error releases the key",
+			status:     []int{http.StatusInternalServerError, http.StatusCreated},
+			bodies:     []string{`{"title":"a"}`, `{"title":"a"}`},
+			wantStatus: []int{http.StatusInternalServerError, http.StatusCreated},
+			wantCalls:  2,
+			wantReplay: []bool{false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			handler := IdempotencyMiddleware(newMemoryIdempotencyStore(), 1<<20)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.status[min(calls, len(tt.status)-1)]
+				calls++
+				respondWithJSON(w, status, map[string]int{"run": calls})
+			}))
+
+			userID := uuid.New()
+			var first string
+			for i, body := range tt.bodies {
+				rec := idempotentRequest(handler, userID, "key-1", body)
+				if rec.Code != tt.wantStatus[i] {
+					t.Errorf("request %d status = %d, want %d", i+1, rec.Code, tt.wantStatus[i])
+				}
+				replayed := rec.Header().Get(IdempotentReplayedHeader) == "true"
+				if replayed != tt.wantReplay[i] {
+					t.Errorf("request %d replayed = %v, want %v", i+1, replayed, tt.wantReplay[i])
+				}
+				if i == 0 {
+					first = rec.Body.String()
+				} else if replayed && rec.Body.String() != first {
+					t.Errorf("replayed body = %q, want %q", rec.Body.String(), first)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareReleasesKeyOnPanic(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	calls := 0
+	handler := IdempotencyMiddleware(store, 1<<20)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("handler failed")
+		}
+		respondWithJSON(w, http.StatusCreated, map[string]string{"status": "created"})
+	}))
+
+	userID := uuid.New()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic was not passed on")
+			}
+		}()
+		idempotentRequest(handler, userID, "key-1", `{}`)
+	}()
+
+	if rec := idempotentRequest(handler, userID, "key-1", `{}`); rec.Code != http.StatusCreated {
+		t.Errorf("retry after panic status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyMiddlewareKeepsTakenOverKey(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	userID := uuid.New()
+	handler := IdempotencyMiddleware(store, 1<<20)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// While this request runs its lease runs out and another request
+		// takes the key over
+		now := time.Now().Add(IdempotencyLeaseTTL).Truncate(time.Microsecond)
+		store.ReserveIdempotencyKey(r.Context(), effects.IdempotencyRecord{
+			UserID:      userID,
+			Key:         "key-1",
+			RequestHash: "other",
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(IdempotencyLeaseTTL),
+		})
+		respondWithError(w, http.StatusInternalServerError, "failed")
+	}))
+
+	idempotentRequest(handler, userID, "key-1", `{}`)
+
+	record, ok := store.records[userID.String()+"/key-1"]
+	if !ok || record.RequestHash != "other" {
+		t.Errorf("reservation of the other request was released: %+v", record)
+	}
+}
@@ -0,0 +1,51 @@
+// Package middleware provides HTTP middleware functions
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/i18n"
+)
+
+// localeWriter wraps http.ResponseWriter to carry the negotiated locale down
+// to helpers that only see the writer, such as respondWithError
+type localeWriter struct {
+	http.ResponseWriter
+	locale i18n.Locale
+}
+
+func (lw *localeWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
+
+// LocaleMiddleware negotiates the response locale from the Accept-Language
+// header. The locale is stored in the request context for services and in the
+// response writer so error responses can be translated.
+func LocaleMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale := i18n.Negotiate(r.Header.Get("Accept-Language"))
+
+			w.Header().Add("Vary", "Accept-Language")
+			w.Header().Set("Content-Language", string(locale))
+
+			next.ServeHTTP(&localeWriter{ResponseWriter: w, locale: locale}, r.WithContext(i18n.WithLocale(r.Context(), locale)))
+		})
+	}
+}
+
+// LocaleOf returns the locale negotiated for the response, or the default
+// locale when the writer did not pass through LocaleMiddleware
+func LocaleOf(w http.ResponseWriter) i18n.Locale {
+	for w != nil {
+		if lw, ok := w.(*localeWriter); ok {
+			return lw.locale
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = unwrapper.Unwrap()
+	}
+	return i18n.DefaultLocale
+}
@@ -3,6 +3,8 @@ package mocks
 
 import (
 	"context"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -55,6 +57,10 @@ func (m *MockDatabase) UpdateUserProfile(ctx context.Context, profile user.UserP
 	return common.Ok(profile)
 }
 
+func (m *MockDatabase) FindUserUsage(ctx context.Context, userID uuid.UUID) common.Result[user.Usage] {
+	return common.Ok(user.Usage{})
+}
+
 func (m *MockDatabase) SaveMessage(ctx context.Context, msg message.Message) common.Result[message.Message] {
 	return common.Ok(msg)
 }
@@ -67,6 +73,14 @@ func (m *MockDatabase) FindMessagesByUserID(ctx context.Context, userID uuid.UUI
 	return common.Ok([]message.Message{})
 }
 
+func (m *MockDatabase) FindMessagesPage(ctx context.Context, userID uuid.UUID, options message.ListOptions) common.Result[effects.MessagePage] {
+	return common.Ok(effects.MessagePage{
+		Messages:   []message.Message{},
+		NextCursor: common.None[message.ListCursor](),
+		Total:      0,
+	})
+}
+
 func (m *MockDatabase) FindMessagesByStatus(ctx context.Context, status message.MessageStatus, limit int) common.Result[[]message.Message] {
 	return common.Ok([]message.Message{})
 }
@@ -75,6 +89,10 @@ func (m *MockDatabase) FindDueMessages(ctx context.Context, before time.Time, li
 	return common.Ok([]message.Message{})
 }
 
+func (m *MockDatabase) FindPendingMessages(ctx context.Context, userID uuid.UUID) common.Result[[]message.Message] {
+	return common.Ok([]message.Message{})
+}
+
 func (m *MockDatabase) UpdateMessage(ctx context.Context, msg message.Message) common.Result[message.Message] {
 	return common.Ok(msg)
 }
@@ -83,6 +101,18 @@ func (m *MockDatabase) DeleteMessage(ctx context.Context, messageID uuid.UUID) c
 	return common.Ok(true)
 }
 
+func (m *MockDatabase) SearchMessages(ctx context.Context, query effects.MessageSearchQuery) common.Result[[]effects.MessageSearchResult] {
+	return common.Ok([]effects.MessageSearchResult{})
+}
+
+func (m *MockDatabase) FindDeletedMessages(ctx context.Context, userID uuid.UUID, limit, offset int) common.Result[[]message.Message] {
+	return common.Ok([]message.Message{})
+}
+
+func (m *MockDatabase) FindPurgeableMessages(ctx context.Context, deletedBefore time.Time, limit int) common.Result[[]message.Message] {
+	return common.Ok([]message.Message{})
+}
+
 func (m *MockDatabase) SaveMessageAttachment(ctx context.Context, attachment message.MessageAttachment) common.Result[message.MessageAttachment] {
 	return common.Ok(attachment)
 }
@@ -95,6 +125,50 @@ func (m *MockDatabase) DeleteAttachment(ctx context.Context, attachmentID uuid.U
 	return common.Ok(true)
 }
 
+func (m *MockDatabase) SaveTag(ctx context.Context, tag message.Tag) common.Result[message.Tag] {
+	return common.Ok(tag)
+}
+
+func (m *MockDatabase) FindTagByID(ctx context.Context, tagID uuid.UUID) common.Result[message.Tag] {
+	return common.Err[message.Tag](NewError("tag not found"))
+}
+
+func (m *MockDatabase) FindTagsByUserID(ctx context.Context, userID uuid.UUID) common.Result[[]effects.TagUsage] {
+	return common.Ok([]effects.TagUsage{})
+}
+
+func (m *MockDatabase) UpdateTag(ctx context.Context, tag message.Tag) common.Result[message.Tag] {
+	return common.Ok(tag)
+}
+
+func (m *MockDatabase) DeleteTag(ctx context.Context, tagID uuid.UUID) common.Result[bool] {
+	return common.Ok(true)
+}
+
+func (m *MockDatabase) MergeTags(ctx context.Context, sourceID, targetID uuid.UUID) common.Result[bool] {
+	return common.Ok(true)
+}
+
+func (m *MockDatabase) EnsureTags(ctx context.Context, userID uuid.UUID, names []string) common.Result[[]message.Tag] {
+	tags := make([]message.Tag, 0, len(names))
+	for _, name := range names {
+		tagResult := message.NewTag(userID, name)
+		if tagResult.IsErr() {
+			return common.Err[[]message.Tag](tagResult.Error())
+		}
+		tags = append(tags, tagResult.Value())
+	}
+	return common.Ok(tags)
+}
+
+func (m *MockDatabase) SetMessageTags(ctx context.Context, messageID uuid.UUID, tagIDs []uuid.UUID) common.Result[bool] {
+	return common.Ok(true)
+}
+
+func (m *MockDatabase) FindTagsForMessages(ctx context.Context, messageIDs []uuid.UUID) common.Result[map[uuid.UUID][]message.Tag] {
+	return common.Ok(map[uuid.UUID][]message.Tag{})
+}
+
 func (m *MockDatabase) SaveDeliveryLog(ctx context.Context, log effects.DeliveryLog) common.Result[effects.DeliveryLog] {
 	return common.Ok(log)
 }
@@ -212,6 +286,27 @@ func (m *MockEmailService) SendPasswordResetEmail(ctx context.Context, email, re
 	return common.Ok(result)
 }
 
+func (m *MockEmailService) PreviewMessage(ctx context.Context, deliveryInfo message.MessageDeliveryInfo) common.Result[effects.EmailPreview] {
+	preview := effects.EmailPreview{
+		Subject:  deliveryInfo.GetSubject(),
+		HTMLBody: deliveryInfo.GetHTMLContent(),
+		TextBody: deliveryInfo.GetBody(),
+	}
+	return common.Ok(preview)
+}
+
+func (m *MockEmailService) SendCheckInWarning(ctx context.Context, warning effects.CheckInWarning) common.Result[effects.EmailResult] {
+	result := effects.EmailResult{
+		MessageID: warning.MessageID.String(),
+		Status:    effects.EmailStatusSent,
+		SentAt:    time.Now(),
+		Error:     common.None[string](),
+		Recipient: warning.RecipientEmail,
+		Subject:   "Check-in Reminder",
+	}
+	return common.Ok(result)
+}
+
 func (m *MockEmailService) ValidateEmailConfiguration(ctx context.Context) common.Result[bool] {
 	return common.Ok(true)
 }
@@ -246,6 +341,10 @@ func (m *MockStorageService) DownloadFile(ctx context.Context, key string) commo
 	return common.Ok(result)
 }
 
+func (m *MockStorageService) OpenFile(ctx context.Context, key string) common.Result[io.ReadCloser] {
+	return common.Ok(io.NopCloser(strings.NewReader("mock file content")))
+}
+
 func (m *MockStorageService) GeneratePresignedURL(ctx context.Context, key string, expiration time.Duration) common.Result[string] {
 	url := "https://mock-presigned.com/" + key + "?expires=" + time.Now().Add(expiration).Format(time.RFC3339)
 	return common.Ok(url)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/thanhphuchuynh/dear-future/pkg/auth"
 	"github.com/thanhphuchuynh/dear-future/pkg/composition"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
 	"github.com/thanhphuchuynh/dear-future/pkg/handlers"
 	"github.com/thanhphuchuynh/dear-future/pkg/middleware"
 )
@@ -27,6 +28,10 @@ func NewRouter(app *composition.App) *http.ServeMux {
 	messageHandler := handlers.NewMessageHandler(app)
 	attachmentHandler := handlers.NewAttachmentHandler(app)
 	analyticsHandler := handlers.NewAnalyticsHandler(app)
+	tagHandler := handlers.NewTagHandler(app)
+	transferHandler := handlers.NewTransferHandler(app)
+	checkInHandler := handlers.NewCheckInHandler(app)
+	receiptHandler := handlers.NewReceiptHandler(app)
 
 	// Create middleware chain
 	authMiddleware := middleware.AuthMiddleware(jwtService)
@@ -34,6 +39,12 @@ func NewRouter(app *composition.App) *http.ServeMux {
 	loggingMiddleware := middleware.LoggingMiddleware()
 	recoveryMiddleware := middleware.RecoveryMiddleware()
 	securityMiddleware := middleware.SecurityHeadersMiddleware()
+	localeMiddleware := middleware.LocaleMiddleware()
+
+	// Create endpoints opt into idempotency keys when the database can store them
+	idempotencyStore, _ := app.Database().(effects.IdempotencyStore)
+	// Attachment uploads are the largest bodies, plus room for the form around the file
+	idempotencyMiddleware := middleware.IdempotencyMiddleware(idempotencyStore, app.Config().FileUpload.MaxFileSize+1<<20)
 
 	// Apply global middleware
 	globalMiddleware := chain(
@@ -41,10 +52,12 @@ func NewRouter(app *composition.App) *http.ServeMux {
 		loggingMiddleware,
 		corsMiddleware,
 		securityMiddleware,
+		localeMiddleware,
 	)
 
 	// Public routes (no authentication required)
 	mux.Handle("/health", globalMiddleware(http.HandlerFunc(healthHandler(app))))
+	mux.Handle("/metrics/scheduler", globalMiddleware(http.HandlerFunc(schedulerMetricsHandler(app))))
 	mux.Handle("/environment/current", globalMiddleware(http.HandlerFunc(environmentHandler(app))))
 
 	// Auth routes (public)
@@ -52,14 +65,41 @@ func NewRouter(app *composition.App) *http.ServeMux {
 	mux.Handle("/api/v1/auth/login", globalMiddleware(http.HandlerFunc(userHandler.Login)))
 	mux.Handle("/api/v1/auth/refresh", globalMiddleware(http.HandlerFunc(userHandler.RefreshToken)))
 
+	// Check-in email links (public, authorized by signed token)
+	mux.Handle("/api/v1/checkin/confirm", globalMiddleware(http.HandlerFunc(checkInHandler.ConfirmCheckIn)))
+	mux.Handle("/api/v1/checkin/cancel", globalMiddleware(http.HandlerFunc(checkInHandler.CancelReleaseByToken)))
+
+	// Delivery receipt email links (public, authorized by signed token)
+	mux.Handle("/api/v1/receipts/open", globalMiddleware(http.HandlerFunc(receiptHandler.TrackOpen)))
+	mux.Handle("/api/v1/receipts/read", globalMiddleware(http.HandlerFunc(receiptHandler.MarkRead)))
+
+	// Unsubscribe email links (public, authorized by signed token)
+	mux.Handle("/api/v1/notifications/unsubscribe", globalMiddleware(http.HandlerFunc(userHandler.Unsubscribe)))
+
 	// User routes (authenticated)
 	mux.Handle("/api/v1/user/profile", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(userHandler.GetProfile)))
 	mux.Handle("/api/v1/user/update", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(userHandler.UpdateProfile)))
+	mux.Handle("/api/v1/user/usage", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(userHandler.GetUsage)))
+	mux.Handle("/api/v1/user/notifications", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(userHandler.Notifications)))
 
 	// Message routes (authenticated)
-	mux.Handle("/api/v1/messages", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(handleMessagesRoute(messageHandler))))
-	mux.Handle("/api/v1/messages/create", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(messageHandler.CreateMessage)))
-	mux.Handle("/api/v1/messages/attachments", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(handleAttachmentsRoute(attachmentHandler))))
+	mux.Handle("/api/v1/messages", chain(globalMiddleware, authMiddleware, idempotencyMiddleware)(http.HandlerFunc(handleMessagesRoute(messageHandler))))
+	mux.Handle("/api/v1/messages/create", chain(globalMiddleware, authMiddleware, idempotencyMiddleware)(http.HandlerFunc(messageHandler.CreateMessage)))
+	mux.Handle("/api/v1/messages/search", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(messageHandler.SearchMessages)))
+	mux.Handle("/api/v1/messages/preview", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(messageHandler.PreviewMessage)))
+	mux.Handle("/api/v1/messages/resolve-date", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(messageHandler.ResolveDate)))
+	mux.Handle("/api/v1/holidays", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(messageHandler.ListHolidays)))
+	mux.Handle("/api/v1/messages/import", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(transferHandler.Import)))
+	mux.Handle("/api/v1/messages/export", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(transferHandler.Export)))
+	mux.Handle("/api/v1/messages/timeline", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(receiptHandler.GetTimeline)))
+	mux.Handle("/api/v1/messages/reply", chain(globalMiddleware, authMiddleware, idempotencyMiddleware)(http.HandlerFunc(messageHandler.ReplyToMessage)))
+	mux.Handle("/api/v1/messages/trash", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(messageHandler.ListTrash)))
+	mux.Handle("/api/v1/messages/restore", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(messageHandler.RestoreMessage)))
+	mux.Handle("/api/v1/messages/cancel-release", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(checkInHandler.CancelRelease)))
+	mux.Handle("/api/v1/checkin", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(checkInHandler.CheckIn)))
+	mux.Handle("/api/v1/messages/attachments", chain(globalMiddleware, authMiddleware, idempotencyMiddleware)(http.HandlerFunc(handleAttachmentsRoute(attachmentHandler))))
+	mux.Handle("/api/v1/tags", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(handleTagsRoute(tagHandler))))
+	mux.Handle("/api/v1/tags/merge", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(tagHandler.Merge)))
 	mux.Handle("/api/v1/analytics/summary", chain(globalMiddleware, authMiddleware)(http.HandlerFunc(analyticsHandler.GetSummary)))
 
 	// API info route
@@ -111,6 +151,23 @@ func handleAttachmentsRoute(h *handlers.AttachmentHandler) http.HandlerFunc {
 	}
 }
 
+func handleTagsRoute(h *handlers.TagHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.List(w, r)
+		case http.MethodPost:
+			h.Create(w, r)
+		case http.MethodPut:
+			h.Rename(w, r)
+		case http.MethodDelete:
+			h.Delete(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
 // apiInfoHandler returns API information
 func apiInfoHandler(app *composition.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -123,6 +180,10 @@ func apiInfoHandler(app *composition.App) http.HandlerFunc {
 					"path":   "/health",
 					"method": "GET",
 				},
+				"scheduler_metrics": map[string]string{
+					"path":   "/metrics/scheduler",
+					"method": "GET",
+				},
 				"auth": map[string]interface{}{
 					"register": map[string]string{
 						"path":   "/api/v1/auth/register",
@@ -146,10 +207,26 @@ func apiInfoHandler(app *composition.App) http.HandlerFunc {
 						"path":   "/api/v1/user/update",
 						"method": "PUT",
 					},
+					"usage": map[string]string{
+						"path":   "/api/v1/user/usage",
+						"method": "GET",
+					},
+					"notifications": map[string]string{
+						"path":   "/api/v1/user/notifications",
+						"method": "GET",
+					},
+					"update_notifications": map[string]string{
+						"path":   "/api/v1/user/notifications",
+						"method": "PUT",
+					},
+					"unsubscribe": map[string]string{
+						"path":   "/api/v1/notifications/unsubscribe?category={category}&token={token}",
+						"method": "POST",
+					},
 				},
 				"messages": map[string]interface{}{
 					"list": map[string]string{
-						"path":   "/api/v1/messages",
+						"path":   "/api/v1/messages[?status=&delivery_method=&recurrence=&tag=&from=&to=&sort=&order=&cursor=&limit=]",
 						"method": "GET",
 					},
 					"create": map[string]string{
@@ -168,6 +245,96 @@ func apiInfoHandler(app *composition.App) http.HandlerFunc {
 						"path":   "/api/v1/messages?id={id}",
 						"method": "DELETE",
 					},
+					"search": map[string]string{
+						"path":   "/api/v1/messages/search?q={query}[&status=&from=&to=&limit=&offset=]",
+						"method": "GET",
+					},
+					"preview": map[string]string{
+						"path":   "/api/v1/messages/preview[?id={id}]",
+						"method": "POST",
+					},
+					"resolve_date": map[string]string{
+						"path":   "/api/v1/messages/resolve-date",
+						"method": "POST",
+					},
+					"holidays": map[string]string{
+						"path":   "/api/v1/holidays[?country={code}&year={year}]",
+						"method": "GET",
+					},
+					"import": map[string]string{
+						"path":   "/api/v1/messages/import?format={jsonl|csv|mbox}[&dry_run=true]",
+						"method": "POST",
+					},
+					"export": map[string]string{
+						"path":   "/api/v1/messages/export[?format={jsonl|csv|mbox}]",
+						"method": "GET",
+					},
+					"timeline": map[string]string{
+						"path":   "/api/v1/messages/timeline?id={id}",
+						"method": "GET",
+					},
+					"reply": map[string]string{
+						"path":   "/api/v1/messages/reply?id={id}",
+						"method": "POST",
+					},
+					"trash": map[string]string{
+						"path":   "/api/v1/messages/trash",
+						"method": "GET",
+					},
+					"restore": map[string]string{
+						"path":   "/api/v1/messages/restore?id={id}",
+						"method": "POST",
+					},
+					"cancel_release": map[string]string{
+						"path":   "/api/v1/messages/cancel-release?id={id}",
+						"method": "POST",
+					},
+				},
+				"checkin": map[string]interface{}{
+					"check_in": map[string]string{
+						"path":   "/api/v1/checkin",
+						"method": "POST",
+					},
+					"confirm": map[string]string{
+						"path":   "/api/v1/checkin/confirm?token={token}",
+						"method": "POST",
+					},
+					"cancel": map[string]string{
+						"path":   "/api/v1/checkin/cancel?token={token}",
+						"method": "POST",
+					},
+				},
+				"receipts": map[string]interface{}{
+					"open": map[string]string{
+						"path":   "/api/v1/receipts/open?token={token}",
+						"method": "GET",
+					},
+					"read": map[string]string{
+						"path":   "/api/v1/receipts/read?token={token}",
+						"method": "POST",
+					},
+				},
+				"tags": map[string]interface{}{
+					"list": map[string]string{
+						"path":   "/api/v1/tags",
+						"method": "GET",
+					},
+					"create": map[string]string{
+						"path":   "/api/v1/tags",
+						"method": "POST",
+					},
+					"rename": map[string]string{
+						"path":   "/api/v1/tags?id={id}",
+						"method": "PUT",
+					},
+					"delete": map[string]string{
+						"path":   "/api/v1/tags?id={id}",
+						"method": "DELETE",
+					},
+					"merge": map[string]string{
+						"path":   "/api/v1/tags/merge",
+						"method": "POST",
+					},
 				},
 			},
 		}
@@ -10,6 +10,7 @@ import (
 	"github.com/thanhphuchuynh/dear-future/pkg/composition"
 	"github.com/thanhphuchuynh/dear-future/pkg/config"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
 )
 
 // NewServer creates a new HTTP server with all routes configured
@@ -60,6 +61,30 @@ func healthHandler(app *composition.App) http.HandlerFunc {
 	}
 }
 
+// schedulerMetricsHandler returns the scheduler metrics, including the
+// delivery smoothing policy. It is not found when metrics are disabled.
+func schedulerMetricsHandler(app *composition.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !app.Config().MetricsEnabled || app.MessageService() == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		provider, ok := app.MessageService().Scheduling().(effects.SchedulerMetricsProvider)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		respondWithJSON(w, http.StatusOK, provider.Metrics())
+	}
+}
+
 // apiHandler handles all API routes
 func apiHandler(app *composition.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
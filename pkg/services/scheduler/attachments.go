@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// attachmentLoader adds the files of a letter to its delivery info. Small
+// files are streamed from storage into the email as it is sent; large ones
+// get an expiring download link. A nil loader sends letters without files.
+type attachmentLoader struct {
+	db      effects.Database
+	storage effects.StorageService
+}
+
+// newAttachmentLoader returns nil when there is no storage to load files from
+func newAttachmentLoader(db effects.Database, storage effects.StorageService) *attachmentLoader {
+	if storage == nil {
+		return nil
+	}
+	return &attachmentLoader{db: db, storage: storage}
+}
+
+// attach loads the attachments of the letter. A file missing from storage
+// is sent as a link instead; one that cannot be linked either is left out,
+// so a storage problem never blocks the delivery.
+func (l *attachmentLoader) attach(ctx context.Context, info message.MessageDeliveryInfo) message.MessageDeliveryInfo {
+	if l == nil {
+		return info
+	}
+
+	found := l.db.FindAttachmentsByMessageID(ctx, info.Message.ID())
+	if found.IsErr() {
+		slog.Warn("scheduler: failed to load attachments", "message_id", info.Message.ID(), "error", found.Error())
+		return info
+	}
+	if len(found.Value()) == 0 {
+		return info
+	}
+
+	var attachments []message.DeliveredAttachment
+	for _, attachment := range message.PlanAttachmentDelivery(found.Value()) {
+		if attachment.IsEmbedded() {
+			stored := l.storage.GetFileMetadata(ctx, attachment.StorageKey)
+			if stored.IsOk() {
+				attachments = append(attachments, attachment.WithContent(l.opener(ctx, attachment.StorageKey)))
+				continue
+			}
+			slog.Warn("scheduler: attachment not found in storage, sending a link", "message_id", info.Message.ID(), "attachment_id", attachment.ID, "error", stored.Error())
+		}
+
+		link := l.storage.GeneratePresignedURL(ctx, attachment.StorageKey, message.AttachmentLinkLifetime)
+		if link.IsErr() {
+			slog.Warn("scheduler: failed to link attachment, leaving it out", "message_id", info.Message.ID(), "attachment_id", attachment.ID, "error", link.Error())
+			continue
+		}
+		attachments = append(attachments, attachment.AsLink(link.Value(), time.Now().Add(message.AttachmentLinkLifetime)))
+	}
+
+	return info.WithAttachments(attachments)
+}
+
+// opener streams a stored file into the email being written
+func (l *attachmentLoader) opener(ctx context.Context, key string) message.AttachmentOpener {
+	return func() (io.ReadCloser, error) {
+		opened := l.storage.OpenFile(ctx, key)
+		if opened.IsErr() {
+			return nil, opened.Error()
+		}
+		return opened.Value(), nil
+	}
+}
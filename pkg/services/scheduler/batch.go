@@ -0,0 +1,284 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/config"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// defaultBatchSize is used when scheduling.batch_size is not configured
+const defaultBatchSize = 50
+
+// batchDeliverer delivers due messages in batches. It uses the EmailBatch and
+// DatabaseBatch interfaces when the configured services implement them and
+// falls back to one call per message otherwise.
+type batchDeliverer struct {
+	db          effects.Database
+	email       effects.EmailService
+	batchSize   int
+	receipts    *receiptIssuer
+	attachments *attachmentLoader
+	smoother    *deliverySmoother // Spreads deferred deliveries and next occurrences, may be nil
+}
+
+// messageUpdate is the new state of a processed message with the transition
+// that produced it, applied again when the message changed in the meantime.
+// Deferrals have no transition: a deferred message that changed is left for
+// the next run.
+type messageUpdate struct {
+	next  message.Message
+	apply Transition
+}
+
+// batchStats summarizes the outcome of a delivery run
+type batchStats struct {
+	Delivered int
+	Failed    int
+	Deferred  int // Moved out of the recipient's quiet hours
+}
+
+// newBatchDeliverer creates a deliverer using the configured batch size
+func newBatchDeliverer(db effects.Database, email effects.EmailService, cfg *config.Config, smoother *deliverySmoother, attachments *attachmentLoader) *batchDeliverer {
+	batchSize := defaultBatchSize
+	if cfg != nil && cfg.Scheduling.BatchSize > 0 {
+		batchSize = cfg.Scheduling.BatchSize
+	}
+
+	return &batchDeliverer{
+		db:          db,
+		email:       email,
+		batchSize:   batchSize,
+		receipts:    newReceiptIssuer(db, cfg),
+		attachments: attachments,
+		smoother:    smoother,
+	}
+}
+
+// batchProcessingEnabled reports whether due messages should be delivered in batches
+func batchProcessingEnabled(cfg *config.Config) bool {
+	return cfg != nil && cfg.Features.EnableBatchProcessing
+}
+
+// deliver sends the messages and persists their new state, one batch at a time
+func (d *batchDeliverer) deliver(ctx context.Context, messages []message.Message) batchStats {
+	var stats batchStats
+	for _, batch := range message.BatchMessages(messages, d.batchSize) {
+		if ctx.Err() != nil {
+			break
+		}
+		batchResult := d.deliverBatch(ctx, batch)
+		stats.Delivered += batchResult.Delivered
+		stats.Failed += batchResult.Failed
+		stats.Deferred += batchResult.Deferred
+	}
+	return stats
+}
+
+func (d *batchDeliverer) deliverBatch(ctx context.Context, batch []message.Message) batchStats {
+	var stats batchStats
+	updates := make([]messageUpdate, 0, len(batch))
+
+	fail := func(msg message.Message, err error) {
+		slog.Error("scheduler: delivery failed", "message_id", msg.ID(), "error", err)
+		stats.Failed++
+
+		failed := statusTransition(message.StatusFailed)
+		next, err := failed(msg)
+		if err != nil {
+			slog.Error("scheduler: failed to update message status", "message_id", msg.ID(), "error", err)
+			return
+		}
+		updates = append(updates, messageUpdate{next: next, apply: failed})
+	}
+
+	// Build delivery info, loading each recipient once per batch
+	profiles := make(map[uuid.UUID]common.Result[user.UserProfile])
+	now := time.Now()
+	pending := make([]message.Message, 0, len(batch))
+	infos := make([]message.MessageDeliveryInfo, 0, len(batch))
+	receipts := make([]common.Option[message.DeliveryReceipt], 0, len(batch))
+	for _, msg := range batch {
+		profile, ok := profiles[msg.UserID()]
+		if !ok {
+			profile = loadRecipientProfile(ctx, d.db, msg.UserID())
+			profiles[msg.UserID()] = profile
+		}
+		if profile.IsErr() {
+			// A missing user is usually transient; leave the message scheduled
+			slog.Error("scheduler: failed to load user", "message_id", msg.ID(), "error", profile.Error())
+			continue
+		}
+
+		if deferred := message.DeferDelivery(msg, profile.Value(), now); deferred.IsSome() {
+			stats.Deferred++
+			updates = append(updates, messageUpdate{next: d.smoother.smooth(ctx, deferred.Value())})
+			continue
+		}
+
+		infoResult := message.ProcessMessageDelivery(msg, profile.Value())
+		if infoResult.IsErr() {
+			fail(msg, infoResult.Error())
+			continue
+		}
+		info, receipt := d.receipts.issue(d.attachments.attach(ctx, attachThread(ctx, d.db, infoResult.Value())))
+		pending = append(pending, msg)
+		infos = append(infos, info)
+		receipts = append(receipts, receipt)
+	}
+
+	for i, err := range d.send(ctx, infos) {
+		msg := pending[i]
+		if err != nil {
+			fail(msg, err)
+			continue
+		}
+		d.receipts.record(ctx, receipts[i])
+
+		complete := d.complete(ctx)
+		next, err := complete(msg)
+		if err != nil {
+			slog.Error("scheduler: failed to prepare message update", "message_id", msg.ID(), "error", err)
+			continue
+		}
+		stats.Delivered++
+		updates = append(updates, messageUpdate{next: next, apply: complete})
+	}
+
+	d.persist(ctx, updates)
+	return stats
+}
+
+// send delivers the emails and returns one error (or nil) per delivery info
+func (d *batchDeliverer) send(ctx context.Context, infos []message.MessageDeliveryInfo) []error {
+	errs := make([]error, len(infos))
+	if len(infos) == 0 {
+		return errs
+	}
+
+	if batchEmail, ok := d.email.(effects.EmailBatch); ok {
+		batchResult := batchEmail.SendMessagesBatch(ctx, infos)
+		if batchResult.IsErr() {
+			for i := range errs {
+				errs[i] = batchResult.Error()
+			}
+			return errs
+		}
+
+		for i, item := range batchResult.Value() {
+			if i < len(errs) {
+				errs[i] = batchItemError(item)
+			}
+		}
+		return errs
+	}
+
+	for i, info := range infos {
+		emailResult := d.email.SendMessage(ctx, info)
+		switch {
+		case emailResult.IsErr():
+			errs[i] = emailResult.Error()
+		case emailResult.Value().Status != effects.EmailStatusSent:
+			errs[i] = errors.New("email not sent")
+		}
+	}
+	return errs
+}
+
+// complete moves a delivered message to its completed state, spreading the
+// next occurrence of recurring messages
+func (d *batchDeliverer) complete(ctx context.Context) Transition {
+	return func(msg message.Message) (message.Message, error) {
+		next, err := completedState(msg)
+		if err != nil {
+			return msg, err
+		}
+		if next.HasRecurrence() {
+			next = d.smoother.smooth(ctx, next)
+		}
+		return next, nil
+	}
+}
+
+// persist saves the new message states. Updates the batch could not store,
+// typically because the author edited the message meanwhile, are saved one
+// at a time so their transition is applied to the current version. When the
+// batch itself fails every update is saved one at a time: the emails were
+// sent, so leaving the messages due would deliver them again.
+func (d *batchDeliverer) persist(ctx context.Context, updates []messageUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+
+	if batchDB, ok := d.db.(effects.DatabaseBatch); ok {
+		messages := make([]message.Message, len(updates))
+		for i, update := range updates {
+			messages[i] = update.next
+		}
+
+		batchResult := batchDB.UpdateMessagesBatch(ctx, messages)
+		if batchResult.IsErr() {
+			slog.Warn("scheduler: failed to persist message batch, saving messages one at a time", "count", len(updates), "error", batchResult.Error())
+		} else {
+			var retries []messageUpdate
+			for i, item := range batchResult.Value() {
+				if item.Success || i >= len(updates) {
+					continue
+				}
+				if updates[i].apply == nil {
+					slog.Error("scheduler: failed to persist message updates", "message_id", item.ID, "error", item.Error.ValueOr("unknown error"))
+					continue
+				}
+				retries = append(retries, updates[i])
+			}
+			updates = retries
+		}
+	}
+
+	for _, update := range updates {
+		if saveResult := saveTransition(ctx, d.db, update.next, update.apply); saveResult.IsErr() {
+			slog.Error("scheduler: failed to persist message updates", "message_id", update.next.ID(), "error", saveResult.Error())
+		}
+	}
+}
+
+func batchItemError(item effects.BatchResult[effects.EmailResult]) error {
+	if !item.Success {
+		return errors.New(item.Error.ValueOr("email not sent"))
+	}
+	if item.Result.IsSome() && item.Result.Value().Status != effects.EmailStatusSent {
+		return errors.New("email not sent")
+	}
+	return nil
+}
+
+// completedState returns the message as it should be stored after a
+// successful delivery: the next occurrence for recurring messages, otherwise
+// marked as delivered
+func completedState(msg message.Message) (message.Message, error) {
+	if !msg.HasRecurrence() {
+		statusResult := msg.WithStatus(message.StatusDelivered)
+		if statusResult.IsErr() {
+			return msg, statusResult.Error()
+		}
+		return statusResult.Value(), nil
+	}
+
+	updatedResult := msg.WithNextRecurrence()
+	if updatedResult.IsErr() {
+		return msg, updatedResult.Error()
+	}
+
+	statusResult := updatedResult.Value().WithStatus(message.StatusScheduled)
+	if statusResult.IsErr() {
+		return msg, statusResult.Error()
+	}
+	return statusResult.Value(), nil
+}
@@ -0,0 +1,188 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/auth"
+	"github.com/thanhphuchuynh/dear-future/pkg/config"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// armedMessagesPerRun bounds how many armed messages one evaluation handles
+const armedMessagesPerRun = 500
+
+// checkInEvaluator warns authors who missed a check-in and releases armed
+// messages whose grace period has passed
+type checkInEvaluator struct {
+	db          effects.Database
+	email       effects.EmailService
+	tokens      *auth.ActionTokenService
+	receipts    *receiptIssuer
+	attachments *attachmentLoader
+}
+
+// checkInStats summarizes an evaluation run
+type checkInStats struct {
+	Warned   int
+	Released int
+	Failed   int
+}
+
+// newCheckInEvaluator creates an evaluator that signs its links with the JWT secret
+func newCheckInEvaluator(db effects.Database, email effects.EmailService, cfg *config.Config, attachments *attachmentLoader) *checkInEvaluator {
+	if cfg == nil || email == nil {
+		return nil
+	}
+
+	return &checkInEvaluator{
+		db:          db,
+		email:       email,
+		tokens:      auth.NewActionTokenService(cfg.JWTSecret),
+		receipts:    newReceiptIssuer(db, cfg),
+		attachments: attachments,
+	}
+}
+
+// evaluate processes every armed message as of now
+func (e *checkInEvaluator) evaluate(ctx context.Context, now time.Time) (checkInStats, error) {
+	var stats checkInStats
+
+	armedResult := e.db.FindMessagesByStatus(ctx, message.StatusArmed, armedMessagesPerRun)
+	if armedResult.IsErr() {
+		return stats, armedResult.Error()
+	}
+
+	for _, msg := range armedResult.Value() {
+		if ctx.Err() != nil {
+			break
+		}
+
+		var err error
+		switch msg.EvaluateCheckIn(now) {
+		case message.CheckInWarn:
+			if err = e.warn(ctx, msg, now); err == nil {
+				stats.Warned++
+			}
+		case message.CheckInRelease:
+			if err = e.release(ctx, msg); err == nil {
+				stats.Released++
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			slog.Error("scheduler: check-in evaluation failed", "message_id", msg.ID(), "error", err)
+			stats.Failed++
+		}
+	}
+
+	return stats, nil
+}
+
+// warn emails the author check-in and cancel links. The message is only
+// marked as warned once the email is sent, so a failed warning is retried and
+// the grace period never starts without the author knowing. Like account
+// emails, the warning ignores notification preferences: an author who turned
+// reminders off still has to be told before their letter is released.
+func (e *checkInEvaluator) warn(ctx context.Context, msg message.Message, now time.Time) error {
+	notifier, ok := e.email.(effects.CheckInNotifier)
+	if !ok {
+		return errors.New("email service cannot send check-in warnings")
+	}
+
+	profileResult := loadRecipientProfile(ctx, e.db, msg.UserID())
+	if profileResult.IsErr() {
+		return fmt.Errorf("failed to load user: %w", profileResult.Error())
+	}
+	profile := profileResult.Value()
+
+	warnedResult := msg.WithCheckInWarned(now)
+	if warnedResult.IsErr() {
+		return warnedResult.Error()
+	}
+	warned := warnedResult.Value()
+
+	releaseAt := warned.DeliveryDate()
+	emailResult := notifier.SendCheckInWarning(ctx, effects.CheckInWarning{
+		RecipientEmail: profile.GetEffectiveEmail(),
+		MessageID:      msg.ID(),
+		MessageTitle:   msg.Title(),
+		ReleaseAt:      releaseAt,
+		CheckInToken:   e.tokens.Sign(auth.PurposeCheckIn, msg.UserID(), releaseAt),
+		CancelToken:    e.tokens.Sign(auth.PurposeCancelRelease, msg.ID(), releaseAt),
+		Locale:         profile.User().Locale(),
+		Location:       profile.User().Location(),
+	})
+	if emailResult.IsErr() {
+		return emailResult.Error()
+	}
+	if emailResult.Value().Status != effects.EmailStatusSent {
+		return errors.New(emailResult.Value().Error.ValueOr("warning email not sent"))
+	}
+
+	if saveResult := e.db.UpdateMessage(ctx, warned); saveResult.IsErr() {
+		return fmt.Errorf("failed to persist check-in warning: %w", saveResult.Error())
+	}
+	return nil
+}
+
+// release delivers the message to its check-in recipients, or to its author
+// when none were given. The message counts as delivered if any recipient
+// received it.
+func (e *checkInEvaluator) release(ctx context.Context, msg message.Message) error {
+	userResult := e.db.FindUserByID(ctx, msg.UserID())
+	if userResult.IsErr() {
+		return fmt.Errorf("failed to load user: %w", userResult.Error())
+	}
+
+	infoResult := message.BuildDeliveryInfo(msg, user.NewUserProfile(userResult.Value()))
+	if infoResult.IsErr() {
+		return e.persistStatus(ctx, msg, message.StatusFailed, infoResult.Error())
+	}
+
+	// Files are loaded once and sent to every recipient
+	delivery := e.attachments.attach(ctx, infoResult.Value())
+
+	recipients := msg.CheckIn().Value().Recipients()
+	if len(recipients) == 0 {
+		recipients = []string{delivery.RecipientEmail}
+	}
+
+	sent := 0
+	for _, recipient := range recipients {
+		info := delivery
+		info.RecipientEmail = recipient
+		info, receipt := e.receipts.issue(info)
+
+		emailResult := e.email.SendMessage(ctx, info)
+		switch {
+		case emailResult.IsErr():
+			slog.Error("scheduler: failed to release message", "message_id", msg.ID(), "recipient", recipient, "error", emailResult.Error())
+		case emailResult.Value().Status != effects.EmailStatusSent:
+			slog.Error("scheduler: failed to release message", "message_id", msg.ID(), "recipient", recipient, "error", emailResult.Value().Error.ValueOr("email not sent"))
+		default:
+			e.receipts.record(ctx, receipt)
+			sent++
+		}
+	}
+
+	if sent == 0 {
+		return e.persistStatus(ctx, msg, message.StatusFailed, errors.New("no recipient could be reached"))
+	}
+	return e.persistStatus(ctx, msg, message.StatusDelivered, nil)
+}
+
+// persistStatus stores the message with status and returns cause, if any
+func (e *checkInEvaluator) persistStatus(ctx context.Context, msg message.Message, status message.MessageStatus, cause error) error {
+	if saveResult := ApplyTransition(ctx, e.db, msg, statusTransition(status)); saveResult.IsErr() {
+		return fmt.Errorf("failed to persist message status: %w", saveResult.Error())
+	}
+	return cause
+}
@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/config"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// digestInterval is how often users are checked for a due weekly digest.
+// Digests are due for a whole day in each user's timezone, so an hourly run
+// reaches everyone within an hour of the digest time.
+const digestInterval = time.Hour
+
+// digestRecipientsPerPage bounds how many users are loaded at once
+const digestRecipientsPerPage = 200
+
+// digestMessagesPerUser bounds how many letters of a user are summarized
+const digestMessagesPerUser = 500
+
+// errEmptyDigest is returned by deliver when the user has nothing to read about
+var errEmptyDigest = errors.New("weekly digest is empty")
+
+// digestSender emails the weekly digest to users who opted in. Each digest
+// is claimed per user and ISO week before it is sent, so runs on several
+// scheduler processes or retries never send a user two digests in a week.
+type digestSender struct {
+	store       effects.WeeklyDigestStore
+	receipts    effects.DeliveryReceiptStore // Nil when deliveries are not recorded
+	notifier    effects.DigestNotifier
+	unsubscribe *unsubscribeSigner
+}
+
+// digestStats summarizes a digest run
+type digestStats struct {
+	Sent    int
+	Skipped int
+	Failed  int
+}
+
+// newDigestSender returns nil when the database cannot track digests or the
+// email service cannot send them
+func newDigestSender(db effects.Database, email effects.EmailService, cfg *config.Config) *digestSender {
+	store, ok := db.(effects.WeeklyDigestStore)
+	if !ok || cfg == nil {
+		return nil
+	}
+	notifier, ok := email.(effects.DigestNotifier)
+	if !ok {
+		return nil
+	}
+
+	receipts, _ := db.(effects.DeliveryReceiptStore)
+	return &digestSender{
+		store:       store,
+		receipts:    receipts,
+		notifier:    notifier,
+		unsubscribe: newUnsubscribeSigner(cfg),
+	}
+}
+
+// send emails every subscribed user whose digest is due at now
+func (d *digestSender) send(ctx context.Context, now time.Time) (digestStats, error) {
+	var stats digestStats
+
+	after := uuid.Nil
+	for ctx.Err() == nil {
+		recipientsResult := d.store.FindWeeklyDigestRecipients(ctx, after, digestRecipientsPerPage)
+		if recipientsResult.IsErr() {
+			return stats, recipientsResult.Error()
+		}
+
+		recipients := recipientsResult.Value()
+		for _, profile := range recipients {
+			if ctx.Err() != nil {
+				break
+			}
+
+			sent, err := d.sendTo(ctx, profile, now)
+			switch {
+			case err != nil:
+				slog.Error("scheduler: failed to send weekly digest", "user_id", profile.User().ID(), "error", err)
+				stats.Failed++
+			case sent:
+				stats.Sent++
+			default:
+				stats.Skipped++
+			}
+		}
+
+		if len(recipients) < digestRecipientsPerPage {
+			break
+		}
+		after = recipients[len(recipients)-1].User().ID()
+	}
+
+	return stats, nil
+}
+
+// sendTo emails the user their digest if it is due and was not sent this
+// week. A digest that fails to send is released to be retried on the next
+// run; one with nothing to report stays claimed.
+func (d *digestSender) sendTo(ctx context.Context, profile user.UserProfile, now time.Time) (bool, error) {
+	week := profile.WeeklyDigestDue(now)
+	if week.IsNone() || !profile.AllowsEmail(user.NotifyWeeklyDigest) {
+		return false, nil
+	}
+
+	userID := profile.User().ID()
+	claimed := d.store.ClaimWeeklyDigest(ctx, userID, week.Value(), now)
+	if claimed.IsErr() {
+		return false, claimed.Error()
+	}
+	if !claimed.Value() {
+		return false, nil
+	}
+
+	err := d.deliver(ctx, profile, week.Value(), now)
+	if errors.Is(err, errEmptyDigest) {
+		return false, nil
+	}
+	if err != nil {
+		if released := d.store.ReleaseWeeklyDigest(ctx, userID, week.Value()); released.IsErr() {
+			slog.Error("scheduler: failed to release weekly digest", "user_id", userID, "week", week.Value(), "error", released.Error())
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// deliver builds the user's digest and emails it
+func (d *digestSender) deliver(ctx context.Context, profile user.UserProfile, week string, now time.Time) error {
+	userID := profile.User().ID()
+	messagesResult := d.store.FindWeeklyDigestMessages(ctx, userID, now, message.DigestPeriod, digestMessagesPerUser)
+	if messagesResult.IsErr() {
+		return fmt.Errorf("failed to load messages: %w", messagesResult.Error())
+	}
+	messages := messagesResult.Value()
+
+	digest := message.BuildWeeklyDigest(messages, d.recurringReceipts(ctx, messages), now)
+	if digest.IsEmpty() {
+		return errEmptyDigest
+	}
+
+	emailResult := d.notifier.SendWeeklyDigest(ctx, effects.WeeklyDigestEmail{
+		RecipientEmail:   profile.GetEffectiveEmail(),
+		RecipientName:    profile.User().GetDisplayName(),
+		Week:             week,
+		Locale:           profile.User().Locale(),
+		Location:         profile.User().Location(),
+		Digest:           digest,
+		UnsubscribeToken: d.unsubscribe.token(userID, user.NotifyWeeklyDigest),
+	})
+	if emailResult.IsErr() {
+		return emailResult.Error()
+	}
+	if emailResult.Value().Status != effects.EmailStatusSent {
+		return errors.New(emailResult.Value().Error.ValueOr("digest email not sent"))
+	}
+	return nil
+}
+
+// recurringReceipts loads the delivery receipts of the recurring letters,
+// which tell the digest when they were last sent
+func (d *digestSender) recurringReceipts(ctx context.Context, messages []message.Message) []message.DeliveryReceipt {
+	if d.receipts == nil {
+		return nil
+	}
+
+	var receipts []message.DeliveryReceipt
+	for _, msg := range messages {
+		if !msg.HasRecurrence() {
+			continue
+		}
+		found := d.receipts.FindDeliveryReceiptsByMessageID(ctx, msg.ID())
+		if found.IsErr() {
+			slog.Warn("scheduler: failed to load delivery receipts for digest", "message_id", msg.ID(), "error", found.Error())
+			continue
+		}
+		receipts = append(receipts, found.Value()...)
+	}
+	return receipts
+}
@@ -0,0 +1,24 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// deleteExpiredIdempotencyKeys removes stored responses of idempotent
+// requests once their keys expired. Databases without an idempotency store
+// have nothing to clean up.
+func deleteExpiredIdempotencyKeys(ctx context.Context, db effects.Database, now time.Time) (int, error) {
+	store, ok := db.(effects.IdempotencyStore)
+	if !ok {
+		return 0, nil
+	}
+
+	deleted := store.DeleteExpiredIdempotencyKeys(ctx, now)
+	if deleted.IsErr() {
+		return 0, deleted.Error()
+	}
+	return deleted.Value(), nil
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// loadRecipientProfile loads the profile of a message's recipient. Databases
+// without profile storage fall back to the user with default settings.
+func loadRecipientProfile(ctx context.Context, db effects.Database, userID uuid.UUID) common.Result[user.UserProfile] {
+	profileResult := db.FindUserProfile(ctx, userID)
+	if profileResult.IsOk() {
+		return profileResult
+	}
+
+	userResult := db.FindUserByID(ctx, userID)
+	if userResult.IsErr() {
+		return common.Err[user.UserProfile](userResult.Error())
+	}
+	return common.Ok(user.NewUserProfile(userResult.Value()))
+}
+
+// applyDeliveryPreferences moves a message being scheduled out of its
+// recipient's quiet hours. The message is left unchanged when the profile
+// cannot be loaded; the delivery-time check still applies then.
+func applyDeliveryPreferences(ctx context.Context, db effects.Database, msg message.Message) message.Message {
+	profileResult := loadRecipientProfile(ctx, db, msg.UserID())
+	if profileResult.IsErr() {
+		slog.Warn("scheduler: failed to load delivery preferences", "message_id", msg.ID(), "error", profileResult.Error())
+		return msg
+	}
+
+	shifted := message.ApplyDeliveryPreferences(msg, profileResult.Value())
+	if shifted.IsErr() {
+		slog.Warn("scheduler: failed to apply delivery preferences", "message_id", msg.ID(), "error", shifted.Error())
+		return msg
+	}
+	return shifted.Value()
+}
@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/auth"
+	"github.com/thanhphuchuynh/dear-future/pkg/config"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// receiptIssuer creates a delivery receipt for every email sent and embeds
+// its token in the email. A nil issuer sends emails without receipts.
+type receiptIssuer struct {
+	store  effects.DeliveryReceiptStore
+	tokens *auth.ActionTokenService
+}
+
+// newReceiptIssuer returns nil when the database cannot store receipts
+func newReceiptIssuer(db effects.Database, cfg *config.Config) *receiptIssuer {
+	store, ok := db.(effects.DeliveryReceiptStore)
+	if !ok || cfg == nil {
+		return nil
+	}
+
+	return &receiptIssuer{
+		store:  store,
+		tokens: auth.NewActionTokenService(cfg.JWTSecret),
+	}
+}
+
+// issue prepares the receipt of a delivery and adds its token to the
+// delivery info. The receipt is only stored once the email was sent.
+func (ri *receiptIssuer) issue(info message.MessageDeliveryInfo) (message.MessageDeliveryInfo, common.Option[message.DeliveryReceipt]) {
+	if ri == nil {
+		return info, common.None[message.DeliveryReceipt]()
+	}
+
+	receipt := message.NewDeliveryReceipt(info.Message.ID(), info.RecipientEmail, time.Now())
+	if receipt.IsErr() {
+		slog.Warn("scheduler: failed to create delivery receipt", "message_id", info.Message.ID(), "error", receipt.Error())
+		return info, common.None[message.DeliveryReceipt]()
+	}
+
+	info.ReceiptToken = ri.tokens.Sign(auth.PurposeReceipt, receipt.Value().ID(), receipt.Value().TrackingExpiresAt())
+	return info, common.Some(receipt.Value())
+}
+
+// record stores the receipt of a sent email. A receipt that cannot be
+// stored only disables tracking; the delivery itself succeeded.
+func (ri *receiptIssuer) record(ctx context.Context, receipt common.Option[message.DeliveryReceipt]) {
+	if ri == nil || receipt.IsNone() {
+		return
+	}
+
+	if saved := ri.store.SaveDeliveryReceipt(ctx, receipt.Value()); saved.IsErr() {
+		slog.Warn("scheduler: failed to save delivery receipt", "message_id", receipt.Value().MessageID(), "error", saved.Error())
+	}
+}
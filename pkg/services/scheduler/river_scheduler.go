@@ -14,16 +14,17 @@ import (
 	"github.com/thanhphuchuynh/dear-future/pkg/config"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
-	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
 	"github.com/thanhphuchuynh/dear-future/pkg/effects"
 )
 
 // RiverScheduler provides a River-based message scheduling engine
 type RiverScheduler struct {
-	client *river.Client[pgx.Tx]
-	db     effects.Database
-	email  effects.EmailService
-	cfg    *config.Config
+	client   *river.Client[pgx.Tx]
+	db       effects.Database
+	email    effects.EmailService
+	cfg      *config.Config
+	batch    bool              // Deliver through the periodic batch job instead of one job per message
+	smoother *deliverySmoother // Set when delivery smoothing is configured
 }
 
 // DeliverMessageArgs are the arguments for the message delivery job
@@ -36,12 +37,143 @@ func (DeliverMessageArgs) Kind() string {
 	return "deliver_message"
 }
 
+// DeliverDueMessagesArgs are the arguments for the periodic batch delivery job
+type DeliverDueMessagesArgs struct{}
+
+// Kind returns the unique name for this job type
+func (DeliverDueMessagesArgs) Kind() string {
+	return "deliver_due_messages"
+}
+
+// EvaluateCheckInsArgs are the arguments for the periodic check-in evaluation job
+type EvaluateCheckInsArgs struct{}
+
+// Kind returns the unique name for this job type
+func (EvaluateCheckInsArgs) Kind() string {
+	return "evaluate_check_ins"
+}
+
+// EvaluateCheckInsWorker warns about missed check-ins and releases armed messages
+type EvaluateCheckInsWorker struct {
+	river.WorkerDefaults[EvaluateCheckInsArgs]
+	checkIns *checkInEvaluator
+}
+
+// Work evaluates all armed messages
+func (w *EvaluateCheckInsWorker) Work(ctx context.Context, job *river.Job[EvaluateCheckInsArgs]) error {
+	stats, err := w.checkIns.evaluate(ctx, time.Now())
+	if err != nil {
+		slog.Error("river: failed to load armed messages", "error", err)
+		return err
+	}
+
+	if stats.Warned+stats.Released+stats.Failed > 0 {
+		slog.Info("river: check-ins evaluated", "warned", stats.Warned, "released", stats.Released, "failed", stats.Failed)
+	}
+	return nil
+}
+
+// PurgeTrashArgs are the arguments for the periodic trash purge job
+type PurgeTrashArgs struct{}
+
+// Kind returns the unique name for this job type
+func (PurgeTrashArgs) Kind() string {
+	return "purge_trash"
+}
+
+// PurgeTrashWorker permanently deletes messages whose trash retention ended,
+// along with expired idempotency keys
+type PurgeTrashWorker struct {
+	river.WorkerDefaults[PurgeTrashArgs]
+	db    effects.Database
+	trash *trashPurger
+}
+
+// Work purges expired messages from the trash
+func (w *PurgeTrashWorker) Work(ctx context.Context, job *river.Job[PurgeTrashArgs]) error {
+	now := time.Now()
+	if expired, err := deleteExpiredIdempotencyKeys(ctx, w.db, now); err != nil {
+		slog.Error("river: failed to delete expired idempotency keys", "error", err)
+	} else if expired > 0 {
+		slog.Info("river: expired idempotency keys deleted", "deleted", expired)
+	}
+
+	purged, err := w.trash.purge(ctx, now)
+	if err != nil {
+		slog.Error("river: failed to load purgeable messages", "error", err)
+		return err
+	}
+
+	if purged > 0 {
+		slog.Info("river: trash purged", "purged", purged)
+	}
+	return nil
+}
+
+// SendWeeklyDigestsArgs are the arguments for the periodic weekly digest job
+type SendWeeklyDigestsArgs struct{}
+
+// Kind returns the unique name for this job type
+func (SendWeeklyDigestsArgs) Kind() string {
+	return "send_weekly_digests"
+}
+
+// SendWeeklyDigestsWorker emails the weekly digest to users whose digest is due
+type SendWeeklyDigestsWorker struct {
+	river.WorkerDefaults[SendWeeklyDigestsArgs]
+	digests *digestSender
+}
+
+// Work sends the digests due now
+func (w *SendWeeklyDigestsWorker) Work(ctx context.Context, job *river.Job[SendWeeklyDigestsArgs]) error {
+	stats, err := w.digests.send(ctx, time.Now())
+	if err != nil {
+		slog.Error("river: failed to load digest recipients", "error", err)
+		return err
+	}
+
+	if stats.Sent+stats.Failed > 0 {
+		slog.Info("river: weekly digests sent", "sent", stats.Sent, "failed", stats.Failed)
+	}
+	return nil
+}
+
+// dueMessagesPerRun bounds how many due messages one batch delivery job handles
+const dueMessagesPerRun = 500
+
+// DeliverDueMessagesWorker delivers all due messages in batches
+type DeliverDueMessagesWorker struct {
+	river.WorkerDefaults[DeliverDueMessagesArgs]
+	db      effects.Database
+	batches *batchDeliverer
+}
+
+// Work delivers the messages that are due now
+func (w *DeliverDueMessagesWorker) Work(ctx context.Context, job *river.Job[DeliverDueMessagesArgs]) error {
+	dueResult := w.db.FindDueMessages(ctx, time.Now(), dueMessagesPerRun)
+	if dueResult.IsErr() {
+		slog.Error("river: failed to load due messages", "error", dueResult.Error())
+		return dueResult.Error()
+	}
+
+	if len(dueResult.Value()) == 0 {
+		return nil
+	}
+
+	stats := w.batches.deliver(ctx, dueResult.Value())
+	slog.Info("river: batch delivery complete", "delivered", stats.Delivered, "failed", stats.Failed)
+	return nil
+}
+
 // DeliverMessageWorker processes message delivery jobs
 type DeliverMessageWorker struct {
 	river.WorkerDefaults[DeliverMessageArgs]
-	db     effects.Database
-	email  effects.EmailService
-	client *river.Client[pgx.Tx]
+	db          effects.Database
+	email       effects.EmailService
+	client      *river.Client[pgx.Tx]
+	receipts    *receiptIssuer
+	attachments *attachmentLoader
+	smoother    *deliverySmoother
 }
 
 // Work processes a single message delivery job
@@ -57,28 +189,47 @@ func (w *DeliverMessageWorker) Work(ctx context.Context, job *river.Job[DeliverM
 
 	msg := msgResult.Value()
 
+	// Jobs of deleted messages stay queued; the trash is never delivered
+	if msg.IsDeleted() {
+		slog.Info("river: message is in the trash, skipping", "message_id", job.Args.MessageID)
+		return nil
+	}
+
 	// Check if message is still scheduled
 	if msg.Status() != message.StatusScheduled {
 		slog.Info("river: message no longer scheduled, skipping", "message_id", job.Args.MessageID, "status", msg.Status())
 		return nil
 	}
 
+	// A recurring message may already have been delivered and moved to its
+	// next occurrence (for example by the batch delivery job)
+	if msg.DeliveryDate().After(time.Now().Add(time.Minute)) {
+		slog.Info("river: message not due yet, skipping", "message_id", job.Args.MessageID, "delivery_date", msg.DeliveryDate())
+		return nil
+	}
+
 	// Load user
-	userResult := w.db.FindUserByID(ctx, msg.UserID())
-	if userResult.IsErr() {
-		slog.Error("river: failed to load user", "message_id", job.Args.MessageID, "error", userResult.Error())
-		return userResult.Error()
+	profileResult := loadRecipientProfile(ctx, w.db, msg.UserID())
+	if profileResult.IsErr() {
+		slog.Error("river: failed to load user", "message_id", job.Args.MessageID, "error", profileResult.Error())
+		return profileResult.Error()
+	}
+
+	// Wait for the end of the recipient's quiet hours
+	profile := profileResult.Value()
+	if deferred := message.DeferDelivery(msg, profile, time.Now()); deferred.IsSome() {
+		return w.deferMessage(ctx, deferred.Value())
 	}
 
 	// Prepare delivery info
-	profile := user.NewUserProfile(userResult.Value())
 	deliveryInfoResult := message.ProcessMessageDelivery(msg, profile)
 	if deliveryInfoResult.IsErr() {
 		return w.failMessage(ctx, msg, deliveryInfoResult.Error())
 	}
 
-	// Send email
-	emailResult := w.email.SendMessage(ctx, deliveryInfoResult.Value())
+	// Send email, quoting the earlier letters of a reply and carrying its files
+	info, receipt := w.receipts.issue(w.attachments.attach(ctx, attachThread(ctx, w.db, deliveryInfoResult.Value())))
+	emailResult := w.email.SendMessage(ctx, info)
 	if emailResult.IsErr() {
 		return w.failMessage(ctx, msg, emailResult.Error())
 	}
@@ -88,21 +239,44 @@ func (w *DeliverMessageWorker) Work(ctx context.Context, job *river.Job[DeliverM
 	if result.Status != effects.EmailStatusSent {
 		return w.failMessage(ctx, msg, errors.New("email not sent"))
 	}
+	w.receipts.record(ctx, receipt)
 
 	// Handle completion
 	return w.completeMessage(ctx, msg)
 }
 
-func (w *DeliverMessageWorker) failMessage(ctx context.Context, msg message.Message, err error) error {
-	slog.Error("river: delivery failed", "message_id", msg.ID(), "error", err)
+// deferMessage stores a message moved out of its recipient's quiet hours and
+// schedules a job for the new delivery time
+func (w *DeliverMessageWorker) deferMessage(ctx context.Context, msg message.Message) error {
+	msg = w.smoother.smooth(ctx, msg)
+	saveResult := w.db.UpdateMessage(ctx, msg)
+	if saveResult.IsErr() {
+		slog.Error("river: failed to persist deferred message", "message_id", msg.ID(), "error", saveResult.Error())
+		return saveResult.Error()
+	}
 
-	statusResult := msg.WithStatus(message.StatusFailed)
-	if statusResult.IsErr() {
-		slog.Error("river: failed to update message status", "message_id", msg.ID(), "error", statusResult.Error())
-		return statusResult.Error()
+	// Not unique by args: this job is still running and would count as a
+	// duplicate. A stray extra job finds the message not yet due and skips it.
+	if w.client != nil {
+		_, err := w.client.Insert(ctx, DeliverMessageArgs{MessageID: msg.ID()}, &river.InsertOpts{
+			ScheduledAt: msg.DeliveryDate(),
+			Queue:       river.QueueDefault,
+			MaxAttempts: 5,
+		})
+		if err != nil {
+			slog.Error("river: failed to schedule deferred delivery", "message_id", msg.ID(), "error", err)
+			return err
+		}
 	}
 
-	saveResult := w.db.UpdateMessage(ctx, statusResult.Value())
+	slog.Info("river: delivery deferred for quiet hours", "message_id", msg.ID(), "delivery_date", msg.DeliveryDate())
+	return nil
+}
+
+func (w *DeliverMessageWorker) failMessage(ctx context.Context, msg message.Message, err error) error {
+	slog.Error("river: delivery failed", "message_id", msg.ID(), "error", err)
+
+	saveResult := ApplyTransition(ctx, w.db, msg, statusTransition(message.StatusFailed))
 	if saveResult.IsErr() {
 		slog.Error("river: failed to persist message status", "message_id", msg.ID(), "error", saveResult.Error())
 		return saveResult.Error()
@@ -114,17 +288,18 @@ func (w *DeliverMessageWorker) failMessage(ctx context.Context, msg message.Mess
 func (w *DeliverMessageWorker) completeMessage(ctx context.Context, msg message.Message) error {
 	if msg.HasRecurrence() {
 		// For recurring messages, keep status as scheduled and update delivery date
-		nextMessage, err := w.prepareNextOccurrence(msg)
-		if err != nil {
-			slog.Error("river: failed to prepare recurring message", "message_id", msg.ID(), "error", err)
-			return err
-		}
-
-		saveResult := w.db.UpdateMessage(ctx, nextMessage)
+		saveResult := ApplyTransition(ctx, w.db, msg, func(msg message.Message) (message.Message, error) {
+			nextMessage, err := w.prepareNextOccurrence(msg)
+			if err != nil {
+				return msg, err
+			}
+			return w.smoother.smooth(ctx, nextMessage), nil
+		})
 		if saveResult.IsErr() {
 			slog.Error("river: failed to persist message updates", "message_id", msg.ID(), "error", saveResult.Error())
 			return saveResult.Error()
 		}
+		nextMessage := saveResult.Value()
 
 		// Schedule the next occurrence job
 		if w.client != nil {
@@ -148,13 +323,7 @@ func (w *DeliverMessageWorker) completeMessage(ctx context.Context, msg message.
 			"next_delivery", nextMessage.DeliveryDate())
 	} else {
 		// For one-time messages, mark as delivered
-		statusResult := msg.WithStatus(message.StatusDelivered)
-		if statusResult.IsErr() {
-			slog.Error("river: failed to mark message as delivered", "message_id", msg.ID(), "error", statusResult.Error())
-			return statusResult.Error()
-		}
-
-		saveResult := w.db.UpdateMessage(ctx, statusResult.Value())
+		saveResult := ApplyTransition(ctx, w.db, msg, statusTransition(message.StatusDelivered))
 		if saveResult.IsErr() {
 			slog.Error("river: failed to persist message updates", "message_id", msg.ID(), "error", saveResult.Error())
 			return saveResult.Error()
@@ -167,13 +336,7 @@ func (w *DeliverMessageWorker) completeMessage(ctx context.Context, msg message.
 }
 
 func (w *DeliverMessageWorker) prepareNextOccurrence(msg message.Message) (message.Message, error) {
-	nextTimeResult := msg.NextRecurrenceTime()
-	if nextTimeResult.IsErr() {
-		return msg, nextTimeResult.Error()
-	}
-
-	nextDelivery := nextTimeResult.Value()
-	updatedResult := msg.WithDeliveryDate(nextDelivery, msg.Timezone())
+	updatedResult := msg.WithNextRecurrence()
 	if updatedResult.IsErr() {
 		return msg, updatedResult.Error()
 	}
@@ -187,8 +350,10 @@ func (w *DeliverMessageWorker) prepareNextOccurrence(msg message.Message) (messa
 	return statusResult.Value(), nil
 }
 
-// NewRiverScheduler creates a new River-based scheduler
-func NewRiverScheduler(pool *pgxpool.Pool, db effects.Database, email effects.EmailService, cfg *config.Config) (*RiverScheduler, error) {
+// NewRiverScheduler creates a new River-based scheduler. Storage is used to
+// send the files of delivered letters and to remove the files of purged
+// messages.
+func NewRiverScheduler(pool *pgxpool.Pool, db effects.Database, email effects.EmailService, storage effects.StorageService, cfg *config.Config) (*RiverScheduler, error) {
 	// Get configuration values
 	maxWorkers := 10
 	queueName := river.QueueDefault
@@ -211,6 +376,65 @@ func NewRiverScheduler(pool *pgxpool.Pool, db effects.Database, email effects.Em
 	// Register the message delivery worker
 	workers := river.NewWorkers()
 
+	// With batch processing, due messages are swept up by a periodic job
+	// instead of each message getting its own job
+	interval := time.Minute
+	if cfg != nil && cfg.SchedulerInterval > 0 {
+		interval = cfg.SchedulerInterval
+	}
+
+	batch := batchProcessingEnabled(cfg)
+	smoother := newDeliverySmoother(db, cfg)
+	attachments := newAttachmentLoader(db, storage)
+	var periodicJobs []*river.PeriodicJob
+	if batch {
+		river.AddWorker(workers, &DeliverDueMessagesWorker{
+			db:      db,
+			batches: newBatchDeliverer(db, email, cfg, smoother, attachments),
+		})
+		periodicJobs = append(periodicJobs, river.NewPeriodicJob(
+			river.PeriodicInterval(interval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return DeliverDueMessagesArgs{}, &river.InsertOpts{Queue: queueName, MaxAttempts: 1}
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		))
+	}
+
+	// Armed messages are checked periodically for missed check-ins
+	if checkIns := newCheckInEvaluator(db, email, cfg, attachments); checkIns != nil {
+		river.AddWorker(workers, &EvaluateCheckInsWorker{checkIns: checkIns})
+		periodicJobs = append(periodicJobs, river.NewPeriodicJob(
+			river.PeriodicInterval(interval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return EvaluateCheckInsArgs{}, &river.InsertOpts{Queue: queueName, MaxAttempts: 1}
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		))
+	}
+
+	// Messages left in the trash past the retention period are purged
+	river.AddWorker(workers, &PurgeTrashWorker{db: db, trash: newTrashPurger(db, storage, cfg)})
+	periodicJobs = append(periodicJobs, river.NewPeriodicJob(
+		river.PeriodicInterval(trashPurgeInterval),
+		func() (river.JobArgs, *river.InsertOpts) {
+			return PurgeTrashArgs{}, &river.InsertOpts{Queue: queueName, MaxAttempts: 1}
+		},
+		&river.PeriodicJobOpts{RunOnStart: true},
+	))
+
+	// Users who opted in get a weekly digest in their own timezone
+	if digests := newDigestSender(db, email, cfg); digests != nil {
+		river.AddWorker(workers, &SendWeeklyDigestsWorker{digests: digests})
+		periodicJobs = append(periodicJobs, river.NewPeriodicJob(
+			river.PeriodicInterval(digestInterval),
+			func() (river.JobArgs, *river.InsertOpts) {
+				return SendWeeklyDigestsArgs{}, &river.InsertOpts{Queue: queueName, MaxAttempts: 1}
+			},
+			&river.PeriodicJobOpts{RunOnStart: true},
+		))
+	}
+
 	// Create River client with workers
 	riverClient, err := river.NewClient(riverpgxv5.New(pool), &river.Config{
 		Queues: map[string]river.QueueConfig{
@@ -219,7 +443,8 @@ func NewRiverScheduler(pool *pgxpool.Pool, db effects.Database, email effects.Em
 				FetchPollInterval: fetchPollInterval,
 			},
 		},
-		Workers: workers,
+		Workers:      workers,
+		PeriodicJobs: periodicJobs,
 	})
 	if err != nil {
 		return nil, err
@@ -227,26 +452,39 @@ func NewRiverScheduler(pool *pgxpool.Pool, db effects.Database, email effects.Em
 
 	// Add worker with client reference after client is created
 	river.AddWorker(workers, &DeliverMessageWorker{
-		db:     db,
-		email:  email,
-		client: riverClient,
+		db:          db,
+		email:       email,
+		client:      riverClient,
+		receipts:    newReceiptIssuer(db, cfg),
+		attachments: attachments,
+		smoother:    smoother,
 	})
 
 	slog.Info("river: scheduler configured",
 		"max_workers", maxWorkers,
 		"queue", queueName,
-		"fetch_poll_interval", fetchPollInterval)
+		"fetch_poll_interval", fetchPollInterval,
+		"batch_processing", batch,
+		"smoothing", smoother != nil)
 
 	return &RiverScheduler{
-		client: riverClient,
-		db:     db,
-		email:  email,
-		cfg:    cfg,
+		client:   riverClient,
+		db:       db,
+		email:    email,
+		cfg:      cfg,
+		batch:    batch,
+		smoother: smoother,
 	}, nil
 }
 
 // ScheduleMessage schedules a message for delivery using River
 func (s *RiverScheduler) ScheduleMessage(ctx context.Context, messageID uuid.UUID, deliveryTime time.Time) common.Result[effects.ScheduleResult] {
+	return s.schedule(ctx, messageID, deliveryTime, true)
+}
+
+// schedule stores the delivery time and inserts a delivery job. A unique
+// job is skipped while one for the message is already queued.
+func (s *RiverScheduler) schedule(ctx context.Context, messageID uuid.UUID, deliveryTime time.Time, unique bool) common.Result[effects.ScheduleResult] {
 	msgResult := s.db.FindMessageByID(ctx, messageID)
 	if msgResult.IsErr() {
 		return common.Err[effects.ScheduleResult](msgResult.Error())
@@ -254,13 +492,18 @@ func (s *RiverScheduler) ScheduleMessage(ctx context.Context, messageID uuid.UUI
 
 	msg := msgResult.Value()
 
-	// Update message delivery time and status
-	updatedMsgResult := msg.WithDeliveryDate(deliveryTime, msg.Timezone())
-	if updatedMsgResult.IsErr() {
-		return common.Err[effects.ScheduleResult](updatedMsgResult.Error())
+	// Update message delivery time and status; surprise messages keep the
+	// time drawn when they were sealed, and a message scheduled at its own
+	// delivery date keeps its milestone anchor
+	updatedMsg := msg
+	if msg.Surprise().IsNone() && !deliveryTime.Equal(msg.DeliveryDate()) {
+		updatedMsgResult := msg.WithDeliveryDate(deliveryTime, msg.Timezone())
+		if updatedMsgResult.IsErr() {
+			return common.Err[effects.ScheduleResult](updatedMsgResult.Error())
+		}
+		updatedMsg = updatedMsgResult.Value()
 	}
 
-	updatedMsg := updatedMsgResult.Value()
 	if msg.Status() != message.StatusScheduled {
 		statusResult := updatedMsg.WithStatus(message.StatusScheduled)
 		if statusResult.IsErr() {
@@ -269,11 +512,27 @@ func (s *RiverScheduler) ScheduleMessage(ctx context.Context, messageID uuid.UUI
 		updatedMsg = statusResult.Value()
 	}
 
+	// Deliveries never happen in the recipient's quiet hours, and are spread
+	// when many are due at the same moment; the job is inserted for the
+	// smoothed time
+	updatedMsg = s.smoother.smooth(ctx, applyDeliveryPreferences(ctx, s.db, updatedMsg))
+	scheduledFor := updatedMsg.DeliveryDate()
+
 	saveResult := s.db.UpdateMessage(ctx, updatedMsg)
 	if saveResult.IsErr() {
 		return common.Err[effects.ScheduleResult](saveResult.Error())
 	}
 
+	// The periodic batch job delivers the message once it is due
+	if s.batch {
+		return common.Ok(effects.ScheduleResult{
+			MessageID:    messageID,
+			ScheduledFor: scheduledFor,
+			ScheduleID:   messageID.String(),
+			Status:       effects.ScheduleStatusActive,
+		})
+	}
+
 	// Get max attempts from config
 	maxAttempts := 5
 	queueName := river.QueueDefault
@@ -289,21 +548,24 @@ func (s *RiverScheduler) ScheduleMessage(ctx context.Context, messageID uuid.UUI
 
 	// Schedule the job with River using unique key to prevent duplicates
 	jobArgs := DeliverMessageArgs{MessageID: messageID}
-	_, err := s.client.Insert(ctx, jobArgs, &river.InsertOpts{
-		ScheduledAt: deliveryTime,
+	opts := &river.InsertOpts{
+		ScheduledAt: scheduledFor,
 		Queue:       queueName,
 		MaxAttempts: maxAttempts,
-		UniqueOpts: river.UniqueOpts{
+	}
+	if unique {
+		opts.UniqueOpts = river.UniqueOpts{
 			ByArgs: true, // Unique by message ID
-		},
-	})
+		}
+	}
+	_, err := s.client.Insert(ctx, jobArgs, opts)
 	if err != nil {
 		return common.Err[effects.ScheduleResult](err)
 	}
 
 	return common.Ok(effects.ScheduleResult{
 		MessageID:    messageID,
-		ScheduledFor: deliveryTime,
+		ScheduledFor: scheduledFor,
 		ScheduleID:   messageID.String(),
 		Status:       effects.ScheduleStatusActive,
 	})
@@ -332,9 +594,12 @@ func (s *RiverScheduler) CancelScheduledMessage(ctx context.Context, messageID u
 	return common.Ok(true)
 }
 
-// RescheduleMessage changes an existing schedule to a new delivery time
+// RescheduleMessage changes an existing schedule to a new delivery time.
+// The job is not unique by args: the queued job for the old time would count
+// as a duplicate. Whichever job runs before the new time finds the message
+// not yet due and skips it.
 func (s *RiverScheduler) RescheduleMessage(ctx context.Context, messageID uuid.UUID, newDeliveryTime time.Time) common.Result[effects.ScheduleResult] {
-	return s.ScheduleMessage(ctx, messageID, newDeliveryTime)
+	return s.schedule(ctx, messageID, newDeliveryTime, false)
 }
 
 // GetScheduledMessages returns scheduled messages within the provided window
@@ -362,6 +627,15 @@ func (s *RiverScheduler) GetScheduledMessages(ctx context.Context, from, to time
 	return common.Ok(scheduled)
 }
 
+// Metrics reports the delivery smoothing policy and what it did
+func (s *RiverScheduler) Metrics() effects.SchedulerMetrics {
+	return effects.SchedulerMetrics{
+		Scheduler:       "river",
+		BatchProcessing: s.batch,
+		Smoothing:       s.smoother.metrics(),
+	}
+}
+
 // Start begins the River worker
 func (s *RiverScheduler) Start(ctx context.Context) common.Result[bool] {
 	if s.client == nil {
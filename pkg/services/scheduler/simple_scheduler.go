@@ -11,34 +11,57 @@ import (
 	"github.com/thanhphuchuynh/dear-future/pkg/config"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
 	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
-	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
 	"github.com/thanhphuchuynh/dear-future/pkg/effects"
 )
 
 // SimpleScheduler provides a basic in-process message scheduling engine.
 type SimpleScheduler struct {
-	db       effects.Database
-	email    effects.EmailService
-	cfg      *config.Config
-	interval time.Duration
-
-	stop chan struct{}
-	wg   sync.WaitGroup
+	db          effects.Database
+	email       effects.EmailService
+	cfg         *config.Config
+	interval    time.Duration
+	batch       *batchDeliverer // Set when batch processing is enabled
+	checkIns    *checkInEvaluator
+	receipts    *receiptIssuer
+	attachments *attachmentLoader
+	trash       *trashPurger
+	digests     *digestSender     // Set when weekly digests can be tracked and sent
+	smoother    *deliverySmoother // Set when delivery smoothing is configured
+
+	lastPurge  time.Time
+	lastDigest time.Time
+	stop       chan struct{}
+	wg         sync.WaitGroup
 }
 
-// NewSimpleScheduler creates a scheduler that polls the database for due messages.
-func NewSimpleScheduler(db effects.Database, email effects.EmailService, cfg *config.Config) *SimpleScheduler {
+// NewSimpleScheduler creates a scheduler that polls the database for due
+// messages. Storage is used to send the files of delivered letters and to
+// remove the files of purged messages.
+func NewSimpleScheduler(db effects.Database, email effects.EmailService, storage effects.StorageService, cfg *config.Config) *SimpleScheduler {
 	interval := time.Minute
 	if cfg != nil && cfg.SchedulerInterval > 0 {
 		interval = cfg.SchedulerInterval
 	}
 
-	return &SimpleScheduler{
-		db:       db,
-		email:    email,
-		cfg:      cfg,
-		interval: interval,
+	attachments := newAttachmentLoader(db, storage)
+
+	scheduler := &SimpleScheduler{
+		db:          db,
+		email:       email,
+		cfg:         cfg,
+		interval:    interval,
+		checkIns:    newCheckInEvaluator(db, email, cfg, attachments),
+		receipts:    newReceiptIssuer(db, cfg),
+		attachments: attachments,
+		trash:       newTrashPurger(db, storage, cfg),
+		digests:     newDigestSender(db, email, cfg),
+		smoother:    newDeliverySmoother(db, cfg),
+	}
+	if batchProcessingEnabled(cfg) {
+		scheduler.batch = newBatchDeliverer(db, email, cfg, scheduler.smoother, attachments)
 	}
+
+	return scheduler
 }
 
 // ScheduleMessage updates a message's delivery time and re-queues it.
@@ -50,12 +73,17 @@ func (s *SimpleScheduler) ScheduleMessage(ctx context.Context, messageID uuid.UU
 
 	msg := msgResult.Value()
 
-	updatedMsgResult := msg.WithDeliveryDate(deliveryTime, msg.Timezone())
-	if updatedMsgResult.IsErr() {
-		return common.Err[effects.ScheduleResult](updatedMsgResult.Error())
+	// Surprise messages keep the time drawn when they were sealed, and a
+	// message scheduled at its own delivery date keeps its milestone anchor
+	updatedMsg := msg
+	if msg.Surprise().IsNone() && !deliveryTime.Equal(msg.DeliveryDate()) {
+		updatedMsgResult := msg.WithDeliveryDate(deliveryTime, msg.Timezone())
+		if updatedMsgResult.IsErr() {
+			return common.Err[effects.ScheduleResult](updatedMsgResult.Error())
+		}
+		updatedMsg = updatedMsgResult.Value()
 	}
 
-	updatedMsg := updatedMsgResult.Value()
 	if msg.Status() != message.StatusScheduled {
 		statusResult := updatedMsg.WithStatus(message.StatusScheduled)
 		if statusResult.IsErr() {
@@ -64,6 +92,11 @@ func (s *SimpleScheduler) ScheduleMessage(ctx context.Context, messageID uuid.UU
 		updatedMsg = statusResult.Value()
 	}
 
+	// Deliveries never happen in the recipient's quiet hours, and are spread
+	// when many are due at the same moment
+	updatedMsg = s.smoother.smooth(ctx, applyDeliveryPreferences(ctx, s.db, updatedMsg))
+	scheduledFor := updatedMsg.DeliveryDate()
+
 	saveResult := s.db.UpdateMessage(ctx, updatedMsg)
 	if saveResult.IsErr() {
 		return common.Err[effects.ScheduleResult](saveResult.Error())
@@ -71,7 +104,7 @@ func (s *SimpleScheduler) ScheduleMessage(ctx context.Context, messageID uuid.UU
 
 	return common.Ok(effects.ScheduleResult{
 		MessageID:    messageID,
-		ScheduledFor: deliveryTime,
+		ScheduledFor: scheduledFor,
 		ScheduleID:   messageID.String(),
 		Status:       effects.ScheduleStatusActive,
 	})
@@ -127,6 +160,15 @@ func (s *SimpleScheduler) GetScheduledMessages(ctx context.Context, from, to tim
 	return common.Ok(scheduled)
 }
 
+// Metrics reports the delivery smoothing policy and what it did
+func (s *SimpleScheduler) Metrics() effects.SchedulerMetrics {
+	return effects.SchedulerMetrics{
+		Scheduler:       "simple",
+		BatchProcessing: s.batch != nil,
+		Smoothing:       s.smoother.metrics(),
+	}
+}
+
 // Start begins the background polling loop.
 func (s *SimpleScheduler) Start(ctx context.Context) common.Result[bool] {
 	if s == nil || s.db == nil {
@@ -184,37 +226,113 @@ func (s *SimpleScheduler) run(ctx context.Context) {
 }
 
 func (s *SimpleScheduler) executeCycle(ctx context.Context) {
+	s.evaluateCheckIns(ctx)
+	s.purgeTrash(ctx)
+	s.sendDigests(ctx)
+
 	dueResult := s.db.FindDueMessages(ctx, time.Now(), 100)
 	if dueResult.IsErr() {
 		slog.Error("scheduler: failed to load due messages", "error", dueResult.Error())
 		return
 	}
 
+	if s.batch != nil && s.email != nil {
+		stats := s.batch.deliver(ctx, dueResult.Value())
+		if stats.Delivered+stats.Failed+stats.Deferred > 0 {
+			slog.Info("scheduler: batch delivery complete", "delivered", stats.Delivered, "failed", stats.Failed, "deferred", stats.Deferred)
+		}
+		return
+	}
+
 	for _, msg := range dueResult.Value() {
 		s.processMessage(ctx, msg)
 	}
 }
 
+func (s *SimpleScheduler) evaluateCheckIns(ctx context.Context) {
+	if s.checkIns == nil {
+		return
+	}
+
+	stats, err := s.checkIns.evaluate(ctx, time.Now())
+	if err != nil {
+		slog.Error("scheduler: failed to load armed messages", "error", err)
+		return
+	}
+	if stats.Warned+stats.Released+stats.Failed > 0 {
+		slog.Info("scheduler: check-ins evaluated", "warned", stats.Warned, "released", stats.Released, "failed", stats.Failed)
+	}
+}
+
+// purgeTrash deletes expired messages from the trash and expired
+// idempotency keys at most once per trashPurgeInterval
+func (s *SimpleScheduler) purgeTrash(ctx context.Context) {
+	now := time.Now()
+	if now.Sub(s.lastPurge) < trashPurgeInterval {
+		return
+	}
+	s.lastPurge = now
+
+	if expired, err := deleteExpiredIdempotencyKeys(ctx, s.db, now); err != nil {
+		slog.Error("scheduler: failed to delete expired idempotency keys", "error", err)
+	} else if expired > 0 {
+		slog.Info("scheduler: expired idempotency keys deleted", "deleted", expired)
+	}
+
+	purged, err := s.trash.purge(ctx, now)
+	if err != nil {
+		slog.Error("scheduler: failed to load purgeable messages", "error", err)
+		return
+	}
+	if purged > 0 {
+		slog.Info("scheduler: trash purged", "purged", purged)
+	}
+}
+
+// sendDigests emails the weekly digests due at most once per digestInterval
+func (s *SimpleScheduler) sendDigests(ctx context.Context) {
+	now := time.Now()
+	if s.digests == nil || now.Sub(s.lastDigest) < digestInterval {
+		return
+	}
+	s.lastDigest = now
+
+	stats, err := s.digests.send(ctx, now)
+	if err != nil {
+		slog.Error("scheduler: failed to load digest recipients", "error", err)
+		return
+	}
+	if stats.Sent+stats.Failed > 0 {
+		slog.Info("scheduler: weekly digests sent", "sent", stats.Sent, "failed", stats.Failed)
+	}
+}
+
 func (s *SimpleScheduler) processMessage(ctx context.Context, msg message.Message) {
 	if s.email == nil {
 		slog.Warn("scheduler: email service not configured, skipping delivery", "message_id", msg.ID())
 		return
 	}
 
-	userResult := s.db.FindUserByID(ctx, msg.UserID())
-	if userResult.IsErr() {
-		slog.Error("scheduler: failed to load user", "message_id", msg.ID(), "error", userResult.Error())
+	profileResult := loadRecipientProfile(ctx, s.db, msg.UserID())
+	if profileResult.IsErr() {
+		slog.Error("scheduler: failed to load user", "message_id", msg.ID(), "error", profileResult.Error())
+		return
+	}
+
+	profile := profileResult.Value()
+	if deferred := message.DeferDelivery(msg, profile, time.Now()); deferred.IsSome() {
+		s.deferMessage(ctx, deferred.Value())
 		return
 	}
 
-	profile := user.NewUserProfile(userResult.Value())
 	deliveryInfoResult := message.ProcessMessageDelivery(msg, profile)
 	if deliveryInfoResult.IsErr() {
 		s.failMessage(ctx, msg, deliveryInfoResult.Error())
 		return
 	}
 
-	emailResult := s.email.SendMessage(ctx, deliveryInfoResult.Value())
+	info, receipt := s.receipts.issue(s.attachments.attach(ctx, attachThread(ctx, s.db, deliveryInfoResult.Value())))
+	emailResult := s.email.SendMessage(ctx, info)
 	if emailResult.IsErr() {
 		s.failMessage(ctx, msg, emailResult.Error())
 		return
@@ -226,57 +344,51 @@ func (s *SimpleScheduler) processMessage(ctx context.Context, msg message.Messag
 		return
 	}
 
+	s.receipts.record(ctx, receipt)
+
 	s.completeMessage(ctx, msg)
 }
 
-func (s *SimpleScheduler) failMessage(ctx context.Context, msg message.Message, err error) {
-	slog.Error("scheduler: delivery failed", "message_id", msg.ID(), "error", err)
-
-	statusResult := msg.WithStatus(message.StatusFailed)
-	if statusResult.IsErr() {
-		slog.Error("scheduler: failed to update message status", "message_id", msg.ID(), "error", statusResult.Error())
+// deferMessage stores a message moved out of its recipient's quiet hours
+func (s *SimpleScheduler) deferMessage(ctx context.Context, msg message.Message) {
+	msg = s.smoother.smooth(ctx, msg)
+	saveResult := s.db.UpdateMessage(ctx, msg)
+	if saveResult.IsErr() {
+		slog.Error("scheduler: failed to persist deferred message", "message_id", msg.ID(), "error", saveResult.Error())
 		return
 	}
+	slog.Info("scheduler: delivery deferred for quiet hours", "message_id", msg.ID(), "delivery_date", msg.DeliveryDate())
+}
 
-	saveResult := s.db.UpdateMessage(ctx, statusResult.Value())
+func (s *SimpleScheduler) failMessage(ctx context.Context, msg message.Message, err error) {
+	slog.Error("scheduler: delivery failed", "message_id", msg.ID(), "error", err)
+
+	saveResult := ApplyTransition(ctx, s.db, msg, statusTransition(message.StatusFailed))
 	if saveResult.IsErr() {
 		slog.Error("scheduler: failed to persist message status", "message_id", msg.ID(), "error", saveResult.Error())
 	}
 }
 
 func (s *SimpleScheduler) completeMessage(ctx context.Context, msg message.Message) {
-	var nextMessage message.Message
-	var err error
-
+	complete := statusTransition(message.StatusDelivered)
 	if msg.HasRecurrence() {
-		nextMessage, err = s.prepareNextOccurrence(msg)
-		if err != nil {
-			slog.Error("scheduler: failed to prepare recurring message", "message_id", msg.ID(), "error", err)
-			return
+		complete = func(msg message.Message) (message.Message, error) {
+			nextMessage, err := s.prepareNextOccurrence(msg)
+			if err != nil {
+				return msg, err
+			}
+			return s.smoother.smooth(ctx, nextMessage), nil
 		}
-	} else {
-		statusResult := msg.WithStatus(message.StatusDelivered)
-		if statusResult.IsErr() {
-			slog.Error("scheduler: failed to mark message as delivered", "message_id", msg.ID(), "error", statusResult.Error())
-			return
-		}
-		nextMessage = statusResult.Value()
 	}
 
-	saveResult := s.db.UpdateMessage(ctx, nextMessage)
+	saveResult := ApplyTransition(ctx, s.db, msg, complete)
 	if saveResult.IsErr() {
 		slog.Error("scheduler: failed to persist message updates", "message_id", msg.ID(), "error", saveResult.Error())
 	}
 }
 
 func (s *SimpleScheduler) prepareNextOccurrence(msg message.Message) (message.Message, error) {
-	nextTimeResult := msg.NextRecurrenceTime()
-	if nextTimeResult.IsErr() {
-		return msg, nextTimeResult.Error()
-	}
-
-	nextDelivery := nextTimeResult.Value()
-	updatedResult := msg.WithDeliveryDate(nextDelivery, msg.Timezone())
+	updatedResult := msg.WithNextRecurrence()
 	if updatedResult.IsErr() {
 		return msg, updatedResult.Error()
 	}
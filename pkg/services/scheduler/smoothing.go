@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/config"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// deliverySmoother spreads the deliveries a scheduler books so that
+// thousands of letters due at the same moment, such as midnight on New
+// Year's Day, do not all reach the mail server in the same second. The load
+// of each minute is counted from the letters scheduled in the database, so
+// the capacity is shared by every scheduler process and survives restarts.
+type deliverySmoother struct {
+	policy message.SmoothingPolicy
+	load   effects.DeliveryLoadStore
+
+	mu    sync.Mutex
+	stats effects.SmoothingMetrics
+}
+
+// newDeliverySmoother creates a smoother from scheduling.smoothing. It
+// returns nil when smoothing is not configured, the policy is invalid or the
+// database cannot count the deliveries due in each minute.
+func newDeliverySmoother(db effects.Database, cfg *config.Config) *deliverySmoother {
+	if cfg == nil || cfg.Scheduling.Smoothing.PerMinute <= 0 {
+		return nil
+	}
+
+	load, ok := db.(effects.DeliveryLoadStore)
+	if !ok {
+		slog.Warn("scheduler: database cannot count deliveries per minute, smoothing disabled")
+		return nil
+	}
+
+	delays := make(map[message.DeliveryClass]time.Duration, len(cfg.Scheduling.Smoothing.MaxDelay))
+	for class, raw := range cfg.Scheduling.Smoothing.MaxDelay {
+		delay, err := time.ParseDuration(raw)
+		if err != nil {
+			slog.Warn("scheduler: invalid smoothing delay, smoothing disabled", "class", class, "delay", raw, "error", err)
+			return nil
+		}
+		delays[message.DeliveryClass(class)] = delay
+	}
+
+	policy := message.NewSmoothingPolicy(cfg.Scheduling.Smoothing.PerMinute, delays)
+	if policy.IsErr() {
+		slog.Warn("scheduler: invalid smoothing policy, smoothing disabled", "error", policy.Error())
+		return nil
+	}
+
+	return &deliverySmoother{
+		policy: policy.Value(),
+		load:   load,
+	}
+}
+
+// smooth returns the message moved to the least busy minute it may be
+// delivered in. The message's own booking is left out of the count, so a
+// message smoothed again does not compete with itself. When the load cannot
+// be counted the message is returned unchanged.
+func (s *deliverySmoother) smooth(ctx context.Context, msg message.Message) message.Message {
+	if s == nil {
+		return msg
+	}
+
+	requested := msg.DeliveryDate()
+	first, last := s.policy.Window(requested, msg.DeliveryClass())
+	booked := s.load.CountDeliveriesPerMinute(ctx, first, last.Truncate(time.Minute).Add(time.Minute), msg.ID())
+	if booked.IsErr() {
+		slog.Warn("scheduler: failed to count deliveries, delivery not smoothed", "message_id", msg.ID(), "error", booked.Error())
+		return msg
+	}
+
+	slot, ok := s.policy.Slot(requested, msg.DeliveryClass(), booked.Value())
+	delay := slot.Sub(requested)
+
+	s.mu.Lock()
+	s.stats.Booked++
+	if delay > 0 {
+		s.stats.Delayed++
+		s.stats.TotalDelaySeconds += delay.Seconds()
+		if delay.Seconds() > s.stats.LongestDelaySeconds {
+			s.stats.LongestDelaySeconds = delay.Seconds()
+		}
+	}
+	if !ok {
+		s.stats.OverCapacity++
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		slog.Warn("scheduler: no delivery slot within the maximum delay", "message_id", msg.ID(), "class", msg.DeliveryClass(), "delivery_date", slot)
+	}
+
+	if delay == 0 {
+		return msg
+	}
+	return msg.WithShiftedDelivery(slot)
+}
+
+// metrics reports the policy and what smoothing did so far
+func (s *deliverySmoother) metrics() effects.SmoothingMetrics {
+	if s == nil {
+		return effects.SmoothingMetrics{Enabled: false}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics := s.stats
+	metrics.Enabled = true
+	metrics.PerMinute = s.policy.PerMinute()
+	metrics.MaxDelay = make(map[string]string, len(message.DeliveryClasses))
+	for _, class := range message.DeliveryClasses {
+		metrics.MaxDelay[string(class)] = s.policy.MaxDelay(class).String()
+	}
+	if metrics.Delayed > 0 {
+		metrics.AverageDelaySeconds = metrics.TotalDelaySeconds / float64(metrics.Delayed)
+	}
+	return metrics
+}
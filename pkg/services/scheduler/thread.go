@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// loadThread loads the earlier letters a reply answers, direct parent first.
+// The walk stops at MaxThreadDepth, at a letter of another user, at a letter
+// in the trash and at letters already seen, so a broken chain never blocks
+// the delivery and deleted letters are never quoted.
+func loadThread(ctx context.Context, db effects.Database, msg message.Message) []message.Message {
+	var ancestors []message.Message
+	seen := map[uuid.UUID]bool{msg.ID(): true}
+
+	parentID := msg.ParentID()
+	for parentID.IsSome() && len(ancestors) < message.MaxThreadDepth {
+		if seen[parentID.Value()] {
+			break
+		}
+		seen[parentID.Value()] = true
+
+		parentResult := db.FindMessageByID(ctx, parentID.Value())
+		if parentResult.IsErr() {
+			slog.Warn("scheduler: failed to load earlier letter", "message_id", msg.ID(), "parent_id", parentID.Value(), "error", parentResult.Error())
+			break
+		}
+
+		parent := parentResult.Value()
+		if parent.UserID() != msg.UserID() || parent.IsDeleted() {
+			break
+		}
+
+		ancestors = append(ancestors, parent)
+		parentID = parent.ParentID()
+	}
+
+	return ancestors
+}
+
+// attachThread adds the quoted history of a reply to its delivery info. The
+// letter is delivered without the history if it cannot be rendered.
+func attachThread(ctx context.Context, db effects.Database, info message.MessageDeliveryInfo) message.MessageDeliveryInfo {
+	if !info.Message.IsReply() {
+		return info
+	}
+
+	history := message.BuildThreadHistory(loadThread(ctx, db, info.Message))
+	if history.IsErr() {
+		slog.Warn("scheduler: failed to render thread history", "message_id", info.Message.ID(), "error", history.Error())
+		return info
+	}
+	return info.WithThread(history.Value())
+}
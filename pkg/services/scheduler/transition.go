@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// maxTransitionAttempts bounds how often a transition is applied again to a
+// message that keeps changing under the scheduler
+const maxTransitionAttempts = 3
+
+// Transition computes the state a message is moved to, such as delivered
+// after it was sent
+type Transition func(msg message.Message) (message.Message, error)
+
+// statusTransition moves a message to status
+func statusTransition(status message.MessageStatus) Transition {
+	return func(msg message.Message) (message.Message, error) {
+		statusResult := msg.WithStatus(status)
+		if statusResult.IsErr() {
+			return msg, statusResult.Error()
+		}
+		return statusResult.Value(), nil
+	}
+}
+
+// ApplyTransition moves msg to its next state and stores it, see
+// saveTransition. Handlers changing a message on behalf of the scheduler's
+// state machine, such as check-ins, use it too.
+func ApplyTransition(ctx context.Context, db effects.Database, msg message.Message, apply Transition) common.Result[message.Message] {
+	next, err := apply(msg)
+	if err != nil {
+		return common.Err[message.Message](err)
+	}
+	return saveTransition(ctx, db, next, apply)
+}
+
+// saveTransition stores next, the state apply moved a message to. Message
+// writes are version-checked, so when the author edited the message after
+// the scheduler loaded it, apply is run again on the current version rather
+// than overwriting the edit or giving up: a letter that was sent has to be
+// recorded as sent, or it would be delivered again. With a nil apply next is
+// stored once.
+func saveTransition(ctx context.Context, db effects.Database, next message.Message, apply Transition) common.Result[message.Message] {
+	for attempt := 1; ; attempt++ {
+		saveResult := db.UpdateMessage(ctx, next)
+		if saveResult.IsOk() || apply == nil || !errors.Is(saveResult.Error(), message.ErrVersionConflict) || attempt == maxTransitionAttempts {
+			return saveResult
+		}
+
+		current := db.FindMessageByID(ctx, next.ID())
+		if current.IsErr() {
+			return common.Err[message.Message](current.Error())
+		}
+
+		applied, err := apply(current.Value())
+		if err != nil {
+			return common.Err[message.Message](err)
+		}
+		next = applied
+	}
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/config"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// purgedMessagesPerRun bounds how many messages one purge handles
+const purgedMessagesPerRun = 200
+
+// trashPurgeInterval is how often the trash is checked for expired messages
+const trashPurgeInterval = time.Hour
+
+// trashPurger permanently deletes messages that stayed in the trash past the
+// retention period. Attachment objects are removed from storage first so
+// deleting the message rows never orphans them.
+type trashPurger struct {
+	db        effects.Database
+	storage   effects.StorageService
+	retention time.Duration
+}
+
+// newTrashPurger creates a purger using the configured trash retention
+func newTrashPurger(db effects.Database, storage effects.StorageService, cfg *config.Config) *trashPurger {
+	retention := message.DefaultTrashRetention
+	if cfg != nil && cfg.TrashRetention > 0 {
+		retention = cfg.TrashRetention
+	}
+
+	return &trashPurger{
+		db:        db,
+		storage:   storage,
+		retention: retention,
+	}
+}
+
+// purge deletes every message whose retention ended before now and returns
+// how many were purged
+func (p *trashPurger) purge(ctx context.Context, now time.Time) (int, error) {
+	expiredResult := p.db.FindPurgeableMessages(ctx, now.Add(-p.retention), purgedMessagesPerRun)
+	if expiredResult.IsErr() {
+		return 0, expiredResult.Error()
+	}
+
+	purged := 0
+	for _, msg := range expiredResult.Value() {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// A message whose files could not be removed stays in the trash and
+		// is retried on the next run
+		if err := p.purgeMessage(ctx, msg); err != nil {
+			slog.Error("scheduler: failed to purge message", "message_id", msg.ID(), "error", err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+func (p *trashPurger) purgeMessage(ctx context.Context, msg message.Message) error {
+	attachmentsResult := p.db.FindAttachmentsByMessageID(ctx, msg.ID())
+	if attachmentsResult.IsErr() {
+		return attachmentsResult.Error()
+	}
+
+	if p.storage != nil {
+		for _, attachment := range attachmentsResult.Value() {
+			// The original file and its thumbnails
+			for _, key := range attachment.StorageKeys() {
+				if deleted := p.storage.DeleteFile(ctx, key); deleted.IsErr() {
+					return fmt.Errorf("failed to delete attachment %s: %w", attachment.ID(), deleted.Error())
+				}
+			}
+		}
+	}
+
+	// Attachment rows are removed with the message
+	if deleted := p.db.DeleteMessage(ctx, msg.ID()); deleted.IsErr() {
+		return deleted.Error()
+	}
+	return nil
+}
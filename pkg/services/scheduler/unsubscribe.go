@@ -0,0 +1,35 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/auth"
+	"github.com/thanhphuchuynh/dear-future/pkg/config"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+)
+
+// unsubscribeTokenLifetime is how long the unsubscribe link of an email works
+const unsubscribeTokenLifetime = 365 * 24 * time.Hour
+
+// unsubscribeSigner signs the one-click unsubscribe links of outgoing
+// emails. A nil signer sends emails without them.
+type unsubscribeSigner struct {
+	tokens *auth.ActionTokenService
+}
+
+// newUnsubscribeSigner creates a signer that signs its links with the JWT secret
+func newUnsubscribeSigner(cfg *config.Config) *unsubscribeSigner {
+	if cfg == nil {
+		return nil
+	}
+	return &unsubscribeSigner{tokens: auth.NewActionTokenService(cfg.JWTSecret)}
+}
+
+// token signs the link unsubscribing a user from a category
+func (s *unsubscribeSigner) token(userID uuid.UUID, category user.NotificationCategory) string {
+	if s == nil {
+		return ""
+	}
+	return s.tokens.Sign(auth.UnsubscribePurpose(string(category)), userID, time.Now().Add(unsubscribeTokenLifetime))
+}
@@ -0,0 +1,131 @@
+// Package thumbnail reads the EXIF orientation of JPEG images
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// orientationTag is the EXIF tag recording how a camera was held
+const orientationTag = 0x0112
+
+// jpegOrientation returns the EXIF orientation of a JPEG image, from 1 to 8,
+// or 1 when it records none. Cameras store photos as the sensor read them
+// and note in this tag how they have to be turned to be displayed upright.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for offset := 2; offset+4 <= len(data); {
+		if data[offset] != 0xFF {
+			return 1
+		}
+		marker := data[offset+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Image data follows the start of scan, so no metadata is left
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if length < 2 || offset+2+length > len(data) {
+			return 1
+		}
+		segment := data[offset+4 : offset+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation reads the orientation tag from the first IFD of the TIFF
+// structure in an EXIF segment
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != orientationTag {
+			continue
+		}
+		// A SHORT value is stored in the first bytes of the value field
+		if order.Uint16(tiff[entry+2:]) != 3 {
+			return 1
+		}
+		if orientation := int(order.Uint16(tiff[entry+8:])); orientation >= 1 && orientation <= 8 {
+			return orientation
+		}
+		return 1
+	}
+	return 1
+}
+
+// swapsAxes reports whether an orientation turns the image by a quarter, so
+// its width and height trade places
+func swapsAxes(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// orient turns and flips an image as its EXIF orientation describes, so it
+// is displayed upright
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	outWidth, outHeight := width, height
+	if swapsAxes(orientation) {
+		outWidth, outHeight = height, width
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, outWidth, outHeight))
+	for y := 0; y < outHeight; y++ {
+		for x := 0; x < outWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored
+				sx, sy = width-1-x, y
+			case 3: // Upside down
+				sx, sy = width-1-x, height-1-y
+			case 4: // Mirrored upside down
+				sx, sy = x, height-1-y
+			case 5: // Mirrored, turned a quarter counterclockwise
+				sx, sy = y, x
+			case 6: // Turned a quarter counterclockwise
+				sx, sy = y, height-1-x
+			case 7: // Mirrored, turned a quarter clockwise
+				sx, sy = width-1-y, height-1-x
+			case 8: // Turned a quarter clockwise
+				sx, sy = width-1-y, x
+			}
+			out.SetRGBA(x, y, img.RGBAAt(img.Bounds().Min.X+sx, img.Bounds().Min.Y+sy))
+		}
+	}
+	return out
+}
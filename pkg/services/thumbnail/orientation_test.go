@@ -0,0 +1,96 @@
+package thumbnail
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// withOrientation inserts an EXIF segment recording orientation after the
+// start of a JPEG image
+func withOrientation(t *testing.T, jpegData []byte, orientation uint16) []byte {
+	t.Helper()
+
+	tiff := []byte("MM\x00\x2A")
+	tiff = binary.BigEndian.AppendUint32(tiff, 8) // First IFD
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // Entries
+	tiff = binary.BigEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1) // Count
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // Padding and no next IFD
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, app1...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	photo := encodeJPEG(t, filled(8, 4, color.White))
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no EXIF", photo, 1},
+		{"turned", withOrientation(t, photo, 6), 6},
+		{"mirrored", withOrientation(t, photo, 2), 2},
+		{"out of range", withOrientation(t, photo, 9), 1},
+		{"not a JPEG", encodePNG(t, filled(8, 4, color.White)), 1},
+		{"truncated", withOrientation(t, photo, 6)[:12], 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 3x2 image whose pixels are numbered row by row:
+	//   0 1 2
+	//   3 4 5
+	source := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		source.SetRGBA(i%3, i/3, color.RGBA{R: uint8(i), A: 255})
+	}
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8 // Rows of the upright image
+	}{
+		{1, [][]uint8{{0, 1, 2}, {3, 4, 5}}},
+		{2, [][]uint8{{2, 1, 0}, {5, 4, 3}}},
+		{3, [][]uint8{{5, 4, 3}, {2, 1, 0}}},
+		{4, [][]uint8{{3, 4, 5}, {0, 1, 2}}},
+		{5, [][]uint8{{0, 3}, {1, 4}, {2, 5}}},
+		{6, [][]uint8{{3, 0}, {4, 1}, {5, 2}}},
+		{7, [][]uint8{{5, 2}, {4, 1}, {3, 0}}},
+		{8, [][]uint8{{2, 5}, {1, 4}, {0, 3}}},
+	}
+
+	for _, tt := range tests {
+		upright := orient(source, tt.orientation)
+		if upright.Bounds().Dx() != len(tt.want[0]) || upright.Bounds().Dy() != len(tt.want) {
+			t.Errorf("orientation %d: got %v, want %dx%d", tt.orientation, upright.Bounds(), len(tt.want[0]), len(tt.want))
+			continue
+		}
+		for y, row := range tt.want {
+			for x, want := range row {
+				if got := upright.RGBAAt(x, y).R; got != want {
+					t.Errorf("orientation %d: pixel (%d, %d) = %d, want %d", tt.orientation, x, y, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package thumbnail generates and stores the thumbnails of image attachments
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register the WebP decoder
+)
+
+// maxSourcePixels bounds the images that are decoded, so a small file
+// declaring huge dimensions cannot exhaust memory
+const maxSourcePixels = 50_000_000
+
+// jpegQuality is the quality thumbnails of opaque images are encoded with
+const jpegQuality = 80
+
+// Image is an encoded thumbnail
+type Image struct {
+	Size        message.ThumbnailSize
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// Generate decodes an image once and scales it to every size in
+// message.ThumbnailSizes, keeping its aspect ratio. Images smaller than a
+// size keep their dimensions. JPEG photos are turned upright as their EXIF
+// orientation describes. Transparent images are encoded as PNG and opaque
+// ones as JPEG.
+func Generate(data []byte) ([]Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxSourcePixels {
+		return nil, errors.New("image dimensions are not supported")
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	opaque := isOpaque(source)
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	sourceWidth, sourceHeight := source.Bounds().Dx(), source.Bounds().Dy()
+	if swapsAxes(orientation) {
+		sourceWidth, sourceHeight = sourceHeight, sourceWidth
+	}
+
+	thumbnails := make([]Image, 0, len(message.ThumbnailSizes))
+	for _, size := range message.ThumbnailSizes {
+		width, height := fit(sourceWidth, sourceHeight, size.MaxDimension())
+		// Scaling comes first, so only the thumbnail is turned upright
+		scaledWidth, scaledHeight := width, height
+		if swapsAxes(orientation) {
+			scaledWidth, scaledHeight = height, width
+		}
+		scaled := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+		draw.CatmullRom.Scale(scaled, scaled.Bounds(), source, source.Bounds(), draw.Src, nil)
+		upright := orient(scaled, orientation)
+
+		var buf bytes.Buffer
+		contentType := "image/jpeg"
+		if opaque {
+			err = jpeg.Encode(&buf, upright, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			contentType = "image/png"
+			err = png.Encode(&buf, upright)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s thumbnail: %w", size, err)
+		}
+
+		thumbnails = append(thumbnails, Image{
+			Size:        size,
+			ContentType: contentType,
+			Data:        buf.Bytes(),
+			Width:       width,
+			Height:      height,
+		})
+	}
+
+	return thumbnails, nil
+}
+
+// fit scales width and height down so the longest side is at most
+// maxDimension, never below one pixel
+func fit(width, height, maxDimension int) (int, int) {
+	longest := max(width, height)
+	if longest <= maxDimension {
+		return width, height
+	}
+	return max(1, width*maxDimension/longest), max(1, height*maxDimension/longest)
+}
+
+// isOpaque reports whether the image has no transparent pixels
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// maxConcurrentJobs bounds how many images are decoded at once, as a large
+// image takes hundreds of megabytes while it is scaled
+const maxConcurrentJobs = 2
+
+// jobTimeout bounds generating and storing the thumbnails of one attachment
+const jobTimeout = 2 * time.Minute
+
+// Service generates the thumbnails of attachments in the background and
+// stores them alongside the original file
+type Service struct {
+	storage effects.StorageService
+	store   effects.AttachmentThumbnailStore
+	jobs    chan struct{}
+}
+
+// NewService creates a service storing thumbnails in storage and recording
+// them in store. Without either no thumbnails are generated.
+func NewService(storage effects.StorageService, store effects.AttachmentThumbnailStore) *Service {
+	return &Service{storage: storage, store: store, jobs: make(chan struct{}, maxConcurrentJobs)}
+}
+
+// Start generates the thumbnails of a saved image attachment after the
+// upload request returned, so uploads do not wait for decoding and scaling.
+// The attachment is listed without thumbnails until they are recorded.
+// Thumbnails are a convenience: failures are logged and the attachment is
+// left without them.
+func (s *Service) Start(ctx context.Context, attachment message.MessageAttachment, data []byte) {
+	if s == nil || s.storage == nil || s.store == nil || !message.CanThumbnail(attachment.FileType()) {
+		return
+	}
+
+	// The job outlives the request, but keeps its values for logging
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		s.jobs <- struct{}{}
+		defer func() { <-s.jobs }()
+
+		ctx, cancel := context.WithTimeout(ctx, jobTimeout)
+		defer cancel()
+		s.record(ctx, s.attach(ctx, attachment, data))
+	}()
+}
+
+// attach generates and stores the thumbnails of an image attachment and
+// returns it with their keys. Thumbnails that cannot be generated or stored
+// are left out.
+func (s *Service) attach(ctx context.Context, attachment message.MessageAttachment, data []byte) message.MessageAttachment {
+	thumbnails, err := Generate(data)
+	if err != nil {
+		slog.Warn("thumbnail: failed to generate thumbnails", "attachment_id", attachment.ID(), "error", err)
+		return attachment
+	}
+
+	for _, thumbnail := range thumbnails {
+		uploaded := s.storage.UploadFile(ctx, effects.FileUpload{
+			FileName:    fileName(attachment.FileName(), thumbnail),
+			ContentType: thumbnail.ContentType,
+			Data:        thumbnail.Data,
+			Size:        int64(len(thumbnail.Data)),
+			Metadata: map[string]string{
+				"thumbnail_of":   attachment.ID().String(),
+				"thumbnail_size": string(thumbnail.Size),
+			},
+		})
+		if uploaded.IsErr() {
+			slog.Warn("thumbnail: failed to store thumbnail", "attachment_id", attachment.ID(), "size", thumbnail.Size, "error", uploaded.Error())
+			continue
+		}
+		attachment = attachment.WithThumbnail(thumbnail.Size, uploaded.Value().Key)
+	}
+
+	return attachment
+}
+
+// record saves the thumbnail keys on the attachment. When the attachment was
+// deleted while its thumbnails were generated they are removed again.
+func (s *Service) record(ctx context.Context, attachment message.MessageAttachment) {
+	thumbnails := attachment.Thumbnails()
+	if len(thumbnails) == 0 {
+		return
+	}
+
+	saved := s.store.SaveAttachmentThumbnails(ctx, attachment)
+	if saved.IsErr() {
+		slog.Warn("thumbnail: failed to record thumbnails", "attachment_id", attachment.ID(), "error", saved.Error())
+	}
+	if saved.IsOk() && saved.Value() {
+		return
+	}
+
+	for size, key := range thumbnails {
+		if deleted := s.storage.DeleteFile(ctx, key); deleted.IsErr() {
+			slog.Warn("thumbnail: failed to remove thumbnail", "attachment_id", attachment.ID(), "size", size, "error", deleted.Error())
+		}
+	}
+}
+
+// fileName names a thumbnail after its original, such as
+// "beach.thumb-small.jpg" for "beach.png"
+func fileName(original string, thumbnail Image) string {
+	extension := ".jpg"
+	if thumbnail.ContentType == "image/png" {
+		extension = ".png"
+	}
+	base := strings.TrimSuffix(original, path.Ext(original))
+	return base + ".thumb-" + string(thumbnail.Size) + extension
+}
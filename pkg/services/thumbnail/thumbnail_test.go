@@ -0,0 +1,153 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func filled(width, height int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// plainImage hides every method of an image beyond image.Image
+type plainImage struct{ image.Image }
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxDimension  int
+		wantW, wantH  int
+	}{
+		{"smaller than the size", 100, 50, 160, 100, 50},
+		{"exactly the size", 160, 90, 160, 160, 90},
+		{"landscape", 1600, 900, 160, 160, 90},
+		{"portrait", 900, 1600, 160, 90, 160},
+		{"square", 1000, 1000, 640, 640, 640},
+		{"never below one pixel", 10000, 10, 160, 160, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := fit(tt.width, tt.height, tt.maxDimension)
+			if width != tt.wantW || height != tt.wantH {
+				t.Errorf("fit(%d, %d, %d) = %d, %d, want %d, %d", tt.width, tt.height, tt.maxDimension, width, height, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestIsOpaque(t *testing.T) {
+	tests := []struct {
+		name string
+		img  image.Image
+		want bool
+	}{
+		{"opaque", filled(4, 4, color.NRGBA{R: 255, A: 255}), true},
+		{"transparent pixel", filled(4, 4, color.NRGBA{R: 255, A: 128}), false},
+		{"grayscale", image.NewGray(image.Rect(0, 0, 4, 4)), true},
+		{"without Opaque", plainImage{filled(4, 4, color.White)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOpaque(tt.img); got != tt.want {
+				t.Errorf("isOpaque() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            []byte
+		wantContentType string
+		wantSizes       [][2]int // Width and height per size in message.ThumbnailSizes
+	}{
+		{"opaque image as JPEG", encodePNG(t, filled(1280, 960, color.NRGBA{R: 200, A: 255})), "image/jpeg", [][2]int{{160, 120}, {640, 480}}},
+		{"transparent image as PNG", encodePNG(t, filled(960, 1280, color.NRGBA{B: 200, A: 100})), "image/png", [][2]int{{120, 160}, {480, 640}}},
+		{"small image keeps its dimensions", encodeJPEG(t, filled(100, 40, color.White)), "image/jpeg", [][2]int{{100, 40}, {100, 40}}},
+		{"EXIF orientation turns the image", withOrientation(t, encodeJPEG(t, filled(100, 40, color.White)), 6), "image/jpeg", [][2]int{{40, 100}, {40, 100}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumbnails, err := Generate(tt.data)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if len(thumbnails) != len(message.ThumbnailSizes) {
+				t.Fatalf("got %d thumbnails, want %d", len(thumbnails), len(message.ThumbnailSizes))
+			}
+
+			for i, thumbnail := range thumbnails {
+				if thumbnail.Size != message.ThumbnailSizes[i] {
+					t.Errorf("thumbnail %d has size %s, want %s", i, thumbnail.Size, message.ThumbnailSizes[i])
+				}
+				if thumbnail.ContentType != tt.wantContentType {
+					t.Errorf("%s content type = %s, want %s", thumbnail.Size, thumbnail.ContentType, tt.wantContentType)
+				}
+				if thumbnail.Width != tt.wantSizes[i][0] || thumbnail.Height != tt.wantSizes[i][1] {
+					t.Errorf("%s = %dx%d, want %dx%d", thumbnail.Size, thumbnail.Width, thumbnail.Height, tt.wantSizes[i][0], tt.wantSizes[i][1])
+				}
+
+				config, _, err := image.DecodeConfig(bytes.NewReader(thumbnail.Data))
+				if err != nil {
+					t.Fatalf("%s does not decode: %v", thumbnail.Size, err)
+				}
+				if config.Width != thumbnail.Width || config.Height != thumbnail.Height {
+					t.Errorf("%s decodes as %dx%d, want %dx%d", thumbnail.Size, config.Width, config.Height, thumbnail.Width, thumbnail.Height)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not an image", []byte("plain text")},
+		{"truncated image", encodePNG(t, filled(64, 64, color.White))[:40]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.data); err == nil {
+				t.Error("Generate() should fail")
+			}
+		})
+	}
+}
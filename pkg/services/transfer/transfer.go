@@ -0,0 +1,213 @@
+// Package transfer provides bulk import and export of messages
+package transfer
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+)
+
+// Row outcomes reported by an import
+const (
+	RowImported = "imported"
+	RowValid    = "valid" // Passed validation during a dry run
+	RowFailed   = "error"
+)
+
+// Service imports and exports a user's messages in the formats supported by
+// message.TransferFormat. Scheduling is optional; without it imported
+// messages are saved but not queued for delivery.
+type Service struct {
+	db         effects.Database
+	scheduling effects.SchedulingService
+}
+
+// ImportOptions controls a single import run
+type ImportOptions struct {
+	UserID uuid.UUID
+	Format message.TransferFormat
+	DryRun bool // Validate every row without saving anything
+	Limits user.PlanLimits
+	Usage  user.Usage // Usage before the import; imported rows are added as they go
+}
+
+// ImportReport summarizes an import with one entry per row
+type ImportReport struct {
+	Format   message.TransferFormat `json:"format"`
+	DryRun   bool                   `json:"dry_run"`
+	Total    int                    `json:"total"`
+	Imported int                    `json:"imported"`
+	Failed   int                    `json:"failed"`
+	Rows     []RowResult            `json:"rows"`
+}
+
+// RowResult reports the outcome of importing one row
+type RowResult struct {
+	Row       int    `json:"row"`
+	Title     string `json:"title,omitempty"`
+	Status    string `json:"status"`
+	MessageID string `json:"message_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// NewService creates a new transfer service
+func NewService(db effects.Database, scheduling effects.SchedulingService) *Service {
+	return &Service{
+		db:         db,
+		scheduling: scheduling,
+	}
+}
+
+// Import reads messages from r and creates them for the user. Each row goes
+// through message.TransferRecord.ToMessage validation; failing rows are
+// reported and the remaining rows are still imported.
+func (s *Service) Import(ctx context.Context, options ImportOptions, r io.Reader) common.Result[ImportReport] {
+	rowsResult := message.DecodeTransfer(options.Format, r)
+	if rowsResult.IsErr() {
+		return common.Err[ImportReport](rowsResult.Error())
+	}
+
+	report := ImportReport{
+		Format: options.Format,
+		DryRun: options.DryRun,
+		Total:  len(rowsResult.Value()),
+		Rows:   make([]RowResult, 0, len(rowsResult.Value())),
+	}
+
+	for _, row := range rowsResult.Value() {
+		result := s.importRow(ctx, &options, row)
+		if result.Status == RowFailed {
+			report.Failed++
+		} else if result.Status == RowImported {
+			report.Imported++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	return common.Ok(report)
+}
+
+func (s *Service) importRow(ctx context.Context, options *ImportOptions, row message.TransferRow) RowResult {
+	result := RowResult{Row: row.Row, Title: row.Record.Title, Status: RowFailed}
+	if row.Err != nil {
+		result.Error = row.Err.Error()
+		return result
+	}
+
+	msgResult := row.Record.ToMessage(options.UserID)
+	if msgResult.IsErr() {
+		result.Error = msgResult.Error().Error()
+		return result
+	}
+
+	tagNames := message.NormalizeTagNames(row.Record.Tags)
+	if tagNames.IsErr() {
+		result.Error = tagNames.Error().Error()
+		return result
+	}
+
+	if allowed := message.ValidateNewMessageForPlan(msgResult.Value(), options.Limits, options.Usage); allowed.IsErr() {
+		result.Error = allowed.Error().Error()
+		return result
+	}
+	if msgResult.Value().IsAwaitingDelivery() {
+		options.Usage.ScheduledMessages++
+	}
+
+	if options.DryRun {
+		result.Status = RowValid
+		return result
+	}
+
+	saveResult := s.db.SaveMessage(ctx, msgResult.Value())
+	if saveResult.IsErr() {
+		slog.Error("Failed to save imported message", "row", row.Row, "error", saveResult.Error())
+		result.Error = "failed to save message"
+		return result
+	}
+	saved := saveResult.Value()
+	result.MessageID = saved.ID().String()
+
+	if len(tagNames.Value()) > 0 {
+		if tagsResult := s.setMessageTags(ctx, options.UserID, saved.ID(), tagNames.Value()); tagsResult.IsErr() {
+			slog.Error("Failed to tag imported message", "message_id", saved.ID(), "error", tagsResult.Error())
+		}
+	}
+
+	// Archived letters were delivered or cancelled and are not sent again
+	if s.scheduling != nil && saved.IsAwaitingDelivery() {
+		scheduleResult := s.scheduling.ScheduleMessage(ctx, saved.ID(), saved.DeliveryDate())
+		if scheduleResult.IsErr() {
+			// The message is saved; scheduling can be retried like any other message
+			slog.Error("Failed to schedule imported message", "message_id", saved.ID(), "error", scheduleResult.Error())
+		}
+	}
+
+	result.Status = RowImported
+	return result
+}
+
+func (s *Service) setMessageTags(ctx context.Context, userID, messageID uuid.UUID, names []string) common.Result[bool] {
+	tagsResult := s.db.EnsureTags(ctx, userID, names)
+	if tagsResult.IsErr() {
+		return common.Err[bool](tagsResult.Error())
+	}
+
+	tagIDs := make([]uuid.UUID, len(tagsResult.Value()))
+	for i, tag := range tagsResult.Value() {
+		tagIDs[i] = tag.ID()
+	}
+
+	return s.db.SetMessageTags(ctx, messageID, tagIDs)
+}
+
+// Export writes all of the user's messages to w, oldest first, and returns
+// how many were written
+func (s *Service) Export(ctx context.Context, userID uuid.UUID, format message.TransferFormat, w io.Writer) common.Result[int] {
+	records := []message.TransferRecord{}
+	cursor := ""
+
+	for {
+		options := message.NewListOptions(message.ListFilter{}, message.SortByCreatedAt, message.SortAscending, cursor, message.MaxListLimit)
+		if options.IsErr() {
+			return common.Err[int](options.Error())
+		}
+
+		pageResult := s.db.FindMessagesPage(ctx, userID, options.Value())
+		if pageResult.IsErr() {
+			return common.Err[int](pageResult.Error())
+		}
+		page := pageResult.Value()
+
+		messageIDs := make([]uuid.UUID, len(page.Messages))
+		for i, msg := range page.Messages {
+			messageIDs[i] = msg.ID()
+		}
+
+		tagsResult := s.db.FindTagsForMessages(ctx, messageIDs)
+		if tagsResult.IsErr() {
+			return common.Err[int](tagsResult.Error())
+		}
+
+		for _, msg := range page.Messages {
+			var tagNames []string
+			for _, tag := range tagsResult.Value()[msg.ID()] {
+				tagNames = append(tagNames, tag.Name())
+			}
+			records = append(records, message.NewTransferRecord(msg, tagNames))
+		}
+
+		if page.NextCursor.IsNone() {
+			break
+		}
+		cursor = page.NextCursor.Value().Encode()
+	}
+
+	return message.EncodeTransfer(format, w, records)
+}
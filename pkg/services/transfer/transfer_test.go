@@ -0,0 +1,155 @@
+package transfer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/common"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/message"
+	"github.com/thanhphuchuynh/dear-future/pkg/domain/user"
+	"github.com/thanhphuchuynh/dear-future/pkg/effects"
+	"github.com/thanhphuchuynh/dear-future/pkg/mocks"
+)
+
+// recordingDatabase remembers the messages it saved
+type recordingDatabase struct {
+	*mocks.MockDatabase
+	saved []message.Message
+}
+
+func (d *recordingDatabase) SaveMessage(ctx context.Context, msg message.Message) common.Result[message.Message] {
+	d.saved = append(d.saved, msg)
+	return common.Ok(msg)
+}
+
+// recordingScheduler remembers the messages it was asked to schedule
+type recordingScheduler struct {
+	scheduled []uuid.UUID
+}
+
+func (s *recordingScheduler) ScheduleMessage(ctx context.Context, messageID uuid.UUID, deliveryTime time.Time) common.Result[effects.ScheduleResult] {
+	s.scheduled = append(s.scheduled, messageID)
+	return common.Ok(effects.ScheduleResult{MessageID: messageID, ScheduledFor: deliveryTime})
+}
+
+func (s *recordingScheduler) CancelScheduledMessage(ctx context.Context, messageID uuid.UUID) common.Result[bool] {
+	return common.Ok(true)
+}
+
+func (s *recordingScheduler) RescheduleMessage(ctx context.Context, messageID uuid.UUID, newDeliveryTime time.Time) common.Result[effects.ScheduleResult] {
+	return common.Ok(effects.ScheduleResult{MessageID: messageID, ScheduledFor: newDeliveryTime})
+}
+
+func (s *recordingScheduler) GetScheduledMessages(ctx context.Context, from, to time.Time) common.Result[[]effects.ScheduledMessage] {
+	return common.Ok([]effects.ScheduledMessage{})
+}
+
+// importFile has a scheduled letter, a delivered one, a recurring one and a
+// letter due in the past, one per line
+func importFile() string {
+	future := time.Now().AddDate(0, 1, 0).UTC().Format(time.RFC3339)
+	past := time.Now().AddDate(-1, 0, 0).UTC().Format(time.RFC3339)
+	return strings.Join([]string{
+		`{"title":"Scheduled","content":"Hello","delivery_date":"` + future + `","tags":["family"]}`,
+		`{"title":"Delivered","content":"Hello","delivery_date":"` + past + `","status":"delivered"}`,
+		`{"title":"Recurring","content":"Hello","delivery_date":"` + future + `","recurrence":"monthly"}`,
+		`{"title":"Too late","content":"Hello","delivery_date":"` + past + `","status":"scheduled"}`,
+	}, "\n") + "\n"
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name          string
+		dryRun        bool
+		limits        user.PlanLimits
+		wantStatuses  []string
+		wantSaved     int
+		wantScheduled int
+	}{
+		{
+			name:          "import",
+			limits:        user.PlanLimits{MaxScheduledMessages: 10, Recurrence: true},
+			wantStatuses:  []string{RowImported, RowImported, RowImported, RowFailed},
+			wantSaved:     3,
+			wantScheduled: 2, // The delivered letter is archived, not sent again
+		},
+		{
+			name:         "dry run saves nothing",
+			dryRun:       true,
+			limits:       user.PlanLimits{MaxScheduledMessages: 10, Recurrence: true},
+			wantStatuses: []string{RowValid, RowValid, RowValid, RowFailed},
+		},
+		{
+			name:          "plan without recurrence",
+			limits:        user.PlanLimits{MaxScheduledMessages: 10},
+			wantStatuses:  []string{RowImported, RowImported, RowFailed, RowFailed},
+			wantSaved:     2,
+			wantScheduled: 1,
+		},
+		{
+			name:          "quota counts imported letters",
+			limits:        user.PlanLimits{MaxScheduledMessages: 1, Recurrence: true},
+			wantStatuses:  []string{RowImported, RowImported, RowFailed, RowFailed},
+			wantSaved:     2,
+			wantScheduled: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &recordingDatabase{MockDatabase: mocks.NewMockDatabase()}
+			scheduling := &recordingScheduler{}
+
+			result := NewService(db, scheduling).Import(context.Background(), ImportOptions{
+				UserID: uuid.New(),
+				Format: message.TransferJSONLines,
+				DryRun: tt.dryRun,
+				Limits: tt.limits,
+			}, strings.NewReader(importFile()))
+			if result.IsErr() {
+				t.Fatalf("Import() error = %v", result.Error())
+			}
+
+			report := result.Value()
+			if report.Total != len(tt.wantStatuses) || len(report.Rows) != len(tt.wantStatuses) || report.DryRun != tt.dryRun {
+				t.Fatalf("report = %+v, want %d rows", report, len(tt.wantStatuses))
+			}
+			var imported, failed int
+			for i, row := range report.Rows {
+				if row.Row != i+1 || row.Status != tt.wantStatuses[i] {
+					t.Errorf("row %d = %d %s (%s), want %d %s", i, row.Row, row.Status, row.Error, i+1, tt.wantStatuses[i])
+				}
+				if (row.Status == RowFailed) != (row.Error != "") {
+					t.Errorf("row %d: status %s with error %q", row.Row, row.Status, row.Error)
+				}
+				if (row.Status == RowImported) != (row.MessageID != "") {
+					t.Errorf("row %d: status %s with message ID %q", row.Row, row.Status, row.MessageID)
+				}
+				switch row.Status {
+				case RowImported:
+					imported++
+				case RowFailed:
+					failed++
+				}
+			}
+			if report.Imported != imported || report.Failed != failed {
+				t.Errorf("Imported, Failed = %d, %d, want %d, %d", report.Imported, report.Failed, imported, failed)
+			}
+
+			if len(db.saved) != tt.wantSaved {
+				t.Errorf("saved %d messages, want %d", len(db.saved), tt.wantSaved)
+			}
+			if len(scheduling.scheduled) != tt.wantScheduled {
+				t.Errorf("scheduled %d messages, want %d", len(scheduling.scheduled), tt.wantScheduled)
+			}
+			for _, msg := range db.saved {
+				if msg.Title() == "Delivered" && msg.Status() != message.StatusDelivered {
+					t.Errorf("delivered letter imported as %s", msg.Status())
+				}
+			}
+		})
+	}
+}
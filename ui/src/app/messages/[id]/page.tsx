@@ -131,6 +131,9 @@ export default function EditMessagePage() {
 
   const handleSubmit = async (e: FormEvent<HTMLFormElement>) => {
     e.preventDefault();
+    if (!message) {
+      return;
+    }
     setError('');
     setSaving(true);
 
@@ -140,7 +143,7 @@ export default function EditMessagePage() {
         reminder_minutes: reminderMinutes ? Number(reminderMinutes) : undefined,
       };
 
-      await apiClient.updateMessage(messageId, payload);
+      await apiClient.updateMessage(messageId, message.version, payload);
       router.push('/messages');
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to update message');
@@ -150,12 +153,12 @@ export default function EditMessagePage() {
   };
 
   const handleDelete = async () => {
-    if (!confirm('Are you sure you want to delete this message?')) {
+    if (!message || !confirm('Are you sure you want to delete this message?')) {
       return;
     }
 
     try {
-      await apiClient.deleteMessage(messageId);
+      await apiClient.deleteMessage(messageId, message.version);
       router.push('/messages');
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to delete message');
@@ -399,14 +402,23 @@ export default function EditMessagePage() {
                             key={attachment.id}
                             className="flex items-center justify-between rounded-md border border-gray-200 dark:border-gray-700 px-3 py-2 text-sm"
                           >
-                            <div>
-                              <p className="text-gray-800 dark:text-gray-100">
-                                {attachment.file_name}
-                              </p>
-                              <p className="text-xs text-gray-500 dark:text-gray-400">
-                                {(attachment.file_size / (1024 * 1024)).toFixed(2)} MB ·{' '}
-                                {new Date(attachment.uploaded_at).toLocaleString()}
-                              </p>
+                            <div className="flex items-center gap-3">
+                              {attachment.thumbnails?.small && (
+                                <img
+                                  src={attachment.thumbnails.small}
+                                  alt=""
+                                  className="h-10 w-10 rounded object-cover"
+                                />
+                              )}
+                              <div>
+                                <p className="text-gray-800 dark:text-gray-100">
+                                  {attachment.file_name}
+                                </p>
+                                <p className="text-xs text-gray-500 dark:text-gray-400">
+                                  {(attachment.file_size / (1024 * 1024)).toFixed(2)} MB ·{' '}
+                                  {new Date(attachment.uploaded_at).toLocaleString()}
+                                </p>
+                              </div>
                             </div>
                             <div className="flex items-center gap-3">
                               {attachment.download_url && (
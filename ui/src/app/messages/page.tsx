@@ -30,13 +30,13 @@ export default function MessagesPage() {
     }
   };
 
-  const handleDelete = async (id: string) => {
+  const handleDelete = async (id: string, version: number) => {
     if (!confirm('Are you sure you want to delete this message?')) {
       return;
     }
 
     try {
-      await apiClient.deleteMessage(id);
+      await apiClient.deleteMessage(id, version);
       setMessages(messages.filter((m) => m.id !== id));
     } catch (err) {
       alert(err instanceof Error ? err.message : 'Failed to delete message');
@@ -238,7 +238,7 @@ export default function MessagesPage() {
                               </svg>
                             </button>
                             <button
-                              onClick={() => handleDelete(message.id)}
+                              onClick={() => handleDelete(message.id, message.version)}
                               className="p-2 text-red-600 hover:bg-red-50 dark:text-red-400 dark:hover:bg-red-900/20 rounded"
                               title="Delete"
                             >
@@ -7,15 +7,38 @@ import type {
   Message,
   CreateMessageRequest,
   UpdateMessageRequest,
+  CheckInResponse,
+  MessageTimeline,
+  TrashListResponse,
+  MessagePreview,
+  ResolveDateRequest,
+  ResolveDateResponse,
+  HolidayCalendar,
+  MessageListParams,
+  MessageListResponse,
+  MessageSearchParams,
+  MessageSearchResponse,
   User,
+  UpdateProfileRequest,
+  UsageResponse,
+  NotificationPreferences,
   HealthStatus,
   ApiError,
   Attachment,
   AnalyticsSummary,
+  Tag,
+  TransferFormat,
+  ImportReport,
 } from './types';
 
 const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || '/api/v1';
 
+// newIdempotencyKey returns a fresh key for the Idempotency-Key header of
+// create requests, which the server uses to replay retried requests
+function newIdempotencyKey(): string {
+  return crypto.randomUUID();
+}
+
 class ApiClient {
   private accessToken: string | null = null;
 
@@ -57,6 +80,10 @@ class ApiClient {
       headers.set('Authorization', `Bearer ${this.accessToken}`);
     }
 
+    if (typeof navigator !== 'undefined' && !headers.has('Accept-Language')) {
+      headers.set('Accept-Language', navigator.language);
+    }
+
     const response = await fetch(url, {
       ...options,
       headers,
@@ -109,32 +136,98 @@ class ApiClient {
     return this.request<User>('/user/profile');
   }
 
-  async updateProfile(data: Partial<User>): Promise<User> {
+  async updateProfile(data: UpdateProfileRequest): Promise<User> {
     return this.request<User>('/user/profile', {
       method: 'PUT',
       body: JSON.stringify(data),
     });
   }
 
+  async getUsage(): Promise<UsageResponse> {
+    return this.request<UsageResponse>('/user/usage');
+  }
+
+  async getNotificationPreferences(): Promise<NotificationPreferences> {
+    return this.request<NotificationPreferences>('/user/notifications');
+  }
+
+  async updateNotificationPreferences(data: Partial<NotificationPreferences>): Promise<NotificationPreferences> {
+    return this.request<NotificationPreferences>('/user/notifications', {
+      method: 'PUT',
+      body: JSON.stringify(data),
+    });
+  }
+
   // Message Endpoints
   async getMessages(): Promise<Message[]> {
-    return this.request<Message[]>('/messages');
+    const page = await this.listMessages();
+    return page.messages;
+  }
+
+  async listMessages(params: MessageListParams = {}): Promise<MessageListResponse> {
+    const query = new URLSearchParams();
+    Object.entries(params).forEach(([key, value]) => {
+      if (value !== undefined && value !== '') {
+        query.set(key, String(value));
+      }
+    });
+
+    const suffix = query.toString() ? `?${query.toString()}` : '';
+    return this.request<MessageListResponse>(`/messages${suffix}`);
   }
 
   async getMessage(id: string): Promise<Message> {
     return this.request<Message>(`/messages?id=${encodeURIComponent(id)}`);
   }
 
-  async createMessage(data: CreateMessageRequest): Promise<Message> {
-    data.delivery_date = new Date(data.delivery_date).toISOString();
+  async searchMessages(q: string, params: MessageSearchParams = {}): Promise<MessageSearchResponse> {
+    const query = new URLSearchParams({ q });
+    Object.entries(params).forEach(([key, value]) => {
+      if (value !== undefined && value !== '') {
+        query.set(key, String(value));
+      }
+    });
+
+    return this.request<MessageSearchResponse>(`/messages/search?${query.toString()}`);
+  }
+
+  // Pass the same idempotencyKey when retrying a create so a request that
+  // already went through is not scheduled twice
+  async createMessage(
+    data: CreateMessageRequest,
+    idempotencyKey: string = newIdempotencyKey()
+  ): Promise<Message> {
+    if (data.delivery_date) {
+      data.delivery_date = new Date(data.delivery_date).toISOString();
+    }
 
     return this.request<Message>('/messages', {
       method: 'POST',
+      headers: { 'Idempotency-Key': idempotencyKey },
       body: JSON.stringify(data),
     });
   }
 
-  async updateMessage(id: string, data: UpdateMessageRequest): Promise<Message> {
+  async replyToMessage(
+    id: string,
+    data: CreateMessageRequest,
+    idempotencyKey: string = newIdempotencyKey()
+  ): Promise<Message> {
+    const payload: CreateMessageRequest = { ...data };
+    if (payload.delivery_date) {
+      payload.delivery_date = new Date(payload.delivery_date).toISOString();
+    }
+
+    return this.request<Message>(`/messages/reply?id=${encodeURIComponent(id)}`, {
+      method: 'POST',
+      headers: { 'Idempotency-Key': idempotencyKey },
+      body: JSON.stringify(payload),
+    });
+  }
+
+  // version is the message version the edit is based on; the update fails
+  // with 412 if the message changed since, for example in another tab
+  async updateMessage(id: string, version: number, data: UpdateMessageRequest): Promise<Message> {
     const payload: UpdateMessageRequest = { ...data };
     if (payload.delivery_date) {
       payload.delivery_date = new Date(payload.delivery_date).toISOString();
@@ -142,17 +235,110 @@ class ApiClient {
 
     return this.request<Message>(`/messages?id=${encodeURIComponent(id)}`, {
       method: 'PUT',
+      headers: { 'If-Match': `"${version}"` },
+      body: JSON.stringify(payload),
+    });
+  }
+
+  async previewMessage(data: CreateMessageRequest): Promise<MessagePreview> {
+    const payload: CreateMessageRequest = { ...data };
+    payload.delivery_date = new Date(payload.delivery_date).toISOString();
+
+    return this.request<MessagePreview>('/messages/preview', {
+      method: 'POST',
       body: JSON.stringify(payload),
     });
   }
 
-  async deleteMessage(id: string): Promise<void> {
+  async resolveDate(data: ResolveDateRequest): Promise<ResolveDateResponse> {
+    return this.request<ResolveDateResponse>('/messages/resolve-date', {
+      method: 'POST',
+      body: JSON.stringify(data),
+    });
+  }
+
+  async getHolidays(country?: string, year?: number): Promise<HolidayCalendar> {
+    const params = new URLSearchParams();
+    if (country) params.set('country', country);
+    if (year) params.set('year', String(year));
+    const query = params.toString();
+    return this.request<HolidayCalendar>(`/holidays${query ? `?${query}` : ''}`);
+  }
+
+  async deleteMessage(id: string, version: number): Promise<void> {
     await this.request<void>(`/messages?id=${encodeURIComponent(id)}`, {
       method: 'DELETE',
+      headers: { 'If-Match': `"${version}"` },
+    });
+  }
+
+  async listTrash(limit = 20, offset = 0): Promise<TrashListResponse> {
+    return this.request<TrashListResponse>(`/messages/trash?limit=${limit}&offset=${offset}`);
+  }
+
+  async restoreMessage(id: string): Promise<Message> {
+    return this.request<Message>(`/messages/restore?id=${encodeURIComponent(id)}`, {
+      method: 'POST',
+    });
+  }
+
+  async getMessageTimeline(id: string): Promise<MessageTimeline> {
+    return this.request<MessageTimeline>(`/messages/timeline?id=${encodeURIComponent(id)}`);
+  }
+
+  async checkIn(): Promise<CheckInResponse> {
+    return this.request<CheckInResponse>('/checkin', {
+      method: 'POST',
     });
   }
 
-  async uploadAttachment(messageId: string, file: File): Promise<Attachment> {
+  async cancelRelease(id: string): Promise<Message> {
+    return this.request<Message>(`/messages/cancel-release?id=${encodeURIComponent(id)}`, {
+      method: 'POST',
+    });
+  }
+
+  async importMessages(file: File, format?: TransferFormat, dryRun = false): Promise<ImportReport> {
+    const formData = new FormData();
+    formData.append('file', file);
+
+    const query = new URLSearchParams();
+    if (format) {
+      query.set('format', format);
+    }
+    if (dryRun) {
+      query.set('dry_run', 'true');
+    }
+
+    const suffix = query.toString() ? `?${query.toString()}` : '';
+    return this.request<ImportReport>(`/messages/import${suffix}`, {
+      method: 'POST',
+      body: formData,
+    });
+  }
+
+  async exportMessages(format: TransferFormat = 'jsonl'): Promise<Blob> {
+    const headers = new Headers();
+    if (this.accessToken) {
+      headers.set('Authorization', `Bearer ${this.accessToken}`);
+    }
+
+    const response = await fetch(`${API_BASE_URL}/messages/export?format=${format}`, { headers });
+    if (!response.ok) {
+      const error: ApiError = await response.json().catch(() => ({
+        error: 'An unknown error occurred',
+      }));
+      throw new Error(error.error || `HTTP ${response.status}`);
+    }
+
+    return response.blob();
+  }
+
+  async uploadAttachment(
+    messageId: string,
+    file: File,
+    idempotencyKey: string = newIdempotencyKey()
+  ): Promise<Attachment> {
     const formData = new FormData();
     formData.append('file', file);
 
@@ -160,6 +346,7 @@ class ApiClient {
       `/messages/attachments?message_id=${encodeURIComponent(messageId)}`,
       {
         method: 'POST',
+        headers: { 'Idempotency-Key': idempotencyKey },
         body: formData,
       }
     );
@@ -182,6 +369,38 @@ class ApiClient {
     );
   }
 
+  // Tag Endpoints
+  async listTags(): Promise<Tag[]> {
+    return this.request<Tag[]>('/tags');
+  }
+
+  async createTag(name: string): Promise<Tag> {
+    return this.request<Tag>('/tags', {
+      method: 'POST',
+      body: JSON.stringify({ name }),
+    });
+  }
+
+  async renameTag(id: string, name: string): Promise<Tag> {
+    return this.request<Tag>(`/tags?id=${encodeURIComponent(id)}`, {
+      method: 'PUT',
+      body: JSON.stringify({ name }),
+    });
+  }
+
+  async deleteTag(id: string): Promise<void> {
+    await this.request<void>(`/tags?id=${encodeURIComponent(id)}`, {
+      method: 'DELETE',
+    });
+  }
+
+  async mergeTags(sourceId: string, targetId: string): Promise<Tag> {
+    return this.request<Tag>('/tags/merge', {
+      method: 'POST',
+      body: JSON.stringify({ source_id: sourceId, target_id: targetId }),
+    });
+  }
+
   async getAnalyticsSummary(): Promise<AnalyticsSummary> {
     return this.request<AnalyticsSummary>('/analytics/summary');
   }
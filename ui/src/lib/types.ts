@@ -5,7 +5,66 @@ export interface User {
   email: string;
   name?: string;
   timezone?: string;
+  locale?: string;
   created_at: string;
+  delivery_preferences?: DeliveryPreferences;
+  plan?: Plan;
+  milestones?: Milestone[];
+}
+
+// What happens to scheduled letters when the timezone changes: keep the
+// moment of delivery, or keep the local delivery time in the new zone
+export type TimezonePolicy = 'keep_instant' | 'keep_local_time';
+
+export type UpdateProfileRequest = Partial<User> & {
+  timezone_policy?: TimezonePolicy; // Default keep_instant
+};
+
+// Personal date letters can be anchored to, such as a birthday
+export interface Milestone {
+  key: string; // e.g. "birthday" or "wedding"
+  name?: string;
+  date: string; // YYYY-MM-DD
+}
+
+export type Plan = 'free' | 'pro';
+
+export interface UsageResponse {
+  plan: Plan;
+  limits: {
+    max_scheduled_messages: number; // 0 means unlimited
+    max_attachment_bytes: number; // 0 means unlimited
+    recurrence: boolean;
+  };
+  usage: {
+    scheduled_messages: number;
+    attachment_bytes: number;
+  };
+}
+
+export type Weekday = 'sun' | 'mon' | 'tue' | 'wed' | 'thu' | 'fri' | 'sat';
+
+export interface ScheduleRule {
+  days?: Weekday[]; // Every day when empty
+  start: string; // HH:MM in the user's timezone
+  end: string; // Before start when the range runs past midnight
+}
+
+export interface DeliveryPreferences {
+  quiet_hours: ScheduleRule[];
+  windows: ScheduleRule[]; // Deliveries only happen inside a window when any are set
+  country?: string; // ISO 3166-1 alpha-2 code of the recipient's holiday calendar
+  avoid_holidays?: boolean; // Move letters off public holidays to the first working day after
+}
+
+export interface NotificationPreferences {
+  email_enabled: boolean; // Turns every email off, including delivered letters
+  push_enabled: boolean;
+  webhook_url?: string; // https only
+  delivery_reminders: boolean; // Check-in warnings
+  marketing_emails: boolean;
+  security_alerts: boolean;
+  weekly_digest: boolean;
 }
 
 export interface AuthResponse {
@@ -25,9 +84,12 @@ export interface RegisterRequest {
   password: string;
   name?: string;
   timezone?: string;
+  locale?: string; // Defaults to the browser language
 }
 
 export type DeliveryMethod = 'email' | 'push';
+
+export type ContentFormat = 'plain' | 'markdown';
 export type RecurrencePattern = 'none' | 'daily' | 'weekly' | 'monthly' | 'yearly';
 
 export interface Attachment {
@@ -37,6 +99,7 @@ export interface Attachment {
   file_type: string;
   file_size: number;
   download_url?: string;
+  thumbnails?: Partial<Record<'small' | 'medium', string>>;
   uploaded_at: string;
 }
 
@@ -45,36 +108,241 @@ export interface Message {
   user_id: string;
   title: string;
   content: string;
-  delivery_date: string;
+  content_format: ContentFormat;
+  delivery_date: string; // Empty for surprise messages until delivery
+  intended_delivery_date?: string; // Set when delivery was moved out of quiet hours
   timezone: string;
-  status: 'scheduled' | 'sent' | 'failed' | 'cancelled';
+  status: 'scheduled' | 'armed' | 'sent' | 'failed' | 'cancelled';
   delivery_method: DeliveryMethod;
   recurrence: RecurrencePattern;
   reminder_minutes?: number;
   attachment_count: number;
   attachments?: Attachment[];
+  tags: string[];
+  check_in?: CheckInSettings;
+  surprise?: SurpriseRange;
+  parent_message_id?: string; // Delivered message this letter replies to
+  receipts?: DeliveryReceipt[]; // Only returned for a single message
+  deleted_at?: string; // Set while the message is in the trash
+  purge_at?: string; // When a message in the trash is deleted for good
+  anchor?: MilestoneAnchor & { milestone_date: string }; // Milestone the delivery date is derived from
+  holiday?: HolidayTarget & { name: string; country: string; time: string }; // Public holiday the message is delivered on
+  version: number; // Sent back as If-Match when updating or deleting
   created_at: string;
   updated_at: string;
 }
 
+export interface TrashListResponse {
+  messages: Message[];
+  retention_days: number;
+  limit: number;
+  offset: number;
+}
+
+export interface DeliveryReceipt {
+  recipient: string;
+  delivered_at: string;
+  opened_at?: string;
+  read_at?: string;
+}
+
+export type TimelineEventKind = 'created' | 'delivered' | 'opened' | 'read';
+
+export interface TimelineEvent {
+  kind: TimelineEventKind;
+  at: string;
+  recipient?: string;
+}
+
+export interface MessageTimeline {
+  message_id: string;
+  events: TimelineEvent[];
+}
+
+export interface SurpriseRange {
+  earliest: string;
+  latest: string;
+}
+
+// Delivery on an anniversary of a profile milestone, e.g. the 40th birthday
+export interface MilestoneAnchor {
+  milestone: string;
+  occurrence: number; // 0 is the milestone day itself
+  offset_months?: number; // Negative for before the anniversary
+  offset_days?: number; // Negative for before the anniversary
+  time?: string; // HH:MM in the message timezone, default 09:00
+}
+
+export interface CheckInSettingsRequest {
+  interval_days: number;
+  grace_days: number;
+  recipients?: string[];
+}
+
+export interface CheckInSettings {
+  interval_days: number;
+  grace_days: number;
+  recipients: string[];
+  last_check_in_at: string;
+  warned_at?: string;
+  next_check_in_by: string;
+  release_at: string;
+}
+
+export interface CheckInResponse {
+  checked_in: number;
+  next_check_in_by?: string;
+}
+
 export interface CreateMessageRequest {
   title: string;
   content: string;
-  delivery_date: string;
+  content_format?: ContentFormat;
+  delivery_date?: string; // Not needed for check-in, surprise and anchored messages
   timezone: string;
   delivery_method: DeliveryMethod;
   recurrence: RecurrencePattern;
   reminder_minutes?: number;
+  tags?: string[];
+  check_in?: CheckInSettingsRequest;
+  surprise?: SurpriseRange; // Deliver at a hidden time within the range
+  anchor?: MilestoneAnchor; // Derive the delivery date from a profile milestone
+  holiday?: HolidayTarget; // Deliver on a public holiday, every year if recurring yearly
 }
 
 export interface UpdateMessageRequest {
   title?: string;
   content?: string;
+  content_format?: ContentFormat;
   delivery_date?: string;
   timezone?: string;
   delivery_method?: DeliveryMethod;
   recurrence?: RecurrencePattern;
   reminder_minutes?: number;
+  tags?: string[];
+  check_in?: CheckInSettingsRequest;
+  surprise?: SurpriseRange; // Deliver at a hidden time within the range
+  anchor?: MilestoneAnchor; // Derive the delivery date from a profile milestone
+  holiday?: HolidayTarget; // Deliver on a public holiday, every year if recurring yearly
+}
+
+export interface Tag {
+  id: string;
+  name: string;
+  message_count: number;
+  created_at: string;
+}
+
+export interface TagCount {
+  id: string;
+  name: string;
+  count: number;
+}
+
+export type TransferFormat = 'jsonl' | 'csv' | 'mbox';
+
+export interface ImportRowResult {
+  row: number;
+  title?: string;
+  status: 'imported' | 'valid' | 'error';
+  message_id?: string;
+  error?: string;
+}
+
+export interface ImportReport {
+  format: TransferFormat;
+  dry_run: boolean;
+  total: number;
+  imported: number;
+  failed: number;
+  rows: ImportRowResult[];
+}
+
+export interface MessagePreview {
+  subject: string;
+  content_html: string;
+  content_text: string;
+  email?: {
+    html: string;
+    text: string;
+  };
+}
+
+// Delivers a message on a public holiday, e.g. { holiday: 'christmas', time: '08:00' }
+export interface HolidayTarget {
+  holiday: string; // Key from the holiday calendar
+  country?: string; // Defaults to the country of the delivery preferences
+  time?: string; // HH:MM in the message timezone, default 09:00
+}
+
+export interface Holiday {
+  key: string;
+  name: string;
+  date: string; // YYYY-MM-DD
+}
+
+export interface HolidayCalendar {
+  country: string;
+  name: string;
+  year: number;
+  holidays: Holiday[];
+  countries: string[]; // Countries with a bundled calendar
+}
+
+export interface ResolveDateRequest {
+  expression: string; // e.g. "in 5 years", "next new year's eve at 9pm", "3 months after my birthday"
+  timezone?: string; // Defaults to the profile timezone
+}
+
+export interface ResolveDateResponse {
+  expression: string;
+  description: string; // Normalized form, e.g. "next New Year's Eve at 21:00"
+  delivery_date: string;
+  local_time: string;
+  timezone: string;
+  anchor?: MilestoneAnchor & { milestone_date: string }; // Set when counted from a milestone
+}
+
+export interface MessageListParams {
+  status?: string;
+  delivery_method?: DeliveryMethod;
+  recurrence?: RecurrencePattern;
+  tag?: string;
+  from?: string;
+  to?: string;
+  sort?: 'created_at' | 'delivery_date';
+  order?: 'asc' | 'desc';
+  cursor?: string;
+  limit?: number;
+}
+
+export interface MessageListResponse {
+  messages: Message[];
+  next_cursor?: string;
+  total: number;
+  limit: number;
+}
+
+export interface MessageSearchResult {
+  message: Message;
+  rank: number;
+  title_snippet: string;
+  content_snippet: string;
+}
+
+export interface MessageSearchResponse {
+  query: string;
+  results: MessageSearchResult[];
+  limit: number;
+  offset: number;
+}
+
+export interface MessageSearchParams {
+  status?: string;
+  from?: string;
+  to?: string;
+  limit?: number;
+  offset?: number;
 }
 
 export interface ApiError {
@@ -101,6 +369,7 @@ export interface AnalyticsSummary {
   upcoming?: UpcomingSummary;
   recent_messages: MessageOverview[];
   attachment_count: number;
+  tags: TagCount[];
 }
 
 export interface UpcomingSummary {